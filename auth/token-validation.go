@@ -130,7 +130,7 @@ func ExtractTokenMetadata(r *http.Request) (ValidationPair, error) {
 }
 
 // Verify that claimed authentication details are stored in database, if so return stored username, token, and ok=true
-func AuthenticateWithDatabase(authD ValidationPair, userDB rdb.Database) (username string, token string, err error) {
+func AuthenticateWithDatabase(authD ValidationPair, userDB rdb.InteractiveDB) (username string, token string, err error) {
 	// Get user with claimed token
 	dbuser, userFound, getUserErr := schema.GetUserFromDB(authD.Token, userDB)
 	if getUserErr != nil {
@@ -153,7 +153,7 @@ func AuthenticateWithDatabase(authD ValidationPair, userDB rdb.Database) (userna
 }
 
 // Extract token metadata and check claimed token against database
-func ValidateUserToken(r *http.Request, userDB rdb.Database) (username string, token string, err error) {
+func ValidateUserToken(r *http.Request, userDB rdb.InteractiveDB) (username string, token string, err error) {
 	// Extract metadata & validate
 	tokenAuth, err := ExtractTokenMetadata(r)
 	tokenAuthJsonString, tokenAuthJsonStringErr := responses.JSON(tokenAuth)
@@ -175,7 +175,7 @@ func ValidateUserToken(r *http.Request, userDB rdb.Database) (username string, t
 }
 
 // Generates a middleware function for handling token validation on secure routes
-func GenerateTokenValidationMiddlewareFunc(userDB rdb.Database) func(http.Handler) http.Handler {
+func GenerateTokenValidationMiddlewareFunc(userDB rdb.InteractiveDB) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			log.Debug.Println(log.Yellow("-- GenerateTokenValidationMiddlewareFunc --"))
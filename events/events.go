@@ -0,0 +1,161 @@
+// Package events provides a broker publishing game events to players subscribed on any server, relaying them between servers through the database
+package events
+
+import (
+	"encoding/json"
+	"sync"
+
+	"apricate/log"
+	"apricate/rdb"
+	"apricate/schema"
+)
+
+// Events buffered per subscriber before new events are dropped for a slow client
+const SubscriberBufferSize int = 64
+
+// Channel events are relayed on between servers
+const RelayChannel string = "Events"
+
+// Defines a function also sent every published event, such as webhook delivery
+type Forwarder func(username string, event schema.Event)
+
+// Defines an event as relayed between servers
+type relayedEvent struct {
+	Username string `json:"username"`
+	Event schema.Event `json:"event"`
+}
+
+// Defines a broker fanning out events to every subscription of a user.
+// Once relaying, published events reach the subscriptions on every server relaying through the same database
+type Broker struct {
+	mu sync.Mutex
+	subscribers map[string]map[chan schema.Event]bool
+	forwarder Forwarder
+	relay rdb.InteractiveDB // nil if not relaying, events then only reach subscriptions on this server
+}
+
+func NewBroker() *Broker {
+	return &Broker{
+		subscribers: make(map[string]map[chan schema.Event]bool),
+	}
+}
+
+// Subscribe to events for username, the channel must be released with Unsubscribe
+func (b *Broker) Subscribe(username string) chan schema.Event {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	ch := make(chan schema.Event, SubscriberBufferSize)
+	if _, ok := b.subscribers[username]; !ok {
+		b.subscribers[username] = make(map[chan schema.Event]bool)
+	}
+	b.subscribers[username][ch] = true
+	log.Debug.Printf("Subscribed to events for %s, %d subscriptions", username, len(b.subscribers[username]))
+	return ch
+}
+
+// Release a subscription and close its channel
+func (b *Broker) Unsubscribe(username string, ch chan schema.Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if _, ok := b.subscribers[username][ch]; !ok {
+		return
+	}
+	delete(b.subscribers[username], ch)
+	if len(b.subscribers[username]) == 0 {
+		delete(b.subscribers, username)
+	}
+	close(ch)
+}
+
+// Set the forwarder sent every published event, replacing any previous forwarder
+func (b *Broker) SetForwarder(forwarder Forwarder) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.forwarder = forwarder
+}
+
+// Relay published events through db until stop is closed, replacing any previous relay.
+// Events published by any server relaying through db are sent to the subscriptions on this server
+func (b *Broker) Relay(db rdb.InteractiveDB, stop <-chan struct{}) error {
+	messages, err := db.Subscribe(RelayChannel, stop)
+	if err != nil {
+		return err
+	}
+	b.mu.Lock()
+	b.relay = db
+	b.mu.Unlock()
+	go func() {
+		for message := range messages {
+			var relayed relayedEvent
+			if unmarshalErr := json.Unmarshal(message, &relayed); unmarshalErr != nil {
+				log.Error.Printf("Could not unmarshal relayed event: %v", unmarshalErr)
+				continue
+			}
+			b.mu.Lock()
+			b.notify(relayed.Username, relayed.Event)
+			b.mu.Unlock()
+		}
+		// Stopped, publish to this server's subscriptions directly unless relaying again
+		b.mu.Lock()
+		if b.relay == db {
+			b.relay = nil
+		}
+		b.mu.Unlock()
+	}()
+	return nil
+}
+
+// Get the usernames with at least one subscription on this broker
+func (b *Broker) Subscribers() []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	usernames := make([]string, 0, len(b.subscribers))
+	for username := range b.subscribers {
+		usernames = append(usernames, username)
+	}
+	return usernames
+}
+
+// Send event to every subscription of username without blocking, dropping it for subscribers whose buffer is full.
+// The event is relayed to every server if relaying, and sent to the forwarder by this server only
+func (b *Broker) Publish(username string, event schema.Event) {
+	b.mu.Lock()
+	relay, forwarder := b.relay, b.forwarder
+	if relay == nil {
+		b.notify(username, event)
+	}
+	b.mu.Unlock()
+	if relay != nil {
+		message, relayErr := json.Marshal(relayedEvent{Username: username, Event: event})
+		if relayErr == nil {
+			relayErr = relay.Publish(RelayChannel, message)
+		}
+		if relayErr != nil {
+			log.Error.Printf("Could not relay %s event for %s, sending to this server only: %v", event.Type, username, relayErr)
+			b.mu.Lock()
+			b.notify(username, event)
+			b.mu.Unlock()
+		}
+	}
+	if forwarder != nil {
+		forwarder(username, event)
+	}
+}
+
+func (b *Broker) notify(username string, event schema.Event) {
+	for ch := range b.subscribers[username] {
+		select {
+		case ch <- event:
+		default:
+			log.Important.Printf("Dropped %s event for %s, subscriber buffer full", event.Type, username)
+		}
+	}
+}
+
+// Broker used by handlers on this server
+var Default = NewBroker()
+
+// Publish event to username on the default broker
+func Publish(username string, event schema.Event) {
+	Default.Publish(username, event)
+}
@@ -0,0 +1,88 @@
+package events
+
+import (
+	"testing"
+	"time"
+
+	"apricate/rdb"
+	"apricate/schema"
+)
+
+// Wait for the next event on ch
+func awaitEvent(t *testing.T, ch chan schema.Event) schema.Event {
+	t.Helper()
+	select {
+	case event := <-ch:
+		return event
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timed out waiting for event")
+	}
+	return schema.Event{}
+}
+
+// Events published on one server reach subscriptions on every server relaying through the same database, and are forwarded once
+func TestRelay(t *testing.T) {
+	store := rdb.NewMemoryStore()
+	stop := make(chan struct{})
+	defer close(stop)
+	servers := []*Broker{NewBroker(), NewBroker()}
+	forwarded := make([]int, len(servers))
+	subscriptions := make([]chan schema.Event, len(servers))
+	for i, broker := range servers {
+		i := i
+		if err := broker.Relay(rdb.NewMemoryDatabase(store, i), stop); err != nil {
+			t.Fatalf("could not relay: %v", err)
+		}
+		broker.SetForwarder(func(username string, event schema.Event) { forwarded[i]++ })
+		subscriptions[i] = broker.Subscribe("Farmer")
+	}
+	other := servers[1].Subscribe("Other")
+
+	servers[0].Publish("Farmer", *schema.NewEvent(schema.Event_PlotInteracted, 1, "Plot-0", nil))
+	for i, subscription := range subscriptions {
+		if event := awaitEvent(t, subscription); event.Subject != "Plot-0" {
+			t.Fatalf("expected server %d to receive the Plot-0 event, got %+v", i, event)
+		}
+	}
+	if forwarded[0] != 1 || forwarded[1] != 0 {
+		t.Fatalf("expected the event forwarded by the publishing server only, got %v", forwarded)
+	}
+	select {
+	case event := <-other:
+		t.Fatalf("expected no event for another user, got %+v", event)
+	default:
+	}
+}
+
+// Without a relay, or once it stops, events reach this server's subscriptions directly
+func TestPublishWithoutRelay(t *testing.T) {
+	broker := NewBroker()
+	subscription := broker.Subscribe("Farmer")
+	broker.Publish("Farmer", *schema.NewEvent(schema.Event_PlotInteracted, 1, "Plot-0", nil))
+	if event := awaitEvent(t, subscription); event.Subject != "Plot-0" {
+		t.Fatalf("expected the Plot-0 event, got %+v", event)
+	}
+
+	stop := make(chan struct{})
+	if err := broker.Relay(rdb.NewMemoryDatabase(rdb.NewMemoryStore(), 0), stop); err != nil {
+		t.Fatalf("could not relay: %v", err)
+	}
+	close(stop)
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		broker.mu.Lock()
+		relaying := broker.relay != nil
+		broker.mu.Unlock()
+		if !relaying {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected relay released once stopped")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	broker.Publish("Farmer", *schema.NewEvent(schema.Event_PlotInteracted, 2, "Plot-1", nil))
+	if event := awaitEvent(t, subscription); event.Subject != "Plot-1" {
+		t.Fatalf("expected the Plot-1 event, got %+v", event)
+	}
+}
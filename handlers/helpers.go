@@ -3,15 +3,20 @@ package handlers
 
 import (
 	"apricate/auth"
+	"apricate/events"
 	"apricate/log"
 	"apricate/metrics"
 	"apricate/rdb"
 	"apricate/responses"
 	"apricate/schema"
+	"bytes"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/gorilla/mux"
 )
@@ -83,7 +88,7 @@ func GetValidationFromCtx(r *http.Request) (auth.ValidationPair, error) {
 
 // Get User from Middleware and DB
 // Returns: OK, userData, userAuthPair
-func secureGetUser(w http.ResponseWriter, r *http.Request, udb rdb.Database) (bool, schema.User, auth.ValidationPair) {
+func secureGetUser(w http.ResponseWriter, r *http.Request, udb rdb.InteractiveDB) (bool, schema.User, auth.ValidationPair) {
 	// Get userinfoContext from validation middleware
 	userInfo, userInfoErr := GetValidationFromCtx(r)
 	if userInfoErr != nil {
@@ -110,4 +115,325 @@ func secureGetUser(w http.ResponseWriter, r *http.Request, udb rdb.Database) (bo
 	}
 
 	return true, thisUser, userInfo
-}
\ No newline at end of file
+}
+
+// Get Market with live prices from clearinghouse DB in place of base prices
+// Returns: OK, liveMarket
+func getLiveMarket(w http.ResponseWriter, cdb rdb.InteractiveDB, now time.Time, market schema.Market) (bool, schema.Market) {
+	pricesOk, marketPrices := getMarketPrices(w, cdb, now, market.LocationSymbol)
+	if !pricesOk {
+		return false, schema.Market{}
+	}
+	return true, marketPrices.PriceMarket(market)
+}
+
+// Get the live price state of a market with drift applied up to now
+// Returns: OK, marketPrices
+func getMarketPrices(w http.ResponseWriter, cdb rdb.InteractiveDB, now time.Time, locationSymbol string) (bool, schema.MarketPrices) {
+	marketPrices, pricesErr := schema.GetMarketPricesFromDB(locationSymbol, now, cdb)
+	if pricesErr != nil {
+		// fail state
+		getErrorMsg := fmt.Sprintf("in getMarketPrices, could not get market prices from DB for market: %s, error: %v", locationSymbol, pricesErr)
+		log.Error.Printf(getErrorMsg)
+		responses.SendRes(w, responses.DB_Get_Failure, nil, getErrorMsg)
+		return false, schema.MarketPrices{}
+	}
+	return true, marketPrices
+}
+
+// Get the current contract board for a location, generating and saving a new one if missing or expired
+// Returns: OK, contractBoard
+func getContractBoard(w http.ResponseWriter, cdb rdb.InteractiveDB, now time.Time, world *schema.World, markets map[string]schema.Market, locationSymbol string) (bool, schema.ContractBoard) {
+	board, foundBoard, boardErr := schema.GetContractBoardFromDB(locationSymbol, cdb)
+	if boardErr != nil {
+		// fail state
+		getErrorMsg := fmt.Sprintf("in getContractBoard, could not get contract board from DB for location: %s, error: %v", locationSymbol, boardErr)
+		log.Error.Printf(getErrorMsg)
+		responses.SendRes(w, responses.DB_Get_Failure, nil, getErrorMsg)
+		return false, schema.ContractBoard{}
+	}
+	if !foundBoard || board.Rotation != schema.ContractBoardRotation(now) {
+		// rotate board
+		board = *schema.GenerateContractBoard(*world, markets, locationSymbol, now)
+		saveBoardErr := schema.SaveContractBoardToDB(cdb, &board)
+		if saveBoardErr != nil {
+			saveErrorMsg := fmt.Sprintf("in getContractBoard, could not save contract board to DB for location: %s, error: %v", locationSymbol, saveBoardErr)
+			log.Error.Printf(saveErrorMsg)
+			responses.SendRes(w, responses.DB_Save_Failure, nil, saveErrorMsg)
+			return false, schema.ContractBoard{}
+		}
+	}
+	return true, board
+}
+
+// Attempts for state-mutating handlers before giving up on transaction conflicts
+const TransactionAttempts int = 5
+
+// Get the locations of markets visible through the survey offices of the user's farms
+func getSurveyedLocations(w http.ResponseWriter, fdb rdb.InteractiveDB, userData schema.User, dictionary *schema.MainDictionary, now time.Time) (bool, map[string]bool) {
+	surveyed := make(map[string]bool)
+	farms, foundFarms, farmsErr := schema.GetFarmsFromDB(userData.Farms, fdb)
+	if farmsErr != nil {
+		log.Error.Printf("Error in getSurveyedLocations, could not get farms from DB. foundFarms: %v, error: %v", foundFarms, farmsErr)
+		responses.SendRes(w, responses.DB_Get_Failure, nil, farmsErr.Error())
+		return false, surveyed
+	}
+	for _, farm := range farms {
+		farm.CompleteConstruction(now.Unix())
+		scope := schema.GetFarmEffects(farm.Buildings, dictionary.Buildings).SurveyScope
+		for loc := range dictionary.Markets {
+			if scope.Reaches(farm.LocationSymbol, loc) {
+				surveyed[loc] = true
+			}
+		}
+	}
+	return true, surveyed
+}
+
+// Get the capacity of the user's warehouse at locationSymbol, from the location and the buildings of the user's farm there
+func getWarehouseCapacity(w http.ResponseWriter, fdb rdb.InteractiveDB, userData schema.User, dictionary *schema.MainDictionary, world *schema.World, locationSymbol string, now time.Time) (bool, uint64) {
+	var effects schema.FarmEffects
+	farmUUID := userData.Username + "|Farm-" + locationSymbol
+	if stringInSlice(farmUUID, userData.Farms) {
+		farm, foundFarm, farmErr := schema.GetFarmFromDB(farmUUID, fdb)
+		if farmErr != nil || !foundFarm {
+			errmsg := fmt.Sprintf("Error in getWarehouseCapacity, could not get farm from DB. foundFarm: %v, error: %v", foundFarm, farmErr)
+			log.Error.Printf(errmsg)
+			responses.SendRes(w, responses.DB_Get_Failure, nil, errmsg)
+			return false, 0
+		}
+		farm.CompleteConstruction(now.Unix())
+		effects = schema.GetFarmEffects(farm.Buildings, dictionary.Buildings)
+	}
+	return true, schema.WarehouseCapacity(world.Locations[locationSymbol], effects)
+}
+
+// Get the user's farm at locationSymbol and the warehouse there
+// Returns: OK, farm, warehouse; failure responses already sent to w
+func getFarmAndWarehouse(w http.ResponseWriter, dbs *map[string]rdb.InteractiveDB, username string, locationSymbol string) (bool, schema.Farm, schema.Warehouse) {
+	farm, foundFarm, farmsErr := schema.GetFarmFromDB(username + "|Farm-" + locationSymbol, (*dbs)["farms"])
+	if farmsErr != nil {
+		errmsg := fmt.Sprintf("in getFarmAndWarehouse, could not get farm from DB. foundFarm: %v, error: %v", foundFarm, farmsErr)
+		log.Error.Printf(errmsg)
+		responses.SendRes(w, responses.DB_Get_Failure, nil, errmsg)
+		return false, schema.Farm{}, schema.Warehouse{}
+	}
+	if !foundFarm {
+		errmsg := fmt.Sprintf("in getFarmAndWarehouse, no farm at %s", locationSymbol)
+		log.Debug.Printf(errmsg)
+		responses.SendRes(w, responses.Object_Not_Found, nil, errmsg)
+		return false, schema.Farm{}, schema.Warehouse{}
+	}
+	warehouse, foundWarehouse, warehousesErr := schema.GetWarehouseFromDB(username + "|Warehouse-" + locationSymbol, (*dbs)["warehouses"])
+	if warehousesErr != nil || !foundWarehouse {
+		errmsg := fmt.Sprintf("in getFarmAndWarehouse, could not get warehouse from DB. foundWarehouse: %v, error: %v", foundWarehouse, warehousesErr)
+		log.Error.Printf(errmsg)
+		responses.SendRes(w, responses.DB_Get_Failure, nil, errmsg)
+		return false, schema.Farm{}, schema.Warehouse{}
+	}
+	return true, farm, warehouse
+}
+
+// Quote the route given priority between origin and destination for a team at slowestSpeed carrying carryCap, departing now.
+// username is empty for previews quoted without a team
+// Returns: OK, route; failure responses already sent to w
+func quoteRoute(w http.ResponseWriter, world *schema.World, username string, now time.Time, assistants []int64, slowestSpeed int, carryCap uint64, origin string, destination string, priority schema.RoutePriority) (bool, *schema.Route) {
+	validationMap, plan := schema.PlanCaravanRoute(*world, origin, destination, slowestSpeed, priority)
+	if len(validationMap) > 0 {
+		log.Debug.Printf("in quoteRoute, route could not be planned: %v", validationMap)
+		responses.SendRes(w, responses.Bad_Request, validationMap, "Route could not be planned, see data for specifics.")
+		return false, nil
+	}
+	return true, schema.NewRoute(username, now, assistants, slowestSpeed, carryCap, plan, origin, destination)
+}
+
+// Run op on each of the farm's plots listed by a batch, passing its index in the batch, recording each plot's result from the response op sends to its own writer.
+// op must leave the farm unchanged when it fails, so failed plots do not affect the rest of the batch
+func runPlotBatch(w http.ResponseWriter, farm *schema.Farm, plotIDs []string, op func(w http.ResponseWriter, i int, uuid string) (bool, *schema.Plot, *schema.GrowthStage)) []schema.PlotBatchResult {
+	results := make([]schema.PlotBatchResult, len(plotIDs))
+	for i, plotID := range plotIDs {
+		uuid := farm.PlotUUID(plotID)
+		if _, foundPlot := farm.Plots[uuid]; !foundPlot {
+			results[i] = schema.PlotBatchResult{PlotID: plotID, Code: responses.Object_Not_Found, Message: fmt.Sprintf("plot not found: %s", uuid)}
+			continue
+		}
+		res := newBufferedResponseWriter()
+		OK, plot, nextStage := op(res, i, uuid)
+		if OK {
+			res.requeue(w)
+			results[i] = schema.PlotBatchResult{PlotID: plotID, Code: responses.Generic_Success, Plot: plot, NextStage: nextStage}
+			continue
+		}
+		failure, failureErr := res.response()
+		if failureErr != nil {
+			log.Error.Printf("in runPlotBatch, could not decode failure response for plot %s. error: %v", uuid, failureErr)
+			failure = responses.Response{Code: responses.Internal_Server_Error}
+		}
+		results[i] = schema.PlotBatchResult{PlotID: plotID, Code: failure.Code, Message: failure.Message}
+	}
+	return results
+}
+
+// Save the farm's plots and warehouse after a batch, then send the batch response
+func sendPlotBatch(w http.ResponseWriter, dbs *map[string]rdb.InteractiveDB, farm schema.Farm, warehouse schema.Warehouse, results []schema.PlotBatchResult) {
+	saveFarmErr := schema.SaveFarmDataAtPathToDB((*dbs)["farms"], farm.UUID, "plots", farm.Plots)
+	if saveFarmErr != nil {
+		log.Error.Printf("Error in sendPlotBatch, could not save farm. error: %v", saveFarmErr)
+		responses.SendRes(w, responses.DB_Save_Failure, nil, saveFarmErr.Error())
+		return
+	}
+	saveWarehouseErr := schema.SaveWarehouseToDB((*dbs)["warehouses"], &warehouse)
+	if saveWarehouseErr != nil {
+		log.Error.Printf("Error in sendPlotBatch, could not save warehouse. error: %v", saveWarehouseErr)
+		responses.SendRes(w, responses.DB_Save_Failure, nil, saveWarehouseErr.Error())
+		return
+	}
+	succeeded := 0
+	for _, result := range results {
+		if result.Code == responses.Generic_Success {
+			succeeded++
+		}
+	}
+	response := schema.PlotBatchResponse{Warehouse: &warehouse, Results: results}
+	responses.SendRes(w, responses.Generic_Success, response, fmt.Sprintf("%d of %d plots succeeded", succeeded, len(results)))
+}
+
+// Buffers a handler response so it can be discarded if the transaction must retry
+type bufferedResponseWriter struct {
+	header http.Header
+	status int
+	body bytes.Buffer
+	events []queuedEvent // published only once the transaction commits
+	metrics []func() // tracked only once the transaction commits
+}
+
+// Defines an event waiting on a transaction to commit
+type queuedEvent struct {
+	username string
+	event schema.Event
+}
+
+func newBufferedResponseWriter() *bufferedResponseWriter {
+	return &bufferedResponseWriter{header: make(http.Header)}
+}
+
+func (b *bufferedResponseWriter) Header() http.Header {
+	return b.header
+}
+
+func (b *bufferedResponseWriter) WriteHeader(status int) {
+	if b.status == 0 {
+		b.status = status
+	}
+}
+
+func (b *bufferedResponseWriter) Write(data []byte) (int, error) {
+	if b.status == 0 {
+		b.status = http.StatusOK
+	}
+	return b.body.Write(data)
+}
+
+// Copy buffered response to the real response writer
+func (b *bufferedResponseWriter) flush(w http.ResponseWriter) {
+	for key, values := range b.header {
+		w.Header()[key] = values
+	}
+	if b.status == 0 {
+		b.status = http.StatusOK
+	}
+	w.WriteHeader(b.status)
+	w.Write(b.body.Bytes())
+}
+
+// Decode the buffered response, such as to record why a scheduled action failed
+func (b *bufferedResponseWriter) response() (responses.Response, error) {
+	var res responses.Response
+	err := json.Unmarshal(b.body.Bytes(), &res)
+	return res, err
+}
+
+// Queue event for username to be published once the handler's transaction commits, or publish now if not in a transaction
+func queueEvent(w http.ResponseWriter, username string, event *schema.Event) {
+	if res, ok := w.(*bufferedResponseWriter); ok {
+		res.events = append(res.events, queuedEvent{username: username, event: *event})
+		return
+	}
+	events.Publish(username, *event)
+}
+
+// Queue track to update in-memory metrics once the handler's transaction commits, or track now if not in a transaction.
+// Attempts retried after a conflict, or aborted, are then never counted
+func queueMetric(w http.ResponseWriter, track func()) {
+	if res, ok := w.(*bufferedResponseWriter); ok {
+		res.metrics = append(res.metrics, track)
+		return
+	}
+	track()
+}
+
+// Queue tracking the user's coins as saved by the handler's transaction
+func queueCoinsMetric(w http.ResponseWriter, userData *schema.User) {
+	username, coins := userData.Username, userData.Ledger.Currencies["Coins"]
+	queueMetric(w, func() { schema.TrackUserCoins(username, coins) })
+}
+
+// Queue the events and metrics queued on b onto w, such as when b buffered one operation of a batch that succeeded
+func (b *bufferedResponseWriter) requeue(w http.ResponseWriter) {
+	for _, track := range b.metrics {
+		queueMetric(w, track)
+	}
+	for _, queued := range b.events {
+		queuedEvent := queued.event
+		queueEvent(w, queued.username, &queuedEvent)
+	}
+}
+
+// Publish queued events and track queued metrics of a committed transaction
+func (b *bufferedResponseWriter) committed() {
+	for _, track := range b.metrics {
+		track()
+	}
+	for _, queued := range b.events {
+		events.Publish(queued.username, queued.event)
+	}
+}
+
+// Run a state-mutating handler inside an optimistic transaction over all dbs, retrying on conflict.
+// All reads are watched and all writes are applied together, only if the handler responds with success
+func secureTransact(w http.ResponseWriter, r *http.Request, dbs *map[string]rdb.InteractiveDB, fn func(w http.ResponseWriter, r *http.Request, dbs *map[string]rdb.InteractiveDB)) {
+	// Body can only be read once, so keep it for retries
+	body, readErr := io.ReadAll(r.Body)
+	if readErr != nil {
+		log.Debug.Printf("in secureTransact, could not read request body: %v", readErr)
+		responses.SendRes(w, responses.Bad_Request, nil, "Could not read request body")
+		return
+	}
+	var res *bufferedResponseWriter
+	txErr := (*dbs)["users"].Transact(TransactionAttempts, func(tx rdb.Tx) error {
+		res = newBufferedResponseWriter()
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		txDbs := tx.BindAll(*dbs)
+		fn(res, r, &txDbs)
+		if res.status >= http.StatusMultipleChoices {
+			// Failure response, discard any buffered writes
+			return rdb.ErrTxAborted
+		}
+		return nil
+	})
+	if txErr == rdb.ErrTxConflict {
+		log.Important.Printf("in secureTransact, gave up after %d conflicting attempts for %s", TransactionAttempts, r.URL.Path)
+		responses.SendRes(w, responses.Transaction_Conflict, nil, "")
+		return
+	}
+	if txErr != nil && txErr != rdb.ErrTxAborted {
+		errmsg := fmt.Sprintf("in secureTransact, could not execute transaction: %v", txErr)
+		log.Error.Printf(errmsg)
+		responses.SendRes(w, responses.DB_Save_Failure, nil, errmsg)
+		return
+	}
+	if txErr == nil {
+		res.committed()
+	}
+	res.flush(w)
+}
@@ -12,6 +12,7 @@ import (
 	"apricate/rdb"
 	"apricate/responses"
 	"apricate/schema"
+	"apricate/timecalc"
 	"apricate/tokengen"
 )
 
@@ -77,6 +78,7 @@ func AboutMagic(w http.ResponseWriter, r *http.Request) {
 			"Arcane Flux": "Arcane Flux is a measure of magic power available to a given mage. Rituals may add or remove various amounts of flux. Arcane Flux is bounded between 1 and 1 billion inclusive.",
 			"Distortion (Tier)": "Distortion, or Distortion Tier is a metric for the power level of a given mage or spell. It is equal to Log10(flux). Distortion is bounded between 0 and 9 inclusive.",
 			"Lattice Interference Rejection": "Casting rituals causes interference in the Lattice that prevents the mage from further magic for a given time depending on the ritual.",
+			"Rite Effects": "Rites may list effects applied in order when the ritual is conducted at a farm: summoning an assistant, hastening the growth of the farm's plots, adding yield to its planted plants, granting wares to its warehouse (together with the rite's materials this converts goods), or granting the farm a temporary bonus.",
 		},
 	}
 	responses.SendRes(w, responses.Generic_Success, res, "")
@@ -168,6 +170,40 @@ func (h *IslandOverview) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	log.Debug.Println(log.Cyan("-- End IslandOverview --"))
 }
 
+// Handler function for the route: /api/routes/{origin}/{destination}
+// Previews the fastest and cheapest caravan routes between two locations at the optional speed query param, which defaults to the slowest base assistant speed.
+// Each is quoted as by RouteQuote, without a team
+type RoutesOverview struct {
+	World *schema.World
+	Clock timecalc.Clock
+}
+func (h *RoutesOverview) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	log.Debug.Println(log.Yellow("-- RoutesOverview --"))
+	origin := GetVarEntries(r, "origin", AllCaps)
+	destination := GetVarEntries(r, "destination", AllCaps)
+	speed := 1
+	if speedParam := r.URL.Query().Get("speed"); speedParam != "" {
+		parsedSpeed, parseErr := strconv.Atoi(speedParam)
+		if parseErr != nil || parsedSpeed < 1 {
+			log.Debug.Printf("in RoutesOverview, invalid speed %s", speedParam)
+			responses.SendRes(w, responses.Bad_Request, nil, "speed must be a whole number of at least 1")
+			return
+		}
+		speed = parsedSpeed
+	}
+	log.Debug.Printf("Routes Overview From: %s To: %s At Speed: %d", origin, destination, speed)
+	now := h.Clock.Now()
+	OK, fastest := quoteRoute(w, h.World, "", now, []int64{}, speed, 0, origin, destination, schema.Route_Fastest)
+	if !OK {
+		log.Debug.Println(log.Cyan("-- End RoutesOverview --"))
+		return
+	}
+	_, cheapest := quoteRoute(w, h.World, "", now, []int64{}, speed, 0, origin, destination, schema.Route_Cheapest)
+	res := map[string]*schema.Route{"fastest": fastest, "cheapest": cheapest}
+	responses.SendRes(w, responses.Generic_Success, res, "")
+	log.Debug.Println(log.Cyan("-- End RoutesOverview --"))
+}
+
 // Handler function for the route: /api/regions
 type RegionsOverview struct {
 	World *schema.World
@@ -200,7 +236,7 @@ func (h *RegionOverview) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 
 // Handler function for the route: /api/users/{username}
 type UsernameInfo struct {
-	Dbs *map[string]rdb.Database
+	Dbs *map[string]rdb.InteractiveDB
 }
 func (h *UsernameInfo) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	log.Debug.Println(log.Yellow("-- usernameInfo --"))
@@ -247,8 +283,9 @@ func (h *UsernameInfo) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 
 // Handler function for the route: /api/users/{username}/claim
 type UsernameClaim struct {
-	Dbs *map[string]rdb.Database
+	Dbs *map[string]rdb.InteractiveDB
 	SlurFilter *[]string
+	Clock timecalc.Clock
 }
 func (h *UsernameClaim) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	log.Debug.Println(log.Yellow("-- usernameClaim --"))
@@ -292,7 +329,7 @@ func (h *UsernameClaim) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	// create new user in DB
-	newUser := schema.NewUser(token, username, *h.Dbs, false)
+	newUser := schema.NewUser(token, username, h.Clock.Now(), *h.Dbs, false)
 	saveUserErr := schema.SaveUserToDB(udb, newUser)
 	if saveUserErr != nil {
 		// fail state - could not save
@@ -304,6 +341,7 @@ func (h *UsernameClaim) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	// Created successfully
 	// Track in user metrics
 	metrics.TrackNewUser(username)
+	queueCoinsMetric(w, newUser)
 	log.Debug.Printf("Generated token %s and claimed username %s", token, username)
 	responses.SendRes(w, responses.Generic_Success, newUser, "")
 	log.Debug.Println(log.Cyan("-- End usernameClaim --"))
@@ -402,4 +440,93 @@ func (h *RiteOverview) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		responses.SendRes(w, responses.Specified_Rite_Not_Found, nil, "")
 	}
 	log.Debug.Println(log.Cyan("-- End RiteOverview --"))
-}
\ No newline at end of file
+}
+// Handler function for the route: /api/buildings
+type BuildingsOverview struct {
+	MainDictionary *schema.MainDictionary
+}
+func (h *BuildingsOverview) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	log.Debug.Println(log.Yellow("-- BuildingsOverview --"))
+	res := h.MainDictionary.Buildings
+	responses.SendRes(w, responses.Generic_Success, res, "")
+	log.Debug.Println(log.Cyan("-- End BuildingsOverview --"))
+}
+
+// Handler function for the route: /api/buildings/{building-name}
+type BuildingOverview struct {
+	MainDictionary *schema.MainDictionary
+}
+func (h *BuildingOverview) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	log.Debug.Println(log.Yellow("-- BuildingOverview --"))
+	// Get building name from route
+	building_name := GetVarEntries(r, "building-name", SpacedName)
+	log.Debug.Printf("BuildingOverview Requested for: %s", building_name)
+	// Get building
+	if building, ok := (*h.MainDictionary).Buildings[building_name]; ok {
+		res := building
+		responses.SendRes(w, responses.Generic_Success, res, "")
+	} else {
+		responses.SendRes(w, responses.Specified_Building_Not_Found, nil, "")
+	}
+	log.Debug.Println(log.Cyan("-- End BuildingOverview --"))
+}
+
+// Handler function for the route: /api/recipes
+type RecipesOverview struct {
+	MainDictionary *schema.MainDictionary
+}
+func (h *RecipesOverview) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	log.Debug.Println(log.Yellow("-- RecipesOverview --"))
+	res := h.MainDictionary.Recipes
+	responses.SendRes(w, responses.Generic_Success, res, "")
+	log.Debug.Println(log.Cyan("-- End RecipesOverview --"))
+}
+
+// Handler function for the route: /api/recipes/{recipe-name}
+type RecipeOverview struct {
+	MainDictionary *schema.MainDictionary
+}
+func (h *RecipeOverview) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	log.Debug.Println(log.Yellow("-- RecipeOverview --"))
+	// Get recipe name from route
+	recipe_name := GetVarEntries(r, "recipe-name", UnderscoresToSpaces)
+	log.Debug.Printf("RecipeOverview Requested for: %s", recipe_name)
+	// Get recipe
+	if recipe, ok := h.MainDictionary.GetRecipe(recipe_name); ok {
+		res := recipe
+		responses.SendRes(w, responses.Generic_Success, res, "")
+	} else {
+		responses.SendRes(w, responses.Specified_Recipe_Not_Found, nil, "")
+	}
+	log.Debug.Println(log.Cyan("-- End RecipeOverview --"))
+}
+
+// Handler function for the route: /api/improvements
+type ImprovementsOverview struct {
+	MainDictionary *schema.MainDictionary
+}
+func (h *ImprovementsOverview) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	log.Debug.Println(log.Yellow("-- ImprovementsOverview --"))
+	res := h.MainDictionary.Improvements
+	responses.SendRes(w, responses.Generic_Success, res, "")
+	log.Debug.Println(log.Cyan("-- End ImprovementsOverview --"))
+}
+
+// Handler function for the route: /api/improvements/{improvement-name}
+type ImprovementOverview struct {
+	MainDictionary *schema.MainDictionary
+}
+func (h *ImprovementOverview) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	log.Debug.Println(log.Yellow("-- ImprovementOverview --"))
+	// Get improvement name from route
+	improvement_name := GetVarEntries(r, "improvement-name", SpacedName)
+	log.Debug.Printf("ImprovementOverview Requested for: %s", improvement_name)
+	// Get improvement
+	if improvement, ok := h.MainDictionary.Improvements[improvement_name]; ok {
+		res := improvement
+		responses.SendRes(w, responses.Generic_Success, res, "")
+	} else {
+		responses.SendRes(w, responses.Specified_Improvement_Not_Found, nil, "")
+	}
+	log.Debug.Println(log.Cyan("-- End ImprovementOverview --"))
+}
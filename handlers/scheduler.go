@@ -0,0 +1,345 @@
+// Package handlers provides functions for handling web routes
+package handlers
+
+import (
+	"apricate/events"
+	"apricate/log"
+	"apricate/rdb"
+	"apricate/schema"
+	"apricate/timecalc"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Interval between scans for arrived caravans to auto unpack
+var CaravanSchedulerInterval = 5 * time.Second
+
+// Interval between scans for plots ready for their next queued action
+var PlotSchedulerInterval = 5 * time.Second
+
+// Call scan every interval until stop is closed
+func runScheduler(interval time.Duration, stop <-chan struct{}, scan func() int) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			scan()
+		}
+	}
+}
+
+// Run fn in an optimistic transaction over all dbs on behalf of a scheduler, publishing queued events and tracking queued metrics once it commits.
+// fn returns rdb.ErrTxAborted to discard its writes, such as when another server got there first
+// Returns: committed
+func scheduledTransact(dbs *map[string]rdb.InteractiveDB, subject string, fn func(res *bufferedResponseWriter, txDbs *map[string]rdb.InteractiveDB) error) bool {
+	var res *bufferedResponseWriter
+	txErr := (*dbs)["users"].Transact(TransactionAttempts, func(tx rdb.Tx) error {
+		res = newBufferedResponseWriter()
+		txDbs := tx.BindAll(*dbs)
+		return fn(res, &txDbs)
+	})
+	if txErr == rdb.ErrTxConflict {
+		log.Important.Printf("in scheduledTransact, gave up after %d conflicting attempts for %s", TransactionAttempts, subject)
+		return false
+	}
+	if txErr != nil {
+		if txErr != rdb.ErrTxAborted {
+			log.Error.Printf("in scheduledTransact, could not execute transaction for %s: %v", subject, txErr)
+		}
+		return false
+	}
+	res.committed()
+	return true
+}
+
+// Defines a background scheduler unpacking arrived caravans chartered with auto_unpack.
+//
+// Each scan reads only arrived caravans from their arrival index. Each caravan is re-read and unpacked in its own transaction,
+// so several servers may scan the same DBs and each caravan is only unpacked once
+type CaravanScheduler struct {
+	Dbs *map[string]rdb.InteractiveDB
+	MainDictionary *schema.MainDictionary
+	World *schema.World
+	Clock timecalc.Clock
+}
+
+// Scan for arrived caravans every CaravanSchedulerInterval until stop is closed
+func (s *CaravanScheduler) Run(stop <-chan struct{}) {
+	runScheduler(CaravanSchedulerInterval, stop, s.UnpackArrived)
+}
+
+// Unpack every arrived caravan chartered with auto_unpack, returns the number unpacked by this call
+func (s *CaravanScheduler) UnpackArrived() int {
+	uuids, arrivedErr := schema.GetArrivedCaravanUUIDsFromDB(s.Clock.Now().Unix(), (*s.Dbs)["caravans"])
+	if arrivedErr != nil {
+		log.Error.Printf("Error in CaravanScheduler, could not list arrived caravans from DB. error: %v", arrivedErr)
+		return 0
+	}
+	unpacked := 0
+	for _, uuid := range uuids {
+		if s.unpack(uuid) {
+			unpacked++
+		}
+	}
+	return unpacked
+}
+
+// Unpack the caravan if still present, arrived, and chartered with auto_unpack, returns if unpacked
+func (s *CaravanScheduler) unpack(uuid string) bool {
+	unpacked := false
+	committed := scheduledTransact(s.Dbs, uuid, func(res *bufferedResponseWriter, txDbs *map[string]rdb.InteractiveDB) error {
+		unpacked = false
+		// Re-read the caravan in the transaction, another server may have unpacked it since the scan
+		caravan, foundCaravan, caravanErr := schema.GetCaravanFromDB(uuid, (*txDbs)["caravans"])
+		if caravanErr != nil {
+			log.Error.Printf("Error in CaravanScheduler, could not get caravan %s from DB. error: %v", uuid, caravanErr)
+			return rdb.ErrTxAborted
+		}
+		now := s.Clock.Now()
+		if !foundCaravan || !caravan.AutoUnpack {
+			// Drop the stale index entry
+			return (*txDbs)["caravans"].ZRem(schema.CaravanArrivalsKey, uuid)
+		}
+		if caravan.ArrivalTime > now.Unix() {
+			return rdb.ErrTxAborted
+		}
+		username := strings.Split(uuid, "|")[0]
+		userData, foundUser, userErr := schema.GetUserByUsernameFromDB(username, (*txDbs)["users"])
+		if userErr != nil || !foundUser {
+			log.Error.Printf("Error in CaravanScheduler, could not get user %s of caravan %s from DB. foundUser: %v, error: %v", username, uuid, foundUser, userErr)
+			return rdb.ErrTxAborted
+		}
+		if !unpackCaravan(res, txDbs, s.MainDictionary, s.World, now, userData, caravan) || res.status >= http.StatusMultipleChoices {
+			// Such as the warehouse lacking room, left for the player or a later scan
+			log.Debug.Printf("CaravanScheduler could not unpack caravan %s, status: %d", uuid, res.status)
+			return rdb.ErrTxAborted
+		}
+		log.Debug.Printf("CaravanScheduler unpacked caravan %s", uuid)
+		unpacked = true
+		return nil
+	})
+	return committed && unpacked
+}
+
+// Defines a background scheduler taking the actions queued on plots as soon as each plot is ready.
+//
+// A failed action pauses the plot's queue and is recorded on it. Each action is taken in its own transaction, so several servers may scan the same DBs
+type PlotScheduler struct {
+	Dbs *map[string]rdb.InteractiveDB
+	MainDictionary *schema.MainDictionary
+	World *schema.World
+	Clock timecalc.Clock
+	HarvestSeed int64
+}
+
+// Scan for plots ready for their next queued action every PlotSchedulerInterval until stop is closed
+func (s *PlotScheduler) Run(stop <-chan struct{}) {
+	runScheduler(PlotSchedulerInterval, stop, s.TakeReadyActions)
+}
+
+// Take every queued action plots are ready for, including actions ready straight after another, returns the number taken by this call
+func (s *PlotScheduler) TakeReadyActions() int {
+	fdb := (*s.Dbs)["farms"]
+	uuids, keysErr := fdb.Keys("*|Farm-*")
+	if keysErr != nil {
+		log.Error.Printf("Error in PlotScheduler, could not list farms from DB. error: %v", keysErr)
+		return 0
+	}
+	taken := 0
+	for _, farmUUID := range uuids {
+		farm, foundFarm, farmErr := schema.GetFarmFromDB(farmUUID, fdb)
+		if farmErr != nil || !foundFarm {
+			log.Error.Printf("Error in PlotScheduler, could not get farm %s from DB. foundFarm: %v, error: %v", farmUUID, foundFarm, farmErr)
+			continue
+		}
+		plotUUIDs := make([]string, 0)
+		for plotUUID, plot := range farm.Plots {
+			if plot.IsQueueReady(s.Clock.Now().Unix()) {
+				plotUUIDs = append(plotUUIDs, plotUUID)
+			}
+		}
+		sort.Strings(plotUUIDs)
+		for _, plotUUID := range plotUUIDs {
+			// each action removes itself from the queue, so this ends once the queue empties, pauses, or waits on growth
+			for s.takeAction(farmUUID, plotUUID) {
+				taken++
+			}
+		}
+	}
+	return taken
+}
+
+// Take the next queued action on the plot if it is ready, pausing the queue if the action fails
+// Returns if an action was taken
+func (s *PlotScheduler) takeAction(farmUUID string, plotUUID string) bool {
+	var action schema.PlotInteractBody
+	var failure *schema.PlotQueueFailure
+	taken := scheduledTransact(s.Dbs, plotUUID, func(res *bufferedResponseWriter, txDbs *map[string]rdb.InteractiveDB) error {
+		failure = nil
+		// Re-read the farm in the transaction, the player or another server may have changed the plot since the scan
+		farm, foundFarm, farmErr := schema.GetFarmFromDB(farmUUID, (*txDbs)["farms"])
+		if farmErr != nil || !foundFarm {
+			log.Error.Printf("Error in PlotScheduler, could not get farm %s from DB. foundFarm: %v, error: %v", farmUUID, foundFarm, farmErr)
+			return rdb.ErrTxAborted
+		}
+		plot, foundPlot := farm.Plots[plotUUID]
+		now := s.Clock.Now()
+		if !foundPlot || !plot.IsQueueReady(now.Unix()) {
+			return rdb.ErrTxAborted
+		}
+		action = plot.PopQueuedAction()
+		farm.Plots[plotUUID] = plot
+		username := strings.Split(farmUUID, "|")[0]
+		if OK, _ := interactPlot(res, txDbs, s.MainDictionary, s.World, s.HarvestSeed, now, username, farm, plotUUID, action); !OK || res.status >= http.StatusMultipleChoices {
+			response, responseErr := res.response()
+			if responseErr != nil {
+				log.Error.Printf("Error in PlotScheduler, could not decode failure response for plot %s. error: %v", plotUUID, responseErr)
+			}
+			failure = &schema.PlotQueueFailure{Action: action, Code: response.Code, Message: response.Message, Timestamp: now.Unix()}
+			return rdb.ErrTxAborted
+		}
+		log.Debug.Printf("PlotScheduler took action %v on plot %s", action, plotUUID)
+		return nil
+	})
+	if failure != nil {
+		s.pause(farmUUID, plotUUID, *failure)
+	}
+	return taken
+}
+
+// Pause the plot's queue recording failure, unless the queue has changed since the failed action was taken from it
+func (s *PlotScheduler) pause(farmUUID string, plotUUID string, failure schema.PlotQueueFailure) {
+	scheduledTransact(s.Dbs, plotUUID, func(res *bufferedResponseWriter, txDbs *map[string]rdb.InteractiveDB) error {
+		fdb := (*txDbs)["farms"]
+		farm, foundFarm, farmErr := schema.GetFarmFromDB(farmUUID, fdb)
+		if farmErr != nil || !foundFarm {
+			log.Error.Printf("Error in PlotScheduler, could not get farm %s from DB. foundFarm: %v, error: %v", farmUUID, foundFarm, farmErr)
+			return rdb.ErrTxAborted
+		}
+		plot, foundPlot := farm.Plots[plotUUID]
+		if !foundPlot || plot.Queue == nil || plot.Queue.Paused || len(plot.Queue.Actions) == 0 || plot.Queue.Actions[0] != failure.Action {
+			return rdb.ErrTxAborted
+		}
+		log.Debug.Printf("PlotScheduler pausing queue on plot %s, action %v failed: %s", plotUUID, failure.Action, failure.Message)
+		plot.Queue.Paused = true
+		plot.Queue.Failure = &failure
+		farm.Plots[plotUUID] = plot
+		saveFarmErr := schema.SaveFarmDataAtPathToDB(fdb, farmUUID, "plots", farm.Plots)
+		if saveFarmErr != nil {
+			log.Error.Printf("Error in PlotScheduler, could not save farm. error: %v", saveFarmErr)
+			return rdb.ErrTxAborted
+		}
+		username := strings.Split(farmUUID, "|")[0]
+		queueEvent(res, username, schema.NewEvent(schema.Event_PlotQueuePaused, failure.Timestamp, plotUUID, plot.Queue))
+		return nil
+	})
+}
+
+// Interval between checks of the timers of players streaming events
+var TimerSchedulerInterval = time.Second
+
+// How long a server's claim to publish a timer event is kept, well beyond the interval between scans
+var TimerClaimWindow = time.Minute
+
+// Defines a background scheduler notifying players streaming events on this server of their expired timers: arrived caravans, plots done growing, and lattice rejections ending.
+//
+// Timers are read once per scan for each player subscribed to broker, however many streams they have open, and each is notified in the scan it expires in.
+// Every server scans its own subscribers, so each event is first claimed in the counters db and published by the one server claiming it
+type TimerScheduler struct {
+	Dbs *map[string]rdb.InteractiveDB
+	Clock timecalc.Clock
+	Broker *events.Broker
+	lastScan int64
+}
+
+// Create a timer scheduler notifying timers which expire from now on
+func NewTimerScheduler(dbs *map[string]rdb.InteractiveDB, clock timecalc.Clock, broker *events.Broker) *TimerScheduler {
+	return &TimerScheduler{
+		Dbs: dbs,
+		Clock: clock,
+		Broker: broker,
+		lastScan: clock.Now().Unix(),
+	}
+}
+
+// Notify expired timers every TimerSchedulerInterval until stop is closed
+func (s *TimerScheduler) Run(stop <-chan struct{}) {
+	runScheduler(TimerSchedulerInterval, stop, s.NotifyExpired)
+}
+
+// Publish each timer which expired since the last scan to its subscribed player, returns the number published by this call
+func (s *TimerScheduler) NotifyExpired() int {
+	since := s.lastScan
+	now := s.Clock.Now().Unix()
+	if now <= since {
+		return 0
+	}
+	s.lastScan = now
+	notified := 0
+	for _, username := range s.Broker.Subscribers() {
+		for _, event := range s.expiredTimers(username, since, now) {
+			if !s.claim(username, event) {
+				continue
+			}
+			s.Broker.Publish(username, event)
+			notified++
+		}
+	}
+	return notified
+}
+
+// Claim publishing the user's timer event, false if another scan already claimed it
+func (s *TimerScheduler) claim(username string, event schema.Event) bool {
+	key := fmt.Sprintf("Timer|%s|%s|%s|%d", username, event.Type, event.Subject, event.Timestamp)
+	count, _, claimErr := (*s.Dbs)["ratelimits"].Incr(key, TimerClaimWindow)
+	if claimErr != nil {
+		log.Error.Printf("Error in TimerScheduler, could not claim %s. error: %v", key, claimErr)
+		return false
+	}
+	return count == 1
+}
+
+// Get events for the user's timers which expired after since, up to and including now
+func (s *TimerScheduler) expiredTimers(username string, since int64, now int64) []schema.Event {
+	expired := func(timestamp int64) bool {
+		return timestamp > since && timestamp <= now
+	}
+	timerEvents := make([]schema.Event, 0)
+	userData, foundUser, userErr := schema.GetUserByUsernameFromDB(username, (*s.Dbs)["users"])
+	if userErr != nil || !foundUser {
+		log.Error.Printf("Error in TimerScheduler, could not get user %s from DB. foundUser: %v, error: %v", username, foundUser, userErr)
+		return timerEvents
+	}
+	if expired(userData.LatticeInterferenceRejectionEnd) {
+		timerEvents = append(timerEvents, *schema.NewEvent(schema.Event_LatticeRejectionEnded, userData.LatticeInterferenceRejectionEnd, userData.Username, nil))
+	}
+	if len(userData.Caravans) > 0 {
+		caravans, _, caravansErr := schema.GetCaravansFromDB(userData.Caravans, (*s.Dbs)["caravans"])
+		if caravansErr != nil {
+			log.Error.Printf("Error in TimerScheduler, could not get caravans of %s from DB. error: %v", username, caravansErr)
+		}
+		for _, caravan := range caravans {
+			if expired(caravan.ArrivalTime) {
+				timerEvents = append(timerEvents, *schema.NewEvent(schema.Event_CaravanArrived, caravan.ArrivalTime, caravan.UUID, caravan))
+			}
+		}
+	}
+	farms, _, farmsErr := schema.GetFarmsFromDB(userData.Farms, (*s.Dbs)["farms"])
+	if farmsErr != nil {
+		log.Error.Printf("Error in TimerScheduler, could not get farms of %s from DB. error: %v", username, farmsErr)
+	}
+	for _, farm := range farms {
+		for _, plot := range farm.Plots {
+			if plot.PlantedPlant != nil && expired(plot.GrowthCompleteTimestamp) {
+				timerEvents = append(timerEvents, *schema.NewEvent(schema.Event_PlotGrowthComplete, plot.GrowthCompleteTimestamp, plot.UUID, plot))
+			}
+		}
+	}
+	return timerEvents
+}
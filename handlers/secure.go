@@ -3,6 +3,7 @@ package handlers
 
 import (
 	"apricate/auth"
+	"apricate/events"
 	"apricate/log"
 	"apricate/metrics"
 	"apricate/rdb"
@@ -13,6 +14,8 @@ import (
 	"fmt"
 	"math"
 	"net/http"
+	"net/url"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -21,7 +24,7 @@ import (
 
 // Handler function for the secure route: /api/my/account
 type AccountInfo struct {
-	Dbs *map[string]rdb.Database
+	Dbs *map[string]rdb.InteractiveDB
 }
 func (h *AccountInfo) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	log.Debug.Println(log.Yellow("-- accountInfo --"))
@@ -43,7 +46,7 @@ func (h *AccountInfo) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 
 // Handler function for the secure route: /api/my/assistants
 type AssistantsInfo struct {
-	Dbs *map[string]rdb.Database
+	Dbs *map[string]rdb.InteractiveDB
 }
 func (h *AssistantsInfo) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	log.Debug.Println(log.Yellow("-- AssistantsInfo --"))
@@ -72,7 +75,7 @@ func (h *AssistantsInfo) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 
 // Handler function for the secure route: /api/my/assistants/{uuid}
 type AssistantInfo struct {
-	Dbs *map[string]rdb.Database
+	Dbs *map[string]rdb.InteractiveDB
 }
 func (h *AssistantInfo) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	log.Debug.Println(log.Yellow("-- AssistantInfo --"))
@@ -107,9 +110,154 @@ func (h *AssistantInfo) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	log.Debug.Println(log.Cyan("-- End AssistantInfo --"))
 }
 
+// Handler function for the secure route: POST: /api/my/assistants/{assistant-id}/improve
+// Improves an assistant to the next level of the specified improvement, paid from the ledger and the warehouse where the assistant is
+type ImproveAssistant struct {
+	Dbs *map[string]rdb.InteractiveDB
+	MainDictionary *schema.MainDictionary
+}
+func (h *ImproveAssistant) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	secureTransact(w, r, h.Dbs, func(w http.ResponseWriter, r *http.Request, dbs *map[string]rdb.InteractiveDB) {
+		txHandler := *h
+		txHandler.Dbs = dbs
+		txHandler.serve(w, r)
+	})
+}
+func (h *ImproveAssistant) serve(w http.ResponseWriter, r *http.Request) {
+	log.Debug.Println(log.Yellow("-- ImproveAssistant --"))
+	udb := (*h.Dbs)["users"]
+	OK, userData, _ := secureGetUser(w, r, udb)
+	if !OK {
+		return // Failure states handled by secureGetUser, simply return
+	}
+
+	// unmarshall request body to get improvement
+	var body schema.ImproveBody
+	decoder := json.NewDecoder(r.Body)
+	if decodeErr := decoder.Decode(&body); decodeErr != nil {
+		// Fail case, could not decode
+		errmsg := fmt.Sprintf("Decode Error in ImproveAssistant: %v", decodeErr)
+		log.Debug.Printf(errmsg)
+		responses.SendRes(w, responses.Bad_Request, nil, "Could not decode request body, ensure it conforms to expected json format.")
+		return
+	}
+	improvementDef, improvementOk := h.MainDictionary.Improvements[body.Improvement]
+	if !improvementOk {
+		log.Debug.Printf("in ImproveAssistant, improvement %s could not be mapped to known improvement", body.Improvement)
+		responses.SendRes(w, responses.Specified_Improvement_Not_Found, nil, "")
+		return
+	}
+
+	// Get assistant
+	id := GetVarEntries(r, "assistant-id", AllCaps)
+	uuid := userData.Username + "|Assistant-" + id
+	adb := (*h.Dbs)["assistants"]
+	if !stringInSlice(uuid, userData.Assistants) {
+		log.Debug.Printf("in ImproveAssistant, assistant %s not found", uuid)
+		responses.SendRes(w, responses.Object_Not_Found, nil, "")
+		return
+	}
+	assistant, foundAssistant, assistantErr := schema.GetAssistantFromDB(uuid, adb)
+	if assistantErr != nil || !foundAssistant {
+		errmsg := fmt.Sprintf("Error in ImproveAssistant, could not get assistant from DB. foundAssistant: %v, error: %v", foundAssistant, assistantErr)
+		log.Error.Printf(errmsg)
+		responses.SendRes(w, responses.DB_Get_Failure, nil, errmsg)
+		return
+	}
+
+	// Validate assistant can take the next level
+	if strings.Contains(assistant.Location, "|Caravan-") {
+		errmsg := fmt.Sprintf("in ImproveAssistant, assistant %s is travelling in caravan %s", uuid, assistant.Location)
+		log.Debug.Printf(errmsg)
+		responses.SendRes(w, responses.Improvement_Not_Allowed, nil, errmsg)
+		return
+	}
+	if !improvementDef.AllowsArchetype(assistant.Archetype) {
+		errmsg := fmt.Sprintf("in ImproveAssistant, %s cannot be taken by %s archetype assistants", body.Improvement, assistant.Archetype)
+		log.Debug.Printf(errmsg)
+		responses.SendRes(w, responses.Improvement_Not_Allowed, nil, errmsg)
+		return
+	}
+	nextLevel := assistant.Improvements[body.Improvement] + 1
+	levelDef, levelOk := improvementDef.Level(nextLevel)
+	if !levelOk {
+		errmsg := fmt.Sprintf("in ImproveAssistant, %s is already at max level %d", body.Improvement, improvementDef.MaxLevel())
+		log.Debug.Printf(errmsg)
+		responses.SendRes(w, responses.Improvement_Not_Allowed, nil, errmsg)
+		return
+	}
+
+	// Validate costs
+	if missing := userData.Ledger.MissingCurrency(levelDef.Currencies); missing != "" {
+		errmsg := fmt.Sprintf("in ImproveAssistant, not enough %s in ledger for %s level %d", missing, body.Improvement, nextLevel)
+		log.Debug.Printf(errmsg)
+		responses.SendRes(w, responses.Improvement_Not_Allowed, nil, errmsg)
+		return
+	}
+	wuuid := userData.Username + "|Warehouse-" + assistant.Location
+	wdb := (*h.Dbs)["warehouses"]
+	warehouse, foundWarehouse, warehouseErr := schema.GetWarehouseFromDB(wuuid, wdb)
+	if warehouseErr != nil {
+		log.Error.Printf("Error in ImproveAssistant, could not get warehouse from DB. error: %v", warehouseErr)
+		responses.SendRes(w, responses.DB_Get_Failure, nil, warehouseErr.Error())
+		return
+	}
+	if !foundWarehouse {
+		warehouse = *schema.NewEmptyWarehouse(userData.Username, assistant.Location)
+	}
+	if category, missing, isMissing := warehouse.MissingWares(levelDef.Materials); isMissing {
+		errmsg := fmt.Sprintf("in ImproveAssistant, not enough %s %s in local warehouse for %s level %d", category, missing, body.Improvement, nextLevel)
+		log.Debug.Printf(errmsg)
+		responses.SendRes(w, responses.Improvement_Not_Allowed, nil, errmsg)
+		return
+	}
+
+	// Pay and improve
+	userData.Ledger.RemoveCurrencies(levelDef.Currencies)
+	warehouse.RemoveWares(levelDef.Materials)
+	if assistant.Improvements == nil {
+		assistant.Improvements = make(map[string]uint8)
+	}
+	assistant.Improvements[body.Improvement] = nextLevel
+
+	// Save to DBs
+	saveAssistantErr := schema.SaveAssistantToDB(adb, &assistant)
+	if saveAssistantErr != nil {
+		log.Error.Printf("Error in ImproveAssistant, could not save assistant. error: %v", saveAssistantErr)
+		responses.SendRes(w, responses.DB_Save_Failure, nil, saveAssistantErr.Error())
+		return
+	}
+	if foundWarehouse {
+		saveWarehouseErr := schema.SaveWarehouseToDB(wdb, &warehouse)
+		if saveWarehouseErr != nil {
+			log.Error.Printf("Error in ImproveAssistant, could not save warehouse. error: %v", saveWarehouseErr)
+			responses.SendRes(w, responses.DB_Save_Failure, nil, saveWarehouseErr.Error())
+			return
+		}
+	}
+	saveUserErr := schema.SaveUserToDB(udb, &userData)
+	if saveUserErr != nil {
+		log.Error.Printf("Error in ImproveAssistant, could not save user. error: %v", saveUserErr)
+		responses.SendRes(w, responses.DB_Save_Failure, nil, saveUserErr.Error())
+		return
+	}
+	queueCoinsMetric(w, &userData)
+
+	res := map[string]interface{}{
+		"assistant": assistant,
+		"speed": assistant.ImprovedSpeed(h.MainDictionary.Improvements),
+		"carrying_capacity": assistant.ImprovedCarryCap(h.MainDictionary.Improvements),
+		"warehouse": warehouse,
+		"ledger": userData.Ledger,
+	}
+	responses.SendRes(w, responses.Generic_Success, res, "")
+	log.Debug.Println(log.Cyan("-- End ImproveAssistant --"))
+}
+
 // Handler function for the secure route: /api/my/caravans
 type CaravansInfo struct {
-	Dbs *map[string]rdb.Database
+	Dbs *map[string]rdb.InteractiveDB
+	Clock timecalc.Clock
 }
 func (h *CaravansInfo) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	log.Debug.Println(log.Yellow("-- CaravansInfo --"))
@@ -132,7 +280,7 @@ func (h *CaravansInfo) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	}
 	// Modify Caravan SecondsTillArrival
 	for i, caravan := range caravans {
-		caravan.SecondsTillArrival = caravan.ArrivalTime - time.Now().Unix() 
+		caravan.SecondsTillArrival = caravan.ArrivalTime - h.Clock.Now().Unix() 
 		if caravan.SecondsTillArrival < 0 {
 			caravan.SecondsTillArrival = 0
 		}
@@ -151,7 +299,8 @@ func (h *CaravansInfo) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 
 // Handler function for the secure route: /api/my/caravans/{uuid}
 type CaravanInfo struct {
-	Dbs *map[string]rdb.Database
+	Dbs *map[string]rdb.InteractiveDB
+	Clock timecalc.Clock
 }
 func (h *CaravanInfo) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	log.Debug.Println(log.Yellow("-- CaravanInfo --"))
@@ -176,7 +325,7 @@ func (h *CaravanInfo) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	// Modify Caravan SecondsTillArrival
-	caravan.SecondsTillArrival = caravan.ArrivalTime - time.Now().Unix() 
+	caravan.SecondsTillArrival = caravan.ArrivalTime - h.Clock.Now().Unix() 
 	if caravan.SecondsTillArrival < 0 {
 		caravan.SecondsTillArrival = 0
 	}
@@ -191,12 +340,100 @@ func (h *CaravanInfo) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	log.Debug.Println(log.Cyan("-- End CaravanInfo --"))
 }
 
+// Handler function for the secure route: GET: /api/my/routes?origin=&destination=&assistants=&route_priority=
+// Quotes the travel time, fare, and carrying capacity of a caravan without chartering it, assistants is a comma separated list of ids and route_priority may be CHEAPEST
+type RouteQuote struct {
+	Dbs *map[string]rdb.InteractiveDB
+	MainDictionary *schema.MainDictionary
+	World *schema.World
+	Clock timecalc.Clock
+}
+func (h *RouteQuote) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	log.Debug.Println(log.Yellow("-- RouteQuote --"))
+	udb := (*h.Dbs)["users"]
+	OK, userData, _ := secureGetUser(w, r, udb)
+	if !OK {
+		return // Failure states handled by secureGetUser, simply return
+	}
+
+	// Validate query params
+	query := r.URL.Query()
+	origin := strings.ToUpper(query.Get("origin"))
+	destination := strings.ToUpper(query.Get("destination"))
+	priority := schema.Route_Fastest
+	if strings.EqualFold(query.Get("route_priority"), schema.Route_Cheapest.String()) {
+		priority = schema.Route_Cheapest
+	}
+	validationMap := make(map[string]string)
+	assistantUUIDs := make([]string, 0)
+	assistantIDs := make([]int64, 0)
+	for _, idString := range strings.Split(query.Get("assistants"), ",") {
+		if idString == "" {
+			continue
+		}
+		id, parseErr := strconv.ParseInt(idString, 10, 64)
+		uuid := userData.Username + "|Assistant-" + idString
+		if parseErr != nil || !stringInSlice(uuid, userData.Assistants) {
+			validationMap["assistants"] = fmt.Sprintf("Assistant %s not found, assistants must be a comma separated list of your assistant ids like 0,1", idString)
+			continue
+		}
+		assistantUUIDs = append(assistantUUIDs, uuid)
+		assistantIDs = append(assistantIDs, id)
+	}
+	if len(assistantIDs) < 1 && len(validationMap) == 0 {
+		validationMap["assistants"] = "Must specify at least one assistant ID to include in caravan"
+	}
+	if len(validationMap) > 0 {
+		errmsg := fmt.Sprintf("Validation Error in RouteQuote: %v", validationMap)
+		log.Debug.Printf(errmsg)
+		responses.SendRes(w, responses.Bad_Request, validationMap, "Request query did not pass validation, see data for specifics.")
+		return
+	}
+
+	// Get slowest speed and team carry cap
+	adb := (*h.Dbs)["assistants"]
+	assistants, foundAssistants, assistantsErr := schema.GetAssistantsFromDB(assistantUUIDs, adb)
+	if assistantsErr != nil || !foundAssistants {
+		errmsg := fmt.Sprintf("Error in RouteQuote, could not get assistants from DB. foundAssistants: %v, error: %v", foundAssistants, assistantsErr)
+		log.Error.Printf(errmsg)
+		responses.SendRes(w, responses.DB_Get_Failure, nil, errmsg)
+		return
+	}
+	slowestSpeed := int(1000000)
+	carryCap := int(0)
+	for _, assistant := range assistants {
+		speed := assistant.ImprovedSpeed(h.MainDictionary.Improvements)
+		if speed < slowestSpeed {
+			slowestSpeed = speed
+		}
+		carryCap += assistant.ImprovedCarryCap(h.MainDictionary.Improvements)
+	}
+
+	// Plan route with the same logic as CharterCaravan
+	OK, route := quoteRoute(w, h.World, userData.Username, h.Clock.Now(), assistantIDs, slowestSpeed, schema.TeamCarryCap(carryCap, len(assistants)), origin, destination, priority)
+	if !OK {
+		return // Failure states handled by quoteRoute, simply return
+	}
+
+	responses.SendRes(w, responses.Generic_Success, route, "")
+	log.Debug.Println(log.Cyan("-- End RouteQuote --"))
+}
+
 // Handler function for the secure route: PATCH: /api/my/caravans/
 type CharterCaravan struct {
-	Dbs *map[string]rdb.Database
+	Dbs *map[string]rdb.InteractiveDB
+	MainDictionary *schema.MainDictionary
 	World *schema.World
+	Clock timecalc.Clock
 }
 func (h *CharterCaravan) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	secureTransact(w, r, h.Dbs, func(w http.ResponseWriter, r *http.Request, dbs *map[string]rdb.InteractiveDB) {
+		txHandler := *h
+		txHandler.Dbs = dbs
+		txHandler.serve(w, r)
+	})
+}
+func (h *CharterCaravan) serve(w http.ResponseWriter, r *http.Request) {
 	log.Debug.Println(log.Yellow("-- CharterCaravan --"))
 	// Get user info
 	udb := (*h.Dbs)["users"]
@@ -241,7 +478,7 @@ func (h *CharterCaravan) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Generate a timestamp for caravan id. Get slowest assistant speeds, total carry cap, set their location to caravan UUID
-	caravanTimestamp := time.Now()
+	caravanTimestamp := h.Clock.Now()
 	caravanUUID := userData.Username + "|Caravan-" + fmt.Sprintf("%d", caravanTimestamp.UnixNano())
 	slowestSpeed := int(1000000)
 	carryCap := int(0)
@@ -251,11 +488,12 @@ func (h *CharterCaravan) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 			// Fail, assistant not at origin, prepare validation response will error later
 			assistantOriginValidation[fmt.Sprintf("Assistant-%s", i)] = fmt.Sprintf("Assistant not at origin (%s) specified in request", body.Origin)
 		}
-		if assistant.Speed < slowestSpeed {
-			slowestSpeed = assistant.Speed
-			log.Debug.Printf("Setting slowest speed to %d based on %d: %s", assistant.Speed, assistant.ID, assistant.Archetype.String())
+		speed := assistant.ImprovedSpeed(h.MainDictionary.Improvements)
+		if speed < slowestSpeed {
+			slowestSpeed = speed
+			log.Debug.Printf("Setting slowest speed to %d based on %d: %s", speed, assistant.ID, assistant.Archetype.String())
 		}
-		carryCap += assistant.CarryCap
+		carryCap += assistant.ImprovedCarryCap(h.MainDictionary.Improvements)
 		assistant.Location = caravanUUID
 		assistants[i] = assistant
 	}
@@ -269,7 +507,8 @@ func (h *CharterCaravan) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Calculate travel time and construct caravan
-	travelTimeValidationMap, caravanTravelTime, caravanFareCost := schema.CalculateTravelTime((*h.World), body.Origin, body.Destination, slowestSpeed)
+	travelTimeValidationMap, caravanRoute := schema.PlanCaravanRoute((*h.World), body.Origin, body.Destination, slowestSpeed, body.RoutePriority)
+	caravanFareCost := caravanRoute.Fare
 	if len(travelTimeValidationMap) > 0 {
 		// Fail, origin and destination could not be routed
 		errmsg := fmt.Sprintf("Validation Error in CharterCaravan: %v", travelTimeValidationMap)
@@ -284,7 +523,7 @@ func (h *CharterCaravan) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 			// Coins not enough, fail validation
 			coinsValidationMap["port_fare"] = fmt.Sprintf("Not enough coins in ledger to pay fare. Have %d need %d", coins, caravanFareCost)
 			log.Debug.Printf(coinsValidationMap["port_fare"])
-			responses.SendRes(w, responses.Bad_Request, travelTimeValidationMap, "Request body did not pass validation, see data for specifics.")
+			responses.SendRes(w, responses.Bad_Request, coinsValidationMap, "Request body did not pass validation, see data for specifics.")
 			return
 		} else {
 			// Enough coins, deduct fare
@@ -297,7 +536,7 @@ func (h *CharterCaravan) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		responses.SendRes(w, responses.Bad_Request, travelTimeValidationMap, "Request body did not pass validation, see data for specifics.")
 		return
 	}
-	caravan := schema.NewCaravan(caravanUUID, caravanTimestamp, body.Origin, body.Destination, body.Assistants, body.Wares, caravanTravelTime)
+	caravan := schema.NewCaravan(caravanUUID, caravanTimestamp, body.Origin, body.Destination, body.Assistants, body.Wares, body.AutoUnpack, caravanRoute)
 	log.Debug.Printf("Prepared caravan, now to validate wares. Caravan: %v", caravan)
 
 	// Validate wares if present
@@ -320,9 +559,8 @@ func (h *CharterCaravan) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 
 		// Get carry cap
 		log.Debug.Printf("carryCap before team_factor: %d", carryCap)
-		teamFactor := 1 + (float64(0.1) * float64(len(assistants) - 1))
-		carryCap := uint64(math.Ceil(float64(carryCap) * teamFactor))
-		log.Debug.Printf("carryCap after team_factor (%f): %d", teamFactor, carryCap)
+		carryCap := schema.TeamCarryCap(carryCap, len(assistants))
+		log.Debug.Printf("carryCap after team_factor: %d", carryCap)
 
 		// Get warehouse
 		wdb := (*h.Dbs)["warehouses"]
@@ -494,6 +732,7 @@ func (h *CharterCaravan) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		responses.SendRes(w, responses.DB_Save_Failure, nil, saveUserErr.Error())
 		return
 	}
+	queueCoinsMetric(w, &userData)
 
 	for _, assistant := range assistants {
 		saveAssistantErr := schema.SaveAssistantDataAtPathToDB(adb, assistant.UUID, "location", assistant.Location)
@@ -519,9 +758,19 @@ func (h *CharterCaravan) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 
 // Handler function for the secure route: DELETE: /api/my/caravans/{caravan-id}
 type UnpackCaravan struct {
-	Dbs *map[string]rdb.Database
+	Dbs *map[string]rdb.InteractiveDB
+	MainDictionary *schema.MainDictionary
+	World *schema.World
+	Clock timecalc.Clock
 }
 func (h *UnpackCaravan) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	secureTransact(w, r, h.Dbs, func(w http.ResponseWriter, r *http.Request, dbs *map[string]rdb.InteractiveDB) {
+		txHandler := *h
+		txHandler.Dbs = dbs
+		txHandler.serve(w, r)
+	})
+}
+func (h *UnpackCaravan) serve(w http.ResponseWriter, r *http.Request) {
 	log.Debug.Println(log.Yellow("-- UnpackCaravan --"))
 	// Get symbol from route
 	id := GetVarEntries(r, "caravan-id", None)
@@ -563,7 +812,7 @@ func (h *UnpackCaravan) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Validate Timestamp
-	now := time.Now()
+	now := h.Clock.Now()
 	if caravan.ArrivalTime > now.Unix() {
 		// Too early
 		caravan.SecondsTillArrival = caravan.ArrivalTime - now.Unix()
@@ -574,17 +823,40 @@ func (h *UnpackCaravan) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// VALID: Update user, warehouses, assistants, caravans DBs
+	if !unpackCaravan(w, h.Dbs, h.MainDictionary, h.World, now, userData, caravan) {
+		return // Failure states handled by unpackCaravan, simply return
+	}
+
+	// Construct and Send response
+	response := map[string]interface{}{"assistants_released": &caravan.Assistants}
+	getPlotPlantResponseJsonString, getPlotPlantResponseJsonStringErr := responses.JSON(response)
+	if getPlotPlantResponseJsonStringErr != nil {
+		log.Error.Printf("Error in PlotInfo, could not format interact response as JSON. response: %v, error: %v", response, getPlotPlantResponseJsonStringErr)
+		responses.SendRes(w, responses.JSON_Marshal_Error, response, getPlotPlantResponseJsonStringErr.Error())
+		return
+	}
+	log.Debug.Printf("Sending response for UnpackCaravan:\n%v", getPlotPlantResponseJsonString)
+	responses.SendRes(w, responses.Generic_Success, response, fmt.Sprintf("Caravan unpacked at %s", caravan.Destination))
+	
+	log.Debug.Println(log.Cyan("-- End UnpackCaravan --"))
+}
+
+// Unpack an arrived caravan into the user's warehouse at its destination and release its assistants there, saving all changes
+// Returns: OK, failure responses already sent to w
+func unpackCaravan(w http.ResponseWriter, dbs *map[string]rdb.InteractiveDB, dictionary *schema.MainDictionary, world *schema.World, now time.Time, userData schema.User, caravan schema.Caravan) bool {
+	udb := (*dbs)["users"]
+	cdb := (*dbs)["caravans"]
 
 	if len(caravan.Wares.Goods) > 0 || len(caravan.Wares.Seeds) > 0 || len(caravan.Wares.Produce) > 0 || len(caravan.Wares.Tools) > 0 {
 		// Get warehouse
-		wdb := (*h.Dbs)["warehouses"]
+		wdb := (*dbs)["warehouses"]
 		warehouseLocationSymbol := userData.Username + "|Warehouse-" + caravan.Destination
 		warehouse, foundWarehouse, warehousesErr := schema.GetWarehouseFromDB(warehouseLocationSymbol, wdb)
 		if warehousesErr != nil {
 			errmsg := fmt.Sprintf("Error in UnpackCaravan, could not get warehouse from DB. foundWarehouse: %v, error: %v", foundWarehouse, warehousesErr)
 			log.Error.Printf(errmsg)
 			responses.SendRes(w, responses.DB_Get_Failure, nil, errmsg)
-			return
+			return false
 		}
 		if !foundWarehouse {
 			// Need to add new warehouse
@@ -592,6 +864,18 @@ func (h *UnpackCaravan) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 			userData.Warehouses = append(userData.Warehouses, warehouse.UUID)
 		}
 
+		// Validate warehouse has room for the wares
+		capacityOk, capacity := getWarehouseCapacity(w, (*dbs)["farms"], userData, dictionary, world, caravan.Destination, now)
+		if !capacityOk {
+			return false // Failure states handled by getWarehouseCapacity, simply return
+		}
+		if caravan.Wares.TotalSize() > warehouse.FreeCapacity(capacity) {
+			errmsg := fmt.Sprintf("in UnpackCaravan, caravan wares (%d) exceed room in warehouse (%d of %d)", caravan.Wares.TotalSize(), warehouse.FreeCapacity(capacity), capacity)
+			log.Debug.Printf(errmsg)
+			responses.SendRes(w, responses.Warehouse_Capacity_Exceeded, caravan, errmsg)
+			return false
+		}
+
 		// Update Warehouse
 		if len(caravan.Wares.Goods) > 0 {
 			for g, q := range caravan.Wares.Goods {
@@ -619,7 +903,7 @@ func (h *UnpackCaravan) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		if saveWarehouseErr != nil {
 			log.Error.Printf("Error in UnpackCaravan, could not save warehouse. error: %v", saveWarehouseErr)
 			responses.SendRes(w, responses.DB_Save_Failure, nil, saveWarehouseErr.Error())
-			return
+			return false
 		}
 	}
 	
@@ -628,18 +912,18 @@ func (h *UnpackCaravan) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	if len(userData.Caravans) <= 1 {
 		userData.Caravans = make([]string, 0)
 	} else {
-		userData.Caravans = remove(userData.Caravans, cUUID)
+		userData.Caravans = remove(userData.Caravans, caravan.UUID)
 	}
 	
 	// Update and Save Assistants
-	adb := (*h.Dbs)["assistants"]
+	adb := (*dbs)["assistants"]
 	for _, aID := range caravan.Assistants {
 		aUUID := userData.Username + "|Assistant-" + fmt.Sprintf("%d", aID)
 		saveAssistantErr := schema.SaveAssistantDataAtPathToDB(adb, aUUID, "location", caravan.Destination)
 		if saveAssistantErr != nil {
 			log.Error.Printf("Error in UnpackCaravan, could not save assistant. error: %v", saveAssistantErr)
 			responses.SendRes(w, responses.DB_Save_Failure, nil, saveAssistantErr.Error())
-			return
+			return false
 		}
 	}
 
@@ -648,36 +932,116 @@ func (h *UnpackCaravan) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	if saveUserErr != nil {
 		log.Error.Printf("Error in UnpackCaravan, could not save user. error: %v", saveUserErr)
 		responses.SendRes(w, responses.DB_Save_Failure, nil, saveUserErr.Error())
-		return
+		return false
 	}
+	queueCoinsMetric(w, &userData)
 	
 	// Delete Caravan
-	delCaravanErr := schema.DeleteCaravanFromDB(cdb, cUUID)
+	delCaravanErr := schema.DeleteCaravanFromDB(cdb, caravan.UUID)
 	if delCaravanErr != nil {
 		log.Error.Printf("Error in UnpackCaravan, could not delete caravan. error: %v", delCaravanErr)
 		responses.SendRes(w, responses.Internal_Server_Error, nil, delCaravanErr.Error())
+		return false
+	}
+
+	queueEvent(w, userData.Username, schema.NewEvent(schema.Event_CaravanUnpacked, now.Unix(), caravan.UUID, caravan))
+	return true
+}
+
+// Handler function for the secure route: POST: /api/my/caravans/{caravan-id}/recall
+// Turns a caravan in transit back toward its origin, see Caravan.PlanRecall for the return time and fare refund rules
+type RecallCaravan struct {
+	Dbs *map[string]rdb.InteractiveDB
+	Clock timecalc.Clock
+}
+func (h *RecallCaravan) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	secureTransact(w, r, h.Dbs, func(w http.ResponseWriter, r *http.Request, dbs *map[string]rdb.InteractiveDB) {
+		txHandler := *h
+		txHandler.Dbs = dbs
+		txHandler.serve(w, r)
+	})
+}
+func (h *RecallCaravan) serve(w http.ResponseWriter, r *http.Request) {
+	log.Debug.Println(log.Yellow("-- RecallCaravan --"))
+	// Get symbol from route
+	id := GetVarEntries(r, "caravan-id", None)
+
+	// Get user info
+	udb := (*h.Dbs)["users"]
+	OK, userData, _ := secureGetUser(w, r, udb)
+	if !OK {
+		return // Failure states handled by secureGetUser, simply return
+	}
+
+	// Get Caravan
+	cUUID := userData.Username + "|Caravan-" + id
+	cdb := (*h.Dbs)["caravans"]
+	caravan, foundCaravan, caravanErr := schema.GetCaravanFromDB(cUUID, cdb)
+	if caravanErr != nil {
+		errmsg := fmt.Sprintf("Error in RecallCaravan, could not get caravan from DB. foundCaravan: %v, error: %v", foundCaravan, caravanErr)
+		log.Error.Printf(errmsg)
+		responses.SendRes(w, responses.DB_Get_Failure, nil, errmsg)
+		return
+	}
+	if !foundCaravan {
+		// Validation error
+		errmsg := fmt.Sprintf("caravan of given id not found")
+		log.Debug.Printf(errmsg)
+		responses.SendRes(w, responses.Bad_Request, nil, errmsg)
 		return
 	}
 
-	// Construct and Send response
-	response := map[string]interface{}{"assistants_released": &caravan.Assistants}
-	getPlotPlantResponseJsonString, getPlotPlantResponseJsonStringErr := responses.JSON(response)
-	if getPlotPlantResponseJsonStringErr != nil {
-		log.Error.Printf("Error in PlotInfo, could not format interact response as JSON. response: %v, error: %v", response, getPlotPlantResponseJsonStringErr)
-		responses.SendRes(w, responses.JSON_Marshal_Error, response, getPlotPlantResponseJsonStringErr.Error())
+	// Validate caravan is still travelling to its destination
+	now := h.Clock.Now()
+	if caravan.ArrivalTime <= now.Unix() {
+		errmsg := fmt.Sprintf("in RecallCaravan, caravan already arrived at %s, unpack it instead", caravan.Destination)
+		log.Debug.Printf(errmsg)
+		responses.SendRes(w, responses.Recall_Not_Allowed, caravan, errmsg)
 		return
 	}
-	log.Debug.Printf("Sending response for UnpackCaravan:\n%v", getPlotPlantResponseJsonString)
-	responses.SendRes(w, responses.Generic_Success, response, fmt.Sprintf("Caravan unpacked at %s", caravan.Destination))
-	
-	log.Debug.Println(log.Cyan("-- End UnpackCaravan --"))
+	if caravan.RecalledFrom != "" {
+		errmsg := fmt.Sprintf("in RecallCaravan, caravan already recalled from %s", caravan.RecalledFrom)
+		log.Debug.Printf(errmsg)
+		responses.SendRes(w, responses.Recall_Not_Allowed, caravan, errmsg)
+		return
+	}
+
+	// Turn caravan around and refund fares of legs not yet begun
+	recallRoute, refund := caravan.PlanRecall(now.Unix())
+	log.Debug.Printf("Recalling caravan %s from %s, returning in %d seconds with %d coins refunded", caravan.UUID, caravan.Destination, recallRoute.Duration, refund)
+	caravan.RecalledFrom = caravan.Destination
+	caravan.Destination = caravan.Origin
+	caravan.Route = recallRoute
+	caravan.ArrivalTime = timecalc.AddSecondsToTimestamp(now, recallRoute.Duration).Unix()
+	if refund > 0 {
+		userData.Ledger.AddCurrency("Coins", refund)
+	}
+
+	// Save caravan and user
+	saveCaravanErr := schema.SaveCaravanToDB(cdb, &caravan)
+	if saveCaravanErr != nil {
+		log.Error.Printf("Error in RecallCaravan, could not save caravan. error: %v", saveCaravanErr)
+		responses.SendRes(w, responses.DB_Save_Failure, nil, saveCaravanErr.Error())
+		return
+	}
+	saveUserErr := schema.SaveUserToDB(udb, &userData)
+	if saveUserErr != nil {
+		log.Error.Printf("Error in RecallCaravan, could not save user. error: %v", saveUserErr)
+		responses.SendRes(w, responses.DB_Save_Failure, nil, saveUserErr.Error())
+		return
+	}
+	queueCoinsMetric(w, &userData)
+
+	caravan.SecondsTillArrival = caravan.ArrivalTime - now.Unix()
+	responses.SendRes(w, responses.Generic_Success, map[string]interface{}{"caravan": caravan, "refunded_fare": refund, "ledger": userData.Ledger}, fmt.Sprintf("Caravan recalled to %s", caravan.Destination))
+	log.Debug.Println(log.Cyan("-- End RecallCaravan --"))
 }
 
 
 // Handler function for the secure route: /api/my/locations
 // Returns a list of locations 
 type LocationsInfo struct {
-	Dbs *map[string]rdb.Database
+	Dbs *map[string]rdb.InteractiveDB
 	World *schema.World
 }
 func (h *LocationsInfo) ServeHTTP(w http.ResponseWriter, r *http.Request) {
@@ -723,7 +1087,7 @@ func (h *LocationsInfo) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 // Handler function for the secure route: /api/my/nearby-locations
 // Returns a list of locations 
 type NearbyLocationsInfo struct {
-	Dbs *map[string]rdb.Database
+	Dbs *map[string]rdb.InteractiveDB
 	World *schema.World
 }
 func (h *NearbyLocationsInfo) ServeHTTP(w http.ResponseWriter, r *http.Request) {
@@ -785,7 +1149,7 @@ func (h *NearbyLocationsInfo) ServeHTTP(w http.ResponseWriter, r *http.Request)
 // Handler function for the secure route: /api/my/locations/location-symbol
 // Returns a locations 
 type LocationInfo struct {
-	Dbs *map[string]rdb.Database
+	Dbs *map[string]rdb.InteractiveDB
 	World *schema.World
 }
 func (h *LocationInfo) ServeHTTP(w http.ResponseWriter, r *http.Request) {
@@ -842,8 +1206,9 @@ func (h *LocationInfo) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 // Handler function for the secure route: /api/my/markets
 // Returns a list of markets 
 type MarketsInfo struct {
-	Dbs *map[string]rdb.Database
+	Dbs *map[string]rdb.InteractiveDB
 	MainDictionary *schema.MainDictionary
+	Clock timecalc.Clock
 }
 func (h *MarketsInfo) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	log.Debug.Println(log.Yellow("-- MarketsInfo --"))
@@ -865,6 +1230,14 @@ func (h *MarketsInfo) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	for _, assistant := range assistants {
 		myLocs[assistant.Location] = true
 	}
+	// add markets surveyed from farms
+	surveyOk, surveyed := getSurveyedLocations(w, (*h.Dbs)["farms"], userData, h.MainDictionary, h.Clock.Now())
+	if !surveyOk {
+		return // Failure states handled by getSurveyedLocations, simply return
+	}
+	for loc := range surveyed {
+		myLocs[loc] = true
+	}
 	// finally get all markets in each region
 	resMarkets := make([]schema.Market, 0)
 	for market := range myLocs {
@@ -873,7 +1246,12 @@ func (h *MarketsInfo) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 			// location doesn't have market, skip
 			continue
 		}
-		resMarkets = append(resMarkets, marketEntry)
+		// Apply live prices
+		priceOk, liveMarket := getLiveMarket(w, (*h.Dbs)["clearinghouse"], h.Clock.Now(), marketEntry)
+		if !priceOk {
+			return // Failure states handled by getLiveMarket, simply return
+		}
+		resMarkets = append(resMarkets, liveMarket)
 	}
 	responses.SendRes(w, responses.Generic_Success, resMarkets, "")
 	log.Debug.Println(log.Cyan("-- End MarketsInfo --"))
@@ -882,8 +1260,9 @@ func (h *MarketsInfo) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 // Handler function for the secure route: /api/my/markets/{symbol}
 // Returns a markets 
 type MarketInfo struct {
-	Dbs *map[string]rdb.Database
+	Dbs *map[string]rdb.InteractiveDB
 	MainDictionary *schema.MainDictionary
+	Clock timecalc.Clock
 }
 func (h *MarketInfo) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	log.Debug.Println(log.Yellow("-- MarketInfo --"))
@@ -905,6 +1284,14 @@ func (h *MarketInfo) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	for _, assistant := range assistants {
 		myLocs[assistant.Location] = true
 	}
+	// add markets surveyed from farms
+	surveyOk, surveyed := getSurveyedLocations(w, (*h.Dbs)["farms"], userData, h.MainDictionary, h.Clock.Now())
+	if !surveyOk {
+		return // Failure states handled by getSurveyedLocations, simply return
+	}
+	for loc := range surveyed {
+		myLocs[loc] = true
+	}
 	// Get symbol from route
 	symbol := GetVarEntries(r, "location-symbol", UUID)
 	// finally get specified market if available
@@ -921,13 +1308,20 @@ func (h *MarketInfo) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		responses.SendRes(w, responses.No_Assitant_At_Location, nil, "")
 		return
 	}
+	// Apply live prices
+	priceOk, resMarket := getLiveMarket(w, (*h.Dbs)["clearinghouse"], h.Clock.Now(), resMarket)
+	if !priceOk {
+		return // Failure states handled by getLiveMarket, simply return
+	}
 	responses.SendRes(w, responses.Generic_Success, resMarket, "")
 	log.Debug.Println(log.Cyan("-- End MarketInfo --"))
 }
 
 // Handler function for the secure route: /api/my/farms
 type FarmsInfo struct {
-	Dbs *map[string]rdb.Database
+	Dbs *map[string]rdb.InteractiveDB
+	MainDictionary *schema.MainDictionary
+	Clock timecalc.Clock
 }
 func (h *FarmsInfo) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	log.Debug.Println(log.Yellow("-- FarmsInfo --"))
@@ -943,6 +1337,12 @@ func (h *FarmsInfo) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		responses.SendRes(w, responses.DB_Get_Failure, nil, farmsErr.Error())
 		return
 	}
+	// Show completed construction, it is saved on the farm's next change, and the effects of buildings
+	for i := range farms {
+		farms[i].CompleteConstruction(h.Clock.Now().Unix())
+		effects := schema.GetFarmEffects(farms[i].Buildings, h.MainDictionary.Buildings)
+		farms[i].Effects = &effects
+	}
 	getFarmJsonString, getFarmJsonStringErr := responses.JSON(farms)
 	if getFarmJsonStringErr != nil {
 		log.Error.Printf("Error in FarmsInfo, could not format farms as JSON. farms: %v, error: %v", farms, getFarmJsonStringErr)
@@ -956,7 +1356,9 @@ func (h *FarmsInfo) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 
 // Handler function for the secure route: /api/my/farms/{uuid}
 type FarmInfo struct {
-	Dbs *map[string]rdb.Database
+	Dbs *map[string]rdb.InteractiveDB
+	MainDictionary *schema.MainDictionary
+	Clock timecalc.Clock
 }
 func (h *FarmInfo) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	log.Debug.Println(log.Yellow("-- FarmInfo --"))
@@ -980,6 +1382,10 @@ func (h *FarmInfo) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		responses.SendRes(w, responses.DB_Get_Failure, nil, farmsErr.Error())
 		return
 	}
+	// Show completed construction, it is saved on the farm's next change, and the effects of buildings
+	farm.CompleteConstruction(h.Clock.Now().Unix())
+	effects := schema.GetFarmEffects(farm.Buildings, h.MainDictionary.Buildings)
+	farm.Effects = &effects
 	getFarmJsonString, getFarmJsonStringErr := responses.JSON(farm)
 	if getFarmJsonStringErr != nil {
 		log.Error.Printf("Error in FarmInfo, could not format farms as JSON. farms: %v, error: %v", farm, getFarmJsonStringErr)
@@ -993,10 +1399,19 @@ func (h *FarmInfo) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 
 // Handler function for the secure route: POST: /api/my/farms/{location-symbol}/ritual/{runic-symbol}
 type ConductRitual struct {
-	Dbs *map[string]rdb.Database
+	Dbs *map[string]rdb.InteractiveDB
 	MainDictionary *schema.MainDictionary
+	World *schema.World
+	Clock timecalc.Clock
 }
 func (h *ConductRitual) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	secureTransact(w, r, h.Dbs, func(w http.ResponseWriter, r *http.Request, dbs *map[string]rdb.InteractiveDB) {
+		txHandler := *h
+		txHandler.Dbs = dbs
+		txHandler.serve(w, r)
+	})
+}
+func (h *ConductRitual) serve(w http.ResponseWriter, r *http.Request) {
 	log.Debug.Println(log.Yellow("-- ConductRitual --"))
 	udb := (*h.Dbs)["users"]
 	OK, userData, _ := secureGetUser(w, r, udb)
@@ -1005,7 +1420,7 @@ func (h *ConductRitual) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Validate timestamp
-	now := time.Now()
+	now := h.Clock.Now()
 	if userData.LatticeInterferenceRejectionEnd > now.Unix() {
 		// FAIL, rejection still in place
 		latticeRejectionMsg := fmt.Sprintf("in ConductRitual, the lattice rejects your manipulation, you must wait %d seconds till you can cast another ritual", userData.LatticeInterferenceRejectionEnd - now.Unix())
@@ -1026,6 +1441,7 @@ func (h *ConductRitual) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		responses.SendRes(w, responses.DB_Get_Failure, nil, farmsErr.Error())
 		return
 	}
+	farm.CompleteConstruction(now.Unix())
 
 	// Get symbol from route
 	symbol := GetVarEntries(r, "runic-symbol", AllCaps)
@@ -1212,70 +1628,77 @@ func (h *ConductRitual) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	// Rite Validated and updated currencies/etc. NOW do other effects as applicable
-	switch rite.RunicSymbol {
-	case "FLGJ": // Summon Familiar
-		log.Debug.Printf("Rite %s cast, summoning Familiar", rite.RunicSymbol)
-		newAssistant := schema.NewAssistant(userData.Username, len(userData.Assistants), schema.Familiar, farmSymbol)
-		// Save newAssistant
-		adb := (*h.Dbs)["assistants"]
-		saveAssistantErr := schema.SaveAssistantToDB(adb, newAssistant)
-		if saveAssistantErr != nil {
-			log.Error.Printf("Error in ConductRitual, could not save assistant. error: %v", saveAssistantErr)
-			responses.SendRes(w, responses.DB_Save_Failure, nil, saveAssistantErr.Error())
-			return
-		}
-		// Add UUID to userdata
-		userData.Assistants = append(userData.Assistants, newAssistant.UUID)
-		// UserData saved later
-	case "HRTKTSK": // Get Vial of Blood
-		log.Debug.Printf("Rite %s cast, giving blood", rite.RunicSymbol)
-		warehouse.AddGoods("Vial of Blood", 1)
-		// Warehouse saved later
-	case "DWLTJ": // Summon Imp
-		log.Debug.Printf("Rite %s cast, summoning Imp", rite.RunicSymbol)
-		newAssistant := schema.NewAssistant(userData.Username, len(userData.Assistants), schema.Imp, farmSymbol)
-		// Save newAssistant
-		adb := (*h.Dbs)["assistants"]
-		saveAssistantErr := schema.SaveAssistantToDB(adb, newAssistant)
-		if saveAssistantErr != nil {
-			log.Error.Printf("Error in ConductRitual, could not save assistant. error: %v", saveAssistantErr)
-			responses.SendRes(w, responses.DB_Save_Failure, nil, saveAssistantErr.Error())
-			return
+	// Rite Validated and updated currencies/etc. NOW apply its effects in order
+	grantedWares := false
+	for _, effect := range rite.Effects {
+		log.Debug.Printf("Rite %s cast, applying effect %s", rite.RunicSymbol, effect.Type)
+		switch effect.Type {
+		case schema.RiteEffect_SummonAssistant:
+			newAssistant := schema.NewAssistant(userData.Username, schema.NextAssistantID(userData.Assistants), *effect.Archetype, farmSymbol)
+			// Save newAssistant
+			adb := (*h.Dbs)["assistants"]
+			saveAssistantErr := schema.SaveAssistantToDB(adb, newAssistant)
+			if saveAssistantErr != nil {
+				log.Error.Printf("Error in ConductRitual, could not save assistant. error: %v", saveAssistantErr)
+				responses.SendRes(w, responses.DB_Save_Failure, nil, saveAssistantErr.Error())
+				return
+			}
+			// Add UUID to userdata
+			userData.Assistants = append(userData.Assistants, newAssistant.UUID)
+			// UserData saved later
+		case schema.RiteEffect_HastenGrowth:
+			for puuid, plot := range farm.Plots {
+				if plot.PlantedPlant == nil || plot.GrowthCompleteTimestamp <= now.Unix() {
+					continue
+				}
+				plot.GrowthCompleteTimestamp -= effect.Seconds
+				if plot.GrowthCompleteTimestamp < now.Unix() {
+					plot.GrowthCompleteTimestamp = now.Unix()
+				}
+				farm.Plots[puuid] = plot
+			}
+			// Farm saved later
+		case schema.RiteEffect_AddYield:
+			for puuid, plot := range farm.Plots {
+				if plot.PlantedPlant == nil {
+					continue
+				}
+				plot.PlantedPlant.Yield += effect.Yield
+				farm.Plots[puuid] = plot
+			}
+			// Farm saved later
+		case schema.RiteEffect_GrantWares:
+			warehouse.AddWares(effect.Wares)
+			grantedWares = true
+			// Warehouse saved later
+		case schema.RiteEffect_GrantFarmBonus:
+			farm.AddTemporaryBonus(*effect.Bonus, now.Unix(), now.Unix() + effect.Seconds)
+			// Farm saved later
 		}
-		// Add UUID to userdata
-		userData.Assistants = append(userData.Assistants, newAssistant.UUID)
-		// UserData saved later
-	case "ASTRVNRPRV": // Get Vial of Fairy Dust
-		log.Debug.Printf("Rite %s cast, giving Fairy Dust", rite.RunicSymbol)
-		warehouse.AddGoods("Vial of Fairy Dust", 1)
-		// Warehouse saved later
-	case "APCRPHNSPRGGNCRGNS": // Summon Sprite
-		log.Debug.Printf("Rite %s cast, summoning Sprite", rite.RunicSymbol)
-		newAssistant := schema.NewAssistant(userData.Username, len(userData.Assistants), schema.Sprite, farmSymbol)
-		// Save newAssistant
-		adb := (*h.Dbs)["assistants"]
-		saveAssistantErr := schema.SaveAssistantToDB(adb, newAssistant)
-		if saveAssistantErr != nil {
-			log.Error.Printf("Error in ConductRitual, could not save assistant. error: %v", saveAssistantErr)
-			responses.SendRes(w, responses.DB_Save_Failure, nil, saveAssistantErr.Error())
+	}
+
+	// Validate warehouse has room for any wares granted
+	if grantedWares {
+		capacity := schema.WarehouseCapacity(h.World.Locations[farmSymbol], schema.GetFarmEffects(farm.Buildings, h.MainDictionary.Buildings))
+		if warehouse.TotalSize() > capacity {
+			errmsg := fmt.Sprintf("in ConductRitual, wares granted by rite would fill warehouse past capacity (%d of %d)", warehouse.TotalSize(), capacity)
+			log.Debug.Printf(errmsg)
+			responses.SendRes(w, responses.Warehouse_Capacity_Exceeded, nil, errmsg)
 			return
 		}
-		// Add UUID to userdata
-		userData.Assistants = append(userData.Assistants, newAssistant.UUID)
-		// UserData saved later
-	default:
-		log.Debug.Printf("Rite %s doesn't appear to have any special effects. Skipping", rite.RunicSymbol)
 	}
 
 	// Update metrics
-	metrics.TrackRitual(rite.RunicSymbol, rite.Name)
-	schema.TrackUserMagic(userData.Username, userData.ArcaneFlux, userData.DistortionTier)
+	queueMetric(w, func() {
+		metrics.TrackRitual(rite.RunicSymbol, rite.Name)
+		schema.TrackUserMagic(userData.Username, userData.ArcaneFlux, userData.DistortionTier)
+	})
 
 	// Send warehouse and user data
 	res := make(map[string]interface{})
 	res["user"] = userData
 	res["warehouse"] = warehouse
+	res["farm"] = farm
 
 	// Save userdata
 	saveUserErr := schema.SaveUserToDB(udb, &userData)
@@ -1284,6 +1707,7 @@ func (h *ConductRitual) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		responses.SendRes(w, responses.DB_Save_Failure, nil, saveUserErr.Error())
 		return
 	}
+	queueCoinsMetric(w, &userData)
 
 	// Save warehouse
 	saveWarehouseErr := schema.SaveWarehouseToDB(wdb, &warehouse)
@@ -1293,6 +1717,14 @@ func (h *ConductRitual) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Save farm
+	saveFarmErr := schema.SaveFarmToDB(fdb, &farm)
+	if saveFarmErr != nil {
+		log.Error.Printf("Error in ConductRitual, could not save farm. error: %v", saveFarmErr)
+		responses.SendRes(w, responses.DB_Save_Failure, nil, saveFarmErr.Error())
+		return
+	}
+
 	getResJsonString, getResJsonStringErr := responses.JSON(res)
 	if getResJsonStringErr != nil {
 		log.Error.Printf("Error in ConductRitual, could not format res as JSON. res: %v, error: %v", res, getResJsonStringErr)
@@ -1304,424 +1736,422 @@ func (h *ConductRitual) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	log.Debug.Println(log.Cyan("-- End ConductRitual --"))
 }
 
-// Handler function for the secure route: /api/my/contracts
-type ContractsInfo struct {
-	Dbs *map[string]rdb.Database
+// Handler function for the secure route: POST: /api/my/farms/{location-symbol}/buildings/{building}
+type ConstructBuilding struct {
+	Dbs *map[string]rdb.InteractiveDB
+	MainDictionary *schema.MainDictionary
+	Clock timecalc.Clock
 }
-func (h *ContractsInfo) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	log.Debug.Println(log.Yellow("-- ContractsInfo --"))
+func (h *ConstructBuilding) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	secureTransact(w, r, h.Dbs, func(w http.ResponseWriter, r *http.Request, dbs *map[string]rdb.InteractiveDB) {
+		txHandler := *h
+		txHandler.Dbs = dbs
+		txHandler.serve(w, r)
+	})
+}
+func (h *ConstructBuilding) serve(w http.ResponseWriter, r *http.Request) {
+	log.Debug.Println(log.Yellow("-- ConstructBuilding --"))
 	udb := (*h.Dbs)["users"]
 	OK, userData, _ := secureGetUser(w, r, udb)
 	if !OK {
 		return // Failure states handled by secureGetUser, simply return
 	}
-	adb := (*h.Dbs)["contracts"]
-	contracts, foundContracts, contractsErr := schema.GetContractsFromDB(userData.Contracts, adb)
-	if contractsErr != nil || !foundContracts {
-		log.Error.Printf("Error in ContractsInfo, could not get contracts from DB. foundContracts: %v, error: %v", foundContracts, contractsErr)
-		responses.SendRes(w, responses.DB_Get_Failure, nil, contractsErr.Error())
-		return
-	}
-	getContractJsonString, getContractJsonStringErr := responses.JSON(contracts)
-	if getContractJsonStringErr != nil {
-		log.Error.Printf("Error in ContractsInfo, could not format contracts as JSON. contracts: %v, error: %v", contracts, getContractJsonStringErr)
-		responses.SendRes(w, responses.JSON_Marshal_Error, contracts, getContractJsonStringErr.Error())
+	now := h.Clock.Now()
+
+	// Get building specified in route
+	buildingName := GetVarEntries(r, "building", SpacedName)
+	buildingDef, buildingOk := h.MainDictionary.Buildings[buildingName]
+	if !buildingOk {
+		log.Debug.Printf("in ConstructBuilding, building %s could not be mapped to known building", buildingName)
+		responses.SendRes(w, responses.Specified_Building_Not_Found, nil, "")
 		return
 	}
-	log.Debug.Printf("Sending response for ContractsInfo:\n%v", getContractJsonString)
-	responses.SendRes(w, responses.Generic_Success, contracts, "")
-	log.Debug.Println(log.Cyan("-- End ContractsInfo --"))
-}
+	building := schema.BuildingsToID[buildingName]
 
-// Handler function for the secure route: /api/my/contracts/{uuid}
-type ContractInfo struct {
-	Dbs *map[string]rdb.Database
-}
-func (h *ContractInfo) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	log.Debug.Println(log.Yellow("-- ContractInfo --"))
-	// Get symbol from route
-	id := GetVarEntries(r, "contract-id", AllCaps)
-	// Get userinfoContext from validation middleware
-	userInfo, userInfoErr := GetValidationFromCtx(r)
-	if userInfoErr != nil {
-		// Fail state getting context
-		log.Error.Printf("Could not get validationpair in AssistantInfo")
-		userInfoErrMsg := fmt.Sprintf("userInfo is nil, check auth validation context %v:\n%v", auth.ValidationContext, r.Context().Value(auth.ValidationContext))
-		responses.SendRes(w, responses.No_AuthPair_Context, nil, userInfoErrMsg)
-		return
-	}
-	uuid := userInfo.Username + "|Contract-" + id
-	log.Debug.Printf("ContractInfo Requested for: %s", uuid)
-	adb := (*h.Dbs)["contracts"]
-	contract, foundContract, contractsErr := schema.GetContractFromDB(uuid, adb)
-	if contractsErr != nil || !foundContract {
-		log.Error.Printf("Error in ContractInfo, could not get contract from DB. foundContract: %v, error: %v", foundContract, contractsErr)
-		responses.SendRes(w, responses.DB_Get_Failure, nil, contractsErr.Error())
+	// Get farm
+	farmSymbol := GetVarEntries(r, "location-symbol", AllCaps)
+	fuuid := userData.Username + "|Farm-" + farmSymbol
+	fdb := (*h.Dbs)["farms"]
+	farm, foundFarm, farmErr := schema.GetFarmFromDB(fuuid, fdb)
+	if farmErr != nil {
+		log.Error.Printf("Error in ConstructBuilding, could not get farm from DB. error: %v", farmErr)
+		responses.SendRes(w, responses.DB_Get_Failure, nil, farmErr.Error())
 		return
 	}
-	getContractJsonString, getContractJsonStringErr := responses.JSON(contract)
-	if getContractJsonStringErr != nil {
-		log.Error.Printf("Error in ContractInfo, could not format contracts as JSON. contracts: %v, error: %v", contract, getContractJsonStringErr)
-		responses.SendRes(w, responses.JSON_Marshal_Error, contract, getContractJsonStringErr.Error())
+	if !foundFarm {
+		log.Debug.Printf("in ConstructBuilding, farm %s not found", fuuid)
+		responses.SendRes(w, responses.Object_Not_Found, nil, "")
 		return
 	}
-	log.Debug.Printf("Sending response for ContractInfo:\n%v", getContractJsonString)
-	responses.SendRes(w, responses.Generic_Success, contract, "")
-	log.Debug.Println(log.Cyan("-- End ContractInfo --"))
-}
+	farm.CompleteConstruction(now.Unix())
 
-// Handler function for the secure route: /api/my/warehouses
-type WarehousesInfo struct {
-	Dbs *map[string]rdb.Database
-}
-func (h *WarehousesInfo) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	log.Debug.Println(log.Yellow("-- WarehousesInfo --"))
-	udb := (*h.Dbs)["users"]
-	OK, userData, _ := secureGetUser(w, r, udb)
-	if !OK {
-		return // Failure states handled by secureGetUser, simply return
-	}
-	adb := (*h.Dbs)["warehouses"]
-	warehouses, foundWarehouses, warehousesErr := schema.GetWarehousesFromDB(userData.Warehouses, adb)
-	if warehousesErr != nil || !foundWarehouses {
-		log.Error.Printf("Error in WarehousesInfo, could not get warehouses from DB. foundWarehouses: %v, error: %v", foundWarehouses, warehousesErr)
-		responses.SendRes(w, responses.DB_Get_Failure, nil, warehousesErr.Error())
+	// Validate farm can construct the next level
+	if farm.Construction != nil {
+		errmsg := fmt.Sprintf("in ConstructBuilding, farm is already constructing %s level %d, complete at %d", farm.Construction.Building, farm.Construction.Level, farm.Construction.CompleteTimestamp)
+		log.Debug.Printf(errmsg)
+		responses.SendRes(w, responses.Construction_Not_Allowed, farm.Construction, errmsg)
 		return
 	}
-	getWarehousesJsonString, getWarehousesJsonStringErr := responses.JSON(warehouses)
-	if getWarehousesJsonStringErr != nil {
-		log.Error.Printf("Error in WarehousesInfo, could not format warehouses as JSON. warehouses: %v, error: %v", warehouses, getWarehousesJsonStringErr)
-		responses.SendRes(w, responses.JSON_Marshal_Error, warehouses, getWarehousesJsonStringErr.Error())
+	nextLevel := farm.Buildings[building] + 1
+	levelDef, levelOk := buildingDef.Level(nextLevel)
+	if !levelOk {
+		errmsg := fmt.Sprintf("in ConstructBuilding, %s is already at max level %d", buildingName, buildingDef.MaxLevel())
+		log.Debug.Printf(errmsg)
+		responses.SendRes(w, responses.Construction_Not_Allowed, nil, errmsg)
 		return
 	}
-	log.Debug.Printf("Sending response for WarehousesInfo:\n%v", getWarehousesJsonString)
-	responses.SendRes(w, responses.Generic_Success, warehouses, "")
-	log.Debug.Println(log.Cyan("-- End WarehousesInfo --"))
-}
+	for requiredName, requiredLevel := range levelDef.RequiredBuildings {
+		if farm.Buildings[schema.BuildingsToID[requiredName]] < requiredLevel {
+			errmsg := fmt.Sprintf("in ConstructBuilding, %s level %d requires %s level %d", buildingName, nextLevel, requiredName, requiredLevel)
+			log.Debug.Printf(errmsg)
+			responses.SendRes(w, responses.Construction_Not_Allowed, nil, errmsg)
+			return
+		}
+	}
 
-// Handler function for the secure route: /api/my/warehouses/{uuid}
-type WarehouseInfo struct {
-	Dbs *map[string]rdb.Database
-}
-func (h *WarehouseInfo) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	log.Debug.Println(log.Yellow("-- WarehouseInfo --"))
-	// Get symbol from route
-	symbol := GetVarEntries(r, "location-symbol", AllCaps)
-	// Get userinfoContext from validation middleware
-	userInfo, userInfoErr := GetValidationFromCtx(r)
-	if userInfoErr != nil {
-		// Fail state getting context
-		log.Error.Printf("Could not get validationpair in AssistantInfo")
-		userInfoErrMsg := fmt.Sprintf("userInfo is nil, check auth validation context %v:\n%v", auth.ValidationContext, r.Context().Value(auth.ValidationContext))
-		responses.SendRes(w, responses.No_AuthPair_Context, nil, userInfoErrMsg)
+	// Validate costs
+	if missing := userData.Ledger.MissingCurrency(levelDef.Currencies); missing != "" {
+		errmsg := fmt.Sprintf("in ConstructBuilding, not enough %s in ledger for %s level %d", missing, buildingName, nextLevel)
+		log.Debug.Printf(errmsg)
+		responses.SendRes(w, responses.Construction_Not_Allowed, nil, errmsg)
 		return
 	}
-	uuid := userInfo.Username + "|Warehouse-" + symbol
-	log.Debug.Printf("WarehouseInfo Requested for: %s", uuid)
-	adb := (*h.Dbs)["warehouses"]
-	warehouse, foundWarehouse, warehousesErr := schema.GetWarehouseFromDB(uuid, adb)
-	if warehousesErr != nil {
-		log.Error.Printf("Error in WarehouseInfo, could not get warehouse from DB. foundWarehouse: %v, error: %v", foundWarehouse, warehousesErr)
-		responses.SendRes(w, responses.DB_Get_Failure, nil, warehousesErr.Error())
+	wuuid := userData.Username + "|Warehouse-" + farmSymbol
+	wdb := (*h.Dbs)["warehouses"]
+	warehouse, foundWarehouse, warehouseErr := schema.GetWarehouseFromDB(wuuid, wdb)
+	if warehouseErr != nil {
+		log.Error.Printf("Error in ConstructBuilding, could not get warehouse from DB. error: %v", warehouseErr)
+		responses.SendRes(w, responses.DB_Get_Failure, nil, warehouseErr.Error())
 		return
 	}
 	if !foundWarehouse {
-		log.Debug.Printf("in WarehouseInfo, warehouse not found for specified uuid. foundWarehouse: %v, error: %v", foundWarehouse, warehousesErr)
-		responses.SendRes(w, responses.Object_Not_Found, nil, "")
-		return
+		warehouse = *schema.NewEmptyWarehouse(userData.Username, farmSymbol)
 	}
-	getWarehouseJsonString, getWarehouseJsonStringErr := responses.JSON(warehouse)
-	if getWarehouseJsonStringErr != nil {
-		log.Error.Printf("Error in WarehouseInfo, could not format warehouses as JSON. warehouses: %v, error: %v", warehouse, getWarehouseJsonStringErr)
-		responses.SendRes(w, responses.JSON_Marshal_Error, warehouse, getWarehouseJsonStringErr.Error())
+	if category, missing, isMissing := warehouse.MissingWares(levelDef.Materials); isMissing {
+		errmsg := fmt.Sprintf("in ConstructBuilding, not enough %s %s in local warehouse for %s level %d", category, missing, buildingName, nextLevel)
+		log.Debug.Printf(errmsg)
+		responses.SendRes(w, responses.Construction_Not_Allowed, nil, errmsg)
 		return
 	}
-	log.Debug.Printf("Sending response for WarehouseInfo:\n%v", getWarehouseJsonString)
-	responses.SendRes(w, responses.Generic_Success, warehouse, "")
-	log.Debug.Println(log.Cyan("-- End WarehouseInfo --"))
-}
 
-// Handler function for the secure route: /api/my/plots
-type PlotsInfo struct {
-	Dbs *map[string]rdb.Database
-}
-func (h *PlotsInfo) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	log.Debug.Println(log.Yellow("-- PlotsInfo --"))
-	udb := (*h.Dbs)["users"]
-	OK, userData, _ := secureGetUser(w, r, udb)
-	if !OK {
-		return // Failure states handled by secureGetUser, simply return
-	}
-	// Get farms from db
-	adb := (*h.Dbs)["farms"]
-	farms, foundFarms, farmsErr := schema.GetFarmsFromDB(userData.Farms, adb)
-	if farmsErr != nil || !foundFarms {
-		log.Error.Printf("Error in FarmsInfo, could not get farms from DB. foundFarms: %v, error: %v", foundFarms, farmsErr)
-		responses.SendRes(w, responses.DB_Get_Failure, nil, farmsErr.Error())
+	// Pay and start construction
+	userData.Ledger.RemoveCurrencies(levelDef.Currencies)
+	warehouse.RemoveWares(levelDef.Materials)
+	farm.Construction = &schema.BuildingConstruction{
+		Building: building,
+		Level: nextLevel,
+		CompleteTimestamp: timecalc.AddSecondsToTimestamp(now, levelDef.ConstructionTime).Unix(),
+	}
+	farm.CompleteConstruction(now.Unix()) // instant when construction time is 0
+
+	// Save to DBs
+	saveFarmErr := schema.SaveFarmToDB(fdb, &farm)
+	if saveFarmErr != nil {
+		log.Error.Printf("Error in ConstructBuilding, could not save farm. error: %v", saveFarmErr)
+		responses.SendRes(w, responses.DB_Save_Failure, nil, saveFarmErr.Error())
 		return
 	}
-	// Get plots from farms
-	plots := make(map[string]schema.Plot, 0)
-	for _, farm := range farms {
-		for plotsymbol, plot := range farm.Plots {
-			plots[plotsymbol] = plot
+	if foundWarehouse {
+		saveWarehouseErr := schema.SaveWarehouseToDB(wdb, &warehouse)
+		if saveWarehouseErr != nil {
+			log.Error.Printf("Error in ConstructBuilding, could not save warehouse. error: %v", saveWarehouseErr)
+			responses.SendRes(w, responses.DB_Save_Failure, nil, saveWarehouseErr.Error())
+			return
 		}
 	}
-	getPlotsJsonString, getPlotsJsonStringErr := responses.JSON(plots)
-	if getPlotsJsonStringErr != nil {
-		log.Error.Printf("Error in PlotsInfo, could not format plots as JSON. plots: %v, error: %v", plots, getPlotsJsonStringErr)
-		responses.SendRes(w, responses.JSON_Marshal_Error, plots, getPlotsJsonStringErr.Error())
+	saveUserErr := schema.SaveUserToDB(udb, &userData)
+	if saveUserErr != nil {
+		log.Error.Printf("Error in ConstructBuilding, could not save user. error: %v", saveUserErr)
+		responses.SendRes(w, responses.DB_Save_Failure, nil, saveUserErr.Error())
 		return
 	}
-	log.Debug.Printf("Sending response for PlotsInfo:\n%v", getPlotsJsonString)
-	responses.SendRes(w, responses.Generic_Success, plots, "")
-	log.Debug.Println(log.Cyan("-- End PlotsInfo --"))
+	queueCoinsMetric(w, &userData)
+
+	responses.SendRes(w, responses.Generic_Success, map[string]interface{}{"farm": farm, "warehouse": warehouse, "ledger": userData.Ledger}, "")
+	log.Debug.Println(log.Cyan("-- End ConstructBuilding --"))
 }
 
-// Handler function for the secure route: /api/my/plots/{uuid}
-type PlotInfo struct {
-	Dbs *map[string]rdb.Database
+// Handler function for the secure route: POST: /api/my/farms/{location-symbol}/craft
+type CraftRecipe struct {
+	Dbs *map[string]rdb.InteractiveDB
+	MainDictionary *schema.MainDictionary
+	Clock timecalc.Clock
 }
-func (h *PlotInfo) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	log.Debug.Println(log.Yellow("-- PlotInfo --"))
-	// Get symbol from route
-	id := GetVarEntries(r, "plot-id", None)
-	// Get userinfoContext from validation middleware
-	userInfo, userInfoErr := GetValidationFromCtx(r)
-	if userInfoErr != nil {
-		// Fail state getting context
-		log.Error.Printf("Could not get validationpair in AssistantInfo")
-		userInfoErrMsg := fmt.Sprintf("userInfo is nil, check auth validation context %v:\n%v", auth.ValidationContext, r.Context().Value(auth.ValidationContext))
-		responses.SendRes(w, responses.No_AuthPair_Context, nil, userInfoErrMsg)
+func (h *CraftRecipe) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	secureTransact(w, r, h.Dbs, func(w http.ResponseWriter, r *http.Request, dbs *map[string]rdb.InteractiveDB) {
+		txHandler := *h
+		txHandler.Dbs = dbs
+		txHandler.serve(w, r)
+	})
+}
+func (h *CraftRecipe) serve(w http.ResponseWriter, r *http.Request) {
+	log.Debug.Println(log.Yellow("-- CraftRecipe --"))
+	udb := (*h.Dbs)["users"]
+	OK, userData, _ := secureGetUser(w, r, udb)
+	if !OK {
+		return // Failure states handled by secureGetUser, simply return
+	}
+	now := h.Clock.Now()
+
+	// unmarshall request body to get recipe and quantity
+	var body schema.CraftBody
+	decoder := json.NewDecoder(r.Body)
+	if decodeErr := decoder.Decode(&body); decodeErr != nil {
+		// Fail case, could not decode
+		errmsg := fmt.Sprintf("Decode Error in CraftRecipe: %v", decodeErr)
+		log.Debug.Printf(errmsg)
+		responses.SendRes(w, responses.Bad_Request, nil, "Could not decode request body, ensure it conforms to expected format.")
 		return
 	}
-	idSlice := strings.Split(id, "!")
-	if len(idSlice) < 2 {
-		// Fail, malformed plot id
-		errmsg := fmt.Sprintf("Malformed plot id, format must be '[farm-location-symbol]!Plot-[id-number]' received: %v", id)
+	if body.Quantity <= 0 || body.Quantity > schema.MaxCraftBatches {
+		errmsg := fmt.Sprintf("in CraftRecipe, invalid quantity, must be > 0 and <= %d, got %d.", schema.MaxCraftBatches, body.Quantity)
 		log.Debug.Printf(errmsg)
 		responses.SendRes(w, responses.Bad_Request, nil, errmsg)
 		return
 	}
-	uuid := userInfo.Username + "|Farm-" + idSlice[0] + "|" + idSlice[1]
-	log.Debug.Printf("PlotInfo Requested for: %s", uuid)
+	recipe, recipeOk := h.MainDictionary.GetRecipe(body.Recipe)
+	if !recipeOk {
+		log.Debug.Printf("in CraftRecipe, recipe %s could not be mapped to known recipe", body.Recipe)
+		responses.SendRes(w, responses.Specified_Recipe_Not_Found, nil, "")
+		return
+	}
+
 	// Get farm
-	symbolSlice := strings.Split(uuid, "|")
-	farmSymbol := strings.Join(symbolSlice[:len(symbolSlice)-1], "|")
-	adb := (*h.Dbs)["farms"]
-	farm, foundFarm, farmsErr := schema.GetFarmFromDB(farmSymbol, adb)
-	if farmsErr != nil || !foundFarm {
-		log.Error.Printf("Error in FarmInfo, could not get farm from DB. foundFarm: %v, error: %v", foundFarm, farmsErr)
-		responses.SendRes(w, responses.DB_Get_Failure, nil, farmsErr.Error())
+	farmSymbol := GetVarEntries(r, "location-symbol", AllCaps)
+	fuuid := userData.Username + "|Farm-" + farmSymbol
+	fdb := (*h.Dbs)["farms"]
+	farm, foundFarm, farmErr := schema.GetFarmFromDB(fuuid, fdb)
+	if farmErr != nil {
+		log.Error.Printf("Error in CraftRecipe, could not get farm from DB. error: %v", farmErr)
+		responses.SendRes(w, responses.DB_Get_Failure, nil, farmErr.Error())
 		return
 	}
-	plot := farm.Plots[uuid]
-	getPlotJsonString, getPlotJsonStringErr := responses.JSON(plot)
-	if getPlotJsonStringErr != nil {
-		log.Error.Printf("Error in PlotInfo, could not format plots as JSON. plots: %v, error: %v", plot, getPlotJsonStringErr)
-		responses.SendRes(w, responses.JSON_Marshal_Error, plot, getPlotJsonStringErr.Error())
+	if !foundFarm {
+		log.Debug.Printf("in CraftRecipe, farm %s not found", fuuid)
+		responses.SendRes(w, responses.Object_Not_Found, nil, "")
 		return
 	}
-	log.Debug.Printf("Sending response for PlotInfo:\n%v", getPlotJsonString)
-	responses.SendRes(w, responses.Generic_Success, plot, "")
-	log.Debug.Println(log.Cyan("-- End PlotInfo --"))
-}
+	farm.CompleteConstruction(now.Unix())
 
-// Handler function for the secure route: /api/my/plots/{uuid}/plant
-type PlantPlot struct {
-	Dbs *map[string]rdb.Database
-	MainDictionary *schema.MainDictionary
-}
-func (h *PlantPlot) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	log.Debug.Println(log.Yellow("-- PlantPlot --"))
-	// Get symbol from route
-	id := GetVarEntries(r, "plot-id", None)
-	// Get userinfoContext from validation middleware
-	userInfo, userInfoErr := GetValidationFromCtx(r)
-	if userInfoErr != nil {
-		// Fail state getting context
-		log.Error.Printf("Could not get validationpair in AssistantInfo")
-		userInfoErrMsg := fmt.Sprintf("userInfo is nil, check auth validation context %v:\n%v", auth.ValidationContext, r.Context().Value(auth.ValidationContext))
-		responses.SendRes(w, responses.No_AuthPair_Context, nil, userInfoErrMsg)
+	// Validate farm can craft the recipe
+	if farm.Crafting != nil {
+		errmsg := fmt.Sprintf("in CraftRecipe, farm is already crafting %d %s, complete at %d, collect it first", farm.Crafting.Quantity, farm.Crafting.Recipe, farm.Crafting.CompleteTimestamp)
+		log.Debug.Printf(errmsg)
+		responses.SendRes(w, responses.Crafting_Not_Allowed, farm.Crafting, errmsg)
 		return
 	}
-	idSlice := strings.Split(id, "!")
-	if len(idSlice) < 2 {
-		// Fail, malformed plot id
-		errmsg := fmt.Sprintf("Malformed plot id, format must be '[farm-location-symbol]!Plot-[id-number]' received: %v", id)
-		log.Debug.Printf(errmsg)
-		responses.SendRes(w, responses.Bad_Request, nil, errmsg)
+	if recipe.Category != "" {
+		effects := schema.GetFarmEffects(farm.Buildings, h.MainDictionary.Buildings)
+		if !effects.HasRecipeCategory(recipe.Category) {
+			errmsg := fmt.Sprintf("in CraftRecipe, %s is a %s recipe, which the farm's buildings do not unlock", recipe.Name, recipe.Category)
+			log.Debug.Printf(errmsg)
+			responses.SendRes(w, responses.Crafting_Not_Allowed, nil, errmsg)
+			return
+		}
+	}
+
+	// Validate materials
+	wuuid := userData.Username + "|Warehouse-" + farmSymbol
+	wdb := (*h.Dbs)["warehouses"]
+	warehouse, foundWarehouse, warehouseErr := schema.GetWarehouseFromDB(wuuid, wdb)
+	if warehouseErr != nil {
+		log.Error.Printf("Error in CraftRecipe, could not get warehouse from DB. error: %v", warehouseErr)
+		responses.SendRes(w, responses.DB_Get_Failure, nil, warehouseErr.Error())
 		return
 	}
-	uuid := userInfo.Username + "|Farm-" + idSlice[0] + "|" + idSlice[1]
-	symbolSlice := strings.Split(uuid, "|")
-	if len(symbolSlice) < 3 {
-		// Fail, malformed plot id
-		errmsg := fmt.Sprintf("Malformed plot id, symbolSlice less than 3: %v", symbolSlice)
+	if !foundWarehouse {
+		warehouse = *schema.NewEmptyWarehouse(userData.Username, farmSymbol)
+	}
+	materials, materialsOk := recipe.Materials.Scale(body.Quantity)
+	products, productsOk := recipe.Products.Scale(body.Quantity)
+	craftingTime, craftingTimeOk := schema.MulUint64(uint64(recipe.CraftingTime), body.Quantity)
+	if !materialsOk || !productsOk || !craftingTimeOk || craftingTime > math.MaxInt32 {
+		errmsg := fmt.Sprintf("in CraftRecipe, %d batches of %s is too large", body.Quantity, recipe.Name)
 		log.Debug.Printf(errmsg)
 		responses.SendRes(w, responses.Bad_Request, nil, errmsg)
 		return
 	}
-	farmLocationSymbol := strings.Join(symbolSlice[:len(symbolSlice)-1], "|")
-	locationSymbol := strings.Split(symbolSlice[1], "-")
-	warehouseLocationSymbol := symbolSlice[0] + "|Warehouse-" + strings.Join(locationSymbol[1:],"-")
-	log.Debug.Printf("PlantPlot Requested for: %s", uuid)
-	// unmarshall request body to get action and consumables if applicable
-	var body schema.PlotPlantBody
-	decoder := json.NewDecoder(r.Body)
-	if decodeErr := decoder.Decode(&body); decodeErr != nil {
-		// Fail case, could not decode
-		errmsg := fmt.Sprintf("Decode Error in PlantPlot: %v", decodeErr)
+	if category, missing, isMissing := warehouse.MissingWares(materials); isMissing {
+		errmsg := fmt.Sprintf("in CraftRecipe, not enough %s %s in local warehouse for %d %s", category, missing, body.Quantity, recipe.Name)
 		log.Debug.Printf(errmsg)
-		responses.SendRes(w, responses.Bad_Request, nil, "Could not decode request body, ensure it conforms to expected format.")
+		responses.SendRes(w, responses.Not_Enough_Items_In_Warehouse, nil, errmsg)
 		return
 	}
-	// Validate specified quantity is above 0
-	if body.SeedQuantity <= 0 {
-		// Fail, quantity must be > 0
-		errmsg := fmt.Sprintf("in PlantPlot, SeedQuantity MUST be greater than 0. received seed quantity: %v", body.SeedQuantity)
-		log.Debug.Printf(errmsg)
-		responses.SendRes(w, responses.Bad_Request, nil, errmsg)
-		return
+
+	// Consume materials and start crafting
+	warehouse.RemoveWares(materials)
+	farm.Crafting = &schema.Crafting{
+		Recipe: recipe.Name,
+		Quantity: body.Quantity,
+		Products: products,
+		CompleteTimestamp: timecalc.AddSecondsToTimestamp(now, int(craftingTime)).Unix(),
 	}
-	// Validate specified seed is a seed
-	seedsDict := (*h.MainDictionary).Seeds
-	plantName, plantNameOk := seedsDict[body.SeedName]
-	if !plantNameOk {
-		// Fail, seed name specified does not match a known seed
-		errmsg := fmt.Sprintf("in PlantPlot, SeedName does not map to seed in seed dictionary. received seed name: %v", body.SeedName)
-		log.Debug.Printf(errmsg)
-		responses.SendRes(w, responses.Item_Is_Not_Seed, nil, errmsg)
+
+	// Save to DBs
+	saveFarmErr := schema.SaveFarmToDB(fdb, &farm)
+	if saveFarmErr != nil {
+		log.Error.Printf("Error in CraftRecipe, could not save farm. error: %v", saveFarmErr)
+		responses.SendRes(w, responses.DB_Save_Failure, nil, saveFarmErr.Error())
 		return
 	}
-	// Validate specified seed meets min/max size requirements
-	plantDict := (*h.MainDictionary).Plants
-	plantDef, plantDefOk := plantDict[plantName]
-	if !plantDefOk {
-		// Fail, could not lookup plant with matching seed name, internal error
-		errmsg := fmt.Sprintf("Error in PlantPlot, found seed but not plant in master dictionary... received seed name: %v", body.SeedName)
-		log.Error.Printf(errmsg)
-		responses.SendRes(w, responses.Internal_Server_Error, nil, errmsg)
+	if foundWarehouse {
+		saveWarehouseErr := schema.SaveWarehouseToDB(wdb, &warehouse)
+		if saveWarehouseErr != nil {
+			log.Error.Printf("Error in CraftRecipe, could not save warehouse. error: %v", saveWarehouseErr)
+			responses.SendRes(w, responses.DB_Save_Failure, nil, saveWarehouseErr.Error())
+			return
+		}
+	}
+
+	responses.SendRes(w, responses.Generic_Success, map[string]interface{}{"farm": farm, "warehouse": warehouse}, "")
+	log.Debug.Println(log.Cyan("-- End CraftRecipe --"))
+}
+
+// Handler function for the secure route: DELETE: /api/my/farms/{location-symbol}/craft
+type CollectCrafting struct {
+	Dbs *map[string]rdb.InteractiveDB
+	MainDictionary *schema.MainDictionary
+	World *schema.World
+	Clock timecalc.Clock
+}
+func (h *CollectCrafting) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	secureTransact(w, r, h.Dbs, func(w http.ResponseWriter, r *http.Request, dbs *map[string]rdb.InteractiveDB) {
+		txHandler := *h
+		txHandler.Dbs = dbs
+		txHandler.serve(w, r)
+	})
+}
+func (h *CollectCrafting) serve(w http.ResponseWriter, r *http.Request) {
+	log.Debug.Println(log.Yellow("-- CollectCrafting --"))
+	udb := (*h.Dbs)["users"]
+	OK, userData, _ := secureGetUser(w, r, udb)
+	if !OK {
+		return // Failure states handled by secureGetUser, simply return
+	}
+	now := h.Clock.Now()
+
+	// Get farm
+	farmSymbol := GetVarEntries(r, "location-symbol", AllCaps)
+	fuuid := userData.Username + "|Farm-" + farmSymbol
+	fdb := (*h.Dbs)["farms"]
+	farm, foundFarm, farmErr := schema.GetFarmFromDB(fuuid, fdb)
+	if farmErr != nil {
+		log.Error.Printf("Error in CollectCrafting, could not get farm from DB. error: %v", farmErr)
+		responses.SendRes(w, responses.DB_Get_Failure, nil, farmErr.Error())
 		return
 	}
-	if body.SeedSize.String() == "" {
-		// FAIL invalid size
-		errmsg := fmt.Sprintf("in PlantPlot, invalid size")
-		log.Debug.Printf(errmsg)
-		responses.SendRes(w, responses.Bad_Request, nil, errmsg)
+	if !foundFarm {
+		log.Debug.Printf("in CollectCrafting, farm %s not found", fuuid)
+		responses.SendRes(w, responses.Object_Not_Found, nil, "")
 		return
 	}
-	if uint16(body.SeedSize) < uint16(schema.SizeToID[plantDef.MinSize]) {
-		// FAIL, this plant won't grow that small
-		errmsg := fmt.Sprintf("in PlantPlot, this plant won't grow that small (%s), minsize: %v", body.SeedSize, plantDef.MinSize)
+	farm.CompleteConstruction(now.Unix())
+
+	// Validate crafting complete
+	if farm.Crafting == nil {
+		errmsg := "in CollectCrafting, nothing is being crafted on the farm"
 		log.Debug.Printf(errmsg)
-		responses.SendRes(w, responses.Bad_Request, nil, errmsg)
+		responses.SendRes(w, responses.Crafting_Not_Allowed, nil, errmsg)
 		return
 	}
-	if uint16(body.SeedSize) > uint16(schema.SizeToID[plantDef.MaxSize]) {
-		// FAIL, this plant won't grow that large
-		errmsg := fmt.Sprintf("in PlantPlot, this plant won't grow that large (%s), maxsize: %v", body.SeedSize, plantDef.MaxSize)
+	if farm.Crafting.CompleteTimestamp > now.Unix() {
+		errmsg := fmt.Sprintf("in CollectCrafting, %s ready in %d seconds", farm.Crafting.Recipe, farm.Crafting.CompleteTimestamp - now.Unix())
 		log.Debug.Printf(errmsg)
-		responses.SendRes(w, responses.Bad_Request, nil, errmsg)
+		responses.SendRes(w, responses.Crafting_Not_Allowed, farm.Crafting, errmsg)
 		return
 	}
-	// Get warehouses
+
+	// Get warehouse
+	wuuid := userData.Username + "|Warehouse-" + farmSymbol
 	wdb := (*h.Dbs)["warehouses"]
-	warehouse, foundWarehouse, warehousesErr := schema.GetWarehouseFromDB(warehouseLocationSymbol, wdb)
-	if warehousesErr != nil || !foundWarehouse {
-		errmsg := fmt.Sprintf("Error in PlantPlot, could not get warehouse from DB. foundWarehouse: %v, error: %v", foundWarehouse, warehousesErr)
-		log.Error.Printf(errmsg)
-		responses.SendRes(w, responses.DB_Get_Failure, nil, errmsg)
-		return
-	}
-	// Validate seeds specified in given warehouse
-	numLocalSeeds, ownedSeedsOk := warehouse.Seeds[body.SeedName]; 
-	if !ownedSeedsOk {
-		// Fail, seed good not in warehouse
-		errmsg := fmt.Sprintf("Error in PlantPlot, Seed item not found in local warehouse. received good name: %v, warehouse goods: %v", body.SeedName, warehouse.Seeds)
-		log.Error.Printf(errmsg)
-		responses.SendRes(w, responses.Not_Enough_Items_In_Warehouse, nil, errmsg)
-		return
-	}
-	if numLocalSeeds < uint64(body.SeedQuantity) {
-		// Fail, not enough seeds in local inventory
-		errmsg := fmt.Sprintf("Error in PlantPlot, not enough seed item found in local warehouse. received good name: %v, # local goods: %v", body.SeedName, numLocalSeeds)
-		log.Error.Printf(errmsg)
-		responses.SendRes(w, responses.Not_Enough_Items_In_Warehouse, map[string]interface{}{"name": body.SeedName, "number_in_warehouse": numLocalSeeds}, errmsg)
+	warehouse, foundWarehouse, warehouseErr := schema.GetWarehouseFromDB(wuuid, wdb)
+	if warehouseErr != nil {
+		log.Error.Printf("Error in CollectCrafting, could not get warehouse from DB. error: %v", warehouseErr)
+		responses.SendRes(w, responses.DB_Get_Failure, nil, warehouseErr.Error())
 		return
 	}
-	// Get farms
-	fdb := (*h.Dbs)["farms"]
-	farm, foundFarm, farmsErr := schema.GetFarmFromDB(farmLocationSymbol, fdb)
-	if farmsErr != nil || !foundFarm {
-		log.Error.Printf("Error in PlantPlot, could not get farm from DB. foundFarm: %v, error: %v", foundFarm, farmsErr)
-		responses.SendRes(w, responses.DB_Get_Failure, nil, farmsErr.Error())
-		return
+	if !foundWarehouse {
+		warehouse = *schema.NewEmptyWarehouse(userData.Username, farmSymbol)
+		userData.Warehouses = append(userData.Warehouses, warehouse.UUID)
 	}
-	// Validate plot available for planting and body meets internal plot validation
-	plot := farm.Plots[uuid]
-	switch plot.IsPlantable(body) {
-	case responses.Plot_Already_Planted:
-		log.Debug.Printf("in PlantPlot, plot already planted")
-		responses.SendRes(w, responses.Plot_Already_Planted, plot, "")
-		return
-	case responses.Bad_Request:
-		log.Debug.Printf("in PlantPlot, seed size invalid")
-		responses.SendRes(w, responses.Bad_Request, plot, "Seed Size specified in request body was invalid")
-		return
-	case responses.Plot_Too_Small:
-		log.Debug.Printf("in PlantPlot, plot too small")
-		responses.SendRes(w, responses.Plot_Too_Small, plot, "")
+
+	// Validate warehouse has room for the products
+	capacity := schema.WarehouseCapacity(h.World.Locations[farmSymbol], schema.GetFarmEffects(farm.Buildings, h.MainDictionary.Buildings))
+	if farm.Crafting.Products.TotalSize() > warehouse.FreeCapacity(capacity) {
+		errmsg := fmt.Sprintf("in CollectCrafting, products (%d) exceed room in warehouse (%d of %d)", farm.Crafting.Products.TotalSize(), warehouse.FreeCapacity(capacity), capacity)
+		log.Debug.Printf(errmsg)
+		responses.SendRes(w, responses.Warehouse_Capacity_Exceeded, farm.Crafting, errmsg)
 		return
-	case responses.Generic_Success:
-		log.Debug.Printf("Plot ready for planting: %s", plot.UUID)
-	default:
-		log.Error.Fatalf("Received unexpected response type from plot.IsPlantable. plot: %v body: %v", plot, body)
 	}
 
-	plot.PlantedPlant = schema.NewPlant(plantName, body.SeedSize)
-	plot.Quantity = body.SeedQuantity
-	plot.GrowthCompleteTimestamp = time.Now().Unix()
-	warehouse.RemoveSeeds(body.SeedName, uint64(body.SeedQuantity))
-	farm.Plots[uuid] = plot
+	// Collect products
+	warehouse.AddWares(farm.Crafting.Products)
+	farm.Crafting = nil
 
 	// Save to DBs
-	saveWarehouseErr := schema.SaveWarehouseDataAtPathToDB(wdb, warehouseLocationSymbol, "seeds", warehouse.Seeds)
+	saveFarmErr := schema.SaveFarmToDB(fdb, &farm)
+	if saveFarmErr != nil {
+		log.Error.Printf("Error in CollectCrafting, could not save farm. error: %v", saveFarmErr)
+		responses.SendRes(w, responses.DB_Save_Failure, nil, saveFarmErr.Error())
+		return
+	}
+	saveWarehouseErr := schema.SaveWarehouseToDB(wdb, &warehouse)
 	if saveWarehouseErr != nil {
-		log.Error.Printf("Error in PlotInfo, could not save warehouse. error: %v", saveWarehouseErr)
+		log.Error.Printf("Error in CollectCrafting, could not save warehouse. error: %v", saveWarehouseErr)
 		responses.SendRes(w, responses.DB_Save_Failure, nil, saveWarehouseErr.Error())
 		return
 	}
-	saveFarmErr := schema.SaveFarmDataAtPathToDB(fdb, farmLocationSymbol, "plots", farm.Plots)
-	if saveFarmErr != nil {
-		log.Error.Printf("Error in PlantPlot, could not save farm. error: %v", saveFarmErr)
-		responses.SendRes(w, responses.DB_Save_Failure, nil, saveFarmErr.Error())
-		return
+	if !foundWarehouse {
+		saveUserErr := schema.SaveUserToDB(udb, &userData)
+		if saveUserErr != nil {
+			log.Error.Printf("Error in CollectCrafting, could not save user. error: %v", saveUserErr)
+			responses.SendRes(w, responses.DB_Save_Failure, nil, saveUserErr.Error())
+			return
+		}
+		queueCoinsMetric(w, &userData)
 	}
 
-	// Construct and Send response
-	response := schema.PlotPlantResponse{Warehouse: &warehouse, Plot: &plot, NextStage: &h.MainDictionary.Plants[plantName].GrowthStages[plot.PlantedPlant.CurrentStage]}
-	getPlotPlantResponseJsonString, getPlotPlantResponseJsonStringErr := responses.JSON(response)
-	if getPlotPlantResponseJsonStringErr != nil {
-		log.Error.Printf("Error in PlotInfo, could not format plant response as JSON. response: %v, error: %v", response, getPlotPlantResponseJsonStringErr)
-		responses.SendRes(w, responses.JSON_Marshal_Error, response, getPlotPlantResponseJsonStringErr.Error())
+	responses.SendRes(w, responses.Generic_Success, map[string]interface{}{"farm": farm, "warehouse": warehouse}, "")
+	log.Debug.Println(log.Cyan("-- End CollectCrafting --"))
+}
+
+// Handler function for the secure route: /api/my/contracts
+type ContractsInfo struct {
+	Dbs *map[string]rdb.InteractiveDB
+}
+func (h *ContractsInfo) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	log.Debug.Println(log.Yellow("-- ContractsInfo --"))
+	udb := (*h.Dbs)["users"]
+	OK, userData, _ := secureGetUser(w, r, udb)
+	if !OK {
+		return // Failure states handled by secureGetUser, simply return
+	}
+	adb := (*h.Dbs)["contracts"]
+	contracts, foundContracts, contractsErr := schema.GetContractsFromDB(userData.Contracts, adb)
+	if contractsErr != nil || !foundContracts {
+		log.Error.Printf("Error in ContractsInfo, could not get contracts from DB. foundContracts: %v, error: %v", foundContracts, contractsErr)
+		responses.SendRes(w, responses.DB_Get_Failure, nil, contractsErr.Error())
 		return
 	}
-	log.Debug.Printf("Sending response for PlantPlot:\n%v", getPlotPlantResponseJsonString)
-	responses.SendRes(w, responses.Generic_Success, response, "")
-	
-	log.Debug.Println(log.Cyan("-- End PlantPlot --"))
+	getContractJsonString, getContractJsonStringErr := responses.JSON(contracts)
+	if getContractJsonStringErr != nil {
+		log.Error.Printf("Error in ContractsInfo, could not format contracts as JSON. contracts: %v, error: %v", contracts, getContractJsonStringErr)
+		responses.SendRes(w, responses.JSON_Marshal_Error, contracts, getContractJsonStringErr.Error())
+		return
+	}
+	log.Debug.Printf("Sending response for ContractsInfo:\n%v", getContractJsonString)
+	responses.SendRes(w, responses.Generic_Success, contracts, "")
+	log.Debug.Println(log.Cyan("-- End ContractsInfo --"))
 }
 
-
-// Handler function for the secure route: /api/my/plots/{uuid}/clear
-type ClearPlot struct {
-	Dbs *map[string]rdb.Database
+// Handler function for the secure route: /api/my/contracts/{uuid}
+type ContractInfo struct {
+	Dbs *map[string]rdb.InteractiveDB
 }
-func (h *ClearPlot) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	log.Debug.Println(log.Yellow("-- ClearPlot --"))
+func (h *ContractInfo) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	log.Debug.Println(log.Yellow("-- ContractInfo --"))
 	// Get symbol from route
-	id := GetVarEntries(r, "plot-id", None)
+	id := GetVarEntries(r, "contract-id", AllCaps)
 	// Get userinfoContext from validation middleware
 	userInfo, userInfoErr := GetValidationFromCtx(r)
 	if userInfoErr != nil {
@@ -1731,546 +2161,2743 @@ func (h *ClearPlot) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		responses.SendRes(w, responses.No_AuthPair_Context, nil, userInfoErrMsg)
 		return
 	}
-	idSlice := strings.Split(id, "!")
-	if len(idSlice) < 2 {
-		// Fail, malformed plot id
-		errmsg := fmt.Sprintf("Malformed plot id, format must be '[farm-location-symbol]!Plot-[id-number]' received: %v", id)
-		log.Debug.Printf(errmsg)
-		responses.SendRes(w, responses.Bad_Request, nil, errmsg)
+	uuid := userInfo.Username + "|Contract-" + id
+	log.Debug.Printf("ContractInfo Requested for: %s", uuid)
+	adb := (*h.Dbs)["contracts"]
+	contract, foundContract, contractsErr := schema.GetContractFromDB(uuid, adb)
+	if contractsErr != nil || !foundContract {
+		log.Error.Printf("Error in ContractInfo, could not get contract from DB. foundContract: %v, error: %v", foundContract, contractsErr)
+		responses.SendRes(w, responses.DB_Get_Failure, nil, contractsErr.Error())
 		return
 	}
-	uuid := userInfo.Username + "|Farm-" + idSlice[0] + "|" + idSlice[1]
-	symbolSlice := strings.Split(uuid, "|")
-	if len(symbolSlice) < 3 {
-		// Fail, malformed plot id
-		errmsg := fmt.Sprintf("Malformed plot id, symbolSlice less than 3: %v", symbolSlice)
-		log.Debug.Printf(errmsg)
-		responses.SendRes(w, responses.Bad_Request, nil, errmsg)
+	getContractJsonString, getContractJsonStringErr := responses.JSON(contract)
+	if getContractJsonStringErr != nil {
+		log.Error.Printf("Error in ContractInfo, could not format contracts as JSON. contracts: %v, error: %v", contract, getContractJsonStringErr)
+		responses.SendRes(w, responses.JSON_Marshal_Error, contract, getContractJsonStringErr.Error())
 		return
 	}
-	farmLocationSymbol := strings.Join(symbolSlice[:len(symbolSlice)-1], "|")
-	log.Debug.Printf("ClearPlot Requested for: %s", uuid)
+	log.Debug.Printf("Sending response for ContractInfo:\n%v", getContractJsonString)
+	responses.SendRes(w, responses.Generic_Success, contract, "")
+	log.Debug.Println(log.Cyan("-- End ContractInfo --"))
+}
 
-	// Get farms
-	fdb := (*h.Dbs)["farms"]
-	farm, foundFarm, farmsErr := schema.GetFarmFromDB(farmLocationSymbol, fdb)
-	if farmsErr != nil || !foundFarm {
-		log.Error.Printf("Error in ClearPlot, could not get farm from DB. foundFarm: %v, error: %v", foundFarm, farmsErr)
-		responses.SendRes(w, responses.DB_Get_Failure, nil, farmsErr.Error())
+// Handler function for the secure route: /api/my/contracts/{uuid}/fulfill
+// Checks contract terms against assistants and the warehouse at the contract location, pays out rewards, and archives the contract
+type FulfillContract struct {
+	Dbs *map[string]rdb.InteractiveDB
+	MainDictionary *schema.MainDictionary
+	World *schema.World
+	Clock timecalc.Clock
+}
+func (h *FulfillContract) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	secureTransact(w, r, h.Dbs, func(w http.ResponseWriter, r *http.Request, dbs *map[string]rdb.InteractiveDB) {
+		txHandler := *h
+		txHandler.Dbs = dbs
+		txHandler.serve(w, r)
+	})
+}
+func (h *FulfillContract) serve(w http.ResponseWriter, r *http.Request) {
+	log.Debug.Println(log.Yellow("-- FulfillContract --"))
+	udb := (*h.Dbs)["users"]
+	OK, userData, _ := secureGetUser(w, r, udb)
+	if !OK {
+		return // Failure states handled by secureGetUser, simply return
+	}
+	// Get id from route
+	id := GetVarEntries(r, "contract-id", AllCaps)
+	uuid := userData.Username + "|Contract-" + id
+	if !stringInSlice(uuid, userData.Contracts) {
+		log.Debug.Printf("in FulfillContract, contract %s not in active contracts %v", uuid, userData.Contracts)
+		responses.SendRes(w, responses.Object_Not_Found, nil, "")
 		return
 	}
-
-	// Validate plot available for clearing and body meets internal plot validation
-	plot := farm.Plots[uuid]
-	if plot.PlantedPlant == nil {
-		log.Error.Printf("Error in ClearPlot, plot already empty")
-		responses.SendRes(w, responses.Plot_Already_Empty, plot, "")
+	cdb := (*h.Dbs)["contracts"]
+	contract, foundContract, contractErr := schema.GetContractFromDB(uuid, cdb)
+	if contractErr != nil || !foundContract {
+		errmsg := fmt.Sprintf("Error in FulfillContract, could not get contract from DB. foundContract: %v, error: %v", foundContract, contractErr)
+		log.Error.Printf(errmsg)
+		responses.SendRes(w, responses.DB_Get_Failure, nil, errmsg)
 		return
 	}
 
-	plot.PlantedPlant = nil
-	plot.Quantity = 0
-	plot.GrowthCompleteTimestamp = time.Now().Unix()
-	farm.Plots[uuid] = plot
+	// All but Collect contracts require an assistant at the contract location
+	if contract.ContractType != schema.ContractType_Collect {
+		adb := (*h.Dbs)["assistants"]
+		assistants, foundAssistants, assistantsErr := schema.GetAssistantsFromDB(userData.Assistants, adb)
+		if assistantsErr != nil || !foundAssistants {
+			errmsg := fmt.Sprintf("Error in FulfillContract, could not get assistants from DB. foundAssistants: %v, error: %v", foundAssistants, assistantsErr)
+			log.Error.Printf(errmsg)
+			responses.SendRes(w, responses.DB_Get_Failure, nil, errmsg)
+			return
+		}
+		assistantPresent := false
+		for _, assistant := range assistants {
+			if assistant.Location == contract.LocationSymbol {
+				assistantPresent = true
+				break
+			}
+		}
+		if !assistantPresent {
+			errmsg := fmt.Sprintf("in FulfillContract, %s contract requires an assistant at %s", contract.ContractType, contract.LocationSymbol)
+			log.Debug.Printf(errmsg)
+			responses.SendRes(w, responses.Contract_Terms_Not_Met, nil, errmsg)
+			return
+		}
+	}
 
-	// Save to DB
-	saveFarmErr := schema.SaveFarmDataAtPathToDB(fdb, farmLocationSymbol, "plots", farm.Plots)
-	if saveFarmErr != nil {
-		log.Error.Printf("Error in ClearPlot, could not save farm. error: %v", saveFarmErr)
-		responses.SendRes(w, responses.DB_Save_Failure, nil, saveFarmErr.Error())
-		return
+	// Get warehouse at contract location
+	wdb := (*h.Dbs)["warehouses"]
+	warehouseUUID := userData.Username + "|Warehouse-" + contract.LocationSymbol
+	var warehouse schema.Warehouse
+	if stringInSlice(warehouseUUID, userData.Warehouses) {
+		var foundWarehouse bool
+		var warehouseErr error
+		warehouse, foundWarehouse, warehouseErr = schema.GetWarehouseFromDB(warehouseUUID, wdb)
+		if warehouseErr != nil || !foundWarehouse {
+			errmsg := fmt.Sprintf("Error in FulfillContract, could not get warehouse from DB. foundWarehouse: %v, error: %v", foundWarehouse, warehouseErr)
+			log.Error.Printf(errmsg)
+			responses.SendRes(w, responses.DB_Get_Failure, nil, errmsg)
+			return
+		}
+	} else {
+		warehouse = *schema.NewEmptyWarehouse(userData.Username, contract.LocationSymbol)
 	}
 
-	// Construct and Send response
-	resmsg := fmt.Sprintf("Successfully cleared plot: %s", uuid)
-	log.Debug.Printf("Sending successful response for PlantPlot",)
-	responses.SendRes(w, responses.Generic_Success, plot, resmsg)
-	
-	log.Debug.Println(log.Cyan("-- End PlantPlot --"))
-}
-
-// Handler function for the secure route: /api/my/plots/{uuid}/interact
-type InteractPlot struct {
-	Dbs *map[string]rdb.Database
-	MainDictionary *schema.MainDictionary
-}
-func (h *InteractPlot) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	log.Debug.Println(log.Yellow("-- InteractPlot --"))
-	// Get symbol from route
-	id := GetVarEntries(r, "plot-id", None)
-	// Get userinfoContext from validation middleware
-	userInfo, userInfoErr := GetValidationFromCtx(r)
-	if userInfoErr != nil {
-		// Fail state getting context
-		log.Error.Printf("Could not get validationpair in AssistantInfo")
-		userInfoErrMsg := fmt.Sprintf("userInfo is nil, check auth validation context %v:\n%v", auth.ValidationContext, r.Context().Value(auth.ValidationContext))
-		responses.SendRes(w, responses.No_AuthPair_Context, nil, userInfoErrMsg)
-		return
-	}
-	idSlice := strings.Split(id, "!")
-	if len(idSlice) < 2 {
-		// Fail, malformed plot id
-		errmsg := fmt.Sprintf("Malformed plot id, format must be '[farm-location-symbol]!Plot-[id-number]' received: %v", id)
-		log.Debug.Printf(errmsg)
-		responses.SendRes(w, responses.Bad_Request, nil, errmsg)
-		return
-	}
-	uuid := userInfo.Username + "|Farm-" + idSlice[0] + "|" + idSlice[1]
-	symbolSlice := strings.Split(uuid, "|")
-	if len(symbolSlice) < 3 {
-		// Fail, malformed plot id
-		errmsg := fmt.Sprintf("Malformed plot id, symbolSlice less than 3: %v", symbolSlice)
-		log.Debug.Printf(errmsg)
-		responses.SendRes(w, responses.Bad_Request, nil, errmsg)
-		return
-	}
-	farmLocationSymbol := strings.Join(symbolSlice[:len(symbolSlice)-1], "|")
-	log.Debug.Printf("InteractPlot Requested for: %s", uuid)
-
-	// Get farm, plot
-	fdb := (*h.Dbs)["farms"]
-	farm, foundFarm, farmsErr := schema.GetFarmFromDB(farmLocationSymbol, fdb)
-	if farmsErr != nil || !foundFarm {
-		log.Error.Printf("Error in InteractPlot, could not get farm from DB. foundFarm: %v, error: %v", foundFarm, farmsErr)
-		responses.SendRes(w, responses.DB_Get_Failure, nil, farmsErr.Error())
-		return
-	}
-	plot := farm.Plots[uuid]
-
-	// Validate Planted
-	if plot.PlantedPlant == nil {
-		// no plant, cannot interact
-		log.Debug.Printf("in InteractPlot, plot not planted. foundPlot: %v", plot)
-		responses.SendRes(w, responses.Plot_Not_Planted, plot, "")
-		return
+	// Deliver and Courier contracts consume the term items from the local warehouse.
+	// The NPC of a Collect contract collects them from any of the user's warehouses, taking from the local warehouse first
+	sources := []*schema.Warehouse{&warehouse}
+	var others []schema.Warehouse
+	if contract.ContractType == schema.ContractType_Collect {
+		otherUUIDs := remove(append([]string{}, userData.Warehouses...), warehouseUUID)
+		if len(otherUUIDs) > 0 {
+			var foundOthers bool
+			var othersErr error
+			others, foundOthers, othersErr = schema.GetWarehousesFromDB(otherUUIDs, wdb)
+			if othersErr != nil || !foundOthers {
+				errmsg := fmt.Sprintf("Error in FulfillContract, could not get warehouses from DB. foundWarehouses: %v, error: %v", foundOthers, othersErr)
+				log.Error.Printf(errmsg)
+				responses.SendRes(w, responses.DB_Get_Failure, nil, errmsg)
+				return
+			}
+		}
+		for i := range others {
+			sources = append(sources, &others[i])
+		}
 	}
-
-	// Validate Timestamp
-	if plot.GrowthCompleteTimestamp > time.Now().Unix() {
-		// too soon, reject
-		timestampMsg := fmt.Sprintf("Ready in %d seconds", plot.GrowthCompleteTimestamp - time.Now().Unix())
-		responses.SendRes(w, responses.Plants_Still_Growing, plot, timestampMsg)
-		return
+	othersSizes := make([]uint64, len(others))
+	for i := range others {
+		othersSizes[i] = others[i].TotalSize()
 	}
-
-	// unmarshall request body to get action and consumables if applicable
-	var body schema.PlotInteractBody
-	decoder := json.NewDecoder(r.Body)
-	if decodeErr := decoder.Decode(&body); decodeErr != nil {
-		// Fail case, could not decode
-		errmsg := fmt.Sprintf("Decode Error in InteractPlot: %v", decodeErr)
-		log.Debug.Printf(errmsg)
-		responses.SendRes(w, responses.Bad_Request, nil, "Could not decode request body, ensure it conforms to expected format.")
-		return
+	if contract.ContractType != schema.ContractType_Talk {
+		required := make(map[string]uint64)
+		for _, term := range contract.Terms {
+			if term.Item != "" {
+				required[term.Item] += term.Quantity
+			}
+		}
+		for item, quantity := range required {
+			category, categoryOk := h.MainDictionary.GetItemCategory(item)
+			if !categoryOk {
+				errmsg := fmt.Sprintf("in FulfillContract, contract term item %s not found in dictionary", item)
+				log.Error.Printf(errmsg)
+				responses.SendRes(w, responses.Item_Does_Not_Exist, nil, errmsg)
+				return
+			}
+			if !schema.RemoveItemAcrossWarehouses(sources, category, item, quantity) {
+				errmsg := fmt.Sprintf("in FulfillContract, contract requires %s x%d but the warehouses it is taken from hold less", item, quantity)
+				log.Debug.Printf(errmsg)
+				responses.SendRes(w, responses.Contract_Terms_Not_Met, nil, errmsg)
+				return
+			}
+		}
 	}
 
-	// Get warehouse
-	wdb := (*h.Dbs)["warehouses"]
-	locationSymbol := strings.Split(symbolSlice[1], "-")
-	warehouseLocationSymbol := symbolSlice[0] + "|Warehouse-" + strings.Join(locationSymbol[1:],"-")
-	warehouse, foundWarehouse, warehousesErr := schema.GetWarehouseFromDB(warehouseLocationSymbol, wdb)
-	if warehousesErr != nil || !foundWarehouse {
-		errmsg := fmt.Sprintf("Error in InteractPlot, could not get warehouse from DB. foundWarehouse: %v, error: %v", foundWarehouse, warehousesErr)
-		log.Error.Printf(errmsg)
-		responses.SendRes(w, responses.DB_Get_Failure, nil, errmsg)
-		return
+	// Validate warehouse has room for item rewards, after the term items are taken
+	var rewardSize uint64
+	for _, reward := range contract.Reward {
+		if reward.RewardType == schema.RewardType_Item {
+			rewardSize += reward.Quantity
+		}
 	}
-
-	consumableQuantityAvailable := uint64(0)
-	consumableName := strings.Title(strings.ToLower(body.Consumable))
-	// If consumables included, validate them
-	if consumableName != string("") {
-		// Validate specified consumable is a good
-		goodsDict := (*h.MainDictionary).Goods
-		if _, ok := goodsDict[consumableName]; !ok {
-			// Fail, seed is not good
-			errmsg := fmt.Sprintf("in InteractPlot, consumable item does not exist in good dictionary. received consumable name: %v", consumableName)
-			log.Debug.Printf(errmsg)
-			responses.SendRes(w, responses.Item_Does_Not_Exist, nil, errmsg)
-			return
+	if rewardSize > 0 {
+		capacityOk, capacity := getWarehouseCapacity(w, (*h.Dbs)["farms"], userData, h.MainDictionary, h.World, contract.LocationSymbol, h.Clock.Now())
+		if !capacityOk {
+			return // Failure states handled by getWarehouseCapacity, simply return
 		}
-		// Validate consumable specified in given warehouse
-		temp, ownedConsumableOk := warehouse.Goods[consumableName]; 
-		if !ownedConsumableOk {
-			// Fail, consumable good not in warehouse
-			errmsg := fmt.Sprintf("in InteractPlot, consumable item not found in local warehouse. received good name: %v, warehouse goods: %v", consumableName, warehouse.Goods)
+		if rewardSize > warehouse.FreeCapacity(capacity) {
+			errmsg := fmt.Sprintf("in FulfillContract, reward items (%d) exceed room in warehouse (%d of %d)", rewardSize, warehouse.FreeCapacity(capacity), capacity)
 			log.Debug.Printf(errmsg)
-			responses.SendRes(w, responses.Not_Enough_Items_In_Warehouse, nil, errmsg)
+			responses.SendRes(w, responses.Warehouse_Capacity_Exceeded, nil, errmsg)
 			return
 		}
-		consumableQuantityAvailable = temp
 	}
 
-	// Validate plot available for interaction and body meets internal plot validation
-	plantDef, plantDefOk := h.MainDictionary.Plants[plot.PlantedPlant.PlantType]
-
-	if !plantDefOk {
-		plotDefErrMsg := fmt.Sprintf("Error in InteractPlot, Plot [%s] has PlantedPlant type [%s] not found in main dictionary!", plot.UUID, plot.PlantedPlant.PlantType)
-		log.Error.Println(plotDefErrMsg)
-		responses.SendRes(w, responses.Internal_Server_Error, plot, plotDefErrMsg)
-	}
-	plotValidationResponse, addedYield, usedConsumableQuantity, growthHarvest, growthTime, repeatStage, errInfoMsg := plot.IsInteractable(body, plantDef, consumableQuantityAvailable, warehouse.Tools)
-	switch plotValidationResponse {
-	case responses.Invalid_Plot_Action:
-		log.Debug.Printf("in PlotInteract, Invalid_Plot_Action")
-		responses.SendRes(w, responses.Invalid_Plot_Action, plot, errInfoMsg)
-		return
-	case responses.Tool_Not_Found:
-		log.Debug.Printf("in PlotInteract, Tool_Not_Found")
-		responses.SendRes(w, responses.Tool_Not_Found, plot, errInfoMsg)
-		return
-	case responses.Missing_Consumable_Selection:
-		log.Debug.Printf("in PlotInteract, Missing_Consumable_Selection")
-		responses.SendRes(w, responses.Missing_Consumable_Selection, plot, errInfoMsg)
-		return
-	case responses.Internal_Server_Error:
-		log.Debug.Printf("in PlotInteract, Internal_Server_Error")
-		responses.SendRes(w, responses.Internal_Server_Error, plot, errInfoMsg)
-		return
-	case responses.Not_Enough_Items_In_Warehouse:
-		log.Debug.Printf("in PlotInteract, Not_Enough_Items_In_Warehouse")
-		responses.SendRes(w, responses.Not_Enough_Items_In_Warehouse, plot, errInfoMsg)
-		return
-	case responses.Consumable_Not_In_Options:
-		log.Debug.Printf("in PlotInteract, Consumable_Not_In_Options")
-		responses.SendRes(w, responses.Consumable_Not_In_Options, plot, errInfoMsg)
-		return
-	case responses.Generic_Success:
-		log.Debug.Printf("Plot growth action validated successfully: %s, action: %s", plot.UUID, body.Action)
-	default:
-		log.Error.Fatalf("Received unexpected response type from plot.IsPlantable. plot: %v body: %v", plot, body)
+	// Pay out rewards
+	for _, reward := range contract.Reward {
+		switch reward.RewardType {
+		case schema.RewardType_Currency:
+			userData.Ledger.AddCurrency(reward.Item, reward.Quantity)
+		case schema.RewardType_Item:
+			category, categoryOk := h.MainDictionary.GetItemCategory(reward.Item)
+			if !categoryOk {
+				errmsg := fmt.Sprintf("in FulfillContract, contract reward item %s not found in dictionary", reward.Item)
+				log.Error.Printf(errmsg)
+				responses.SendRes(w, responses.Item_Does_Not_Exist, nil, errmsg)
+				return
+			}
+			warehouse.AddItem(category, reward.Item, reward.Quantity)
+		}
 	}
 
-	// Update objects with results of interaction
-
-	plot.PlantedPlant.Yield += addedYield
-	log.Debug.Printf("Interact Plot, Growth Time: %d", growthTime)
-	plot.GrowthCompleteTimestamp = time.Now().Unix() + growthTime
-	farm.Plots[uuid] = plot
+	// Fulfilling contracts improves favor with the NPC
+	userData.Ledger.AddFavor(contract.NPC, schema.ContractFavorGain)
 
-	if consumableName != string("") {
-		// if consumables used
-		warehouse.RemoveGoods(consumableName, usedConsumableQuantity)
+	// Archive contract
+	contract.Fulfilled = true
+	userData.Contracts = remove(userData.Contracts, contract.UUID)
+	userData.ArchivedContracts = append(userData.ArchivedContracts, contract.UUID)
+	saveContractErr := schema.SaveContractToDB(cdb, &contract)
+	if saveContractErr != nil {
+		log.Error.Printf("Error in FulfillContract, could not save contract. error: %v", saveContractErr)
+		responses.SendRes(w, responses.DB_Save_Failure, nil, saveContractErr.Error())
+		return
 	}
 
-
-	// Handle updating stage and potential harvesting
-	var nextStage *schema.GrowthStage
-
-	if growthHarvest != nil {
-		// if was a harvest action
-		harvest := plot.CalculateProduce(growthHarvest)
-		log.Debug.Println("Harvest Calculated:")
-		log.Debug.Println(harvest)
-		for producename, producequantity := range harvest.Produce {
-			log.Debug.Printf("Add produce %s quantity: %d", producename, producequantity)
-			warehouse.AddProduce(producename, producequantity)
-			log.Debug.Println(warehouse.Produce)
+	// Save other warehouses items were collected from, deleting any now empty
+	for i := range others {
+		if others[i].TotalSize() == othersSizes[i] {
+			continue
 		}
-		for seedname, seedquantity := range harvest.Seeds {
-			log.Debug.Printf("Add seed %s quantity: %d", seedname, seedquantity)
-			warehouse.AddSeeds(seedname, seedquantity)
-			log.Debug.Println(warehouse.Seeds)
+		if others[i].TotalSize() == 0 {
+			userData.Warehouses = remove(userData.Warehouses, others[i].UUID)
+			schema.DeleteWarehouseFromDB(wdb, others[i].UUID)
+			continue
 		}
-		for goodname, goodquantity := range harvest.Goods {
-			log.Debug.Printf("Add good %s quantity: %d", goodname, goodquantity)
-			warehouse.AddGoods(goodname, goodquantity)
-			log.Debug.Println(warehouse.Goods)
+		saveOtherErr := schema.SaveWarehouseToDB(wdb, &others[i])
+		if saveOtherErr != nil {
+			log.Error.Printf("Error in FulfillContract, could not save warehouse. error: %v", saveOtherErr)
+			responses.SendRes(w, responses.DB_Save_Failure, nil, saveOtherErr.Error())
+			return
 		}
+	}
 
-		log.Debug.Printf("Track Harvest Metric")
-		metrics.TrackHarvest(plantDef.Name)
-		
-		log.Debug.Printf("Check if is final harvest")
-		// check if final harvest
-		if growthHarvest.FinalHarvest {
-			// is final harvest
-			// clear
-			plot.PlantedPlant = nil
-			plot.Quantity = 0
-			farm.Plots[uuid] = plot
-		} else {
-			// not final harvest
-			// move up current stage if not repeat, initialize nextStage for response
-			if !repeatStage {
-				plot.PlantedPlant.CurrentStage++
-			}
-			nextStage = &h.MainDictionary.Plants[plot.PlantedPlant.PlantType].GrowthStages[plot.PlantedPlant.CurrentStage]
+	// If warehouse is empty now, delete it, else save it
+	if warehouse.TotalSize() == 0 {
+		if stringInSlice(warehouse.UUID, userData.Warehouses) {
+			userData.Warehouses = remove(userData.Warehouses, warehouse.UUID)
+			schema.DeleteWarehouseFromDB(wdb, warehouse.UUID)
 		}
 	} else {
-		// if not harvest
-		// move up current stage if not repeat, initialize nextStage for response
-		if !repeatStage {
-			plot.PlantedPlant.CurrentStage++
+		if !stringInSlice(warehouse.UUID, userData.Warehouses) {
+			userData.Warehouses = append(userData.Warehouses, warehouse.UUID)
+		}
+		saveWarehouseErr := schema.SaveWarehouseToDB(wdb, &warehouse)
+		if saveWarehouseErr != nil {
+			log.Error.Printf("Error in FulfillContract, could not save warehouse. error: %v", saveWarehouseErr)
+			responses.SendRes(w, responses.DB_Save_Failure, nil, saveWarehouseErr.Error())
+			return
 		}
-		nextStage = &h.MainDictionary.Plants[plot.PlantedPlant.PlantType].GrowthStages[plot.PlantedPlant.CurrentStage]
 	}
 
-	// Save to DBs
-		
-	saveFarmErr := schema.SaveFarmDataAtPathToDB(fdb, farmLocationSymbol, "plots", farm.Plots)
-	if saveFarmErr != nil {
-		log.Error.Printf("Error in PlotInteract, could not save farm. error: %v", saveFarmErr)
-		responses.SendRes(w, responses.DB_Save_Failure, nil, saveFarmErr.Error())
+	// Save user
+	saveUserErr := schema.SaveUserToDB(udb, &userData)
+	if saveUserErr != nil {
+		log.Error.Printf("Error in FulfillContract, could not save user. error: %v", saveUserErr)
+		responses.SendRes(w, responses.DB_Save_Failure, nil, saveUserErr.Error())
 		return
 	}
+	queueCoinsMetric(w, &userData)
 
-	saveWarehouseErr := schema.SaveWarehouseToDB(wdb, &warehouse)
-	if saveWarehouseErr != nil {
-		log.Error.Printf("Error in PlotInteract, could not save warehouse. error: %v", saveWarehouseErr)
-		responses.SendRes(w, responses.DB_Save_Failure, nil, saveWarehouseErr.Error())
-		return
-	}
+	queueEvent(w, userData.Username, schema.NewEvent(schema.Event_ContractCompleted, h.Clock.Now().Unix(), contract.UUID, contract.Reward))
 
-	// Construct and Send response
-	response := schema.PlotActionResponse{Warehouse: &warehouse, Plot: &plot, NextStage: nextStage}
-	getPlotPlantResponseJsonString, getPlotPlantResponseJsonStringErr := responses.JSON(response)
-	if getPlotPlantResponseJsonStringErr != nil {
-		log.Error.Printf("Error in PlotInfo, could not format interact response as JSON. response: %v, error: %v", response, getPlotPlantResponseJsonStringErr)
-		responses.SendRes(w, responses.JSON_Marshal_Error, response, getPlotPlantResponseJsonStringErr.Error())
+	res := map[string]interface{}{"contract": contract, "ledger": userData.Ledger, "warehouse": warehouse}
+	getResJsonString, getResJsonStringErr := responses.JSON(res)
+	if getResJsonStringErr != nil {
+		log.Error.Printf("Error in FulfillContract, could not format res as JSON. res: %v, error: %v", res, getResJsonStringErr)
+		responses.SendRes(w, responses.JSON_Marshal_Error, res, getResJsonStringErr.Error())
 		return
 	}
-	log.Debug.Printf("Sending response for InteractPlot:\n%v", getPlotPlantResponseJsonString)
-	responses.SendRes(w, responses.Generic_Success, response, "")
-	
-	log.Debug.Println(log.Cyan("-- End InteractPlot --"))
+	log.Debug.Printf("Sending response for FulfillContract:\n%v", getResJsonString)
+	responses.SendRes(w, responses.Generic_Success, res, "")
+	log.Debug.Println(log.Cyan("-- End FulfillContract --"))
 }
 
-// Handler function for the secure route: /api/my/markets/{symbol}/order
-// Returns a list of markets 
-type MarketOrder struct {
-	Dbs *map[string]rdb.Database
+// Handler function for the secure route: /api/my/locations/{symbol}/contracts
+// Returns the contract board at a location, with rewards adjusted by the user's favor
+type ContractBoardInfo struct {
+	Dbs *map[string]rdb.InteractiveDB
 	MainDictionary *schema.MainDictionary
+	World *schema.World
+	Clock timecalc.Clock
 }
-func (h *MarketOrder) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	log.Debug.Println(log.Yellow("-- MarketOrder --"))
+func (h *ContractBoardInfo) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	log.Debug.Println(log.Yellow("-- ContractBoardInfo --"))
 	udb := (*h.Dbs)["users"]
 	OK, userData, _ := secureGetUser(w, r, udb)
 	if !OK {
 		return // Failure states handled by secureGetUser, simply return
 	}
-	// Get assistant markets to determine fog of war
+	// Get assistant locations to determine fog of war
 	adb := (*h.Dbs)["assistants"]
 	assistants, foundAssistants, assistantsErr := schema.GetAssistantsFromDB(userData.Assistants, adb)
-	if assistantsErr != nil || !foundAssistants {
-		log.Error.Printf("Error in MarketOrder, could not get assistants from DB. foundAssistants: %v, error: %v", foundAssistants, assistantsErr)
+	if assistantsErr != nil {
+		log.Error.Printf("Error in ContractBoardInfo, could not get assistants from DB. error: %v", assistantsErr)
 		responses.SendRes(w, responses.DB_Get_Failure, nil, assistantsErr.Error())
 		return
 	}
-	// use myLocs as a set to get all unique markets visible in fow
-	myLocs := make(map[string]bool)
-	for _, assistant := range assistants {
-		myLocs[assistant.Location] = true
+	if !foundAssistants {
+		log.Debug.Printf("in ContractBoardInfo, no assistants found for %s", userData.Username)
+		responses.SendRes(w, responses.No_Assitant_At_Location, nil, "")
+		return
 	}
 	// Get symbol from route
-	symbol := GetVarEntries(r, "location-symbol", UUID)
-	// finally get specified market if available
-	var resMarket schema.Market
+	symbol := GetVarEntries(r, "location-symbol", AllCaps)
 	found := false
-	for market := range myLocs {
-		if strings.ToUpper(market) == symbol {
-			resMarket = h.MainDictionary.Markets[market]
+	for _, assistant := range assistants {
+		if assistant.Location == symbol {
 			found = true
 		}
 	}
 	if !found {
-		log.Debug.Printf("Not found %s in markets %v", symbol, myLocs)
+		log.Debug.Printf("No assistant at %s for ContractBoardInfo", symbol)
 		responses.SendRes(w, responses.No_Assitant_At_Location, nil, "")
 		return
 	}
+	boardOk, board := getContractBoard(w, (*h.Dbs)["contracts"], h.Clock.Now(), h.World, h.MainDictionary.Markets, symbol)
+	if !boardOk {
+		return // Failure states handled by getContractBoard, simply return
+	}
+	resBoard := board.ForUser(userData.Username, userData.Ledger.Favor)
+	getBoardJsonString, getBoardJsonStringErr := responses.JSON(resBoard)
+	if getBoardJsonStringErr != nil {
+		log.Error.Printf("Error in ContractBoardInfo, could not format contract board as JSON. board: %v, error: %v", resBoard, getBoardJsonStringErr)
+		responses.SendRes(w, responses.JSON_Marshal_Error, resBoard, getBoardJsonStringErr.Error())
+		return
+	}
+	log.Debug.Printf("Sending response for ContractBoardInfo:\n%v", getBoardJsonString)
+	responses.SendRes(w, responses.Generic_Success, resBoard, "")
+	log.Debug.Println(log.Cyan("-- End ContractBoardInfo --"))
+}
 
-	// NOW handle setting up the order and executing it if type is MARKET order
-	// unmarshall request order to get action and consumables if applicable
-	var order schema.MarketOrder
-	decoder := json.NewDecoder(r.Body)
-	if decodeErr := decoder.Decode(&order); decodeErr != nil {
-		// Fail case, could not decode
-		errmsg := fmt.Sprintf("Decode Error in MarketOrder: %v", decodeErr)
+// Handler function for the secure route: /api/my/locations/{symbol}/contracts/{listing-id}
+// Accepts a contract from the contract board at a location into the user's contracts
+type AcceptContract struct {
+	Dbs *map[string]rdb.InteractiveDB
+	MainDictionary *schema.MainDictionary
+	World *schema.World
+	Clock timecalc.Clock
+}
+func (h *AcceptContract) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	secureTransact(w, r, h.Dbs, func(w http.ResponseWriter, r *http.Request, dbs *map[string]rdb.InteractiveDB) {
+		txHandler := *h
+		txHandler.Dbs = dbs
+		txHandler.serve(w, r)
+	})
+}
+func (h *AcceptContract) serve(w http.ResponseWriter, r *http.Request) {
+	log.Debug.Println(log.Yellow("-- AcceptContract --"))
+	udb := (*h.Dbs)["users"]
+	OK, userData, _ := secureGetUser(w, r, udb)
+	if !OK {
+		return // Failure states handled by secureGetUser, simply return
+	}
+	// Get assistant locations to determine fog of war
+	adb := (*h.Dbs)["assistants"]
+	assistants, foundAssistants, assistantsErr := schema.GetAssistantsFromDB(userData.Assistants, adb)
+	if assistantsErr != nil {
+		log.Error.Printf("Error in AcceptContract, could not get assistants from DB. error: %v", assistantsErr)
+		responses.SendRes(w, responses.DB_Get_Failure, nil, assistantsErr.Error())
+		return
+	}
+	if !foundAssistants {
+		log.Debug.Printf("in AcceptContract, no assistants found for %s", userData.Username)
+		responses.SendRes(w, responses.No_Assitant_At_Location, nil, "")
+		return
+	}
+	// Get symbol and listing id from route
+	symbol := GetVarEntries(r, "location-symbol", AllCaps)
+	listingIdStr := GetVarEntries(r, "listing-id", None)
+	listingId, parseErr := strconv.ParseUint(listingIdStr, 10, 64)
+	if parseErr != nil {
+		errmsg := fmt.Sprintf("in AcceptContract, could not parse listing-id %s: %v", listingIdStr, parseErr)
 		log.Debug.Printf(errmsg)
-		responses.SendRes(w, responses.Bad_Request, nil, "Could not decode request body, ensure it conforms to expected format.")
+		responses.SendRes(w, responses.Could_Not_Parse_URI_Param, nil, errmsg)
 		return
 	}
-
-	// Get warehouse
-	wdb := (*h.Dbs)["warehouses"]
-	warehouseLocationSymbol := userData.Username + "|Warehouse-" + symbol
-	var warehouse schema.Warehouse
-	if stringInSlice(warehouseLocationSymbol, userData.Warehouses) {
-		// Warehouse should exist, get from DB
-		var foundWarehouse bool
-		var warehousesErr error
-		warehouse, foundWarehouse, warehousesErr = schema.GetWarehouseFromDB(warehouseLocationSymbol, wdb)
-		if warehousesErr != nil || !foundWarehouse {
-			errmsg := fmt.Sprintf("Error in MarketOrder, could not get warehouse from DB. foundWarehouse: %v, error: %v", foundWarehouse, warehousesErr)
-			log.Error.Printf(errmsg)
-			responses.SendRes(w, responses.DB_Get_Failure, nil, errmsg)
-			return
+	found := false
+	for _, assistant := range assistants {
+		if assistant.Location == symbol {
+			found = true
 		}
-	} else {
-		// Warehouse needs to be created
-		log.Debug.Printf("In MarketOrder: Creating warehouse for %s with uuid %s", userData.Username, symbol)
-		warehouse = *schema.NewEmptyWarehouse(userData.Username, symbol)
-		userData.Warehouses = append(userData.Warehouses, warehouse.UUID)
 	}
-	
-
-	// Validate order parameters
-	if order.Quantity <= 0 {
-		// fail, quantity too low
-		errmsg := fmt.Sprintf("in MarketOrder, invalid order quantity, must be > 0, got %d.", order.Quantity)
-		log.Debug.Printf(errmsg)
-		responses.SendRes(w, responses.Bad_Request, nil, errmsg)
+	if !found {
+		log.Debug.Printf("No assistant at %s for AcceptContract", symbol)
+		responses.SendRes(w, responses.No_Assitant_At_Location, nil, "")
 		return
 	}
-	// Find order item in specified market imports/exports
-	var ioField schema.MarketIOField
-	if order.TXType == schema.BUY {
-		ioField = resMarket.Exports
-	} else {
-		ioField = resMarket.Imports
+	cdb := (*h.Dbs)["contracts"]
+	boardOk, board := getContractBoard(w, cdb, h.Clock.Now(), h.World, h.MainDictionary.Markets, symbol)
+	if !boardOk {
+		return // Failure states handled by getContractBoard, simply return
 	}
-	var itemDict map[string]uint64
-	var warehouseDict map[string]uint64
-	var itemName string
-	var simpleItemName string
-	var sizeMod uint64
-	switch order.ItemCategory {
-	case schema.GOOD:
-		itemDict = ioField.Goods
-		warehouseDict = warehouse.Goods
-		itemName = order.ItemName
-		simpleItemName = order.ItemName
-		sizeMod = 1
-	case schema.SEED:
-		itemDict = ioField.Seeds
-		warehouseDict = warehouse.Seeds
-		itemName = order.ItemName
-		simpleItemName = order.ItemName
-		sizeMod = 1
-	case schema.TOOL:
-		itemDict = ioField.Tools
-		warehouseDict = warehouse.Tools
-		itemName = order.ItemName
-		simpleItemName = order.ItemName
-		sizeMod = 1
-	case schema.PRODUCE:
-		itemDict = ioField.Produce
-		warehouseDict = warehouse.Produce
-		splitSlice := strings.Split(order.ItemName, "|")
-		if len(splitSlice) <= 1 {
-			// fail, no size specified
-			errmsg := "in MarketOrder, order.ItemName for PRODUCE category MUST have size specified e.g. 'Potato|Large', ensure correct ItemCategory specified and item name contains size"
-			log.Debug.Printf("%s, %s: %v", errmsg, itemName, splitSlice)
-			responses.SendRes(w, responses.Market_Order_Failed_Validation, nil, errmsg)
-			return
-		}
-		size, ok := schema.SizeToID[strings.Title(strings.ToLower(splitSlice[1]))]
-		if !ok {
-			// fail nonsensical size
-			errmsg := fmt.Sprintf("in MarketOrder, order.ItemName for PRODUCE category MUST have valid size specified e.g. 'Potato|Large', ensure correct ItemCategory specified and item name contains a valid size (received: %s)", splitSlice[1])
-			log.Debug.Printf("%s, %s: %v", errmsg, itemName, splitSlice)
-			responses.SendRes(w, responses.Market_Order_Failed_Validation, nil, errmsg)
-			return
-		}
-		itemName = splitSlice[0] + "|" + strings.Title(strings.ToLower(splitSlice[1]))
-		simpleItemName = splitSlice[0]
-		sizeMod = uint64(size)
+	if listingId >= uint64(len(board.Listings)) || stringInSlice(userData.Username, board.Listings[listingId].AcceptedBy) {
+		log.Debug.Printf("in AcceptContract, listing %d not available on board %s", listingId, board.UUID)
+		responses.SendRes(w, responses.Object_Not_Found, nil, "")
+		return
 	}
-
-	// get market value
-	marketValue, mvOk := itemDict[simpleItemName]
-	if !mvOk {
-		// fail, item not in specified market list
-		errmsg := "in MarketOrder, order.ItemName not in itemDict, ensure correct ItemCategory specified and item is available for trade in specified market for given transaction type"
-		log.Debug.Printf("%s, %s: %v", errmsg, simpleItemName, itemDict)
-		responses.SendRes(w, responses.Market_Order_Failed_Validation, nil, errmsg)
+	listing := board.Listings[listingId]
+	favor := userData.Ledger.Favor[listing.NPC]
+	if favor < listing.MinFavor {
+		errmsg := fmt.Sprintf("in AcceptContract, listing requires %d favor with %s, have %d", listing.MinFavor, listing.NPC, favor)
+		log.Debug.Printf(errmsg)
+		responses.SendRes(w, responses.Contract_Terms_Not_Met, nil, errmsg)
 		return
 	}
 
-	// execute buy or sell is have enough in warehouse/ledger
-	log.Debug.Printf("Execute Market Order: %s %s %s x%d for %d each * %d sizeMod", order.OrderType, order.TXType, itemName, order.Quantity, itemDict[simpleItemName], sizeMod)
-	coins := userData.Ledger.Currencies["Coins"]
-	if order.TXType == schema.BUY {
-		orderCost := order.Quantity * marketValue * sizeMod
-		// Validate currency in ledger in sufficient quantity
-		if orderCost > coins {
-			// fail, not enough currency for specified item and quantity
-			errmsg := fmt.Sprintf("in MarketOrder, order cost %d > coins: %d", orderCost, coins)
-			log.Debug.Printf(errmsg)
-			responses.SendRes(w, responses.Market_Order_Failed_Validation, nil, errmsg)
-			return
-		}
-		// Execute buy
-		coins -= orderCost
-		if len(warehouseDict) == 0 {
-			// empty warehouseDict, create
-			warehouseDict = make(map[string]uint64)
-		}
-		warehouseDict[itemName] += order.Quantity
-		metrics.TrackMarketBuySell(itemName, true, order.Quantity)
-	} else {
-		orderProfit := order.Quantity * marketValue * sizeMod
-		// Validate in warehouse in sufficient quantity
-		warehouseQuantity, wqOk := warehouseDict[itemName]
-		if !wqOk {
-			// fail, item not in location's warehouse
-			errmsg := "in MarketOrder, specified item not found in local warehouse, ensure order.ItemCategory is specified correctly"
-			log.Debug.Printf("%s, %s: %v", errmsg, itemName, warehouseDict)
-			responses.SendRes(w, responses.Market_Order_Failed_Validation, nil, errmsg)
-			return
-		}
-		if warehouseQuantity < order.Quantity {
-			// fail, not enough in location's warehouse
-			errmsg := fmt.Sprintf("in MarketOrder, order specifies greater quantity %d than available in local warehouse %d.", order.Quantity, warehouseQuantity)
-			log.Debug.Printf(errmsg)
-			responses.SendRes(w, responses.Market_Order_Failed_Validation, nil, errmsg)
-			return
-		}
-		// Execute sell
-		coins += orderProfit
-		warehouseDict[itemName] -= order.Quantity
-		// Handle deleting if necessary
-		if warehouseDict[itemName] <= 0 {
-			delete(warehouseDict, itemName)
-		}
-		metrics.TrackMarketBuySell(itemName, false, order.Quantity)
-	}
-	
-	// Apply results to original objects
-	switch order.ItemCategory {
-	case schema.GOOD:
-		warehouse.Goods = warehouseDict
-	case schema.SEED:
-		warehouse.Seeds = warehouseDict
-	case schema.TOOL:
-		warehouse.Tools = warehouseDict
-	case schema.PRODUCE:
-		warehouse.Produce = warehouseDict
+	// Create contract
+	count := uint64(len(userData.Contracts) + len(userData.ArchivedContracts))
+	contract := schema.NewContract(userData.Username, count, listing.LocationSymbol, listing.ContractType, listing.NPC, listing.Terms, listing.RewardForFavor(favor))
+	saveContractErr := schema.SaveContractToDB(cdb, contract)
+	if saveContractErr != nil {
+		log.Error.Printf("Error in AcceptContract, could not save contract. error: %v", saveContractErr)
+		responses.SendRes(w, responses.DB_Save_Failure, nil, saveContractErr.Error())
+		return
 	}
-	userData.Ledger.Currencies["Coins"] = coins
+	userData.Contracts = append(userData.Contracts, contract.UUID)
 
-	// If warehouse is empty now, delete it, else save it
-	if warehouse.TotalSize() == 0 {
-		userData.Warehouses = remove(userData.Warehouses, warehouse.UUID)
-		schema.DeleteWarehouseFromDB(wdb, warehouse.UUID)
-	} else {
-		// Save warehouse
-		saveWarehouseErr := schema.SaveWarehouseToDB(wdb, &warehouse)
-		if saveWarehouseErr != nil {
-			log.Error.Printf("Error in MarketOrder, could not save warehouse. error: %v", saveWarehouseErr)
-			responses.SendRes(w, responses.DB_Save_Failure, nil, saveWarehouseErr.Error())
-			return
-		}
+	// Mark listing accepted
+	board.Listings[listingId].AcceptedBy = append(board.Listings[listingId].AcceptedBy, userData.Username)
+	saveBoardErr := schema.SaveContractBoardToDB(cdb, &board)
+	if saveBoardErr != nil {
+		log.Error.Printf("Error in AcceptContract, could not save contract board. error: %v", saveBoardErr)
+		responses.SendRes(w, responses.DB_Save_Failure, nil, saveBoardErr.Error())
+		return
 	}
 
 	// Save user
-	saveUserErr := schema.SaveUserToDB((*h.Dbs)["users"], &userData)
+	saveUserErr := schema.SaveUserToDB(udb, &userData)
 	if saveUserErr != nil {
-		log.Error.Printf("Error in MarketOrder, could not save user. error: %v", saveUserErr)
+		log.Error.Printf("Error in AcceptContract, could not save user. error: %v", saveUserErr)
 		responses.SendRes(w, responses.DB_Save_Failure, nil, saveUserErr.Error())
 		return
 	}
+	queueCoinsMetric(w, &userData)
 
-	responses.SendRes(w, responses.Generic_Success, map[string]interface{}{"warehouse": warehouse, "ledger": userData.Ledger}, "")
-	log.Debug.Println(log.Cyan("-- End MarketOrder --"))
-}
\ No newline at end of file
+	getContractJsonString, getContractJsonStringErr := responses.JSON(contract)
+	if getContractJsonStringErr != nil {
+		log.Error.Printf("Error in AcceptContract, could not format contract as JSON. contract: %v, error: %v", contract, getContractJsonStringErr)
+		responses.SendRes(w, responses.JSON_Marshal_Error, contract, getContractJsonStringErr.Error())
+		return
+	}
+	log.Debug.Printf("Sending response for AcceptContract:\n%v", getContractJsonString)
+	responses.SendRes(w, responses.Generic_Success, contract, "")
+	log.Debug.Println(log.Cyan("-- End AcceptContract --"))
+}
+
+// Handler function for the secure route: /api/my/warehouses
+type WarehousesInfo struct {
+	Dbs *map[string]rdb.InteractiveDB
+	MainDictionary *schema.MainDictionary
+	World *schema.World
+	Clock timecalc.Clock
+}
+func (h *WarehousesInfo) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	log.Debug.Println(log.Yellow("-- WarehousesInfo --"))
+	udb := (*h.Dbs)["users"]
+	OK, userData, _ := secureGetUser(w, r, udb)
+	if !OK {
+		return // Failure states handled by secureGetUser, simply return
+	}
+	adb := (*h.Dbs)["warehouses"]
+	warehouses, foundWarehouses, warehousesErr := schema.GetWarehousesFromDB(userData.Warehouses, adb)
+	if warehousesErr != nil || !foundWarehouses {
+		log.Error.Printf("Error in WarehousesInfo, could not get warehouses from DB. foundWarehouses: %v, error: %v", foundWarehouses, warehousesErr)
+		responses.SendRes(w, responses.DB_Get_Failure, nil, warehousesErr.Error())
+		return
+	}
+	// Show capacity of each warehouse
+	for i := range warehouses {
+		capacityOk, capacity := getWarehouseCapacity(w, (*h.Dbs)["farms"], userData, h.MainDictionary, h.World, warehouses[i].LocationSymbol, h.Clock.Now())
+		if !capacityOk {
+			return // Failure states handled by getWarehouseCapacity, simply return
+		}
+		warehouses[i].Capacity = capacity
+	}
+	getWarehousesJsonString, getWarehousesJsonStringErr := responses.JSON(warehouses)
+	if getWarehousesJsonStringErr != nil {
+		log.Error.Printf("Error in WarehousesInfo, could not format warehouses as JSON. warehouses: %v, error: %v", warehouses, getWarehousesJsonStringErr)
+		responses.SendRes(w, responses.JSON_Marshal_Error, warehouses, getWarehousesJsonStringErr.Error())
+		return
+	}
+	log.Debug.Printf("Sending response for WarehousesInfo:\n%v", getWarehousesJsonString)
+	responses.SendRes(w, responses.Generic_Success, warehouses, "")
+	log.Debug.Println(log.Cyan("-- End WarehousesInfo --"))
+}
+
+// Handler function for the secure route: /api/my/warehouses/{uuid}
+type WarehouseInfo struct {
+	Dbs *map[string]rdb.InteractiveDB
+	MainDictionary *schema.MainDictionary
+	World *schema.World
+	Clock timecalc.Clock
+}
+func (h *WarehouseInfo) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	log.Debug.Println(log.Yellow("-- WarehouseInfo --"))
+	// Get symbol from route
+	symbol := GetVarEntries(r, "location-symbol", AllCaps)
+	// Get userinfoContext from validation middleware
+	userInfo, userInfoErr := GetValidationFromCtx(r)
+	if userInfoErr != nil {
+		// Fail state getting context
+		log.Error.Printf("Could not get validationpair in AssistantInfo")
+		userInfoErrMsg := fmt.Sprintf("userInfo is nil, check auth validation context %v:\n%v", auth.ValidationContext, r.Context().Value(auth.ValidationContext))
+		responses.SendRes(w, responses.No_AuthPair_Context, nil, userInfoErrMsg)
+		return
+	}
+	uuid := userInfo.Username + "|Warehouse-" + symbol
+	log.Debug.Printf("WarehouseInfo Requested for: %s", uuid)
+	adb := (*h.Dbs)["warehouses"]
+	warehouse, foundWarehouse, warehousesErr := schema.GetWarehouseFromDB(uuid, adb)
+	if warehousesErr != nil {
+		log.Error.Printf("Error in WarehouseInfo, could not get warehouse from DB. foundWarehouse: %v, error: %v", foundWarehouse, warehousesErr)
+		responses.SendRes(w, responses.DB_Get_Failure, nil, warehousesErr.Error())
+		return
+	}
+	if !foundWarehouse {
+		log.Debug.Printf("in WarehouseInfo, warehouse not found for specified uuid. foundWarehouse: %v, error: %v", foundWarehouse, warehousesErr)
+		responses.SendRes(w, responses.Object_Not_Found, nil, "")
+		return
+	}
+	// Show capacity of warehouse
+	OK, userData, _ := secureGetUser(w, r, (*h.Dbs)["users"])
+	if !OK {
+		return // Failure states handled by secureGetUser, simply return
+	}
+	capacityOk, capacity := getWarehouseCapacity(w, (*h.Dbs)["farms"], userData, h.MainDictionary, h.World, warehouse.LocationSymbol, h.Clock.Now())
+	if !capacityOk {
+		return // Failure states handled by getWarehouseCapacity, simply return
+	}
+	warehouse.Capacity = capacity
+	getWarehouseJsonString, getWarehouseJsonStringErr := responses.JSON(warehouse)
+	if getWarehouseJsonStringErr != nil {
+		log.Error.Printf("Error in WarehouseInfo, could not format warehouses as JSON. warehouses: %v, error: %v", warehouse, getWarehouseJsonStringErr)
+		responses.SendRes(w, responses.JSON_Marshal_Error, warehouse, getWarehouseJsonStringErr.Error())
+		return
+	}
+	log.Debug.Printf("Sending response for WarehouseInfo:\n%v", getWarehouseJsonString)
+	responses.SendRes(w, responses.Generic_Success, warehouse, "")
+	log.Debug.Println(log.Cyan("-- End WarehouseInfo --"))
+}
+
+// Handler function for the secure route: /api/my/plots
+type PlotsInfo struct {
+	Dbs *map[string]rdb.InteractiveDB
+}
+func (h *PlotsInfo) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	log.Debug.Println(log.Yellow("-- PlotsInfo --"))
+	udb := (*h.Dbs)["users"]
+	OK, userData, _ := secureGetUser(w, r, udb)
+	if !OK {
+		return // Failure states handled by secureGetUser, simply return
+	}
+	// Get farms from db
+	adb := (*h.Dbs)["farms"]
+	farms, foundFarms, farmsErr := schema.GetFarmsFromDB(userData.Farms, adb)
+	if farmsErr != nil || !foundFarms {
+		log.Error.Printf("Error in FarmsInfo, could not get farms from DB. foundFarms: %v, error: %v", foundFarms, farmsErr)
+		responses.SendRes(w, responses.DB_Get_Failure, nil, farmsErr.Error())
+		return
+	}
+	// Get plots from farms
+	plots := make(map[string]schema.Plot, 0)
+	for _, farm := range farms {
+		for plotsymbol, plot := range farm.Plots {
+			plots[plotsymbol] = plot
+		}
+	}
+	getPlotsJsonString, getPlotsJsonStringErr := responses.JSON(plots)
+	if getPlotsJsonStringErr != nil {
+		log.Error.Printf("Error in PlotsInfo, could not format plots as JSON. plots: %v, error: %v", plots, getPlotsJsonStringErr)
+		responses.SendRes(w, responses.JSON_Marshal_Error, plots, getPlotsJsonStringErr.Error())
+		return
+	}
+	log.Debug.Printf("Sending response for PlotsInfo:\n%v", getPlotsJsonString)
+	responses.SendRes(w, responses.Generic_Success, plots, "")
+	log.Debug.Println(log.Cyan("-- End PlotsInfo --"))
+}
+
+// Handler function for the secure route: /api/my/plots/{uuid}
+type PlotInfo struct {
+	Dbs *map[string]rdb.InteractiveDB
+}
+func (h *PlotInfo) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	log.Debug.Println(log.Yellow("-- PlotInfo --"))
+	// Get symbol from route
+	id := GetVarEntries(r, "plot-id", None)
+	// Get userinfoContext from validation middleware
+	userInfo, userInfoErr := GetValidationFromCtx(r)
+	if userInfoErr != nil {
+		// Fail state getting context
+		log.Error.Printf("Could not get validationpair in AssistantInfo")
+		userInfoErrMsg := fmt.Sprintf("userInfo is nil, check auth validation context %v:\n%v", auth.ValidationContext, r.Context().Value(auth.ValidationContext))
+		responses.SendRes(w, responses.No_AuthPair_Context, nil, userInfoErrMsg)
+		return
+	}
+	idSlice := strings.Split(id, "!")
+	if len(idSlice) < 2 {
+		// Fail, malformed plot id
+		errmsg := fmt.Sprintf("Malformed plot id, format must be '[farm-location-symbol]!Plot-[id-number]' received: %v", id)
+		log.Debug.Printf(errmsg)
+		responses.SendRes(w, responses.Bad_Request, nil, errmsg)
+		return
+	}
+	uuid := userInfo.Username + "|Farm-" + idSlice[0] + "|" + idSlice[1]
+	log.Debug.Printf("PlotInfo Requested for: %s", uuid)
+	// Get farm
+	symbolSlice := strings.Split(uuid, "|")
+	farmSymbol := strings.Join(symbolSlice[:len(symbolSlice)-1], "|")
+	adb := (*h.Dbs)["farms"]
+	farm, foundFarm, farmsErr := schema.GetFarmFromDB(farmSymbol, adb)
+	if farmsErr != nil || !foundFarm {
+		log.Error.Printf("Error in FarmInfo, could not get farm from DB. foundFarm: %v, error: %v", foundFarm, farmsErr)
+		responses.SendRes(w, responses.DB_Get_Failure, nil, farmsErr.Error())
+		return
+	}
+	plot := farm.Plots[uuid]
+	getPlotJsonString, getPlotJsonStringErr := responses.JSON(plot)
+	if getPlotJsonStringErr != nil {
+		log.Error.Printf("Error in PlotInfo, could not format plots as JSON. plots: %v, error: %v", plot, getPlotJsonStringErr)
+		responses.SendRes(w, responses.JSON_Marshal_Error, plot, getPlotJsonStringErr.Error())
+		return
+	}
+	log.Debug.Printf("Sending response for PlotInfo:\n%v", getPlotJsonString)
+	responses.SendRes(w, responses.Generic_Success, plot, "")
+	log.Debug.Println(log.Cyan("-- End PlotInfo --"))
+}
+
+// Handler function for the secure route: /api/my/plots/{uuid}/plant
+type PlantPlot struct {
+	Dbs *map[string]rdb.InteractiveDB
+	MainDictionary *schema.MainDictionary
+	Clock timecalc.Clock
+}
+func (h *PlantPlot) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	secureTransact(w, r, h.Dbs, func(w http.ResponseWriter, r *http.Request, dbs *map[string]rdb.InteractiveDB) {
+		txHandler := *h
+		txHandler.Dbs = dbs
+		txHandler.serve(w, r)
+	})
+}
+func (h *PlantPlot) serve(w http.ResponseWriter, r *http.Request) {
+	log.Debug.Println(log.Yellow("-- PlantPlot --"))
+	// Get symbol from route
+	id := GetVarEntries(r, "plot-id", None)
+	// Get userinfoContext from validation middleware
+	userInfo, userInfoErr := GetValidationFromCtx(r)
+	if userInfoErr != nil {
+		// Fail state getting context
+		log.Error.Printf("Could not get validationpair in AssistantInfo")
+		userInfoErrMsg := fmt.Sprintf("userInfo is nil, check auth validation context %v:\n%v", auth.ValidationContext, r.Context().Value(auth.ValidationContext))
+		responses.SendRes(w, responses.No_AuthPair_Context, nil, userInfoErrMsg)
+		return
+	}
+	idSlice := strings.Split(id, "!")
+	if len(idSlice) < 2 {
+		// Fail, malformed plot id
+		errmsg := fmt.Sprintf("Malformed plot id, format must be '[farm-location-symbol]!Plot-[id-number]' received: %v", id)
+		log.Debug.Printf(errmsg)
+		responses.SendRes(w, responses.Bad_Request, nil, errmsg)
+		return
+	}
+	uuid := userInfo.Username + "|Farm-" + idSlice[0] + "|" + idSlice[1]
+	symbolSlice := strings.Split(uuid, "|")
+	if len(symbolSlice) < 3 {
+		// Fail, malformed plot id
+		errmsg := fmt.Sprintf("Malformed plot id, symbolSlice less than 3: %v", symbolSlice)
+		log.Debug.Printf(errmsg)
+		responses.SendRes(w, responses.Bad_Request, nil, errmsg)
+		return
+	}
+	farmLocationSymbol := strings.Join(symbolSlice[:len(symbolSlice)-1], "|")
+	locationSymbol := strings.Split(symbolSlice[1], "-")
+	warehouseLocationSymbol := symbolSlice[0] + "|Warehouse-" + strings.Join(locationSymbol[1:],"-")
+	log.Debug.Printf("PlantPlot Requested for: %s", uuid)
+	// unmarshall request body to get action and consumables if applicable
+	var body schema.PlotPlantBody
+	decoder := json.NewDecoder(r.Body)
+	if decodeErr := decoder.Decode(&body); decodeErr != nil {
+		// Fail case, could not decode
+		errmsg := fmt.Sprintf("Decode Error in PlantPlot: %v", decodeErr)
+		log.Debug.Printf(errmsg)
+		responses.SendRes(w, responses.Bad_Request, nil, "Could not decode request body, ensure it conforms to expected format.")
+		return
+	}
+	// Get warehouses
+	wdb := (*h.Dbs)["warehouses"]
+	warehouse, foundWarehouse, warehousesErr := schema.GetWarehouseFromDB(warehouseLocationSymbol, wdb)
+	if warehousesErr != nil || !foundWarehouse {
+		errmsg := fmt.Sprintf("Error in PlantPlot, could not get warehouse from DB. foundWarehouse: %v, error: %v", foundWarehouse, warehousesErr)
+		log.Error.Printf(errmsg)
+		responses.SendRes(w, responses.DB_Get_Failure, nil, errmsg)
+		return
+	}
+	// Get farms
+	fdb := (*h.Dbs)["farms"]
+	farm, foundFarm, farmsErr := schema.GetFarmFromDB(farmLocationSymbol, fdb)
+	if farmsErr != nil || !foundFarm {
+		log.Error.Printf("Error in PlantPlot, could not get farm from DB. foundFarm: %v, error: %v", foundFarm, farmsErr)
+		responses.SendRes(w, responses.DB_Get_Failure, nil, farmsErr.Error())
+		return
+	}
+	// Plant, updating farm and warehouse
+	OK, response := plantPlot(w, h.MainDictionary, h.Clock.Now(), &farm, &warehouse, uuid, body)
+	if !OK {
+		return // Failure states handled by plantPlot, simply return
+	}
+
+	// Save to DBs
+	saveWarehouseErr := schema.SaveWarehouseDataAtPathToDB(wdb, warehouseLocationSymbol, "seeds", warehouse.Seeds)
+	if saveWarehouseErr != nil {
+		log.Error.Printf("Error in PlotInfo, could not save warehouse. error: %v", saveWarehouseErr)
+		responses.SendRes(w, responses.DB_Save_Failure, nil, saveWarehouseErr.Error())
+		return
+	}
+	saveFarmErr := schema.SaveFarmDataAtPathToDB(fdb, farmLocationSymbol, "plots", farm.Plots)
+	if saveFarmErr != nil {
+		log.Error.Printf("Error in PlantPlot, could not save farm. error: %v", saveFarmErr)
+		responses.SendRes(w, responses.DB_Save_Failure, nil, saveFarmErr.Error())
+		return
+	}
+
+	// Construct and Send response
+	getPlotPlantResponseJsonString, getPlotPlantResponseJsonStringErr := responses.JSON(response)
+	if getPlotPlantResponseJsonStringErr != nil {
+		log.Error.Printf("Error in PlotInfo, could not format plant response as JSON. response: %v, error: %v", response, getPlotPlantResponseJsonStringErr)
+		responses.SendRes(w, responses.JSON_Marshal_Error, response, getPlotPlantResponseJsonStringErr.Error())
+		return
+	}
+	log.Debug.Printf("Sending response for PlantPlot:\n%v", getPlotPlantResponseJsonString)
+	responses.SendRes(w, responses.Generic_Success, response, "")
+	
+	log.Debug.Println(log.Cyan("-- End PlantPlot --"))
+}
+
+// Plant plot uuid on farm as described by body, taking the seeds from warehouse
+// Returns: OK, response; failure responses already sent to w and farm and warehouse left unchanged
+func plantPlot(w http.ResponseWriter, dictionary *schema.MainDictionary, now time.Time, farm *schema.Farm, warehouse *schema.Warehouse, uuid string, body schema.PlotPlantBody) (bool, schema.PlotPlantResponse) {
+	// Validate specified quantity is above 0
+	if body.SeedQuantity <= 0 {
+		// Fail, quantity must be > 0
+		errmsg := fmt.Sprintf("in PlantPlot, SeedQuantity MUST be greater than 0. received seed quantity: %v", body.SeedQuantity)
+		log.Debug.Printf(errmsg)
+		responses.SendRes(w, responses.Bad_Request, nil, errmsg)
+		return false, schema.PlotPlantResponse{}
+	}
+	// Validate specified seed is a seed
+	seedsDict := (*dictionary).Seeds
+	plantName, plantNameOk := seedsDict[body.SeedName]
+	if !plantNameOk {
+		// Fail, seed name specified does not match a known seed
+		errmsg := fmt.Sprintf("in PlantPlot, SeedName does not map to seed in seed dictionary. received seed name: %v", body.SeedName)
+		log.Debug.Printf(errmsg)
+		responses.SendRes(w, responses.Item_Is_Not_Seed, nil, errmsg)
+		return false, schema.PlotPlantResponse{}
+	}
+	// Validate specified seed meets min/max size requirements
+	plantDict := (*dictionary).Plants
+	plantDef, plantDefOk := plantDict[plantName]
+	if !plantDefOk {
+		// Fail, could not lookup plant with matching seed name, internal error
+		errmsg := fmt.Sprintf("Error in PlantPlot, found seed but not plant in master dictionary... received seed name: %v", body.SeedName)
+		log.Error.Printf(errmsg)
+		responses.SendRes(w, responses.Internal_Server_Error, nil, errmsg)
+		return false, schema.PlotPlantResponse{}
+	}
+	if body.SeedSize.String() == "" {
+		// FAIL invalid size
+		errmsg := fmt.Sprintf("in PlantPlot, invalid size")
+		log.Debug.Printf(errmsg)
+		responses.SendRes(w, responses.Bad_Request, nil, errmsg)
+		return false, schema.PlotPlantResponse{}
+	}
+	if uint16(body.SeedSize) < uint16(schema.SizeToID[plantDef.MinSize]) {
+		// FAIL, this plant won't grow that small
+		errmsg := fmt.Sprintf("in PlantPlot, this plant won't grow that small (%s), minsize: %v", body.SeedSize, plantDef.MinSize)
+		log.Debug.Printf(errmsg)
+		responses.SendRes(w, responses.Bad_Request, nil, errmsg)
+		return false, schema.PlotPlantResponse{}
+	}
+	if uint16(body.SeedSize) > uint16(schema.SizeToID[plantDef.MaxSize]) {
+		// FAIL, this plant won't grow that large
+		errmsg := fmt.Sprintf("in PlantPlot, this plant won't grow that large (%s), maxsize: %v", body.SeedSize, plantDef.MaxSize)
+		log.Debug.Printf(errmsg)
+		responses.SendRes(w, responses.Bad_Request, nil, errmsg)
+		return false, schema.PlotPlantResponse{}
+	}
+	// Validate seeds specified in given warehouse
+	numLocalSeeds, ownedSeedsOk := warehouse.Seeds[body.SeedName]
+	if !ownedSeedsOk {
+		// Fail, seed good not in warehouse
+		errmsg := fmt.Sprintf("Error in PlantPlot, Seed item not found in local warehouse. received good name: %v, warehouse goods: %v", body.SeedName, warehouse.Seeds)
+		log.Error.Printf(errmsg)
+		responses.SendRes(w, responses.Not_Enough_Items_In_Warehouse, nil, errmsg)
+		return false, schema.PlotPlantResponse{}
+	}
+	if numLocalSeeds < uint64(body.SeedQuantity) {
+		// Fail, not enough seeds in local inventory
+		errmsg := fmt.Sprintf("Error in PlantPlot, not enough seed item found in local warehouse. received good name: %v, # local goods: %v", body.SeedName, numLocalSeeds)
+		log.Error.Printf(errmsg)
+		responses.SendRes(w, responses.Not_Enough_Items_In_Warehouse, map[string]interface{}{"name": body.SeedName, "number_in_warehouse": numLocalSeeds}, errmsg)
+		return false, schema.PlotPlantResponse{}
+	}
+	// Validate farm's buildings allow raising livestock
+	if plantDef.Livestock {
+		farm.CompleteConstruction(now.Unix())
+		effects := schema.GetFarmEffects(farm.Buildings, dictionary.Buildings)
+		if !effects.HasLivestock(plantName) {
+			errmsg := fmt.Sprintf("in PlantPlot, %s is livestock and the farm's buildings do not allow raising it", plantName)
+			log.Debug.Printf(errmsg)
+			responses.SendRes(w, responses.Bad_Request, nil, errmsg)
+			return false, schema.PlotPlantResponse{}
+		}
+	}
+	// Validate plot available for planting and body meets internal plot validation
+	plot := farm.Plots[uuid]
+	switch plot.IsPlantable(body) {
+	case responses.Plot_Already_Planted:
+		log.Debug.Printf("in PlantPlot, plot already planted")
+		responses.SendRes(w, responses.Plot_Already_Planted, plot, "")
+		return false, schema.PlotPlantResponse{}
+	case responses.Bad_Request:
+		log.Debug.Printf("in PlantPlot, seed size invalid")
+		responses.SendRes(w, responses.Bad_Request, plot, "Seed Size specified in request body was invalid")
+		return false, schema.PlotPlantResponse{}
+	case responses.Plot_Too_Small:
+		log.Debug.Printf("in PlantPlot, plot too small")
+		responses.SendRes(w, responses.Plot_Too_Small, plot, "")
+		return false, schema.PlotPlantResponse{}
+	case responses.Generic_Success:
+		log.Debug.Printf("Plot ready for planting: %s", plot.UUID)
+	default:
+		log.Error.Fatalf("Received unexpected response type from plot.IsPlantable. plot: %v body: %v", plot, body)
+	}
+
+	plot.PlantedPlant = schema.NewPlant(plantName, body.SeedSize)
+	if farm.HasBonus(schema.FarmBonus_PristineSoil, now.Unix()) {
+		// Pristine Soil doubles base yield
+		plot.PlantedPlant.Yield *= 2
+	}
+	plot.Quantity = body.SeedQuantity
+	plot.GrowthCompleteTimestamp = now.Unix()
+	warehouse.RemoveSeeds(body.SeedName, uint64(body.SeedQuantity))
+	farm.Plots[uuid] = plot
+
+	return true, schema.PlotPlantResponse{Warehouse: warehouse, Plot: &plot, NextStage: &dictionary.Plants[plantName].GrowthStages[plot.PlantedPlant.CurrentStage]}
+}
+
+
+// Handler function for the secure route: /api/my/plots/{uuid}/clear
+type ClearPlot struct {
+	Dbs *map[string]rdb.InteractiveDB
+	Clock timecalc.Clock
+}
+func (h *ClearPlot) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	secureTransact(w, r, h.Dbs, func(w http.ResponseWriter, r *http.Request, dbs *map[string]rdb.InteractiveDB) {
+		txHandler := *h
+		txHandler.Dbs = dbs
+		txHandler.serve(w, r)
+	})
+}
+func (h *ClearPlot) serve(w http.ResponseWriter, r *http.Request) {
+	log.Debug.Println(log.Yellow("-- ClearPlot --"))
+	// Get symbol from route
+	id := GetVarEntries(r, "plot-id", None)
+	// Get userinfoContext from validation middleware
+	userInfo, userInfoErr := GetValidationFromCtx(r)
+	if userInfoErr != nil {
+		// Fail state getting context
+		log.Error.Printf("Could not get validationpair in AssistantInfo")
+		userInfoErrMsg := fmt.Sprintf("userInfo is nil, check auth validation context %v:\n%v", auth.ValidationContext, r.Context().Value(auth.ValidationContext))
+		responses.SendRes(w, responses.No_AuthPair_Context, nil, userInfoErrMsg)
+		return
+	}
+	idSlice := strings.Split(id, "!")
+	if len(idSlice) < 2 {
+		// Fail, malformed plot id
+		errmsg := fmt.Sprintf("Malformed plot id, format must be '[farm-location-symbol]!Plot-[id-number]' received: %v", id)
+		log.Debug.Printf(errmsg)
+		responses.SendRes(w, responses.Bad_Request, nil, errmsg)
+		return
+	}
+	uuid := userInfo.Username + "|Farm-" + idSlice[0] + "|" + idSlice[1]
+	symbolSlice := strings.Split(uuid, "|")
+	if len(symbolSlice) < 3 {
+		// Fail, malformed plot id
+		errmsg := fmt.Sprintf("Malformed plot id, symbolSlice less than 3: %v", symbolSlice)
+		log.Debug.Printf(errmsg)
+		responses.SendRes(w, responses.Bad_Request, nil, errmsg)
+		return
+	}
+	farmLocationSymbol := strings.Join(symbolSlice[:len(symbolSlice)-1], "|")
+	log.Debug.Printf("ClearPlot Requested for: %s", uuid)
+
+	// Get farms
+	fdb := (*h.Dbs)["farms"]
+	farm, foundFarm, farmsErr := schema.GetFarmFromDB(farmLocationSymbol, fdb)
+	if farmsErr != nil || !foundFarm {
+		log.Error.Printf("Error in ClearPlot, could not get farm from DB. foundFarm: %v, error: %v", foundFarm, farmsErr)
+		responses.SendRes(w, responses.DB_Get_Failure, nil, farmsErr.Error())
+		return
+	}
+
+	// Clear, updating farm
+	OK, plot := clearPlot(w, h.Clock.Now(), &farm, uuid)
+	if !OK {
+		return // Failure states handled by clearPlot, simply return
+	}
+
+	// Save to DB
+	saveFarmErr := schema.SaveFarmDataAtPathToDB(fdb, farmLocationSymbol, "plots", farm.Plots)
+	if saveFarmErr != nil {
+		log.Error.Printf("Error in ClearPlot, could not save farm. error: %v", saveFarmErr)
+		responses.SendRes(w, responses.DB_Save_Failure, nil, saveFarmErr.Error())
+		return
+	}
+
+	// Construct and Send response
+	resmsg := fmt.Sprintf("Successfully cleared plot: %s", uuid)
+	log.Debug.Printf("Sending successful response for PlantPlot",)
+	responses.SendRes(w, responses.Generic_Success, plot, resmsg)
+	
+	log.Debug.Println(log.Cyan("-- End PlantPlot --"))
+}
+
+// Clear plot uuid on farm, discarding its plant
+// Returns: OK, cleared plot; failure responses already sent to w and farm left unchanged
+func clearPlot(w http.ResponseWriter, now time.Time, farm *schema.Farm, uuid string) (bool, schema.Plot) {
+	// Validate plot available for clearing
+	plot := farm.Plots[uuid]
+	if plot.PlantedPlant == nil {
+		log.Error.Printf("Error in ClearPlot, plot already empty")
+		responses.SendRes(w, responses.Plot_Already_Empty, plot, "")
+		return false, schema.Plot{}
+	}
+
+	// Queued actions were for the cleared plant, drop them
+	plot.PlantedPlant = nil
+	plot.Quantity = 0
+	plot.Queue = nil
+	plot.GrowthCompleteTimestamp = now.Unix()
+	farm.Plots[uuid] = plot
+	return true, plot
+}
+
+// Handler function for the secure route: /api/my/plots/{uuid}/interact
+type InteractPlot struct {
+	Dbs *map[string]rdb.InteractiveDB
+	MainDictionary *schema.MainDictionary
+	World *schema.World
+	Clock timecalc.Clock
+	HarvestSeed int64 // mixed with plot and time so harvests can be replayed
+}
+func (h *InteractPlot) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	secureTransact(w, r, h.Dbs, func(w http.ResponseWriter, r *http.Request, dbs *map[string]rdb.InteractiveDB) {
+		txHandler := *h
+		txHandler.Dbs = dbs
+		txHandler.serve(w, r)
+	})
+}
+func (h *InteractPlot) serve(w http.ResponseWriter, r *http.Request) {
+	log.Debug.Println(log.Yellow("-- InteractPlot --"))
+	// Get symbol from route
+	id := GetVarEntries(r, "plot-id", None)
+	// Get userinfoContext from validation middleware
+	userInfo, userInfoErr := GetValidationFromCtx(r)
+	if userInfoErr != nil {
+		// Fail state getting context
+		log.Error.Printf("Could not get validationpair in AssistantInfo")
+		userInfoErrMsg := fmt.Sprintf("userInfo is nil, check auth validation context %v:\n%v", auth.ValidationContext, r.Context().Value(auth.ValidationContext))
+		responses.SendRes(w, responses.No_AuthPair_Context, nil, userInfoErrMsg)
+		return
+	}
+	idSlice := strings.Split(id, "!")
+	if len(idSlice) < 2 {
+		// Fail, malformed plot id
+		errmsg := fmt.Sprintf("Malformed plot id, format must be '[farm-location-symbol]!Plot-[id-number]' received: %v", id)
+		log.Debug.Printf(errmsg)
+		responses.SendRes(w, responses.Bad_Request, nil, errmsg)
+		return
+	}
+	uuid := userInfo.Username + "|Farm-" + idSlice[0] + "|" + idSlice[1]
+	symbolSlice := strings.Split(uuid, "|")
+	if len(symbolSlice) < 3 {
+		// Fail, malformed plot id
+		errmsg := fmt.Sprintf("Malformed plot id, symbolSlice less than 3: %v", symbolSlice)
+		log.Debug.Printf(errmsg)
+		responses.SendRes(w, responses.Bad_Request, nil, errmsg)
+		return
+	}
+	farmLocationSymbol := strings.Join(symbolSlice[:len(symbolSlice)-1], "|")
+	log.Debug.Printf("InteractPlot Requested for: %s", uuid)
+
+	// Get farm
+	fdb := (*h.Dbs)["farms"]
+	farm, foundFarm, farmsErr := schema.GetFarmFromDB(farmLocationSymbol, fdb)
+	if farmsErr != nil || !foundFarm {
+		log.Error.Printf("Error in InteractPlot, could not get farm from DB. foundFarm: %v, error: %v", foundFarm, farmsErr)
+		responses.SendRes(w, responses.DB_Get_Failure, nil, farmsErr.Error())
+		return
+	}
+
+	// unmarshall request body to get action and consumables if applicable
+	var body schema.PlotInteractBody
+	decoder := json.NewDecoder(r.Body)
+	if decodeErr := decoder.Decode(&body); decodeErr != nil {
+		// Fail case, could not decode
+		errmsg := fmt.Sprintf("Decode Error in InteractPlot: %v", decodeErr)
+		log.Debug.Printf(errmsg)
+		responses.SendRes(w, responses.Bad_Request, nil, "Could not decode request body, ensure it conforms to expected format.")
+		return
+	}
+
+	// Interact, updating farm and warehouse DBs
+	OK, response := interactPlot(w, h.Dbs, h.MainDictionary, h.World, h.HarvestSeed, h.Clock.Now(), userInfo.Username, farm, uuid, body)
+	if !OK {
+		return // Failure states handled by interactPlot, simply return
+	}
+
+	// Construct and Send response
+	getPlotPlantResponseJsonString, getPlotPlantResponseJsonStringErr := responses.JSON(response)
+	if getPlotPlantResponseJsonStringErr != nil {
+		log.Error.Printf("Error in PlotInfo, could not format interact response as JSON. response: %v, error: %v", response, getPlotPlantResponseJsonStringErr)
+		responses.SendRes(w, responses.JSON_Marshal_Error, response, getPlotPlantResponseJsonStringErr.Error())
+		return
+	}
+	log.Debug.Printf("Sending response for InteractPlot:\n%v", getPlotPlantResponseJsonString)
+	responses.SendRes(w, responses.Generic_Success, response, "")
+	
+	log.Debug.Println(log.Cyan("-- End InteractPlot --"))
+}
+
+// Interact with plot uuid on farm as described by body, saving the farm and warehouse
+// Returns: OK, response; failure responses already sent to w
+func interactPlot(w http.ResponseWriter, dbs *map[string]rdb.InteractiveDB, dictionary *schema.MainDictionary, world *schema.World, harvestSeed int64, now time.Time, username string, farm schema.Farm, uuid string, body schema.PlotInteractBody) (bool, schema.PlotActionResponse) {
+	// Get warehouse
+	wdb := (*dbs)["warehouses"]
+	warehouseLocationSymbol := username + "|Warehouse-" + farm.LocationSymbol
+	warehouse, foundWarehouse, warehousesErr := schema.GetWarehouseFromDB(warehouseLocationSymbol, wdb)
+	if warehousesErr != nil || !foundWarehouse {
+		errmsg := fmt.Sprintf("Error in InteractPlot, could not get warehouse from DB. foundWarehouse: %v, error: %v", foundWarehouse, warehousesErr)
+		log.Error.Printf(errmsg)
+		responses.SendRes(w, responses.DB_Get_Failure, nil, errmsg)
+		return false, schema.PlotActionResponse{}
+	}
+
+	// Interact, updating farm and warehouse
+	OK, response := applyPlotInteraction(w, dictionary, world, harvestSeed, now, &farm, &warehouse, uuid, body)
+	if !OK {
+		return false, schema.PlotActionResponse{} // Failure states handled by applyPlotInteraction
+	}
+
+	// Save to DBs
+	fdb := (*dbs)["farms"]
+	saveFarmErr := schema.SaveFarmDataAtPathToDB(fdb, farm.UUID, "plots", farm.Plots)
+	if saveFarmErr != nil {
+		log.Error.Printf("Error in PlotInteract, could not save farm. error: %v", saveFarmErr)
+		responses.SendRes(w, responses.DB_Save_Failure, nil, saveFarmErr.Error())
+		return false, schema.PlotActionResponse{}
+	}
+
+	saveWarehouseErr := schema.SaveWarehouseToDB(wdb, &warehouse)
+	if saveWarehouseErr != nil {
+		log.Error.Printf("Error in PlotInteract, could not save warehouse. error: %v", saveWarehouseErr)
+		responses.SendRes(w, responses.DB_Save_Failure, nil, saveWarehouseErr.Error())
+		return false, schema.PlotActionResponse{}
+	}
+
+	queueEvent(w, username, schema.NewEvent(schema.Event_PlotInteracted, now.Unix(), uuid, map[string]interface{}{"action": body.Action, "plot": response.Plot}))
+
+	return true, response
+}
+
+// Interact with plot uuid on farm as described by body, using and filling warehouse
+// Returns: OK, response; failure responses already sent to w and farm and warehouse left unchanged
+func applyPlotInteraction(w http.ResponseWriter, dictionary *schema.MainDictionary, world *schema.World, harvestSeed int64, now time.Time, farm *schema.Farm, warehouse *schema.Warehouse, uuid string, body schema.PlotInteractBody) (bool, schema.PlotActionResponse) {
+	plot := farm.Plots[uuid]
+
+	// Validate Planted
+	if plot.PlantedPlant == nil {
+		// no plant, cannot interact
+		log.Debug.Printf("in InteractPlot, plot not planted. foundPlot: %v", plot)
+		responses.SendRes(w, responses.Plot_Not_Planted, plot, "")
+		return false, schema.PlotActionResponse{}
+	}
+
+	// Validate Timestamp
+	if plot.GrowthCompleteTimestamp > now.Unix() {
+		// too soon, reject
+		timestampMsg := fmt.Sprintf("Ready in %d seconds", plot.GrowthCompleteTimestamp - now.Unix())
+		responses.SendRes(w, responses.Plants_Still_Growing, plot, timestampMsg)
+		return false, schema.PlotActionResponse{}
+	}
+
+	consumableQuantityAvailable := uint64(0)
+	consumableName := strings.Title(strings.ToLower(body.Consumable))
+	// If consumables included, validate them
+	if consumableName != string("") {
+		// Validate specified consumable is a good
+		goodsDict := dictionary.Goods
+		if _, ok := goodsDict[consumableName]; !ok {
+			// Fail, seed is not good
+			errmsg := fmt.Sprintf("in InteractPlot, consumable item does not exist in good dictionary. received consumable name: %v", consumableName)
+			log.Debug.Printf(errmsg)
+			responses.SendRes(w, responses.Item_Does_Not_Exist, nil, errmsg)
+			return false, schema.PlotActionResponse{}
+		}
+		// Validate consumable specified in given warehouse
+		temp, ownedConsumableOk := warehouse.Goods[consumableName]
+		if !ownedConsumableOk {
+			// Fail, consumable good not in warehouse
+			errmsg := fmt.Sprintf("in InteractPlot, consumable item not found in local warehouse. received good name: %v, warehouse goods: %v", consumableName, warehouse.Goods)
+			log.Debug.Printf(errmsg)
+			responses.SendRes(w, responses.Not_Enough_Items_In_Warehouse, nil, errmsg)
+			return false, schema.PlotActionResponse{}
+		}
+		consumableQuantityAvailable = temp
+	}
+
+	// Validate plot available for interaction and body meets internal plot validation
+	plantDef, plantDefOk := dictionary.Plants[plot.PlantedPlant.PlantType]
+
+	if !plantDefOk {
+		plotDefErrMsg := fmt.Sprintf("Error in InteractPlot, Plot [%s] has PlantedPlant type [%s] not found in main dictionary!", plot.UUID, plot.PlantedPlant.PlantType)
+		log.Error.Println(plotDefErrMsg)
+		responses.SendRes(w, responses.Internal_Server_Error, plot, plotDefErrMsg)
+		return false, schema.PlotActionResponse{}
+	}
+	plotValidationResponse, addedYield, usedConsumableQuantity, growthHarvest, growthTime, repeatStage, errInfoMsg := plot.IsInteractable(body, plantDef, consumableQuantityAvailable, warehouse.Tools)
+	switch plotValidationResponse {
+	case responses.Invalid_Plot_Action:
+		log.Debug.Printf("in PlotInteract, Invalid_Plot_Action")
+		responses.SendRes(w, responses.Invalid_Plot_Action, plot, errInfoMsg)
+		return false, schema.PlotActionResponse{}
+	case responses.Tool_Not_Found:
+		log.Debug.Printf("in PlotInteract, Tool_Not_Found")
+		responses.SendRes(w, responses.Tool_Not_Found, plot, errInfoMsg)
+		return false, schema.PlotActionResponse{}
+	case responses.Missing_Consumable_Selection:
+		log.Debug.Printf("in PlotInteract, Missing_Consumable_Selection")
+		responses.SendRes(w, responses.Missing_Consumable_Selection, plot, errInfoMsg)
+		return false, schema.PlotActionResponse{}
+	case responses.Internal_Server_Error:
+		log.Debug.Printf("in PlotInteract, Internal_Server_Error")
+		responses.SendRes(w, responses.Internal_Server_Error, plot, errInfoMsg)
+		return false, schema.PlotActionResponse{}
+	case responses.Not_Enough_Items_In_Warehouse:
+		log.Debug.Printf("in PlotInteract, Not_Enough_Items_In_Warehouse")
+		responses.SendRes(w, responses.Not_Enough_Items_In_Warehouse, plot, errInfoMsg)
+		return false, schema.PlotActionResponse{}
+	case responses.Consumable_Not_In_Options:
+		log.Debug.Printf("in PlotInteract, Consumable_Not_In_Options")
+		responses.SendRes(w, responses.Consumable_Not_In_Options, plot, errInfoMsg)
+		return false, schema.PlotActionResponse{}
+	case responses.Generic_Success:
+		log.Debug.Printf("Plot growth action validated successfully: %s, action: %s", plot.UUID, body.Action)
+	default:
+		log.Error.Fatalf("Received unexpected response type from plot.IsPlantable. plot: %v body: %v", plot, body)
+	}
+
+	// Update a copy of the plant, so farm is unchanged if the harvest fails
+	plant := *plot.PlantedPlant
+	plot.PlantedPlant = &plant
+	plot.PlantedPlant.Yield += addedYield
+	log.Debug.Printf("Interact Plot, Growth Time: %d", growthTime)
+	plot.GrowthCompleteTimestamp = now.Unix() + growthTime
+
+	var harvest schema.HarvestProduce
+	if growthHarvest != nil {
+		// if was a harvest action
+		harvest = plot.CalculateProduce(plot.HarvestRand(harvestSeed, now), growthHarvest)
+		log.Debug.Println("Harvest Calculated:")
+		log.Debug.Println(harvest)
+		// Validate warehouse has room for the harvest, including the room freed by any consumables used
+		farm.CompleteConstruction(now.Unix())
+		capacity := schema.WarehouseCapacity(world.Locations[warehouse.LocationSymbol], schema.GetFarmEffects(farm.Buildings, dictionary.Buildings))
+		if harvest.TotalSize() > warehouse.FreeCapacity(capacity + usedConsumableQuantity) {
+			errmsg := fmt.Sprintf("in InteractPlot, harvest (%d) exceeds room in warehouse (%d of %d)", harvest.TotalSize(), warehouse.FreeCapacity(capacity + usedConsumableQuantity), capacity)
+			log.Debug.Printf(errmsg)
+			responses.SendRes(w, responses.Warehouse_Capacity_Exceeded, farm.Plots[uuid], errmsg)
+			return false, schema.PlotActionResponse{}
+		}
+	}
+
+	// VALID: Update farm and warehouse with results of interaction
+	if consumableName != string("") {
+		// if consumables used
+		warehouse.RemoveGoods(consumableName, usedConsumableQuantity)
+	}
+
+	// Handle updating stage and potential harvesting
+	var nextStage *schema.GrowthStage
+
+	if growthHarvest != nil {
+		for producename, producequantity := range harvest.Produce {
+			log.Debug.Printf("Add produce %s quantity: %d", producename, producequantity)
+			warehouse.AddProduce(producename, producequantity)
+			log.Debug.Println(warehouse.Produce)
+		}
+		for seedname, seedquantity := range harvest.Seeds {
+			log.Debug.Printf("Add seed %s quantity: %d", seedname, seedquantity)
+			warehouse.AddSeeds(seedname, seedquantity)
+			log.Debug.Println(warehouse.Seeds)
+		}
+		for goodname, goodquantity := range harvest.Goods {
+			log.Debug.Printf("Add good %s quantity: %d", goodname, goodquantity)
+			warehouse.AddGoods(goodname, goodquantity)
+			log.Debug.Println(warehouse.Goods)
+		}
+
+		log.Debug.Printf("Track Harvest Metric")
+		harvestedName := plantDef.Name
+		queueMetric(w, func() { metrics.TrackHarvest(harvestedName) })
+		
+		log.Debug.Printf("Check if is final harvest")
+		// check if final harvest
+		if growthHarvest.FinalHarvest {
+			// is final harvest
+			// clear, along with any actions still queued for the plant
+			plot.PlantedPlant = nil
+			plot.Quantity = 0
+			plot.Queue = nil
+		} else {
+			// not final harvest
+			// move up current stage if not repeat, initialize nextStage for response
+			if !repeatStage {
+				plot.PlantedPlant.CurrentStage++
+			}
+			nextStage = &dictionary.Plants[plot.PlantedPlant.PlantType].GrowthStages[plot.PlantedPlant.CurrentStage]
+		}
+	} else {
+		// if not harvest
+		// move up current stage if not repeat, initialize nextStage for response
+		if !repeatStage {
+			plot.PlantedPlant.CurrentStage++
+		}
+		nextStage = &dictionary.Plants[plot.PlantedPlant.PlantType].GrowthStages[plot.PlantedPlant.CurrentStage]
+	}
+
+	farm.Plots[uuid] = plot
+
+	return true, schema.PlotActionResponse{Warehouse: warehouse, Plot: &plot, NextStage: nextStage}
+}
+
+// Handler function for the secure route: /api/my/plots/{uuid}/queue
+// Replaces the plot's action queue, which the server works through as the plot becomes ready for each action
+type QueuePlotActions struct {
+	Dbs *map[string]rdb.InteractiveDB
+}
+func (h *QueuePlotActions) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	secureTransact(w, r, h.Dbs, func(w http.ResponseWriter, r *http.Request, dbs *map[string]rdb.InteractiveDB) {
+		txHandler := *h
+		txHandler.Dbs = dbs
+		txHandler.serve(w, r)
+	})
+}
+func (h *QueuePlotActions) serve(w http.ResponseWriter, r *http.Request) {
+	log.Debug.Println(log.Yellow("-- QueuePlotActions --"))
+	// Get symbol from route
+	id := GetVarEntries(r, "plot-id", None)
+	// Get userinfoContext from validation middleware
+	userInfo, userInfoErr := GetValidationFromCtx(r)
+	if userInfoErr != nil {
+		// Fail state getting context
+		log.Error.Printf("Could not get validationpair in QueuePlotActions")
+		userInfoErrMsg := fmt.Sprintf("userInfo is nil, check auth validation context %v:\n%v", auth.ValidationContext, r.Context().Value(auth.ValidationContext))
+		responses.SendRes(w, responses.No_AuthPair_Context, nil, userInfoErrMsg)
+		return
+	}
+	idSlice := strings.Split(id, "!")
+	if len(idSlice) < 2 {
+		// Fail, malformed plot id
+		errmsg := fmt.Sprintf("Malformed plot id, format must be '[farm-location-symbol]!Plot-[id-number]' received: %v", id)
+		log.Debug.Printf(errmsg)
+		responses.SendRes(w, responses.Bad_Request, nil, errmsg)
+		return
+	}
+	uuid := userInfo.Username + "|Farm-" + idSlice[0] + "|" + idSlice[1]
+	symbolSlice := strings.Split(uuid, "|")
+	farmLocationSymbol := strings.Join(symbolSlice[:len(symbolSlice)-1], "|")
+	log.Debug.Printf("QueuePlotActions Requested for: %s", uuid)
+
+	// Get farm, plot
+	fdb := (*h.Dbs)["farms"]
+	farm, foundFarm, farmsErr := schema.GetFarmFromDB(farmLocationSymbol, fdb)
+	if farmsErr != nil || !foundFarm {
+		errmsg := fmt.Sprintf("Error in QueuePlotActions, could not get farm from DB. foundFarm: %v, error: %v", foundFarm, farmsErr)
+		log.Error.Printf(errmsg)
+		responses.SendRes(w, responses.DB_Get_Failure, nil, errmsg)
+		return
+	}
+	plot, foundPlot := farm.Plots[uuid]
+	if !foundPlot {
+		errmsg := fmt.Sprintf("in QueuePlotActions, plot not found: %s", uuid)
+		log.Debug.Printf(errmsg)
+		responses.SendRes(w, responses.Object_Not_Found, nil, errmsg)
+		return
+	}
+
+	// unmarshall request body to get the actions to queue
+	var body schema.PlotQueueBody
+	decoder := json.NewDecoder(r.Body)
+	if decodeErr := decoder.Decode(&body); decodeErr != nil {
+		// Fail case, could not decode
+		errmsg := fmt.Sprintf("Decode Error in QueuePlotActions: %v", decodeErr)
+		log.Debug.Printf(errmsg)
+		responses.SendRes(w, responses.Bad_Request, nil, "Could not decode request body, ensure it conforms to expected format.")
+		return
+	}
+	validationMap := schema.ValidatePlotQueueBody(body)
+	if len(validationMap) > 0 {
+		// Failed basic validation
+		errmsg := fmt.Sprintf("Validation Error in QueuePlotActions: %v", validationMap)
+		log.Debug.Printf(errmsg)
+		responses.SendRes(w, responses.Bad_Request, validationMap, "Request body did not pass validation, see data for specifics.")
+		return
+	}
+
+	// Replace any existing queue, resuming it if paused
+	plot.Queue = &schema.PlotQueue{Actions: body.Actions}
+	farm.Plots[uuid] = plot
+
+	// Save to DB
+	saveFarmErr := schema.SaveFarmDataAtPathToDB(fdb, farmLocationSymbol, "plots", farm.Plots)
+	if saveFarmErr != nil {
+		log.Error.Printf("Error in QueuePlotActions, could not save farm. error: %v", saveFarmErr)
+		responses.SendRes(w, responses.DB_Save_Failure, nil, saveFarmErr.Error())
+		return
+	}
+
+	// Construct and Send response
+	getPlotJsonString, getPlotJsonStringErr := responses.JSON(plot)
+	if getPlotJsonStringErr != nil {
+		log.Error.Printf("Error in QueuePlotActions, could not format plot as JSON. plot: %v, error: %v", plot, getPlotJsonStringErr)
+		responses.SendRes(w, responses.JSON_Marshal_Error, plot, getPlotJsonStringErr.Error())
+		return
+	}
+	log.Debug.Printf("Sending response for QueuePlotActions:\n%v", getPlotJsonString)
+	responses.SendRes(w, responses.Generic_Success, plot, fmt.Sprintf("Queued %d actions", len(body.Actions)))
+
+	log.Debug.Println(log.Cyan("-- End QueuePlotActions --"))
+}
+
+// Handler function for the secure route: /api/my/plots/{uuid}/queue
+// Removes the plot's action queue
+type ClearPlotQueue struct {
+	Dbs *map[string]rdb.InteractiveDB
+}
+func (h *ClearPlotQueue) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	secureTransact(w, r, h.Dbs, func(w http.ResponseWriter, r *http.Request, dbs *map[string]rdb.InteractiveDB) {
+		txHandler := *h
+		txHandler.Dbs = dbs
+		txHandler.serve(w, r)
+	})
+}
+func (h *ClearPlotQueue) serve(w http.ResponseWriter, r *http.Request) {
+	log.Debug.Println(log.Yellow("-- ClearPlotQueue --"))
+	// Get symbol from route
+	id := GetVarEntries(r, "plot-id", None)
+	// Get userinfoContext from validation middleware
+	userInfo, userInfoErr := GetValidationFromCtx(r)
+	if userInfoErr != nil {
+		// Fail state getting context
+		log.Error.Printf("Could not get validationpair in ClearPlotQueue")
+		userInfoErrMsg := fmt.Sprintf("userInfo is nil, check auth validation context %v:\n%v", auth.ValidationContext, r.Context().Value(auth.ValidationContext))
+		responses.SendRes(w, responses.No_AuthPair_Context, nil, userInfoErrMsg)
+		return
+	}
+	idSlice := strings.Split(id, "!")
+	if len(idSlice) < 2 {
+		// Fail, malformed plot id
+		errmsg := fmt.Sprintf("Malformed plot id, format must be '[farm-location-symbol]!Plot-[id-number]' received: %v", id)
+		log.Debug.Printf(errmsg)
+		responses.SendRes(w, responses.Bad_Request, nil, errmsg)
+		return
+	}
+	uuid := userInfo.Username + "|Farm-" + idSlice[0] + "|" + idSlice[1]
+	symbolSlice := strings.Split(uuid, "|")
+	farmLocationSymbol := strings.Join(symbolSlice[:len(symbolSlice)-1], "|")
+	log.Debug.Printf("ClearPlotQueue Requested for: %s", uuid)
+
+	// Get farm, plot
+	fdb := (*h.Dbs)["farms"]
+	farm, foundFarm, farmsErr := schema.GetFarmFromDB(farmLocationSymbol, fdb)
+	if farmsErr != nil || !foundFarm {
+		errmsg := fmt.Sprintf("Error in ClearPlotQueue, could not get farm from DB. foundFarm: %v, error: %v", foundFarm, farmsErr)
+		log.Error.Printf(errmsg)
+		responses.SendRes(w, responses.DB_Get_Failure, nil, errmsg)
+		return
+	}
+	plot, foundPlot := farm.Plots[uuid]
+	if !foundPlot {
+		errmsg := fmt.Sprintf("in ClearPlotQueue, plot not found: %s", uuid)
+		log.Debug.Printf(errmsg)
+		responses.SendRes(w, responses.Object_Not_Found, nil, errmsg)
+		return
+	}
+
+	// Validate queue exists
+	if plot.Queue == nil {
+		errmsg := fmt.Sprintf("in ClearPlotQueue, plot has no queued actions: %s", uuid)
+		log.Debug.Printf(errmsg)
+		responses.SendRes(w, responses.Object_Not_Found, plot, errmsg)
+		return
+	}
+
+	plot.Queue = nil
+	farm.Plots[uuid] = plot
+
+	// Save to DB
+	saveFarmErr := schema.SaveFarmDataAtPathToDB(fdb, farmLocationSymbol, "plots", farm.Plots)
+	if saveFarmErr != nil {
+		log.Error.Printf("Error in ClearPlotQueue, could not save farm. error: %v", saveFarmErr)
+		responses.SendRes(w, responses.DB_Save_Failure, nil, saveFarmErr.Error())
+		return
+	}
+
+	// Construct and Send response
+	getPlotJsonString, getPlotJsonStringErr := responses.JSON(plot)
+	if getPlotJsonStringErr != nil {
+		log.Error.Printf("Error in ClearPlotQueue, could not format plot as JSON. plot: %v, error: %v", plot, getPlotJsonStringErr)
+		responses.SendRes(w, responses.JSON_Marshal_Error, plot, getPlotJsonStringErr.Error())
+		return
+	}
+	log.Debug.Printf("Sending response for ClearPlotQueue:\n%v", getPlotJsonString)
+	responses.SendRes(w, responses.Generic_Success, plot, "")
+
+	log.Debug.Println(log.Cyan("-- End ClearPlotQueue --"))
+}
+
+// Handler function for the secure route: /api/my/farms/{location-symbol}/plots/plant
+// Plants each listed plot of the farm, the seeds for the whole batch are validated up front
+type BatchPlantPlots struct {
+	Dbs *map[string]rdb.InteractiveDB
+	MainDictionary *schema.MainDictionary
+	Clock timecalc.Clock
+}
+func (h *BatchPlantPlots) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	secureTransact(w, r, h.Dbs, func(w http.ResponseWriter, r *http.Request, dbs *map[string]rdb.InteractiveDB) {
+		txHandler := *h
+		txHandler.Dbs = dbs
+		txHandler.serve(w, r)
+	})
+}
+func (h *BatchPlantPlots) serve(w http.ResponseWriter, r *http.Request) {
+	log.Debug.Println(log.Yellow("-- BatchPlantPlots --"))
+	// Get symbol from route
+	symbol := GetVarEntries(r, "location-symbol", AllCaps)
+	// Get userinfoContext from validation middleware
+	userInfo, userInfoErr := GetValidationFromCtx(r)
+	if userInfoErr != nil {
+		// Fail state getting context
+		log.Error.Printf("Could not get validationpair in BatchPlantPlots")
+		userInfoErrMsg := fmt.Sprintf("userInfo is nil, check auth validation context %v:\n%v", auth.ValidationContext, r.Context().Value(auth.ValidationContext))
+		responses.SendRes(w, responses.No_AuthPair_Context, nil, userInfoErrMsg)
+		return
+	}
+
+	// unmarshall request body to get the plots in the batch
+	var body schema.PlotBatchPlantBody
+	decoder := json.NewDecoder(r.Body)
+	if decodeErr := decoder.Decode(&body); decodeErr != nil {
+		// Fail case, could not decode
+		errmsg := fmt.Sprintf("Decode Error in BatchPlantPlots: %v", decodeErr)
+		log.Debug.Printf(errmsg)
+		responses.SendRes(w, responses.Bad_Request, nil, "Could not decode request body, ensure it conforms to expected format.")
+		return
+	}
+	plotIDs := make([]string, len(body.Plots))
+	for i, entry := range body.Plots {
+		plotIDs[i] = entry.PlotID
+	}
+	validationMap := schema.ValidatePlotBatch(plotIDs)
+	if len(validationMap) > 0 {
+		// Failed basic validation
+		errmsg := fmt.Sprintf("Validation Error in BatchPlantPlots: %v", validationMap)
+		log.Debug.Printf(errmsg)
+		responses.SendRes(w, responses.Bad_Request, validationMap, "Request body did not pass validation, see data for specifics.")
+		return
+	}
+
+	// Get farm, warehouse
+	OK, farm, warehouse := getFarmAndWarehouse(w, h.Dbs, userInfo.Username, symbol)
+	if !OK {
+		return // Failure states handled by getFarmAndWarehouse, simply return
+	}
+
+	// Validate the seeds for the whole batch up front, so it is not cut short partway through
+	if category, missing, isMissing := warehouse.MissingWares(schema.BatchSeeds(body.Plots)); isMissing {
+		errmsg := fmt.Sprintf("in BatchPlantPlots, not enough %s %s in local warehouse for the whole batch", category, missing)
+		log.Debug.Printf(errmsg)
+		responses.SendRes(w, responses.Not_Enough_Items_In_Warehouse, nil, errmsg)
+		return
+	}
+
+	// Plant each plot
+	now := h.Clock.Now()
+	results := runPlotBatch(w, &farm, plotIDs, func(w http.ResponseWriter, i int, uuid string) (bool, *schema.Plot, *schema.GrowthStage) {
+		entry := body.Plots[i]
+		OK, response := plantPlot(w, h.MainDictionary, now, &farm, &warehouse, uuid, entry.PlotPlantBody)
+		return OK, response.Plot, response.NextStage
+	})
+
+	// Save to DBs and send each plot's result
+	sendPlotBatch(w, h.Dbs, farm, warehouse, results)
+
+	log.Debug.Println(log.Cyan("-- End BatchPlantPlots --"))
+}
+
+// Handler function for the secure route: /api/my/farms/{location-symbol}/plots/interact
+// Interacts with each listed plot of the farm, the consumables for the whole batch are validated up front
+type BatchInteractPlots struct {
+	Dbs *map[string]rdb.InteractiveDB
+	MainDictionary *schema.MainDictionary
+	World *schema.World
+	Clock timecalc.Clock
+	HarvestSeed int64 // mixed with plot and time so harvests can be replayed
+}
+func (h *BatchInteractPlots) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	secureTransact(w, r, h.Dbs, func(w http.ResponseWriter, r *http.Request, dbs *map[string]rdb.InteractiveDB) {
+		txHandler := *h
+		txHandler.Dbs = dbs
+		txHandler.serve(w, r)
+	})
+}
+func (h *BatchInteractPlots) serve(w http.ResponseWriter, r *http.Request) {
+	log.Debug.Println(log.Yellow("-- BatchInteractPlots --"))
+	// Get symbol from route
+	symbol := GetVarEntries(r, "location-symbol", AllCaps)
+	// Get userinfoContext from validation middleware
+	userInfo, userInfoErr := GetValidationFromCtx(r)
+	if userInfoErr != nil {
+		// Fail state getting context
+		log.Error.Printf("Could not get validationpair in BatchInteractPlots")
+		userInfoErrMsg := fmt.Sprintf("userInfo is nil, check auth validation context %v:\n%v", auth.ValidationContext, r.Context().Value(auth.ValidationContext))
+		responses.SendRes(w, responses.No_AuthPair_Context, nil, userInfoErrMsg)
+		return
+	}
+
+	// unmarshall request body to get the plots in the batch
+	var body schema.PlotBatchInteractBody
+	decoder := json.NewDecoder(r.Body)
+	if decodeErr := decoder.Decode(&body); decodeErr != nil {
+		// Fail case, could not decode
+		errmsg := fmt.Sprintf("Decode Error in BatchInteractPlots: %v", decodeErr)
+		log.Debug.Printf(errmsg)
+		responses.SendRes(w, responses.Bad_Request, nil, "Could not decode request body, ensure it conforms to expected format.")
+		return
+	}
+	plotIDs := make([]string, len(body.Plots))
+	for i, entry := range body.Plots {
+		plotIDs[i] = entry.PlotID
+	}
+	validationMap := schema.ValidatePlotBatch(plotIDs)
+	if len(validationMap) > 0 {
+		// Failed basic validation
+		errmsg := fmt.Sprintf("Validation Error in BatchInteractPlots: %v", validationMap)
+		log.Debug.Printf(errmsg)
+		responses.SendRes(w, responses.Bad_Request, validationMap, "Request body did not pass validation, see data for specifics.")
+		return
+	}
+
+	// Get farm, warehouse
+	OK, farm, warehouse := getFarmAndWarehouse(w, h.Dbs, userInfo.Username, symbol)
+	if !OK {
+		return // Failure states handled by getFarmAndWarehouse, simply return
+	}
+
+	// Validate the consumables for the whole batch up front, so it is not cut short partway through
+	now := h.Clock.Now()
+	if category, missing, isMissing := warehouse.MissingWares(farm.BatchConsumables(body.Plots, h.MainDictionary.Plants, warehouse.Tools, now.Unix())); isMissing {
+		errmsg := fmt.Sprintf("in BatchInteractPlots, not enough %s %s in local warehouse for the whole batch", category, missing)
+		log.Debug.Printf(errmsg)
+		responses.SendRes(w, responses.Not_Enough_Items_In_Warehouse, nil, errmsg)
+		return
+	}
+
+	// Interact with each plot
+	results := runPlotBatch(w, &farm, plotIDs, func(plotW http.ResponseWriter, i int, uuid string) (bool, *schema.Plot, *schema.GrowthStage) {
+		entry := body.Plots[i]
+		OK, response := applyPlotInteraction(plotW, h.MainDictionary, h.World, h.HarvestSeed, now, &farm, &warehouse, uuid, entry.PlotInteractBody)
+		if OK {
+			queueEvent(w, userInfo.Username, schema.NewEvent(schema.Event_PlotInteracted, now.Unix(), uuid, map[string]interface{}{"action": entry.Action, "plot": response.Plot}))
+		}
+		return OK, response.Plot, response.NextStage
+	})
+
+	// Save to DBs and send each plot's result
+	sendPlotBatch(w, h.Dbs, farm, warehouse, results)
+
+	log.Debug.Println(log.Cyan("-- End BatchInteractPlots --"))
+}
+
+// Handler function for the secure route: /api/my/farms/{location-symbol}/plots/clear
+// Clears each listed plot of the farm
+type BatchClearPlots struct {
+	Dbs *map[string]rdb.InteractiveDB
+	Clock timecalc.Clock
+}
+func (h *BatchClearPlots) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	secureTransact(w, r, h.Dbs, func(w http.ResponseWriter, r *http.Request, dbs *map[string]rdb.InteractiveDB) {
+		txHandler := *h
+		txHandler.Dbs = dbs
+		txHandler.serve(w, r)
+	})
+}
+func (h *BatchClearPlots) serve(w http.ResponseWriter, r *http.Request) {
+	log.Debug.Println(log.Yellow("-- BatchClearPlots --"))
+	// Get symbol from route
+	symbol := GetVarEntries(r, "location-symbol", AllCaps)
+	// Get userinfoContext from validation middleware
+	userInfo, userInfoErr := GetValidationFromCtx(r)
+	if userInfoErr != nil {
+		// Fail state getting context
+		log.Error.Printf("Could not get validationpair in BatchClearPlots")
+		userInfoErrMsg := fmt.Sprintf("userInfo is nil, check auth validation context %v:\n%v", auth.ValidationContext, r.Context().Value(auth.ValidationContext))
+		responses.SendRes(w, responses.No_AuthPair_Context, nil, userInfoErrMsg)
+		return
+	}
+
+	// unmarshall request body to get the plots in the batch
+	var body schema.PlotBatchClearBody
+	decoder := json.NewDecoder(r.Body)
+	if decodeErr := decoder.Decode(&body); decodeErr != nil {
+		// Fail case, could not decode
+		errmsg := fmt.Sprintf("Decode Error in BatchClearPlots: %v", decodeErr)
+		log.Debug.Printf(errmsg)
+		responses.SendRes(w, responses.Bad_Request, nil, "Could not decode request body, ensure it conforms to expected format.")
+		return
+	}
+	plotIDs := body.PlotIDs
+	validationMap := schema.ValidatePlotBatch(plotIDs)
+	if len(validationMap) > 0 {
+		// Failed basic validation
+		errmsg := fmt.Sprintf("Validation Error in BatchClearPlots: %v", validationMap)
+		log.Debug.Printf(errmsg)
+		responses.SendRes(w, responses.Bad_Request, validationMap, "Request body did not pass validation, see data for specifics.")
+		return
+	}
+
+	// Get farm, warehouse
+	OK, farm, warehouse := getFarmAndWarehouse(w, h.Dbs, userInfo.Username, symbol)
+	if !OK {
+		return // Failure states handled by getFarmAndWarehouse, simply return
+	}
+
+	// Clear each plot
+	now := h.Clock.Now()
+	results := runPlotBatch(w, &farm, plotIDs, func(w http.ResponseWriter, _ int, uuid string) (bool, *schema.Plot, *schema.GrowthStage) {
+		OK, plot := clearPlot(w, now, &farm, uuid)
+		return OK, &plot, nil
+	})
+
+	// Save to DBs and send each plot's result
+	sendPlotBatch(w, h.Dbs, farm, warehouse, results)
+
+	log.Debug.Println(log.Cyan("-- End BatchClearPlots --"))
+}
+
+// Handler function for the secure route: /api/my/markets/{symbol}/order
+// Returns a list of markets 
+type MarketOrder struct {
+	Dbs *map[string]rdb.InteractiveDB
+	MainDictionary *schema.MainDictionary
+	World *schema.World
+	Clock timecalc.Clock
+}
+func (h *MarketOrder) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	secureTransact(w, r, h.Dbs, func(w http.ResponseWriter, r *http.Request, dbs *map[string]rdb.InteractiveDB) {
+		txHandler := *h
+		txHandler.Dbs = dbs
+		txHandler.serve(w, r)
+	})
+}
+func (h *MarketOrder) serve(w http.ResponseWriter, r *http.Request) {
+	log.Debug.Println(log.Yellow("-- MarketOrder --"))
+	udb := (*h.Dbs)["users"]
+	OK, userData, _ := secureGetUser(w, r, udb)
+	if !OK {
+		return // Failure states handled by secureGetUser, simply return
+	}
+	// Get assistant markets to determine fog of war
+	adb := (*h.Dbs)["assistants"]
+	assistants, foundAssistants, assistantsErr := schema.GetAssistantsFromDB(userData.Assistants, adb)
+	if assistantsErr != nil {
+		log.Error.Printf("Error in MarketOrder, could not get assistants from DB. error: %v", assistantsErr)
+		responses.SendRes(w, responses.DB_Get_Failure, nil, assistantsErr.Error())
+		return
+	}
+	if !foundAssistants {
+		log.Debug.Printf("in MarketOrder, no assistants found for %s", userData.Username)
+		responses.SendRes(w, responses.No_Assitant_At_Location, nil, "")
+		return
+	}
+	// use myLocs as a set to get all unique markets visible in fow
+	myLocs := make(map[string]bool)
+	for _, assistant := range assistants {
+		myLocs[assistant.Location] = true
+	}
+	// Get symbol from route
+	symbol := GetVarEntries(r, "location-symbol", UUID)
+	// finally get specified market if available
+	var resMarket schema.Market
+	found := false
+	for market := range myLocs {
+		if strings.ToUpper(market) == symbol {
+			resMarket = h.MainDictionary.Markets[market]
+			found = true
+		}
+	}
+	if !found {
+		log.Debug.Printf("Not found %s in markets %v", symbol, myLocs)
+		responses.SendRes(w, responses.No_Assitant_At_Location, nil, "")
+		return
+	}
+	// Get live prices, which trades move in this transaction
+	cdb := (*h.Dbs)["clearinghouse"]
+	pricesOk, marketPrices := getMarketPrices(w, cdb, h.Clock.Now(), resMarket.LocationSymbol)
+	if !pricesOk {
+		return // Failure states handled by getMarketPrices, simply return
+	}
+
+	// NOW handle setting up the order and executing it if type is MARKET order
+	// unmarshall request order to get action and consumables if applicable
+	var order schema.MarketOrder
+	decoder := json.NewDecoder(r.Body)
+	if decodeErr := decoder.Decode(&order); decodeErr != nil {
+		// Fail case, could not decode
+		errmsg := fmt.Sprintf("Decode Error in MarketOrder: %v", decodeErr)
+		log.Debug.Printf(errmsg)
+		responses.SendRes(w, responses.Bad_Request, nil, "Could not decode request body, ensure it conforms to expected format.")
+		return
+	}
+
+	// Get warehouse
+	wdb := (*h.Dbs)["warehouses"]
+	warehouseLocationSymbol := userData.Username + "|Warehouse-" + symbol
+	var warehouse schema.Warehouse
+	if stringInSlice(warehouseLocationSymbol, userData.Warehouses) {
+		// Warehouse should exist, get from DB
+		var foundWarehouse bool
+		var warehousesErr error
+		warehouse, foundWarehouse, warehousesErr = schema.GetWarehouseFromDB(warehouseLocationSymbol, wdb)
+		if warehousesErr != nil || !foundWarehouse {
+			errmsg := fmt.Sprintf("Error in MarketOrder, could not get warehouse from DB. foundWarehouse: %v, error: %v", foundWarehouse, warehousesErr)
+			log.Error.Printf(errmsg)
+			responses.SendRes(w, responses.DB_Get_Failure, nil, errmsg)
+			return
+		}
+	} else {
+		// Warehouse needs to be created
+		log.Debug.Printf("In MarketOrder: Creating warehouse for %s with uuid %s", userData.Username, symbol)
+		warehouse = *schema.NewEmptyWarehouse(userData.Username, symbol)
+		userData.Warehouses = append(userData.Warehouses, warehouse.UUID)
+	}
+	
+
+	// Validate order parameters
+	if order.Quantity <= 0 {
+		// fail, quantity too low
+		errmsg := fmt.Sprintf("in MarketOrder, invalid order quantity, must be > 0, got %d.", order.Quantity)
+		log.Debug.Printf(errmsg)
+		responses.SendRes(w, responses.Bad_Request, nil, errmsg)
+		return
+	}
+	// Find order item in specified market imports/exports
+	var ioField schema.MarketIOField
+	if order.TXType == schema.BUY {
+		ioField = resMarket.Exports
+	} else {
+		ioField = resMarket.Imports
+	}
+	var itemDict map[string]uint64
+	var warehouseDict map[string]uint64
+	var itemName string
+	var simpleItemName string
+	var sizeMod uint64
+	switch order.ItemCategory {
+	case schema.GOOD:
+		itemDict = ioField.Goods
+		warehouseDict = warehouse.Goods
+		itemName = order.ItemName
+		simpleItemName = order.ItemName
+		sizeMod = 1
+	case schema.SEED:
+		itemDict = ioField.Seeds
+		warehouseDict = warehouse.Seeds
+		itemName = order.ItemName
+		simpleItemName = order.ItemName
+		sizeMod = 1
+	case schema.TOOL:
+		itemDict = ioField.Tools
+		warehouseDict = warehouse.Tools
+		itemName = order.ItemName
+		simpleItemName = order.ItemName
+		sizeMod = 1
+	case schema.PRODUCE:
+		itemDict = ioField.Produce
+		warehouseDict = warehouse.Produce
+		splitSlice := strings.Split(order.ItemName, "|")
+		if len(splitSlice) <= 1 {
+			// fail, no size specified
+			errmsg := "in MarketOrder, order.ItemName for PRODUCE category MUST have size specified e.g. 'Potato|Large', ensure correct ItemCategory specified and item name contains size"
+			log.Debug.Printf("%s, %s: %v", errmsg, itemName, splitSlice)
+			responses.SendRes(w, responses.Market_Order_Failed_Validation, nil, errmsg)
+			return
+		}
+		size, ok := schema.SizeToID[strings.Title(strings.ToLower(splitSlice[1]))]
+		if !ok {
+			// fail nonsensical size
+			errmsg := fmt.Sprintf("in MarketOrder, order.ItemName for PRODUCE category MUST have valid size specified e.g. 'Potato|Large', ensure correct ItemCategory specified and item name contains a valid size (received: %s)", splitSlice[1])
+			log.Debug.Printf("%s, %s: %v", errmsg, itemName, splitSlice)
+			responses.SendRes(w, responses.Market_Order_Failed_Validation, nil, errmsg)
+			return
+		}
+		itemName = splitSlice[0] + "|" + strings.Title(strings.ToLower(splitSlice[1]))
+		simpleItemName = splitSlice[0]
+		sizeMod = uint64(size)
+	}
+
+	// Validate warehouse has room for purchases, MARKET buys are partially filled to the room available
+	var partialFillMsg string
+	if order.TXType == schema.BUY {
+		capacityOk, capacity := getWarehouseCapacity(w, (*h.Dbs)["farms"], userData, h.MainDictionary, h.World, symbol, h.Clock.Now())
+		if !capacityOk {
+			return // Failure states handled by getWarehouseCapacity, simply return
+		}
+		freeCapacity := warehouse.FreeCapacity(capacity)
+		if freeCapacity == 0 || (order.OrderType == schema.LIMIT && order.Quantity > freeCapacity) {
+			errmsg := fmt.Sprintf("in MarketOrder, order quantity %d exceeds room in warehouse (%d of %d)", order.Quantity, freeCapacity, capacity)
+			log.Debug.Printf(errmsg)
+			responses.SendRes(w, responses.Warehouse_Capacity_Exceeded, nil, errmsg)
+			return
+		}
+		if order.Quantity > freeCapacity {
+			partialFillMsg = fmt.Sprintf("Partially filled %d of %d, warehouse at capacity %d", freeCapacity, order.Quantity, capacity)
+			log.Debug.Printf("in MarketOrder, %s", partialFillMsg)
+			order.Quantity = freeCapacity
+		}
+	}
+
+	// LIMIT orders rest in the order book instead of filling at the market value
+	if order.OrderType == schema.LIMIT {
+		placeLimitOrder(w, h.Dbs, h.MainDictionary, h.World, userData, warehouse, order, itemName, simpleItemName, resMarket, marketPrices, h.Clock.Now())
+		log.Debug.Println(log.Cyan("-- End MarketOrder --"))
+		return
+	}
+
+	// get base market value
+	marketValue, mvOk := itemDict[simpleItemName]
+	if !mvOk {
+		// fail, item not in specified market list
+		errmsg := "in MarketOrder, order.ItemName not in itemDict, ensure correct ItemCategory specified and item is available for trade in specified market for given transaction type"
+		log.Debug.Printf("%s, %s: %v", errmsg, simpleItemName, itemDict)
+		responses.SendRes(w, responses.Market_Order_Failed_Validation, nil, errmsg)
+		return
+	}
+
+	// price each unit along the price impact of the units before it
+	isBuy := order.TXType == schema.BUY
+	unitsValue, unitsValueOk := marketPrices.QuoteTrade(isBuy, order.ItemCategory, itemName, marketValue, order.Quantity)
+	orderValue, orderValueOk := schema.MulUint64(unitsValue, sizeMod)
+	if !unitsValueOk || !orderValueOk {
+		errmsg := fmt.Sprintf("in MarketOrder, order value of %d %s is too large.", order.Quantity, itemName)
+		log.Debug.Printf(errmsg)
+		responses.SendRes(w, responses.Bad_Request, nil, errmsg)
+		return
+	}
+
+	// execute buy or sell is have enough in warehouse/ledger
+	log.Debug.Printf("Execute Market Order: %s %s %s x%d for %d from base %d each * %d sizeMod", order.OrderType, order.TXType, itemName, order.Quantity, orderValue, marketValue, sizeMod)
+	coins := userData.Ledger.Currencies["Coins"]
+	if isBuy {
+		orderCost := orderValue
+		// Validate currency in ledger in sufficient quantity
+		if orderCost > coins {
+			// fail, not enough currency for specified item and quantity
+			errmsg := fmt.Sprintf("in MarketOrder, order cost %d > coins: %d", orderCost, coins)
+			log.Debug.Printf(errmsg)
+			responses.SendRes(w, responses.Market_Order_Failed_Validation, nil, errmsg)
+			return
+		}
+		// Execute buy
+		coins -= orderCost
+		if len(warehouseDict) == 0 {
+			// empty warehouseDict, create
+			warehouseDict = make(map[string]uint64)
+		}
+		warehouseDict[itemName] += order.Quantity
+	} else {
+		orderProfit := orderValue
+		// Validate in warehouse in sufficient quantity
+		warehouseQuantity, wqOk := warehouseDict[itemName]
+		if !wqOk {
+			// fail, item not in location's warehouse
+			errmsg := "in MarketOrder, specified item not found in local warehouse, ensure order.ItemCategory is specified correctly"
+			log.Debug.Printf("%s, %s: %v", errmsg, itemName, warehouseDict)
+			responses.SendRes(w, responses.Market_Order_Failed_Validation, nil, errmsg)
+			return
+		}
+		if warehouseQuantity < order.Quantity {
+			// fail, not enough in location's warehouse
+			errmsg := fmt.Sprintf("in MarketOrder, order specifies greater quantity %d than available in local warehouse %d.", order.Quantity, warehouseQuantity)
+			log.Debug.Printf(errmsg)
+			responses.SendRes(w, responses.Market_Order_Failed_Validation, nil, errmsg)
+			return
+		}
+		// Execute sell
+		coins += orderProfit
+		warehouseDict[itemName] -= order.Quantity
+		// Handle deleting if necessary
+		if warehouseDict[itemName] <= 0 {
+			delete(warehouseDict, itemName)
+		}
+	}
+	marketPrices.ApplyTrade(h.Clock.Now(), isBuy, order.ItemCategory, itemName, order.Quantity)
+	
+	// Apply results to original objects
+	switch order.ItemCategory {
+	case schema.GOOD:
+		warehouse.Goods = warehouseDict
+	case schema.SEED:
+		warehouse.Seeds = warehouseDict
+	case schema.TOOL:
+		warehouse.Tools = warehouseDict
+	case schema.PRODUCE:
+		warehouse.Produce = warehouseDict
+	}
+	userData.Ledger.Currencies["Coins"] = coins
+
+	// If warehouse is empty now, delete it, else save it
+	if warehouse.TotalSize() == 0 {
+		userData.Warehouses = remove(userData.Warehouses, warehouse.UUID)
+		schema.DeleteWarehouseFromDB(wdb, warehouse.UUID)
+	} else {
+		// Save warehouse
+		saveWarehouseErr := schema.SaveWarehouseToDB(wdb, &warehouse)
+		if saveWarehouseErr != nil {
+			log.Error.Printf("Error in MarketOrder, could not save warehouse. error: %v", saveWarehouseErr)
+			responses.SendRes(w, responses.DB_Save_Failure, nil, saveWarehouseErr.Error())
+			return
+		}
+	}
+
+	// Save user
+	saveUserErr := schema.SaveUserToDB((*h.Dbs)["users"], &userData)
+	if saveUserErr != nil {
+		log.Error.Printf("Error in MarketOrder, could not save user. error: %v", saveUserErr)
+		responses.SendRes(w, responses.DB_Save_Failure, nil, saveUserErr.Error())
+		return
+	}
+	queueCoinsMetric(w, &userData)
+
+	// Save market prices
+	savePricesErr := schema.SaveMarketPricesToDB(cdb, &marketPrices)
+	if savePricesErr != nil {
+		log.Error.Printf("Error in MarketOrder, could not save market prices. error: %v", savePricesErr)
+		responses.SendRes(w, responses.DB_Save_Failure, nil, savePricesErr.Error())
+		return
+	}
+	queueMetric(w, func() { metrics.TrackMarketBuySell(itemName, isBuy, order.Quantity) })
+
+	queueEvent(w, userData.Username, schema.NewEvent(schema.Event_MarketOrderExecuted, h.Clock.Now().Unix(), resMarket.LocationSymbol, order))
+
+	responses.SendRes(w, responses.Generic_Success, map[string]interface{}{"warehouse": warehouse, "ledger": userData.Ledger}, partialFillMsg)
+	log.Debug.Println(log.Cyan("-- End MarketOrder --"))
+}
+// Places a LIMIT order in the order book of the specified market, matching it against resting orders first.
+// Funds (BUY) are held in Ledger.EscrowCoins, or items (SELL) in Ledger.EscrowWares, under the order uuid until filled or cancelled
+func placeLimitOrder(w http.ResponseWriter, dbs *map[string]rdb.InteractiveDB, dictionary *schema.MainDictionary, world *schema.World, userData schema.User, warehouse schema.Warehouse, order schema.MarketOrder, itemName string, simpleItemName string, market schema.Market, marketPrices schema.MarketPrices, now time.Time) {
+	udb := (*dbs)["users"]
+	wdb := (*dbs)["warehouses"]
+	cdb := (*dbs)["clearinghouse"]
+	// Validate limit price
+	if order.Price <= 0 || order.Price > schema.MaxLimitOrderPrice {
+		errmsg := fmt.Sprintf("in MarketOrder, invalid limit price, must be > 0 and <= %d, got %d.", schema.MaxLimitOrderPrice, order.Price)
+		log.Debug.Printf(errmsg)
+		responses.SendRes(w, responses.Bad_Request, nil, errmsg)
+		return
+	}
+	orderCost, costOk := schema.MulUint64(order.Quantity, order.Price)
+	if !costOk {
+		errmsg := fmt.Sprintf("in MarketOrder, order value of %d at %d each is too large.", order.Quantity, order.Price)
+		log.Debug.Printf(errmsg)
+		responses.SendRes(w, responses.Bad_Request, nil, errmsg)
+		return
+	}
+	// Item must be traded at this market in either direction
+	tradable := false
+	for _, ioField := range []schema.MarketIOField{market.Imports, market.Exports} {
+		var itemDict map[string]uint64
+		switch order.ItemCategory {
+		case schema.GOOD:
+			itemDict = ioField.Goods
+		case schema.SEED:
+			itemDict = ioField.Seeds
+		case schema.TOOL:
+			itemDict = ioField.Tools
+		case schema.PRODUCE:
+			itemDict = ioField.Produce
+		}
+		if _, ok := itemDict[simpleItemName]; ok {
+			tradable = true
+		}
+	}
+	if !tradable {
+		errmsg := "in MarketOrder, order.ItemName not traded at specified market, ensure correct ItemCategory specified and item is listed in the market's imports or exports"
+		log.Debug.Printf("%s, %s", errmsg, simpleItemName)
+		responses.SendRes(w, responses.Market_Order_Failed_Validation, nil, errmsg)
+		return
+	}
+
+	// Get order book
+	book, foundBook, bookErr := schema.GetOrderBookFromDB(market.LocationSymbol, cdb)
+	if bookErr != nil {
+		errmsg := fmt.Sprintf("Error in MarketOrder, could not get order book from DB. error: %v", bookErr)
+		log.Error.Printf(errmsg)
+		responses.SendRes(w, responses.DB_Get_Failure, nil, errmsg)
+		return
+	}
+	if !foundBook {
+		book = *schema.NewOrderBook(market.LocationSymbol)
+	}
+
+	limitOrder := schema.NewLimitOrder(book.NextOrderID(), userData.Username, now, market.LocationSymbol, order.TXType, order.ItemCategory, itemName, order.Price, order.Quantity)
+	log.Debug.Printf("Place Limit Order: %s %s x%d at %d each", limitOrder.TXType, itemName, limitOrder.Quantity, limitOrder.Price)
+
+	// Move funds or items into escrow
+	if order.TXType == schema.BUY {
+		coins := userData.Ledger.Currencies["Coins"]
+		if orderCost > coins {
+			errmsg := fmt.Sprintf("in MarketOrder, order cost %d > coins: %d", orderCost, coins)
+			log.Debug.Printf(errmsg)
+			responses.SendRes(w, responses.Market_Order_Failed_Validation, nil, errmsg)
+			return
+		}
+		userData.Ledger.RemoveCurrency("Coins", orderCost)
+		userData.Ledger.AddEscrowCoins(limitOrder.UUID, orderCost)
+	} else {
+		warehouseQuantity := warehouse.GetItemQuantity(order.ItemCategory, itemName)
+		if warehouseQuantity < order.Quantity {
+			errmsg := fmt.Sprintf("in MarketOrder, order specifies greater quantity %d than available in local warehouse %d.", order.Quantity, warehouseQuantity)
+			log.Debug.Printf(errmsg)
+			responses.SendRes(w, responses.Market_Order_Failed_Validation, nil, errmsg)
+			return
+		}
+		warehouse.RemoveItem(order.ItemCategory, itemName, order.Quantity)
+		userData.Ledger.AddEscrowWares(limitOrder.UUID, order.Quantity)
+	}
+
+	// Resting buys are filled only up to the room in their owner's warehouse at this market
+	counterparties := make(map[string]schema.User)
+	counterpartyWarehouses := make(map[string]schema.Warehouse)
+	var room map[string]uint64
+	if order.TXType == schema.SELL {
+		room = make(map[string]uint64)
+		for _, resting := range book.Bids {
+			sameItem := resting.ItemCategory == limitOrder.ItemCategory && resting.ItemName == limitOrder.ItemName
+			if _, ok := room[resting.Username]; ok || !sameItem || resting.Price < limitOrder.Price || resting.Username == userData.Username {
+				continue
+			}
+			counterparty, foundCounterparty, counterpartyErr := schema.GetUserByUsernameFromDB(resting.Username, udb)
+			if counterpartyErr != nil || !foundCounterparty {
+				errmsg := fmt.Sprintf("Error in MarketOrder, could not get counterparty %s from DB. foundCounterparty: %v, error: %v", resting.Username, foundCounterparty, counterpartyErr)
+				log.Error.Printf(errmsg)
+				responses.SendRes(w, responses.DB_Get_Failure, nil, errmsg)
+				return
+			}
+			counterpartyWarehouseUUID := counterparty.Username + "|Warehouse-" + market.LocationSymbol
+			counterpartyWarehouse, foundWarehouse, warehouseErr := schema.GetWarehouseFromDB(counterpartyWarehouseUUID, wdb)
+			if warehouseErr != nil {
+				errmsg := fmt.Sprintf("Error in MarketOrder, could not get counterparty warehouse from DB. error: %v", warehouseErr)
+				log.Error.Printf(errmsg)
+				responses.SendRes(w, responses.DB_Get_Failure, nil, errmsg)
+				return
+			}
+			if !foundWarehouse {
+				counterpartyWarehouse = *schema.NewEmptyWarehouse(counterparty.Username, market.LocationSymbol)
+			}
+			capacityOk, capacity := getWarehouseCapacity(w, (*dbs)["farms"], counterparty, dictionary, world, market.LocationSymbol, now)
+			if !capacityOk {
+				return // Failure states handled by getWarehouseCapacity, simply return
+			}
+			room[resting.Username] = counterpartyWarehouse.FreeCapacity(capacity)
+			counterparties[resting.Username] = counterparty
+			counterpartyWarehouses[counterpartyWarehouseUUID] = counterpartyWarehouse
+		}
+	}
+
+	// Match against resting orders
+	fills := book.Match(limitOrder, room)
+	for _, fill := range fills {
+		counterparty, ok := counterparties[fill.Username]
+		if !ok {
+			var foundCounterparty bool
+			var counterpartyErr error
+			counterparty, foundCounterparty, counterpartyErr = schema.GetUserByUsernameFromDB(fill.Username, udb)
+			if counterpartyErr != nil || !foundCounterparty {
+				errmsg := fmt.Sprintf("Error in MarketOrder, could not get counterparty %s from DB. foundCounterparty: %v, error: %v", fill.Username, foundCounterparty, counterpartyErr)
+				log.Error.Printf(errmsg)
+				responses.SendRes(w, responses.DB_Get_Failure, nil, errmsg)
+				return
+			}
+		}
+		// Fill quantities are within both orders and prices within MaxLimitOrderPrice, so only orders resting from before the limit can overflow
+		fillValue, fillValueOk := schema.MulUint64(fill.Quantity, fill.Price)
+		escrowedValue, escrowedValueOk := schema.MulUint64(fill.Quantity, limitOrder.Price)
+		if !fillValueOk || !escrowedValueOk {
+			errmsg := fmt.Sprintf("Error in MarketOrder, value of fill %s x%d at %d overflows", fill.OrderUUID, fill.Quantity, fill.Price)
+			log.Error.Printf(errmsg)
+			responses.SendRes(w, responses.Internal_Server_Error, nil, errmsg)
+			return
+		}
+		var escrowErr error
+		if order.TXType == schema.BUY {
+			// Buyer receives items and is refunded any difference between limit and fill price
+			escrowErr = userData.Ledger.RemoveEscrowCoins(limitOrder.UUID, escrowedValue)
+			userData.Ledger.AddCurrency("Coins", escrowedValue - fillValue)
+			warehouse.AddItem(fill.ItemCategory, fill.ItemName, fill.Quantity)
+			// Seller is paid out of the sold items in escrow
+			if escrowErr == nil {
+				escrowErr = counterparty.Ledger.RemoveEscrowWares(fill.OrderUUID, fill.Quantity)
+			}
+			counterparty.Ledger.AddCurrency("Coins", fillValue)
+		} else {
+			// Seller is paid out of the sold items in escrow
+			escrowErr = userData.Ledger.RemoveEscrowWares(limitOrder.UUID, fill.Quantity)
+			userData.Ledger.AddCurrency("Coins", fillValue)
+			// Buyer receives items in their warehouse at this market
+			if escrowErr == nil {
+				escrowErr = counterparty.Ledger.RemoveEscrowCoins(fill.OrderUUID, fillValue)
+			}
+			counterpartyWarehouseUUID := counterparty.Username + "|Warehouse-" + market.LocationSymbol
+			counterpartyWarehouse := counterpartyWarehouses[counterpartyWarehouseUUID]
+			if !stringInSlice(counterpartyWarehouseUUID, counterparty.Warehouses) {
+				counterparty.Warehouses = append(counterparty.Warehouses, counterpartyWarehouseUUID)
+			}
+			counterpartyWarehouse.AddItem(fill.ItemCategory, fill.ItemName, fill.Quantity)
+			counterpartyWarehouses[counterpartyWarehouseUUID] = counterpartyWarehouse
+		}
+		if escrowErr != nil {
+			errmsg := fmt.Sprintf("Error in MarketOrder, escrow does not cover fill %s. error: %v", fill.OrderUUID, escrowErr)
+			log.Error.Printf(errmsg)
+			responses.SendRes(w, responses.Internal_Server_Error, nil, errmsg)
+			return
+		}
+		counterparties[fill.Username] = counterparty
+		marketPrices.ApplyTrade(now, order.TXType == schema.BUY, fill.ItemCategory, fill.ItemName, fill.Quantity)
+	}
+
+	// Rest any unfilled quantity in the book
+	if limitOrder.Quantity > 0 {
+		book.Insert(*limitOrder)
+	}
+
+	// Save order book and the prices moved by fills
+	saveBookErr := schema.SaveOrderBookToDB(cdb, &book)
+	if saveBookErr != nil {
+		log.Error.Printf("Error in MarketOrder, could not save order book. error: %v", saveBookErr)
+		responses.SendRes(w, responses.DB_Save_Failure, nil, saveBookErr.Error())
+		return
+	}
+	savePricesErr := schema.SaveMarketPricesToDB(cdb, &marketPrices)
+	if savePricesErr != nil {
+		log.Error.Printf("Error in MarketOrder, could not save market prices. error: %v", savePricesErr)
+		responses.SendRes(w, responses.DB_Save_Failure, nil, savePricesErr.Error())
+		return
+	}
+
+	// Save counterparties and their warehouses
+	for _, counterpartyWarehouse := range counterpartyWarehouses {
+		saveWarehouseErr := schema.SaveWarehouseToDB(wdb, &counterpartyWarehouse)
+		if saveWarehouseErr != nil {
+			log.Error.Printf("Error in MarketOrder, could not save counterparty warehouse. error: %v", saveWarehouseErr)
+			responses.SendRes(w, responses.DB_Save_Failure, nil, saveWarehouseErr.Error())
+			return
+		}
+	}
+	for _, counterparty := range counterparties {
+		saveCounterpartyErr := schema.SaveUserToDB(udb, &counterparty)
+		if saveCounterpartyErr != nil {
+			log.Error.Printf("Error in MarketOrder, could not save counterparty. error: %v", saveCounterpartyErr)
+			responses.SendRes(w, responses.DB_Save_Failure, nil, saveCounterpartyErr.Error())
+			return
+		}
+		queueCoinsMetric(w, &counterparty)
+	}
+
+	// If warehouse is empty now, delete it, else save it
+	if warehouse.TotalSize() == 0 {
+		userData.Warehouses = remove(userData.Warehouses, warehouse.UUID)
+		schema.DeleteWarehouseFromDB(wdb, warehouse.UUID)
+	} else {
+		saveWarehouseErr := schema.SaveWarehouseToDB(wdb, &warehouse)
+		if saveWarehouseErr != nil {
+			log.Error.Printf("Error in MarketOrder, could not save warehouse. error: %v", saveWarehouseErr)
+			responses.SendRes(w, responses.DB_Save_Failure, nil, saveWarehouseErr.Error())
+			return
+		}
+	}
+
+	// Save user
+	saveUserErr := schema.SaveUserToDB(udb, &userData)
+	if saveUserErr != nil {
+		log.Error.Printf("Error in MarketOrder, could not save user. error: %v", saveUserErr)
+		responses.SendRes(w, responses.DB_Save_Failure, nil, saveUserErr.Error())
+		return
+	}
+	queueCoinsMetric(w, &userData)
+
+	// Notify both sides of each fill
+	for _, fill := range fills {
+		filledName, filledIsBuy, filledQuantity := fill.ItemName, order.TXType == schema.BUY, fill.Quantity
+		queueMetric(w, func() { metrics.TrackMarketBuySell(filledName, filledIsBuy, filledQuantity) })
+		queueEvent(w, fill.Username, schema.NewEvent(schema.Event_MarketOrderFilled, now.Unix(), fill.OrderUUID, fill))
+		queueEvent(w, userData.Username, schema.NewEvent(schema.Event_MarketOrderFilled, now.Unix(), limitOrder.UUID, fill))
+	}
+
+	responses.SendRes(w, responses.Generic_Success, map[string]interface{}{"order": limitOrder, "fills": fills, "warehouse": warehouse, "ledger": userData.Ledger}, "")
+}
+
+// Handler function for the secure route: /api/my/markets/{symbol}/orders
+// Returns the order book of a market along with the user's resting orders
+type MarketOrderBook struct {
+	Dbs *map[string]rdb.InteractiveDB
+	MainDictionary *schema.MainDictionary
+}
+func (h *MarketOrderBook) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	log.Debug.Println(log.Yellow("-- MarketOrderBook --"))
+	udb := (*h.Dbs)["users"]
+	OK, userData, _ := secureGetUser(w, r, udb)
+	if !OK {
+		return // Failure states handled by secureGetUser, simply return
+	}
+	// Get assistant markets to determine fog of war
+	adb := (*h.Dbs)["assistants"]
+	assistants, foundAssistants, assistantsErr := schema.GetAssistantsFromDB(userData.Assistants, adb)
+	if assistantsErr != nil {
+		log.Error.Printf("Error in MarketOrderBook, could not get assistants from DB. error: %v", assistantsErr)
+		responses.SendRes(w, responses.DB_Get_Failure, nil, assistantsErr.Error())
+		return
+	}
+	if !foundAssistants {
+		log.Debug.Printf("in MarketOrderBook, no assistants found for %s", userData.Username)
+		responses.SendRes(w, responses.No_Assitant_At_Location, nil, "")
+		return
+	}
+	// use myLocs as a set to get all unique markets visible in fow
+	myLocs := make(map[string]bool)
+	for _, assistant := range assistants {
+		myLocs[assistant.Location] = true
+	}
+	// Get symbol from route
+	symbol := GetVarEntries(r, "location-symbol", UUID)
+	found := false
+	for market := range myLocs {
+		if _, ok := h.MainDictionary.Markets[market]; ok && strings.ToUpper(market) == symbol {
+			found = true
+		}
+	}
+	if !found {
+		log.Debug.Printf("Not found %s in markets %v", symbol, myLocs)
+		responses.SendRes(w, responses.No_Assitant_At_Location, nil, "")
+		return
+	}
+	// Get order book
+	book, foundBook, bookErr := schema.GetOrderBookFromDB(symbol, (*h.Dbs)["clearinghouse"])
+	if bookErr != nil {
+		log.Error.Printf("Error in MarketOrderBook, could not get order book from DB. error: %v", bookErr)
+		responses.SendRes(w, responses.DB_Get_Failure, nil, bookErr.Error())
+		return
+	}
+	if !foundBook {
+		book = *schema.NewOrderBook(symbol)
+	}
+	resData := map[string]interface{}{"order_book": book, "my_orders": book.OrdersForUser(userData.Username)}
+	getBookJsonString, getBookJsonStringErr := responses.JSON(resData)
+	if getBookJsonStringErr != nil {
+		log.Error.Printf("Error in MarketOrderBook, could not format order book as JSON. order book: %v, error: %v", book, getBookJsonStringErr)
+		responses.SendRes(w, responses.JSON_Marshal_Error, resData, getBookJsonStringErr.Error())
+		return
+	}
+	log.Debug.Printf("Sending response for MarketOrderBook:\n%v", getBookJsonString)
+	responses.SendRes(w, responses.Generic_Success, resData, "")
+	log.Debug.Println(log.Cyan("-- End MarketOrderBook --"))
+}
+
+// Handler function for the secure route: /api/my/markets/{symbol}/orders/{order-id}
+// Cancels a resting limit order and returns its escrow to the user
+type CancelMarketOrder struct {
+	Dbs *map[string]rdb.InteractiveDB
+}
+func (h *CancelMarketOrder) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	secureTransact(w, r, h.Dbs, func(w http.ResponseWriter, r *http.Request, dbs *map[string]rdb.InteractiveDB) {
+		txHandler := *h
+		txHandler.Dbs = dbs
+		txHandler.serve(w, r)
+	})
+}
+func (h *CancelMarketOrder) serve(w http.ResponseWriter, r *http.Request) {
+	log.Debug.Println(log.Yellow("-- CancelMarketOrder --"))
+	udb := (*h.Dbs)["users"]
+	OK, userData, _ := secureGetUser(w, r, udb)
+	if !OK {
+		return // Failure states handled by secureGetUser, simply return
+	}
+	// Get symbol and id from route
+	symbol := GetVarEntries(r, "location-symbol", UUID)
+	id := GetVarEntries(r, "order-id", AllCaps)
+	orderID, parseErr := strconv.ParseInt(id, 10, 64)
+	if parseErr != nil {
+		log.Debug.Printf("in CancelMarketOrder, could not parse order id %s: %v", id, parseErr)
+		responses.SendRes(w, responses.Could_Not_Parse_URI_Param, nil, "order-id must be an integer")
+		return
+	}
+	uuid := schema.LimitOrderUUID(userData.Username, symbol, orderID)
+	// Get order book
+	cdb := (*h.Dbs)["clearinghouse"]
+	book, foundBook, bookErr := schema.GetOrderBookFromDB(symbol, cdb)
+	if bookErr != nil {
+		log.Error.Printf("Error in CancelMarketOrder, could not get order book from DB. error: %v", bookErr)
+		responses.SendRes(w, responses.DB_Get_Failure, nil, bookErr.Error())
+		return
+	}
+	if !foundBook {
+		log.Debug.Printf("in CancelMarketOrder, order book not found for %s", symbol)
+		responses.SendRes(w, responses.Object_Not_Found, nil, "")
+		return
+	}
+	order, foundOrder := book.Remove(uuid)
+	if !foundOrder {
+		log.Debug.Printf("in CancelMarketOrder, order %s not found in order book for %s", uuid, symbol)
+		responses.SendRes(w, responses.Object_Not_Found, nil, "")
+		return
+	}
+
+	// Return escrow
+	wdb := (*h.Dbs)["warehouses"]
+	var warehouse schema.Warehouse
+	if order.TXType == schema.BUY {
+		refund, refundOk := schema.MulUint64(order.Quantity, order.Price)
+		if !refundOk {
+			errmsg := fmt.Sprintf("Error in CancelMarketOrder, refund of %d at %d each overflows", order.Quantity, order.Price)
+			log.Error.Printf(errmsg)
+			responses.SendRes(w, responses.Internal_Server_Error, nil, errmsg)
+			return
+		}
+		if escrowErr := userData.Ledger.RemoveEscrowCoins(order.UUID, refund); escrowErr != nil {
+			log.Error.Printf("Error in CancelMarketOrder, escrow does not cover refund. error: %v", escrowErr)
+			responses.SendRes(w, responses.Internal_Server_Error, nil, escrowErr.Error())
+			return
+		}
+		userData.Ledger.AddCurrency("Coins", refund)
+	} else {
+		warehouseUUID := userData.Username + "|Warehouse-" + symbol
+		var foundWarehouse bool
+		var warehouseErr error
+		warehouse, foundWarehouse, warehouseErr = schema.GetWarehouseFromDB(warehouseUUID, wdb)
+		if warehouseErr != nil {
+			log.Error.Printf("Error in CancelMarketOrder, could not get warehouse from DB. error: %v", warehouseErr)
+			responses.SendRes(w, responses.DB_Get_Failure, nil, warehouseErr.Error())
+			return
+		}
+		if !foundWarehouse {
+			warehouse = *schema.NewEmptyWarehouse(userData.Username, symbol)
+		}
+		if !stringInSlice(warehouseUUID, userData.Warehouses) {
+			userData.Warehouses = append(userData.Warehouses, warehouseUUID)
+		}
+		if escrowErr := userData.Ledger.RemoveEscrowWares(order.UUID, order.Quantity); escrowErr != nil {
+			log.Error.Printf("Error in CancelMarketOrder, escrow does not cover returned items. error: %v", escrowErr)
+			responses.SendRes(w, responses.Internal_Server_Error, nil, escrowErr.Error())
+			return
+		}
+		warehouse.AddItem(order.ItemCategory, order.ItemName, order.Quantity)
+		saveWarehouseErr := schema.SaveWarehouseToDB(wdb, &warehouse)
+		if saveWarehouseErr != nil {
+			log.Error.Printf("Error in CancelMarketOrder, could not save warehouse. error: %v", saveWarehouseErr)
+			responses.SendRes(w, responses.DB_Save_Failure, nil, saveWarehouseErr.Error())
+			return
+		}
+	}
+
+	// Save order book
+	saveBookErr := schema.SaveOrderBookToDB(cdb, &book)
+	if saveBookErr != nil {
+		log.Error.Printf("Error in CancelMarketOrder, could not save order book. error: %v", saveBookErr)
+		responses.SendRes(w, responses.DB_Save_Failure, nil, saveBookErr.Error())
+		return
+	}
+
+	// Save user
+	saveUserErr := schema.SaveUserToDB(udb, &userData)
+	if saveUserErr != nil {
+		log.Error.Printf("Error in CancelMarketOrder, could not save user. error: %v", saveUserErr)
+		responses.SendRes(w, responses.DB_Save_Failure, nil, saveUserErr.Error())
+		return
+	}
+	queueCoinsMetric(w, &userData)
+
+	responses.SendRes(w, responses.Generic_Success, map[string]interface{}{"order": order, "warehouse": warehouse, "ledger": userData.Ledger}, "")
+	log.Debug.Println(log.Cyan("-- End CancelMarketOrder --"))
+}
+
+// Handler function for the secure route: /api/my/markets/{symbol}/hire/{archetype}
+// Hires a new assistant of the specified archetype at a market for its fee in coins
+type HireAssistant struct {
+	Dbs *map[string]rdb.InteractiveDB
+	MainDictionary *schema.MainDictionary
+}
+func (h *HireAssistant) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	secureTransact(w, r, h.Dbs, func(w http.ResponseWriter, r *http.Request, dbs *map[string]rdb.InteractiveDB) {
+		txHandler := *h
+		txHandler.Dbs = dbs
+		txHandler.serve(w, r)
+	})
+}
+func (h *HireAssistant) serve(w http.ResponseWriter, r *http.Request) {
+	log.Debug.Println(log.Yellow("-- HireAssistant --"))
+	udb := (*h.Dbs)["users"]
+	OK, userData, _ := secureGetUser(w, r, udb)
+	if !OK {
+		return // Failure states handled by secureGetUser, simply return
+	}
+	// Get assistant markets to determine fog of war
+	adb := (*h.Dbs)["assistants"]
+	assistants, foundAssistants, assistantsErr := schema.GetAssistantsFromDB(userData.Assistants, adb)
+	if assistantsErr != nil {
+		log.Error.Printf("Error in HireAssistant, could not get assistants from DB. error: %v", assistantsErr)
+		responses.SendRes(w, responses.DB_Get_Failure, nil, assistantsErr.Error())
+		return
+	}
+	if !foundAssistants {
+		log.Debug.Printf("in HireAssistant, no assistants found for %s", userData.Username)
+		responses.SendRes(w, responses.No_Assitant_At_Location, nil, "")
+		return
+	}
+	// Get symbol and archetype from route
+	symbol := GetVarEntries(r, "location-symbol", AllCaps)
+	archetypeName := GetVarEntries(r, "archetype", TrueTitle)
+	// Hiring requires an assistant at the market
+	atMarket := false
+	for _, assistant := range assistants {
+		if strings.ToUpper(assistant.Location) == symbol {
+			atMarket = true
+		}
+	}
+	market, foundMarket := h.MainDictionary.Markets[symbol]
+	if !atMarket || !foundMarket {
+		log.Debug.Printf("in HireAssistant, no assistant at market %s", symbol)
+		responses.SendRes(w, responses.No_Assitant_At_Location, nil, "")
+		return
+	}
+	// Validate archetype is for hire and fee can be paid
+	archetype, foundArchetype := schema.GetAssistantType(archetypeName)
+	fee, forHire := market.Hires[archetypeName]
+	if !foundArchetype || !forHire {
+		errmsg := fmt.Sprintf("in HireAssistant, market %s does not hire archetype %s", symbol, archetypeName)
+		log.Debug.Printf(errmsg)
+		responses.SendRes(w, responses.Hire_Not_Allowed, nil, errmsg)
+		return
+	}
+	if missing := userData.Ledger.MissingCurrency(map[string]uint64{"Coins": fee}); missing != "" {
+		errmsg := fmt.Sprintf("in HireAssistant, hiring fee %d > coins: %d", fee, userData.Ledger.Currencies["Coins"])
+		log.Debug.Printf(errmsg)
+		responses.SendRes(w, responses.Hire_Not_Allowed, nil, errmsg)
+		return
+	}
+	userData.Ledger.RemoveCurrency("Coins", fee)
+
+	// Hired assistant starts at the market
+	newAssistant := schema.NewAssistant(userData.Username, schema.NextAssistantID(userData.Assistants), archetype, market.LocationSymbol)
+	saveAssistantErr := schema.SaveAssistantToDB(adb, newAssistant)
+	if saveAssistantErr != nil {
+		log.Error.Printf("Error in HireAssistant, could not save assistant. error: %v", saveAssistantErr)
+		responses.SendRes(w, responses.DB_Save_Failure, nil, saveAssistantErr.Error())
+		return
+	}
+	userData.Assistants = append(userData.Assistants, newAssistant.UUID)
+
+	// Save user
+	saveUserErr := schema.SaveUserToDB(udb, &userData)
+	if saveUserErr != nil {
+		log.Error.Printf("Error in HireAssistant, could not save user. error: %v", saveUserErr)
+		responses.SendRes(w, responses.DB_Save_Failure, nil, saveUserErr.Error())
+		return
+	}
+	queueCoinsMetric(w, &userData)
+
+	responses.SendRes(w, responses.Generic_Success, map[string]interface{}{"assistant": newAssistant, "ledger": userData.Ledger}, "")
+	log.Debug.Println(log.Cyan("-- End HireAssistant --"))
+}
+
+// Interval between keepalive comments sent on an idle event stream
+var EventKeepaliveInterval = 15 * time.Second
+
+// Handler function for the secure route: /api/my/events
+type GameEvents struct {
+	Dbs *map[string]rdb.InteractiveDB
+	Clock timecalc.Clock
+}
+func (h *GameEvents) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	log.Debug.Println(log.Yellow("-- GameEvents --"))
+	udb := (*h.Dbs)["users"]
+	OK, userData, _ := secureGetUser(w, r, udb)
+	if !OK {
+		return // Failure states handled by secureGetUser, simply return
+	}
+	flusher, canFlush := w.(http.Flusher)
+	if !canFlush {
+		errmsg := "Error in GameEvents, response writer does not support streaming"
+		log.Error.Printf(errmsg)
+		responses.SendRes(w, responses.Internal_Server_Error, nil, errmsg)
+		return
+	}
+
+	// Published events and the timers notified by the TimerScheduler both arrive on the subscription
+	subscription := events.Default.Subscribe(userData.Username)
+	defer events.Default.Unsubscribe(userData.Username, subscription)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	send := func(event schema.Event) bool {
+		data, jsonErr := json.Marshal(event)
+		if jsonErr != nil {
+			log.Error.Printf("Error in GameEvents, could not format event as JSON. event: %v, error: %v", event, jsonErr)
+			return true
+		}
+		if _, writeErr := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Type, data); writeErr != nil {
+			log.Debug.Printf("in GameEvents, could not write event, client probably disconnected: %v", writeErr)
+			return false
+		}
+		flusher.Flush()
+		return true
+	}
+
+	keepaliveTicker := time.NewTicker(EventKeepaliveInterval)
+	defer keepaliveTicker.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			log.Debug.Println(log.Cyan("-- End GameEvents --"))
+			return
+		case event := <-subscription:
+			if !send(event) {
+				return
+			}
+		case <-keepaliveTicker.C:
+			if _, writeErr := fmt.Fprint(w, ": keepalive\n\n"); writeErr != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+// Length of generated webhook signing secrets
+const WebhookSecretLength int = 32
+
+// Handler function for the secure route: /api/my/webhooks
+type WebhooksInfo struct {
+	Dbs *map[string]rdb.InteractiveDB
+}
+func (h *WebhooksInfo) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	log.Debug.Println(log.Yellow("-- WebhooksInfo --"))
+	udb := (*h.Dbs)["users"]
+	OK, userData, _ := secureGetUser(w, r, udb)
+	if !OK {
+		return // Failure states handled by secureGetUser, simply return
+	}
+	registry, foundRegistry, registryErr := schema.GetWebhookRegistryFromDB(userData.Username, (*h.Dbs)["webhooks"])
+	if registryErr != nil {
+		log.Error.Printf("Error in WebhooksInfo, could not get webhook registry from DB. error: %v", registryErr)
+		responses.SendRes(w, responses.DB_Get_Failure, nil, registryErr.Error())
+		return
+	}
+	if !foundRegistry {
+		log.Debug.Printf("in WebhooksInfo, no webhook registry for %s, probably just none registered", userData.Username)
+		responses.SendRes(w, responses.Generic_Success, schema.NewWebhookRegistry(userData.Username, ""), "None Found")
+		return
+	}
+	responses.SendRes(w, responses.Generic_Success, registry, "")
+	log.Debug.Println(log.Cyan("-- End WebhooksInfo --"))
+}
+
+// Handler function for the secure route: /api/my/webhooks
+type RegisterWebhook struct {
+	Dbs *map[string]rdb.InteractiveDB
+	Clock timecalc.Clock
+}
+func (h *RegisterWebhook) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	secureTransact(w, r, h.Dbs, func(w http.ResponseWriter, r *http.Request, dbs *map[string]rdb.InteractiveDB) {
+		txHandler := *h
+		txHandler.Dbs = dbs
+		txHandler.serve(w, r)
+	})
+}
+func (h *RegisterWebhook) serve(w http.ResponseWriter, r *http.Request) {
+	log.Debug.Println(log.Yellow("-- RegisterWebhook --"))
+	udb := (*h.Dbs)["users"]
+	OK, userData, _ := secureGetUser(w, r, udb)
+	if !OK {
+		return // Failure states handled by secureGetUser, simply return
+	}
+	// unmarshall request body to get url and subscribed events
+	var body schema.WebhookRegistration
+	decoder := json.NewDecoder(r.Body)
+	if decodeErr := decoder.Decode(&body); decodeErr != nil {
+		// Fail case, could not decode
+		errmsg := fmt.Sprintf("Decode Error in RegisterWebhook: %v", decodeErr)
+		log.Debug.Printf(errmsg)
+		responses.SendRes(w, responses.Bad_Request, nil, "Could not decode request body, ensure it conforms to expected json format.")
+		return
+	}
+	// Validate url is absolute http(s)
+	hookURL, urlErr := url.Parse(body.URL)
+	if urlErr != nil || (hookURL.Scheme != "http" && hookURL.Scheme != "https") || hookURL.Host == "" {
+		errmsg := fmt.Sprintf("in RegisterWebhook, url must be an absolute http or https url. received: %s", body.URL)
+		log.Debug.Printf(errmsg)
+		responses.SendRes(w, responses.Bad_Request, nil, errmsg)
+		return
+	}
+	// Validate event types
+	eventTypes := make([]schema.EventType, 0, len(body.Events))
+	for _, eventName := range body.Events {
+		eventType, ok := schema.EventTypeToID[eventName]
+		if !ok {
+			errmsg := fmt.Sprintf("in RegisterWebhook, unknown event type: %s", eventName)
+			log.Debug.Printf(errmsg)
+			responses.SendRes(w, responses.Bad_Request, nil, errmsg)
+			return
+		}
+		eventTypes = append(eventTypes, eventType)
+	}
+
+	// Get or create registry, generating the user's signing secret on first registration
+	wdb := (*h.Dbs)["webhooks"]
+	registry, foundRegistry, registryErr := schema.GetWebhookRegistryFromDB(userData.Username, wdb)
+	if registryErr != nil {
+		log.Error.Printf("Error in RegisterWebhook, could not get webhook registry from DB. error: %v", registryErr)
+		responses.SendRes(w, responses.DB_Get_Failure, nil, registryErr.Error())
+		return
+	}
+	if !foundRegistry {
+		secret, secretErr := auth.GenerateRandomSecureString(WebhookSecretLength)
+		if secretErr != nil {
+			log.Error.Printf("Error in RegisterWebhook, could not generate webhook secret. error: %v", secretErr)
+			responses.SendRes(w, responses.Internal_Server_Error, nil, secretErr.Error())
+			return
+		}
+		registry = *schema.NewWebhookRegistry(userData.Username, secret)
+	}
+	if len(registry.Webhooks) >= schema.MaxWebhooksPerUser {
+		errmsg := fmt.Sprintf("in RegisterWebhook, already registered the maximum of %d webhooks", schema.MaxWebhooksPerUser)
+		log.Debug.Printf(errmsg)
+		responses.SendRes(w, responses.Bad_Request, nil, errmsg)
+		return
+	}
+	webhook := registry.Add(hookURL.String(), eventTypes, h.Clock.Now().Unix())
+
+	// Save registry
+	saveRegistryErr := schema.SaveWebhookRegistryToDB(wdb, &registry)
+	if saveRegistryErr != nil {
+		log.Error.Printf("Error in RegisterWebhook, could not save webhook registry. error: %v", saveRegistryErr)
+		responses.SendRes(w, responses.DB_Save_Failure, nil, saveRegistryErr.Error())
+		return
+	}
+
+	responses.SendRes(w, responses.Generic_Success, map[string]interface{}{"webhook": webhook, "secret": registry.Secret}, "")
+	log.Debug.Println(log.Cyan("-- End RegisterWebhook --"))
+}
+
+// Handler function for the secure route: /api/my/webhooks/{webhook-id}
+type DeleteWebhook struct {
+	Dbs *map[string]rdb.InteractiveDB
+}
+func (h *DeleteWebhook) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	secureTransact(w, r, h.Dbs, func(w http.ResponseWriter, r *http.Request, dbs *map[string]rdb.InteractiveDB) {
+		txHandler := *h
+		txHandler.Dbs = dbs
+		txHandler.serve(w, r)
+	})
+}
+func (h *DeleteWebhook) serve(w http.ResponseWriter, r *http.Request) {
+	log.Debug.Println(log.Yellow("-- DeleteWebhook --"))
+	udb := (*h.Dbs)["users"]
+	OK, userData, _ := secureGetUser(w, r, udb)
+	if !OK {
+		return // Failure states handled by secureGetUser, simply return
+	}
+	// Get id from route
+	idStr := GetVarEntries(r, "webhook-id", None)
+	id, parseErr := strconv.ParseUint(idStr, 10, 64)
+	if parseErr != nil {
+		errmsg := fmt.Sprintf("in DeleteWebhook, could not parse webhook-id %s: %v", idStr, parseErr)
+		log.Debug.Printf(errmsg)
+		responses.SendRes(w, responses.Could_Not_Parse_URI_Param, nil, errmsg)
+		return
+	}
+	wdb := (*h.Dbs)["webhooks"]
+	registry, foundRegistry, registryErr := schema.GetWebhookRegistryFromDB(userData.Username, wdb)
+	if registryErr != nil {
+		log.Error.Printf("Error in DeleteWebhook, could not get webhook registry from DB. error: %v", registryErr)
+		responses.SendRes(w, responses.DB_Get_Failure, nil, registryErr.Error())
+		return
+	}
+	webhook, foundWebhook := registry.Remove(id)
+	if !foundRegistry || !foundWebhook {
+		log.Debug.Printf("in DeleteWebhook, webhook %d not found for %s", id, userData.Username)
+		responses.SendRes(w, responses.Object_Not_Found, nil, "")
+		return
+	}
+
+	// Save registry
+	saveRegistryErr := schema.SaveWebhookRegistryToDB(wdb, &registry)
+	if saveRegistryErr != nil {
+		log.Error.Printf("Error in DeleteWebhook, could not save webhook registry. error: %v", saveRegistryErr)
+		responses.SendRes(w, responses.DB_Save_Failure, nil, saveRegistryErr.Error())
+		return
+	}
+
+	responses.SendRes(w, responses.Generic_Success, webhook, "")
+	log.Debug.Println(log.Cyan("-- End DeleteWebhook --"))
+}
+
+// Handler function for the secure route: /api/my/webhooks/deliveries
+type WebhookDeliveries struct {
+	Dbs *map[string]rdb.InteractiveDB
+}
+func (h *WebhookDeliveries) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	log.Debug.Println(log.Yellow("-- WebhookDeliveries --"))
+	udb := (*h.Dbs)["users"]
+	OK, userData, _ := secureGetUser(w, r, udb)
+	if !OK {
+		return // Failure states handled by secureGetUser, simply return
+	}
+	deliveryLog, foundLog, logErr := schema.GetWebhookDeliveryLogFromDB(userData.Username, (*h.Dbs)["webhooks"])
+	if logErr != nil {
+		log.Error.Printf("Error in WebhookDeliveries, could not get webhook delivery log from DB. error: %v", logErr)
+		responses.SendRes(w, responses.DB_Get_Failure, nil, logErr.Error())
+		return
+	}
+	if !foundLog {
+		log.Debug.Printf("in WebhookDeliveries, no delivery log for %s, probably just none delivered", userData.Username)
+		responses.SendRes(w, responses.Generic_Success, schema.NewWebhookDeliveryLog(userData.Username).Deliveries, "None Found")
+		return
+	}
+	responses.SendRes(w, responses.Generic_Success, deliveryLog.Deliveries, "")
+	log.Debug.Println(log.Cyan("-- End WebhookDeliveries --"))
+}
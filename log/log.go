@@ -16,7 +16,10 @@ var (
 )
 
 func init() {
-	// Handle logging to file
+	// Handle logging to file, ensuring data dir exists for fresh checkouts and tests
+	if mkdirErr := os.MkdirAll("./data", 0755); mkdirErr != nil {
+		log.Fatalf("%v", mkdirErr)
+	}
 	var logpath = "./data/debug.ansi"
 	var debugFile, logErr = os.Create(logpath)
 	var rlogpath = "./data/rdebug.ansi"
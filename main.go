@@ -1,22 +1,24 @@
 package main
 
 import (
-	"context"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
 	"apricate/auth"
+	"apricate/events"
 	"apricate/filemngr"
 	"apricate/handlers"
 	"apricate/log"
 	"apricate/metrics"
+	"apricate/ratelimit"
 	"apricate/rdb"
 	"apricate/responses"
 	"apricate/schema"
+	"apricate/timecalc"
+	"apricate/webhooks"
 
-	"github.com/didip/tollbooth"
-	"github.com/didip/tollbooth/limiter"
 	"github.com/gorilla/mux"
 )
 
@@ -26,11 +28,25 @@ var (
 	RedisAddr = "rdb:6379"
 	apiVersion = "0.5.0"
 	// Define relationship between string database name and redis db
-	dbs = make(map[string]rdb.Database)
+	dbs = make(map[string]rdb.InteractiveDB)
 	world schema.World
 	main_dictionary = schema.MainDictionary{}
 	flush_DBs = false
 	regenerate_auth_secret = false
+	in_memory_DB = false
+	// Multiplier on how fast game time passes, above 1 runs an accelerated speed world
+	speed_world = float64(1)
+	// Unix nanoseconds the speed world clock runs from, kept in secrets.env so game time survives restarts. 0 starts a new epoch
+	speed_world_epoch = int64(0)
+	game_clock timecalc.Clock = timecalc.SystemClock{}
+	// Seed for harvest RNG, 0 draws a new seed on startup. Set in dev to replay harvests
+	harvest_seed = int64(0)
+	// Requests allowed per window for each ratelimit tier
+	rate_limit_tiers = ratelimit.DefaultTiers()
+	// Header a trusted reverse proxy sets to the client IP, such as X-Forwarded-For, empty limits by the connection address
+	rate_limit_proxy_header = ""
+	// Comma separated IPs and CIDR ranges webhooks may deliver to despite being internal addresses
+	webhook_allowed_networks = ""
 )
 
 func load_config() {
@@ -98,6 +114,123 @@ func load_config() {
 			lines = append(lines, "regenerate_auth_secret=false")
 		}
 	}
+	// Search existing file for in_memory_db
+	log.Info.Printf("Search for in_memory_db")
+	foundInMemory, i := filemngr.KeyInSliceOfLines("in_memory_db=", lines)
+	if foundInMemory {
+		// If true/false
+		splitStr := strings.Split(lines[i], "=")[1]
+		log.Info.Printf("Found in_memory_db: %s", splitStr)
+		in_memory_DB = splitStr == "true"
+	} else {
+		// Create secret in env file since could not find one to update
+		log.Info.Printf("Not found in_memory_db, creating")
+		lines = append(lines, "in_memory_db=false")
+	}
+	// Search existing file for speed_world
+	log.Info.Printf("Search for speed_world")
+	foundSpeedWorld, i := filemngr.KeyInSliceOfLines("speed_world=", lines)
+	if foundSpeedWorld {
+		splitStr := strings.Split(lines[i], "=")[1]
+		log.Info.Printf("Found speed_world: %s", splitStr)
+		speed, parseErr := strconv.ParseFloat(splitStr, 64)
+		if parseErr != nil || speed <= 0 {
+			log.Error.Printf("Invalid speed_world %s, must be a number above 0, using 1", splitStr)
+			speed = 1
+		}
+		speed_world = speed
+	} else {
+		// Create secret in env file since could not find one to update
+		log.Info.Printf("Not found speed_world, creating")
+		lines = append(lines, "speed_world=1")
+	}
+	// Search existing file for speed_world_epoch
+	log.Info.Printf("Search for speed_world_epoch")
+	foundEpoch, i := filemngr.KeyInSliceOfLines("speed_world_epoch=", lines)
+	if foundEpoch {
+		splitStr := strings.Split(lines[i], "=")[1]
+		log.Info.Printf("Found speed_world_epoch: %s", splitStr)
+		epoch, parseErr := strconv.ParseInt(splitStr, 10, 64)
+		if parseErr != nil || epoch < 0 || epoch > time.Now().UnixNano() {
+			log.Error.Printf("Invalid speed_world_epoch %s, must be unix nanoseconds no later than now, starting a new epoch", splitStr)
+			epoch = 0
+		}
+		speed_world_epoch = epoch
+	} else {
+		log.Info.Printf("Not found speed_world_epoch, creating")
+		lines = append(lines, "speed_world_epoch=0")
+		i = len(lines) - 1
+	}
+	if speed_world == 1 {
+		// Normal worlds follow the system clock, so a later speed world starts a new epoch
+		speed_world_epoch = 0
+	} else if speed_world_epoch == 0 || flush_DBs {
+		// New speed world, or game state is being flushed so game time restarts with it
+		speed_world_epoch = time.Now().UnixNano()
+		log.Important.Printf("Starting new speed world epoch %d", speed_world_epoch)
+	}
+	lines[i] = "speed_world_epoch=" + strconv.FormatInt(speed_world_epoch, 10)
+	// Search existing file for harvest_seed
+	log.Info.Printf("Search for harvest_seed")
+	foundHarvestSeed, i := filemngr.KeyInSliceOfLines("harvest_seed=", lines)
+	if foundHarvestSeed {
+		splitStr := strings.Split(lines[i], "=")[1]
+		log.Info.Printf("Found harvest_seed: %s", splitStr)
+		seed, parseErr := strconv.ParseInt(splitStr, 10, 64)
+		if parseErr != nil {
+			log.Error.Printf("Invalid harvest_seed %s, must be an integer, using 0", splitStr)
+			seed = 0
+		}
+		harvest_seed = seed
+	} else {
+		// Create secret in env file since could not find one to update
+		log.Info.Printf("Not found harvest_seed, creating")
+		lines = append(lines, "harvest_seed=0")
+	}
+	// Search existing file for each ratelimit tier
+	for _, tier := range []*ratelimit.Tier{&rate_limit_tiers.Public, &rate_limit_tiers.Standard, &rate_limit_tiers.Dev} {
+		key := "rate_limit_" + strings.ToLower(tier.Name)
+		log.Info.Printf("Search for %s", key)
+		foundTier, i := filemngr.KeyInSliceOfLines(key + "=", lines)
+		if foundTier {
+			splitStr := strings.Split(lines[i], "=")[1]
+			log.Info.Printf("Found %s: %s", key, splitStr)
+			parsed, parseErr := ratelimit.ParseTier(tier.Name, splitStr)
+			if parseErr != nil {
+				log.Error.Printf("Invalid %s, using %v. Err: %v", key, tier, parseErr)
+			} else {
+				*tier = parsed
+			}
+		} else {
+			// Create secret in env file since could not find one to update
+			log.Info.Printf("Not found %s, creating", key)
+			lines = append(lines, key + "=" + tier.String())
+		}
+	}
+	// Search existing file for rate_limit_proxy_header
+	log.Info.Printf("Search for rate_limit_proxy_header")
+	foundProxyHeader, i := filemngr.KeyInSliceOfLines("rate_limit_proxy_header=", lines)
+	if foundProxyHeader {
+		splitStr := strings.SplitN(lines[i], "=", 2)[1]
+		log.Info.Printf("Found rate_limit_proxy_header: %s", splitStr)
+		rate_limit_proxy_header = strings.TrimSpace(splitStr)
+	} else {
+		// Create secret in env file since could not find one to update
+		log.Info.Printf("Not found rate_limit_proxy_header, creating")
+		lines = append(lines, "rate_limit_proxy_header=")
+	}
+	// Search existing file for webhook_allowed_networks
+	log.Info.Printf("Search for webhook_allowed_networks")
+	foundAllowed, i := filemngr.KeyInSliceOfLines("webhook_allowed_networks=", lines)
+	if foundAllowed {
+		splitStr := strings.SplitN(lines[i], "=", 2)[1]
+		log.Info.Printf("Found webhook_allowed_networks: %s", splitStr)
+		webhook_allowed_networks = splitStr
+	} else {
+		// Create secret in env file since could not find one to update
+		log.Info.Printf("Not found webhook_allowed_networks, creating")
+		lines = append(lines, "webhook_allowed_networks=")
+	}
 	
 	// Join and write out
 	writeErr := filemngr.WriteLinesToFile("data/secrets.env", lines)
@@ -108,18 +241,32 @@ func load_config() {
 }
 
 func initialize_dbs() {
-	log.Info.Printf("Connecting to Redis server at %s", RedisAddr)
+	newDatabase := func(dbNum int) rdb.InteractiveDB {
+		return rdb.NewDatabase(RedisAddr, dbNum)
+	}
+	if in_memory_DB {
+		// Dev mode without redis, state is lost on restart
+		log.Important.Printf("Using in-memory databases")
+		store := rdb.NewMemoryStore()
+		newDatabase = func(dbNum int) rdb.InteractiveDB {
+			return rdb.NewMemoryDatabase(store, dbNum)
+		}
+	} else {
+		log.Info.Printf("Connecting to Redis server at %s", RedisAddr)
+	}
 
-	dbs["users"] = rdb.NewDatabase(RedisAddr, 0)
-	dbs["assistants"] = rdb.NewDatabase(RedisAddr, 1)
-	dbs["farms"] = rdb.NewDatabase(RedisAddr, 2)
-	dbs["contracts"] = rdb.NewDatabase(RedisAddr, 3)
-	dbs["warehouses"] = rdb.NewDatabase(RedisAddr, 4)
-	dbs["caravans"] = rdb.NewDatabase(RedisAddr, 5)
-	dbs["clearinghouse"] = rdb.NewDatabase(RedisAddr, 5)
+	dbs["users"] = newDatabase(0)
+	dbs["assistants"] = newDatabase(1)
+	dbs["farms"] = newDatabase(2)
+	dbs["contracts"] = newDatabase(3)
+	dbs["warehouses"] = newDatabase(4)
+	dbs["caravans"] = newDatabase(5)
+	dbs["clearinghouse"] = newDatabase(6)
+	dbs["webhooks"] = newDatabase(7)
+	dbs["ratelimits"] = newDatabase(8)
 
 	// Ping server
-	_, err := dbs["users"].Goredis.Ping(context.Background()).Result()
+	err := dbs["users"].Ping()
 	if err != nil {
 		log.Error.Fatalf("Could not ping redis server at %s", RedisAddr)
 	}
@@ -180,15 +327,33 @@ func initialize_dictionaries() {
 	main_dictionary.Rites = schema.Rites_load("./yaml/rites.yaml")
 	log.Debug.Println(responses.JSON(main_dictionary.Rites))
 	log.Info.Printf("Loaded Rites list")
+
+	// Load Buildings from YAML
+	log.Debug.Println("Loading Buildings list")
+	main_dictionary.Buildings = schema.Buildings_load("./yaml/buildings.yaml")
+	log.Debug.Println(responses.JSON(main_dictionary.Buildings))
+	log.Info.Printf("Loaded Buildings list")
+
+	// Load Recipes from YAML
+	log.Debug.Println("Loading Recipes list")
+	main_dictionary.Recipes = schema.Recipes_load("./yaml/recipes.yaml")
+	log.Debug.Println(responses.JSON(main_dictionary.Recipes))
+	log.Info.Printf("Loaded Recipes list")
+
+	// Load Improvements from YAML
+	log.Debug.Println("Loading Improvements list")
+	main_dictionary.Improvements = schema.Improvements_load("./yaml/improvements.yaml")
+	log.Debug.Println(responses.JSON(main_dictionary.Improvements))
+	log.Info.Printf("Loaded Improvements list")
 }
 
 func setup_my_character() {
 	if flush_DBs || regenerate_auth_secret {
-		schema.PregenerateUser("Greenitthe", dbs, true)
+		schema.PregenerateUser("Greenitthe", game_clock.Now(), dbs, true)
 		metrics.TrackNewUser("Greenitthe")
-		schema.PregenerateUser("Viridis", dbs, false)
+		schema.PregenerateUser("Viridis", game_clock.Now(), dbs, false)
 		metrics.TrackNewUser("Viridis")
-		schema.PregenerateUser("Green", dbs, true)
+		schema.PregenerateUser("Green", game_clock.Now(), dbs, true)
 		metrics.TrackNewUser("Green")
 	}
 	log.Info.Println("Neither flushing DBs, nor regenerating auth secret. Token for user: Greenitthe should already exist in secrets.env. Skipping creation")
@@ -201,6 +366,19 @@ func main() {
 	// Load config or use defaults
 	load_config()
 
+	// Setup game clock, accelerated if running a speed world
+	if speed_world != 1 {
+		log.Important.Printf("Running speed world at %vx", speed_world)
+		game_clock = timecalc.NewSpeedClock(speed_world, time.Unix(0, speed_world_epoch))
+	}
+
+	// Setup harvest RNG seed, fixed seeds replay the same harvests for the same plot and time
+	if harvest_seed == 0 {
+		harvest_seed = time.Now().UnixNano()
+	} else {
+		log.Important.Printf("Using fixed harvest seed %d", harvest_seed)
+	}
+
 	// Setup redis databases for each namespace
 	initialize_dbs()
 
@@ -247,6 +425,20 @@ func main() {
 	// Initialize dictionaries
 	initialize_dictionaries()
 
+	// Unpack arrived caravans chartered with auto_unpack in the background
+	caravanScheduler := &handlers.CaravanScheduler{Dbs: &dbs, MainDictionary: &main_dictionary, World: &world, Clock: game_clock}
+	go caravanScheduler.Run(make(chan struct{}))
+	// Take actions queued on plots in the background
+	plotScheduler := &handlers.PlotScheduler{Dbs: &dbs, MainDictionary: &main_dictionary, World: &world, Clock: game_clock, HarvestSeed: harvest_seed}
+	go plotScheduler.Run(make(chan struct{}))
+	// Relay events between servers, channels are shared by every logical db so any will do
+	if relayErr := events.Default.Relay(dbs["users"], make(chan struct{})); relayErr != nil {
+		log.Error.Fatalf("Could not relay events: %v", relayErr)
+	}
+	// Notify players streaming events on this server of their expired timers
+	timerScheduler := handlers.NewTimerScheduler(&dbs, game_clock, events.Default)
+	go timerScheduler.Run(make(chan struct{}))
+
 	// Begin Serving
 	handle_requests(slur_filter)
 }
@@ -259,7 +451,8 @@ func commonMiddleware(next http.Handler) http.Handler {
 	})
 }
 
-func handle_requests(slur_filter []string) {
+// Build the router serving every route, without ratelimiting
+func build_router(slur_filter []string) *mux.Router {
 	// Define Routes
 	//mux router
 	mxr := mux.NewRouter().StrictSlash(true)
@@ -284,56 +477,96 @@ func handle_requests(slur_filter []string) {
 	mxr.HandleFunc("/api/about/world", handlers.AboutWorld).Methods("GET")
 	mxr.HandleFunc("/api/users", handlers.UsersSummary).Methods("GET")
 	mxr.Handle("/api/users/{username}", &handlers.UsernameInfo{Dbs: &dbs}).Methods("GET")
-	mxr.Handle("/api/users/{username}/claim", &handlers.UsernameClaim{Dbs: &dbs, SlurFilter: &slur_filter}).Methods("POST")
+	mxr.Handle("/api/users/{username}/claim", &handlers.UsernameClaim{Dbs: &dbs, SlurFilter: &slur_filter, Clock: game_clock}).Methods("POST")
 	mxr.Handle("/api/islands", &handlers.IslandsOverview{World: &world}).Methods("GET")
 	mxr.Handle("/api/islands/{island-symbol}", &handlers.IslandOverview{World: &world}).Methods("GET")
 	mxr.Handle("/api/regions", &handlers.RegionsOverview{World: &world}).Methods("GET")
 	mxr.Handle("/api/regions/{region-symbol}", &handlers.RegionOverview{World: &world}).Methods("GET")
+	mxr.Handle("/api/routes/{origin}/{destination}", &handlers.RoutesOverview{World: &world, Clock: game_clock}).Methods("GET")
 	mxr.Handle("/api/plants", &handlers.PlantsOverview{MainDictionary: &main_dictionary}).Methods("GET")
 	mxr.Handle("/api/plants/{plant-name}", &handlers.PlantOverview{MainDictionary: &main_dictionary}).Methods("GET")
 	mxr.Handle("/api/plants/{plant-name}/stage/{stageNum}", &handlers.PlantStageOverview{MainDictionary: &main_dictionary}).Methods("GET")
 	mxr.Handle("/api/rites", &handlers.RitesOverview{MainDictionary: &main_dictionary}).Methods("GET")
 	mxr.Handle("/api/rites/{runic-symbol}", &handlers.RiteOverview{MainDictionary: &main_dictionary}).Methods("GET")
+	mxr.Handle("/api/buildings", &handlers.BuildingsOverview{MainDictionary: &main_dictionary}).Methods("GET")
+	mxr.Handle("/api/buildings/{building-name}", &handlers.BuildingOverview{MainDictionary: &main_dictionary}).Methods("GET")
+	mxr.Handle("/api/recipes", &handlers.RecipesOverview{MainDictionary: &main_dictionary}).Methods("GET")
+	mxr.Handle("/api/recipes/{recipe-name}", &handlers.RecipeOverview{MainDictionary: &main_dictionary}).Methods("GET")
+	mxr.Handle("/api/improvements", &handlers.ImprovementsOverview{MainDictionary: &main_dictionary}).Methods("GET")
+	mxr.Handle("/api/improvements/{improvement-name}", &handlers.ImprovementOverview{MainDictionary: &main_dictionary}).Methods("GET")
 	mxr.HandleFunc("/api/metrics", handlers.MetricsOverview).Methods("GET")
 
+	// Deliver published events to registered webhooks
+	allowedNetworks, allowedErr := webhooks.ParseAllowedNetworks(webhook_allowed_networks)
+	if allowedErr != nil {
+		log.Error.Printf("Invalid webhook_allowed_networks %s, allowing no internal addresses. Err: %v", webhook_allowed_networks, allowedErr)
+	}
+	webhooks.AllowedNetworks = allowedNetworks
+	events.Default.SetForwarder(webhooks.NewDispatcher(&dbs).Dispatch)
+
 	// secure subrouter for account-specific routes
 	secure := mxr.PathPrefix("/api/my").Subrouter()
 	secure.Use(auth.GenerateTokenValidationMiddlewareFunc(dbs["users"]))
 	secure.Handle("/user", &handlers.AccountInfo{Dbs: &dbs}).Methods("GET")
 	secure.Handle("/assistants", &handlers.AssistantsInfo{Dbs: &dbs}).Methods("GET")
 	secure.Handle("/assistants/{assistant-id}", &handlers.AssistantInfo{Dbs: &dbs}).Methods("GET")
-	secure.Handle("/caravans", &handlers.CaravansInfo{Dbs: &dbs}).Methods("GET")
-	secure.Handle("/caravans", &handlers.CharterCaravan{Dbs: &dbs, World: &world}).Methods("PATCH")
-	secure.Handle("/caravans/{caravan-id}", &handlers.CaravanInfo{Dbs: &dbs}).Methods("GET")
-	secure.Handle("/caravans/{caravan-id}", &handlers.UnpackCaravan{Dbs: &dbs}).Methods("DELETE")
-	secure.Handle("/farms", &handlers.FarmsInfo{Dbs: &dbs}).Methods("GET")
-	secure.Handle("/farms/{location-symbol}", &handlers.FarmInfo{Dbs: &dbs}).Methods("GET")
-	secure.Handle("/farms/{location-symbol}/ritual/{runic-symbol}", &handlers.ConductRitual{Dbs: &dbs, MainDictionary: &main_dictionary}).Methods("POST")
+	secure.Handle("/assistants/{assistant-id}/improve", &handlers.ImproveAssistant{Dbs: &dbs, MainDictionary: &main_dictionary}).Methods("POST")
+	secure.Handle("/caravans", &handlers.CaravansInfo{Dbs: &dbs, Clock: game_clock}).Methods("GET")
+	secure.Handle("/caravans", &handlers.CharterCaravan{Dbs: &dbs, MainDictionary: &main_dictionary, World: &world, Clock: game_clock}).Methods("PATCH")
+	secure.Handle("/caravans/{caravan-id}", &handlers.CaravanInfo{Dbs: &dbs, Clock: game_clock}).Methods("GET")
+	secure.Handle("/caravans/{caravan-id}", &handlers.UnpackCaravan{Dbs: &dbs, MainDictionary: &main_dictionary, World: &world, Clock: game_clock}).Methods("DELETE")
+	secure.Handle("/caravans/{caravan-id}/recall", &handlers.RecallCaravan{Dbs: &dbs, Clock: game_clock}).Methods("POST")
+	secure.Handle("/routes", &handlers.RouteQuote{Dbs: &dbs, MainDictionary: &main_dictionary, World: &world, Clock: game_clock}).Methods("GET")
+	secure.Handle("/farms", &handlers.FarmsInfo{Dbs: &dbs, MainDictionary: &main_dictionary, Clock: game_clock}).Methods("GET")
+	secure.Handle("/farms/{location-symbol}", &handlers.FarmInfo{Dbs: &dbs, MainDictionary: &main_dictionary, Clock: game_clock}).Methods("GET")
+	secure.Handle("/farms/{location-symbol}/buildings/{building}", &handlers.ConstructBuilding{Dbs: &dbs, MainDictionary: &main_dictionary, Clock: game_clock}).Methods("POST")
+	secure.Handle("/farms/{location-symbol}/craft", &handlers.CraftRecipe{Dbs: &dbs, MainDictionary: &main_dictionary, Clock: game_clock}).Methods("POST")
+	secure.Handle("/farms/{location-symbol}/craft", &handlers.CollectCrafting{Dbs: &dbs, MainDictionary: &main_dictionary, World: &world, Clock: game_clock}).Methods("DELETE")
+	secure.Handle("/farms/{location-symbol}/ritual/{runic-symbol}", &handlers.ConductRitual{Dbs: &dbs, MainDictionary: &main_dictionary, World: &world, Clock: game_clock}).Methods("POST")
+	secure.Handle("/farms/{location-symbol}/plots/plant", &handlers.BatchPlantPlots{Dbs: &dbs, MainDictionary: &main_dictionary, Clock: game_clock}).Methods("POST")
+	secure.Handle("/farms/{location-symbol}/plots/interact", &handlers.BatchInteractPlots{Dbs: &dbs, MainDictionary: &main_dictionary, World: &world, Clock: game_clock, HarvestSeed: harvest_seed}).Methods("PATCH")
+	secure.Handle("/farms/{location-symbol}/plots/clear", &handlers.BatchClearPlots{Dbs: &dbs, Clock: game_clock}).Methods("PUT")
 	secure.Handle("/contracts", &handlers.ContractsInfo{Dbs: &dbs}).Methods("GET")
 	secure.Handle("/contracts/{contract-id}", &handlers.ContractInfo{Dbs: &dbs}).Methods("GET")
-	secure.Handle("/warehouses", &handlers.WarehousesInfo{Dbs: &dbs}).Methods("GET")
-	secure.Handle("/warehouses/{location-symbol}", &handlers.WarehouseInfo{Dbs: &dbs}).Methods("GET")
+	secure.Handle("/contracts/{contract-id}/fulfill", &handlers.FulfillContract{Dbs: &dbs, MainDictionary: &main_dictionary, World: &world, Clock: game_clock}).Methods("POST")
+	secure.Handle("/events", &handlers.GameEvents{Dbs: &dbs, Clock: game_clock}).Methods("GET")
+	secure.Handle("/webhooks", &handlers.WebhooksInfo{Dbs: &dbs}).Methods("GET")
+	secure.Handle("/webhooks", &handlers.RegisterWebhook{Dbs: &dbs, Clock: game_clock}).Methods("POST")
+	secure.Handle("/webhooks/deliveries", &handlers.WebhookDeliveries{Dbs: &dbs}).Methods("GET")
+	secure.Handle("/webhooks/{webhook-id}", &handlers.DeleteWebhook{Dbs: &dbs}).Methods("DELETE")
+	secure.Handle("/warehouses", &handlers.WarehousesInfo{Dbs: &dbs, MainDictionary: &main_dictionary, World: &world, Clock: game_clock}).Methods("GET")
+	secure.Handle("/warehouses/{location-symbol}", &handlers.WarehouseInfo{Dbs: &dbs, MainDictionary: &main_dictionary, World: &world, Clock: game_clock}).Methods("GET")
 	secure.Handle("/nearby-locations", &handlers.NearbyLocationsInfo{Dbs: &dbs, World: &world}).Methods("GET")
 	secure.Handle("/locations", &handlers.LocationsInfo{Dbs: &dbs, World: &world}).Methods("GET")
 	secure.Handle("/locations/{location-symbol}", &handlers.LocationInfo{Dbs: &dbs, World: &world}).Methods("GET")
-	secure.Handle("/markets", &handlers.MarketsInfo{Dbs: &dbs, MainDictionary: &main_dictionary}).Methods("GET")
-	secure.Handle("/markets/{location-symbol}", &handlers.MarketInfo{Dbs: &dbs, MainDictionary: &main_dictionary}).Methods("GET")
-	secure.Handle("/markets/{location-symbol}/order", &handlers.MarketOrder{Dbs: &dbs, MainDictionary: &main_dictionary}).Methods("PATCH")
+	secure.Handle("/locations/{location-symbol}/contracts", &handlers.ContractBoardInfo{Dbs: &dbs, MainDictionary: &main_dictionary, World: &world, Clock: game_clock}).Methods("GET")
+	secure.Handle("/locations/{location-symbol}/contracts/{listing-id}", &handlers.AcceptContract{Dbs: &dbs, MainDictionary: &main_dictionary, World: &world, Clock: game_clock}).Methods("POST")
+	secure.Handle("/markets", &handlers.MarketsInfo{Dbs: &dbs, MainDictionary: &main_dictionary, Clock: game_clock}).Methods("GET")
+	secure.Handle("/markets/{location-symbol}", &handlers.MarketInfo{Dbs: &dbs, MainDictionary: &main_dictionary, Clock: game_clock}).Methods("GET")
+	secure.Handle("/markets/{location-symbol}/order", &handlers.MarketOrder{Dbs: &dbs, MainDictionary: &main_dictionary, World: &world, Clock: game_clock}).Methods("PATCH")
+	secure.Handle("/markets/{location-symbol}/orders", &handlers.MarketOrderBook{Dbs: &dbs, MainDictionary: &main_dictionary}).Methods("GET")
+	secure.Handle("/markets/{location-symbol}/orders/{order-id}", &handlers.CancelMarketOrder{Dbs: &dbs}).Methods("DELETE")
+	secure.Handle("/markets/{location-symbol}/hire/{archetype}", &handlers.HireAssistant{Dbs: &dbs, MainDictionary: &main_dictionary}).Methods("POST")
 	secure.Handle("/plots", &handlers.PlotsInfo{Dbs: &dbs}).Methods("GET")
 	secure.Handle("/plots/{plot-id}", &handlers.PlotInfo{Dbs: &dbs}).Methods("GET")
-	secure.Handle("/plots/{plot-id}/plant", &handlers.PlantPlot{Dbs: &dbs, MainDictionary: &main_dictionary}).Methods("POST")
-	secure.Handle("/plots/{plot-id}/clear", &handlers.ClearPlot{Dbs: &dbs}).Methods("PUT")
-	secure.Handle("/plots/{plot-id}/interact", &handlers.InteractPlot{Dbs: &dbs, MainDictionary: &main_dictionary}).Methods("PATCH")
+	secure.Handle("/plots/{plot-id}/plant", &handlers.PlantPlot{Dbs: &dbs, MainDictionary: &main_dictionary, Clock: game_clock}).Methods("POST")
+	secure.Handle("/plots/{plot-id}/clear", &handlers.ClearPlot{Dbs: &dbs, Clock: game_clock}).Methods("PUT")
+	secure.Handle("/plots/{plot-id}/interact", &handlers.InteractPlot{Dbs: &dbs, MainDictionary: &main_dictionary, World: &world, Clock: game_clock, HarvestSeed: harvest_seed}).Methods("PATCH")
+	secure.Handle("/plots/{plot-id}/queue", &handlers.QueuePlotActions{Dbs: &dbs}).Methods("PUT")
+	secure.Handle("/plots/{plot-id}/queue", &handlers.ClearPlotQueue{Dbs: &dbs}).Methods("DELETE")
+	return mxr
+}
+
+func handle_requests(slur_filter []string) {
+	mxr := build_router(slur_filter)
 
-	// Setup ratelimiting
-	maxRequestsSec := 4
-	lmt := tollbooth.NewLimiter(float64(maxRequestsSec), &limiter.ExpirableOptions{DefaultExpirationTTL: time.Hour})
-	lmt.SetIPLookups([]string{"RemoteAddr", "X-Forwarded-For", "X-Real-IP"}).SetMethods([]string{"GET", "POST", "PATCH", "DELETE", "PUT"}).SetBurst(4)
-	mxr_tollbooth := tollbooth.LimitHandler(lmt, mxr)
+	// Setup ratelimiting per user, shared between servers through the ratelimits DB
+	log.Info.Printf("Ratelimiting Public: %v, Standard: %v, Dev: %v", rate_limit_tiers.Public, rate_limit_tiers.Standard, rate_limit_tiers.Dev)
+	limiter := ratelimit.Limiter{Users: dbs["users"], Counters: dbs["ratelimits"], Tiers: rate_limit_tiers, ProxyHeader: rate_limit_proxy_header}
 
 	// Start listening
 	log.Info.Printf("Listening on %s", ListenPort)
-	if err := http.ListenAndServe(ListenPort, mxr_tollbooth); err != nil {
+	if err := http.ListenAndServe(ListenPort, limiter.Handler(mxr)); err != nil {
 		log.Error.Printf("ListenAndServe Uncaught Err: \n %v", err)
 	}
 }
\ No newline at end of file
@@ -0,0 +1,1512 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"apricate/events"
+	"apricate/handlers"
+	"apricate/metrics"
+	"apricate/ratelimit"
+	"apricate/rdb"
+	"apricate/responses"
+	"apricate/schema"
+	"apricate/timecalc"
+	"apricate/webhooks"
+)
+
+// Secret used to sign and validate tokens during tests
+const testAccessSecret = "apricate-e2e-test-secret"
+
+// Fixed harvest seed and game start time so test harvests are reproducible
+const testHarvestSeed int64 = 20220101
+var testStartTime = time.Unix(1640995200, 0)
+
+// Defines a decoded api response, data is left raw to be decoded per endpoint
+type testResponse struct {
+	Code responses.ResponseCode `json:"code"`
+	Message string `json:"message"`
+	Data json.RawMessage `json:"data"`
+}
+
+// Defines a client driving the api as a single user
+type testClient struct {
+	t *testing.T
+	server *httptest.Server
+	token string
+}
+
+// Start a server with the same router as main, on top of fresh in-memory databases and a stopped game clock
+func newTestServer(t *testing.T) (*httptest.Server, *timecalc.FakeClock) {
+	t.Helper()
+	os.Setenv("APRICATE_ACCESS_SECRET", testAccessSecret)
+	clock := timecalc.NewFakeClock(testStartTime)
+	game_clock = clock
+	harvest_seed = testHarvestSeed
+	in_memory_DB = true
+	flush_DBs = false
+	regenerate_auth_secret = false
+	dbs = make(map[string]rdb.InteractiveDB)
+	initialize_dbs()
+	world = schema.World_load("./yaml/world/regions.yaml", "./yaml/world/islands", "./yaml/world/locations")
+	main_dictionary = schema.MainDictionary{}
+	initialize_dictionaries()
+	stopRelay := make(chan struct{})
+	t.Cleanup(func() { close(stopRelay) })
+	if err := events.Default.Relay(dbs["users"], stopRelay); err != nil {
+		t.Fatalf("could not relay events: %v", err)
+	}
+	server := httptest.NewServer(build_router(make([]string, 0)))
+	t.Cleanup(server.Close)
+	return server, clock
+}
+
+// Claim username and return a client authenticated as that user
+func claimTestUser(t *testing.T, server *httptest.Server, username string) *testClient {
+	t.Helper()
+	c := &testClient{t: t, server: server}
+	res := c.expect(responses.Generic_Success, "POST", "/api/users/" + username + "/claim", nil)
+	var claimed schema.User
+	c.decode(res, &claimed)
+	if claimed.Token == "" {
+		t.Fatalf("claim for %s returned no token: %s", username, res.Data)
+	}
+	c.token = claimed.Token
+	return c
+}
+
+// Send a request with body marshalled as json, returning the decoded response
+func (c *testClient) do(method string, path string, body interface{}) testResponse {
+	c.t.Helper()
+	var reqBody bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&reqBody).Encode(body); err != nil {
+			c.t.Fatalf("could not encode body for %s %s: %v", method, path, err)
+		}
+	}
+	req, err := http.NewRequest(method, c.server.URL + path, &reqBody)
+	if err != nil {
+		c.t.Fatalf("could not create request %s %s: %v", method, path, err)
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer " + c.token)
+	}
+	httpRes, err := c.server.Client().Do(req)
+	if err != nil {
+		c.t.Fatalf("request %s %s failed: %v", method, path, err)
+	}
+	defer httpRes.Body.Close()
+	var res testResponse
+	if err := json.NewDecoder(httpRes.Body).Decode(&res); err != nil {
+		c.t.Fatalf("could not decode response for %s %s: %v", method, path, err)
+	}
+	return res
+}
+
+// Send a request and fail the test unless the response has the expected code
+func (c *testClient) expect(code responses.ResponseCode, method string, path string, body interface{}) testResponse {
+	c.t.Helper()
+	res := c.do(method, path, body)
+	if res.Code != code {
+		c.t.Fatalf("%s %s: expected code %d got %d: %s %s", method, path, code, res.Code, res.Message, res.Data)
+	}
+	return res
+}
+
+// Decode response data into v
+func (c *testClient) decode(res testResponse, v interface{}) {
+	c.t.Helper()
+	if err := json.Unmarshal(res.Data, v); err != nil {
+		c.t.Fatalf("could not decode response data %s: %v", res.Data, err)
+	}
+}
+
+// Get the user's coins from their ledger
+func (c *testClient) coins() uint64 {
+	c.t.Helper()
+	var user schema.User
+	c.decode(c.expect(responses.Generic_Success, "GET", "/api/my/user", nil), &user)
+	return user.Ledger.Currencies["Coins"]
+}
+
+// Plant spectral grass in the first home plot and grow it through to harvest, returning the harvest response
+func (c *testClient) growSpectralGrass(clock *timecalc.FakeClock) schema.PlotActionResponse {
+	c.t.Helper()
+	plotPath := "/api/my/plots/TS-PR-HF!Plot-0"
+
+	// Plant
+	var planted schema.PlotPlantResponse
+	c.decode(c.expect(responses.Generic_Success, "POST", plotPath + "/plant", map[string]interface{}{"name": "Spectral Grass Seeds", "quantity": 16, "size": "Tiny"}), &planted)
+	if planted.Plot.PlantedPlant == nil || planted.Plot.PlantedPlant.PlantType != "Spectral Grass" {
+		c.t.Fatalf("expected spectral grass planted, got plot %+v", planted.Plot)
+	}
+	if planted.Warehouse.Seeds["Spectral Grass Seeds"] != 0 {
+		c.t.Fatalf("expected all spectral grass seeds planted, warehouse has %d left", planted.Warehouse.Seeds["Spectral Grass Seeds"])
+	}
+
+	// Grow, the wait stage is ready immediately but each following stage must wait for growth
+	var grown schema.PlotActionResponse
+	c.decode(c.expect(responses.Generic_Success, "PATCH", plotPath + "/interact", map[string]string{"action": "Wait"}), &grown)
+	c.expect(responses.Plants_Still_Growing, "PATCH", plotPath + "/interact", map[string]string{"action": "Trim"})
+	clock.AdvanceSeconds(grown.Plot.GrowthCompleteTimestamp - clock.Now().Unix())
+	c.decode(c.expect(responses.Generic_Success, "PATCH", plotPath + "/interact", map[string]string{"action": "Trim"}), &grown)
+	c.expect(responses.Plants_Still_Growing, "PATCH", plotPath + "/interact", map[string]string{"action": "Reap"})
+	clock.AdvanceSeconds(grown.Plot.GrowthCompleteTimestamp - clock.Now().Unix())
+
+	// Harvest
+	var harvested schema.PlotActionResponse
+	c.decode(c.expect(responses.Generic_Success, "PATCH", plotPath + "/interact", map[string]string{"action": "Reap"}), &harvested)
+	if harvested.Plot.PlantedPlant != nil {
+		c.t.Fatalf("expected plot cleared after final harvest, got plant %+v", harvested.Plot.PlantedPlant)
+	}
+	return harvested
+}
+
+// Plant and harvest spectral grass, caravan the fiber to market, sell it, and conduct a ritual
+func TestFarmingLoop(t *testing.T) {
+	server, clock := newTestServer(t)
+	c := claimTestUser(t, server, "Farmer")
+
+	harvested := c.growSpectralGrass(clock)
+	fiber := harvested.Warehouse.Goods["Spectral Fiber"]
+	if fiber == 0 {
+		t.Fatalf("expected spectral fiber from harvest, warehouse goods: %v", harvested.Warehouse.Goods)
+	}
+	if harvested.Warehouse.Seeds["Spectral Grass Seeds"] == 0 {
+		t.Fatalf("expected spectral grass seeds from harvest, warehouse seeds: %v", harvested.Warehouse.Seeds)
+	}
+
+	// Charter a caravan carrying the fiber to the ranch, which imports it
+	var caravan schema.Caravan
+	c.decode(c.expect(responses.Generic_Success, "PATCH", "/api/my/caravans", schema.CaravanCharter{
+		Origin: "TS-PR-HF",
+		Destination: "TS-PR-BG",
+		Assistants: []int64{0},
+		Wares: schema.Wareset{Goods: map[string]uint64{"Spectral Fiber": fiber}},
+	}), &caravan)
+	caravanPath := fmt.Sprintf("/api/my/caravans/%d", caravan.ID)
+	c.expect(responses.Caravan_Not_Arrived, "DELETE", caravanPath, nil)
+	clock.AdvanceSeconds(caravan.ArrivalTime - clock.Now().Unix())
+	c.expect(responses.Generic_Success, "DELETE", caravanPath, nil)
+
+	var unpacked schema.Warehouse
+	c.decode(c.expect(responses.Generic_Success, "GET", "/api/my/warehouses/TS-PR-BG", nil), &unpacked)
+	if unpacked.Goods["Spectral Fiber"] != fiber {
+		t.Fatalf("expected %d spectral fiber unpacked at TS-PR-BG, got %d", fiber, unpacked.Goods["Spectral Fiber"])
+	}
+
+	// Sell
+	coinsBefore := c.coins()
+	c.expect(responses.Generic_Success, "PATCH", "/api/my/markets/TS-PR-BG/order", map[string]interface{}{
+		"order_type": "MARKET",
+		"transaction_type": "SELL",
+		"item_category": "GOODS",
+		"item_name": "Spectral Fiber",
+		"quantity": fiber,
+	})
+	if coinsAfter := c.coins(); coinsAfter <= coinsBefore {
+		t.Fatalf("expected coins to increase from selling fiber, before: %d after: %d", coinsBefore, coinsAfter)
+	} else if tracked := metrics.TrackingUserCoins.Coins["Farmer"]; tracked != coinsAfter {
+		t.Fatalf("expected %d coins tracked once the sale committed, got %d", coinsAfter, tracked)
+	}
+
+	// Conduct a ritual at the home farm's summoning circle, the lattice rejects another until its rejection time passes
+	c.expect(responses.Generic_Success, "POST", "/api/my/farms/TS-PR-HF/ritual/FRGJR", nil)
+	c.expect(responses.Bad_Request, "POST", "/api/my/farms/TS-PR-HF/ritual/FRGJR", nil)
+	clock.AdvanceSeconds(int64(main_dictionary.Rites["FRGJR"].RejectionTime))
+	c.expect(responses.Generic_Success, "POST", "/api/my/farms/TS-PR-HF/ritual/FRGJR", nil)
+}
+
+// Harvests replay exactly given the same harvest seed, plot, and game time
+func TestHarvestReplay(t *testing.T) {
+	harvests := make([]*schema.Warehouse, 2)
+	for i := range harvests {
+		server, clock := newTestServer(t)
+		c := claimTestUser(t, server, "Farmer")
+		harvests[i] = c.growSpectralGrass(clock).Warehouse
+	}
+	if !reflect.DeepEqual(harvests[0], harvests[1]) {
+		t.Fatalf("expected identical harvests with the same seed, got %+v and %+v", harvests[0], harvests[1])
+	}
+}
+
+// Open the user's event stream, returning a channel of events read from it until the test ends
+func (c *testClient) streamEvents() chan schema.Event {
+	c.t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	c.t.Cleanup(cancel)
+	req, err := http.NewRequestWithContext(ctx, "GET", c.server.URL + "/api/my/events", nil)
+	if err != nil {
+		c.t.Fatalf("could not create event stream request: %v", err)
+	}
+	req.Header.Set("Authorization", "Bearer " + c.token)
+	httpRes, err := c.server.Client().Do(req)
+	if err != nil {
+		c.t.Fatalf("event stream request failed: %v", err)
+	}
+	if contentType := httpRes.Header.Get("Content-Type"); contentType != "text/event-stream" {
+		c.t.Fatalf("expected event stream content type, got %s", contentType)
+	}
+	stream := make(chan schema.Event, 64)
+	go func() {
+		defer httpRes.Body.Close()
+		scanner := bufio.NewScanner(httpRes.Body)
+		for scanner.Scan() {
+			line := scanner.Text()
+			if !strings.HasPrefix(line, "data: ") {
+				continue
+			}
+			var event schema.Event
+			if json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &event) == nil {
+				stream <- event
+			}
+		}
+	}()
+	return stream
+}
+
+// Wait for an event of eventType about subject, skipping any others
+func (c *testClient) awaitEvent(stream chan schema.Event, eventType schema.EventType, subject string) schema.Event {
+	c.t.Helper()
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case event := <-stream:
+			if event.Type == eventType && event.Subject == subject {
+				return event
+			}
+		case <-timeout:
+			c.t.Fatalf("timed out waiting for %s event for %s", eventType, subject)
+		}
+	}
+}
+
+// Timers expiring and order fills are streamed to the player as events
+func TestEventStream(t *testing.T) {
+	server, clock := newTestServer(t)
+	c := claimTestUser(t, server, "Farmer")
+	stream := c.streamEvents()
+	// The stream has subscribed once its headers are received, so timers are notified to it
+	timers := handlers.NewTimerScheduler(&dbs, clock, events.Default)
+	notify := func(expected int) {
+		t.Helper()
+		if notified := timers.NotifyExpired(); notified != expected {
+			t.Fatalf("expected %d timers notified, got %d", expected, notified)
+		}
+	}
+
+	// Another server the player streams from, relaying through the same database
+	otherServer := events.NewBroker()
+	stopOther := make(chan struct{})
+	defer close(stopOther)
+	if err := otherServer.Relay(dbs["users"], stopOther); err != nil {
+		t.Fatalf("could not relay events: %v", err)
+	}
+	otherServerStream := otherServer.Subscribe("Farmer")
+	otherServerTimers := handlers.NewTimerScheduler(&dbs, clock, otherServer)
+
+	// Plot growth, published once by whichever server claims it and relayed to both
+	plotPath := "/api/my/plots/TS-PR-HF!Plot-0"
+	c.expect(responses.Generic_Success, "POST", plotPath + "/plant", map[string]interface{}{"name": "Spectral Grass Seeds", "quantity": 16, "size": "Tiny"})
+	var grown schema.PlotActionResponse
+	c.decode(c.expect(responses.Generic_Success, "PATCH", plotPath + "/interact", map[string]string{"action": "Wait"}), &grown)
+	clock.AdvanceSeconds(grown.Plot.GrowthCompleteTimestamp - clock.Now().Unix())
+	notify(1)
+	if notified := otherServerTimers.NotifyExpired(); notified != 0 {
+		t.Fatalf("expected the growth timer already claimed, other server notified %d", notified)
+	}
+	if event := c.awaitEvent(stream, schema.Event_PlotGrowthComplete, grown.Plot.UUID); event.Timestamp != grown.Plot.GrowthCompleteTimestamp {
+		t.Fatalf("expected growth event at %d, got %d", grown.Plot.GrowthCompleteTimestamp, event.Timestamp)
+	}
+	c.awaitEvent(otherServerStream, schema.Event_PlotGrowthComplete, grown.Plot.UUID)
+	otherServer.Unsubscribe("Farmer", otherServerStream)
+
+	// Lattice rejection
+	c.expect(responses.Generic_Success, "POST", "/api/my/farms/TS-PR-HF/ritual/FRGJR", nil)
+	clock.AdvanceSeconds(int64(main_dictionary.Rites["FRGJR"].RejectionTime))
+	notify(1)
+	c.awaitEvent(stream, schema.Event_LatticeRejectionEnded, "Farmer")
+
+	// Market order fill, both sides of the trade are notified
+	other := claimTestUser(t, server, "Trader")
+	otherStream := other.streamEvents()
+	var resting struct {
+		Order schema.LimitOrder `json:"order"`
+	}
+	c.decode(c.expect(responses.Generic_Success, "PATCH", "/api/my/markets/TS-PR-HF/order", map[string]interface{}{
+		"order_type": "LIMIT",
+		"transaction_type": "SELL",
+		"item_category": "SEEDS",
+		"item_name": "Cabbage Seeds",
+		"quantity": 1,
+		"price": 1,
+	}), &resting)
+	other.expect(responses.Generic_Success, "PATCH", "/api/my/markets/TS-PR-HF/order", map[string]interface{}{
+		"order_type": "LIMIT",
+		"transaction_type": "BUY",
+		"item_category": "SEEDS",
+		"item_name": "Cabbage Seeds",
+		"quantity": 1,
+		"price": 1,
+	})
+	c.awaitEvent(stream, schema.Event_MarketOrderFilled, resting.Order.UUID)
+	select {
+	case event := <-otherStream:
+		if event.Type != schema.Event_MarketOrderFilled {
+			t.Fatalf("expected taker to be notified of fill, got %s event", event.Type)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timed out waiting for taker fill event")
+	}
+}
+
+// Defines a request received by a test webhook receiver
+type testDelivery struct {
+	header http.Header
+	body []byte
+}
+
+// Plot interactions are delivered to a registered webhook, signed, retried after failure, and logged
+func TestWebhookDelivery(t *testing.T) {
+	retryBackoff := webhooks.RetryBackoff
+	webhooks.RetryBackoff = 10 * time.Millisecond
+	t.Cleanup(func() { webhooks.RetryBackoff = retryBackoff })
+	// The test receiver listens on loopback, which deliveries may only reach once allowed
+	webhook_allowed_networks = "127.0.0.1"
+	t.Cleanup(func() { webhook_allowed_networks = "" })
+	server, _ := newTestServer(t)
+	c := claimTestUser(t, server, "Farmer")
+
+	// Receiver redirects the first attempt to an internal address, which is not followed so the delivery must be retried
+	received := make(chan testDelivery, 8)
+	attempts := 0
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		attempts++
+		if attempts == 1 {
+			http.Redirect(w, r, "http://10.0.0.1/", http.StatusFound)
+			return
+		}
+		received <- testDelivery{header: r.Header, body: body}
+	}))
+	t.Cleanup(receiver.Close)
+
+	c.expect(responses.Bad_Request, "POST", "/api/my/webhooks", map[string]interface{}{"url": "ftp://example.com"})
+	c.expect(responses.Bad_Request, "POST", "/api/my/webhooks", map[string]interface{}{"url": receiver.URL, "events": []string{"not_an_event"}})
+	var registered struct {
+		Webhook schema.Webhook `json:"webhook"`
+		Secret string `json:"secret"`
+	}
+	c.decode(c.expect(responses.Generic_Success, "POST", "/api/my/webhooks", map[string]interface{}{"url": receiver.URL, "events": []string{"plot_interacted"}}), &registered)
+	if registered.Secret == "" {
+		t.Fatalf("expected webhook secret on registration")
+	}
+	// Internal addresses that are not allowed are refused when delivering
+	var internal struct {
+		Webhook schema.Webhook `json:"webhook"`
+	}
+	c.decode(c.expect(responses.Generic_Success, "POST", "/api/my/webhooks", map[string]interface{}{"url": "http://10.0.0.1:9/", "events": []string{"plot_interacted"}}), &internal)
+
+	// Planting is not subscribed to, interacting is
+	plotPath := "/api/my/plots/TS-PR-HF!Plot-0"
+	c.expect(responses.Generic_Success, "POST", plotPath + "/plant", map[string]interface{}{"name": "Spectral Grass Seeds", "quantity": 16, "size": "Tiny"})
+	c.expect(responses.Generic_Success, "PATCH", plotPath + "/interact", map[string]string{"action": "Wait"})
+	var delivered testDelivery
+	select {
+	case delivered = <-received:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timed out waiting for webhook delivery")
+	}
+	if signature := delivered.header.Get(webhooks.SignatureHeader); signature != webhooks.Sign(registered.Secret, delivered.body) {
+		t.Fatalf("delivery signature %s does not match body", signature)
+	}
+	var event schema.Event
+	if err := json.Unmarshal(delivered.body, &event); err != nil || event.Type != schema.Event_PlotInteracted {
+		t.Fatalf("expected plot_interacted event, got %s: %v", delivered.body, err)
+	}
+
+	// Delivery log records the retried success
+	var deliveries []schema.WebhookDelivery
+	for deadline := time.Now().Add(5 * time.Second); len(deliveries) < 2; {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for webhook delivery log, got %+v", deliveries)
+		}
+		time.Sleep(10 * time.Millisecond)
+		c.decode(c.expect(responses.Generic_Success, "GET", "/api/my/webhooks/deliveries", nil), &deliveries)
+	}
+	for _, delivery := range deliveries {
+		switch delivery.WebhookID {
+		case registered.Webhook.ID:
+			if !delivery.Success || delivery.Attempts != 2 {
+				t.Fatalf("expected successful delivery after 2 attempts, got %+v", delivery)
+			}
+		case internal.Webhook.ID:
+			if delivery.Success || delivery.StatusCode != 0 || !strings.Contains(delivery.Error, "internal address") {
+				t.Fatalf("expected delivery to internal address to be refused, got %+v", delivery)
+			}
+		default:
+			t.Fatalf("unexpected delivery %+v", delivery)
+		}
+	}
+	c.expect(responses.Generic_Success, "DELETE", fmt.Sprintf("/api/my/webhooks/%d", internal.Webhook.ID), nil)
+
+	// Remove
+	c.expect(responses.Generic_Success, "DELETE", fmt.Sprintf("/api/my/webhooks/%d", registered.Webhook.ID), nil)
+	c.expect(responses.Object_Not_Found, "DELETE", fmt.Sprintf("/api/my/webhooks/%d", registered.Webhook.ID), nil)
+	var registry schema.WebhookRegistry
+	c.decode(c.expect(responses.Generic_Success, "GET", "/api/my/webhooks", nil), &registry)
+	if len(registry.Webhooks) != 0 {
+		t.Fatalf("expected no webhooks after removal, got %+v", registry.Webhooks)
+	}
+}
+
+// Add wares directly to the user's warehouse at location, for setting up tests
+func (c *testClient) grantWares(username string, locationSymbol string, wares schema.Wareset) {
+	c.t.Helper()
+	wdb := dbs["warehouses"]
+	warehouse, found, err := schema.GetWarehouseFromDB(username + "|Warehouse-" + locationSymbol, wdb)
+	if err != nil || !found {
+		c.t.Fatalf("could not get %s warehouse at %s, found: %v, error: %v", username, locationSymbol, found, err)
+	}
+	categories := map[schema.ItemCategory]map[string]uint64{schema.GOOD: wares.Goods, schema.SEED: wares.Seeds, schema.PRODUCE: wares.Produce, schema.TOOL: wares.Tools}
+	for category, items := range categories {
+		for name, quantity := range items {
+			warehouse.AddItem(category, name, quantity)
+		}
+	}
+	if err := schema.SaveWarehouseToDB(wdb, &warehouse); err != nil {
+		c.t.Fatalf("could not save warehouse: %v", err)
+	}
+}
+
+// Buildings are paid for up front and finish after their construction time
+func TestConstructBuilding(t *testing.T) {
+	server, clock := newTestServer(t)
+	c := claimTestUser(t, server, "Farmer")
+	buildingsPath := "/api/my/farms/TS-PR-HF/buildings/"
+
+	c.expect(responses.Specified_Building_Not_Found, "POST", buildingsPath + "Windmill", nil)
+	c.expect(responses.Construction_Not_Allowed, "POST", buildingsPath + "Home", nil)
+	c.expect(responses.Construction_Not_Allowed, "POST", buildingsPath + "Altar", nil)
+
+	// Build
+	c.grantWares("Farmer", "TS-PR-HF", schema.Wareset{Goods: map[string]uint64{"Bundle of Materials": 5}})
+	coinsBefore := c.coins()
+	var built struct {
+		Farm schema.Farm `json:"farm"`
+		Warehouse schema.Warehouse `json:"warehouse"`
+	}
+	c.decode(c.expect(responses.Generic_Success, "POST", buildingsPath + "Altar", nil), &built)
+	altar := main_dictionary.Buildings["Altar"].Levels[0]
+	if built.Farm.Construction == nil || built.Farm.Construction.Building != schema.Building_Altar || built.Farm.Construction.Level != 1 {
+		t.Fatalf("expected altar level 1 under construction, got %+v", built.Farm.Construction)
+	}
+	if coinsAfter := c.coins(); coinsBefore - coinsAfter != altar.Currencies["Coins"] {
+		t.Fatalf("expected altar to cost %d coins, before: %d after: %d", altar.Currencies["Coins"], coinsBefore, coinsAfter)
+	}
+	if built.Warehouse.Goods["Bundle of Materials"] != 0 {
+		t.Fatalf("expected materials consumed, warehouse has %d", built.Warehouse.Goods["Bundle of Materials"])
+	}
+	c.expect(responses.Construction_Not_Allowed, "POST", buildingsPath + "Silo", nil)
+
+	// Complete
+	clock.AdvanceSeconds(int64(altar.ConstructionTime))
+	var farm schema.Farm
+	c.decode(c.expect(responses.Generic_Success, "GET", "/api/my/farms/TS-PR-HF", nil), &farm)
+	if farm.Buildings[schema.Building_Altar] != 1 || farm.Construction != nil {
+		t.Fatalf("expected altar level 1 built, got buildings %v construction %+v", farm.Buildings, farm.Construction)
+	}
+}
+
+// Add coins directly to the user's ledger, for setting up tests
+func (c *testClient) grantCoins(username string, coins uint64) {
+	c.t.Helper()
+	udb := dbs["users"]
+	userData, found, err := schema.GetUserByUsernameFromDB(username, udb)
+	if err != nil || !found {
+		c.t.Fatalf("could not get user %s, found: %v, error: %v", username, found, err)
+	}
+	userData.Ledger.Currencies["Coins"] += coins
+	if err := schema.SaveUserToDB(udb, &userData); err != nil {
+		c.t.Fatalf("could not save user: %v", err)
+	}
+}
+
+// Get the symbols of the markets visible to the user
+func (c *testClient) visibleMarkets() map[string]bool {
+	c.t.Helper()
+	var markets []schema.Market
+	c.decode(c.expect(responses.Generic_Success, "GET", "/api/my/markets", nil), &markets)
+	visible := make(map[string]bool)
+	for _, market := range markets {
+		visible[market.LocationSymbol] = true
+	}
+	return visible
+}
+
+// Livestock require a pasture, and survey offices reveal markets on the farm's island
+func TestBuildingEffects(t *testing.T) {
+	server, clock := newTestServer(t)
+	c := claimTestUser(t, server, "Farmer")
+	buildingsPath := "/api/my/farms/TS-PR-HF/buildings/"
+	c.grantCoins("Farmer", 500)
+	c.grantWares("Farmer", "TS-PR-HF", schema.Wareset{
+		Seeds: map[string]uint64{"Dust Fowl Egg": 2},
+		Goods: map[string]uint64{"Bundle of Materials": 20},
+	})
+
+	// Livestock
+	plantBody := map[string]interface{}{"name": "Dust Fowl Egg", "quantity": 2, "size": "Miniature"}
+	c.expect(responses.Bad_Request, "POST", "/api/my/plots/TS-PR-HF!Plot-0/plant", plantBody)
+	c.expect(responses.Generic_Success, "POST", buildingsPath + "Pasture", nil)
+	clock.AdvanceSeconds(int64(main_dictionary.Buildings["Pasture"].Levels[0].ConstructionTime))
+	var farm schema.Farm
+	c.decode(c.expect(responses.Generic_Success, "GET", "/api/my/farms/TS-PR-HF", nil), &farm)
+	if farm.Effects == nil || !farm.Effects.HasLivestock("Dust Fowl") {
+		t.Fatalf("expected pasture to allow dust fowl, got effects %+v", farm.Effects)
+	}
+	var planted schema.PlotPlantResponse
+	c.decode(c.expect(responses.Generic_Success, "POST", "/api/my/plots/TS-PR-HF!Plot-0/plant", plantBody), &planted)
+	if planted.Plot.PlantedPlant == nil || planted.Plot.PlantedPlant.PlantType != "Dust Fowl" {
+		t.Fatalf("expected dust fowl planted, got plot %+v", planted.Plot)
+	}
+
+	// Survey
+	if c.visibleMarkets()["TS-PR-YD"] {
+		t.Fatalf("expected TS-PR-YD hidden without an assistant or survey office")
+	}
+	c.expect(responses.Generic_Success, "POST", buildingsPath + "Survey Office", nil)
+	clock.AdvanceSeconds(int64(main_dictionary.Buildings["Survey Office"].Levels[0].ConstructionTime))
+	visible := c.visibleMarkets()
+	if !visible["TS-PR-YD"] || !visible["TS-PR-BG"] {
+		t.Fatalf("expected survey office to reveal markets on Pria, got %v", visible)
+	}
+	c.expect(responses.Generic_Success, "GET", "/api/my/markets/TS-PR-YD", nil)
+}
+
+// Get the user's warehouse at locationSymbol
+func (c *testClient) warehouse(locationSymbol string) schema.Warehouse {
+	c.t.Helper()
+	var warehouse schema.Warehouse
+	c.decode(c.expect(responses.Generic_Success, "GET", "/api/my/warehouses/" + locationSymbol, nil), &warehouse)
+	return warehouse
+}
+
+// Purchases are partially filled to the room left in the warehouse, and silos raise its capacity
+func TestWarehouseCapacity(t *testing.T) {
+	server, clock := newTestServer(t)
+	c := claimTestUser(t, server, "Farmer")
+	homestead := main_dictionary.Markets["TS-PR-HF"]
+	buyWater := map[string]interface{}{
+		"order_type": "MARKET",
+		"transaction_type": "BUY",
+		"item_category": "GOODS",
+		"item_name": "Water",
+		"quantity": 5,
+	}
+
+	// Fill the warehouse to 2 below capacity
+	warehouse := c.warehouse("TS-PR-HF")
+	if warehouse.Capacity != world.Locations["TS-PR-HF"].WarehouseCapacity {
+		t.Fatalf("expected homestead warehouse capacity %d, got %d", world.Locations["TS-PR-HF"].WarehouseCapacity, warehouse.Capacity)
+	}
+	c.grantWares("Farmer", "TS-PR-HF", schema.Wareset{Goods: map[string]uint64{"Bundle of Materials": warehouse.Capacity - warehouse.TotalSize() - 2}})
+
+	// Partial fill
+	coinsBefore := c.coins()
+	c.expect(responses.Generic_Success, "PATCH", "/api/my/markets/TS-PR-HF/order", buyWater)
+	warehouse = c.warehouse("TS-PR-HF")
+	if warehouse.Goods["Water"] != 2 || warehouse.TotalSize() != warehouse.Capacity {
+		t.Fatalf("expected 2 water bought to fill warehouse, got %d water and size %d of %d", warehouse.Goods["Water"], warehouse.TotalSize(), warehouse.Capacity)
+	}
+	if coinsAfter := c.coins(); coinsBefore - coinsAfter != 2 * homestead.Exports.Goods["Water"] {
+		t.Fatalf("expected to pay for 2 water, before: %d after: %d", coinsBefore, coinsAfter)
+	}
+	c.expect(responses.Warehouse_Capacity_Exceeded, "PATCH", "/api/my/markets/TS-PR-HF/order", buyWater)
+
+	// Silo
+	c.grantCoins("Farmer", 200)
+	c.expect(responses.Generic_Success, "POST", "/api/my/farms/TS-PR-HF/buildings/Silo", nil)
+	clock.AdvanceSeconds(int64(main_dictionary.Buildings["Silo"].Levels[0].ConstructionTime))
+	if capacity := c.warehouse("TS-PR-HF").Capacity; capacity != warehouse.Capacity + main_dictionary.Buildings["Silo"].Levels[0].Effects.WarehouseCapacity {
+		t.Fatalf("expected silo to raise capacity from %d, got %d", warehouse.Capacity, capacity)
+	}
+	c.expect(responses.Generic_Success, "PATCH", "/api/my/markets/TS-PR-HF/order", buyWater)
+}
+
+// Recipes consume materials up front and their products are collected once crafting completes
+func TestCrafting(t *testing.T) {
+	server, clock := newTestServer(t)
+	c := claimTestUser(t, server, "Farmer")
+	craftPath := "/api/my/farms/TS-PR-HF/craft"
+	c.grantWares("Farmer", "TS-PR-HF", schema.Wareset{
+		Goods: map[string]uint64{"Spectral Fiber": 8, "Water": 1, "Bundle of Materials": 5},
+		Produce: map[string]uint64{"Cabbage|Small": 1},
+	})
+
+	c.expect(responses.Specified_Recipe_Not_Found, "POST", craftPath, map[string]interface{}{"recipe": "Spectral Quilt", "quantity": 1})
+	c.expect(responses.Not_Enough_Items_In_Warehouse, "POST", craftPath, map[string]interface{}{"recipe": "spectral cloth", "quantity": 3})
+	c.expect(responses.Bad_Request, "POST", craftPath, map[string]interface{}{"recipe": "spectral cloth", "quantity": uint64(1) << 62})
+	c.expect(responses.Bad_Request, "POST", craftPath, map[string]interface{}{"recipe": "spectral cloth", "quantity": schema.MaxCraftBatches + 1})
+
+	// Craft
+	var crafted struct {
+		Farm schema.Farm `json:"farm"`
+		Warehouse schema.Warehouse `json:"warehouse"`
+	}
+	c.decode(c.expect(responses.Generic_Success, "POST", craftPath, map[string]interface{}{"recipe": "spectral cloth", "quantity": 2}), &crafted)
+	if crafted.Farm.Crafting == nil || crafted.Farm.Crafting.Products.Goods["Spectral Cloth"] != 2 {
+		t.Fatalf("expected 2 spectral cloth crafting, got %+v", crafted.Farm.Crafting)
+	}
+	if crafted.Warehouse.Goods["Spectral Fiber"] != 0 {
+		t.Fatalf("expected fiber consumed, warehouse has %d", crafted.Warehouse.Goods["Spectral Fiber"])
+	}
+	c.expect(responses.Crafting_Not_Allowed, "POST", craftPath, map[string]interface{}{"recipe": "Spectral Cloth", "quantity": 1})
+	c.expect(responses.Crafting_Not_Allowed, "DELETE", craftPath, nil)
+
+	// Collect
+	clock.AdvanceSeconds(int64(2 * main_dictionary.Recipes["Spectral Cloth"].CraftingTime))
+	var collected struct {
+		Farm schema.Farm `json:"farm"`
+		Warehouse schema.Warehouse `json:"warehouse"`
+	}
+	c.decode(c.expect(responses.Generic_Success, "DELETE", craftPath, nil), &collected)
+	if collected.Farm.Crafting != nil || collected.Warehouse.Goods["Spectral Cloth"] != 2 {
+		t.Fatalf("expected 2 spectral cloth collected, got crafting %+v warehouse goods %v", collected.Farm.Crafting, collected.Warehouse.Goods)
+	}
+
+	// Recipe categories require a building
+	pickle := map[string]interface{}{"recipe": "Pickled Cabbage", "quantity": 1}
+	c.expect(responses.Crafting_Not_Allowed, "POST", craftPath, pickle)
+	c.grantCoins("Farmer", 150)
+	c.expect(responses.Generic_Success, "POST", "/api/my/farms/TS-PR-HF/buildings/Kitchen", nil)
+	clock.AdvanceSeconds(int64(main_dictionary.Buildings["Kitchen"].Levels[0].ConstructionTime))
+	c.expect(responses.Generic_Success, "POST", craftPath, pickle)
+}
+
+// Rites apply their typed effects to the farm, its plots and its warehouse
+func TestRiteEffects(t *testing.T) {
+	server, clock := newTestServer(t)
+	c := claimTestUser(t, server, "Farmer")
+	ritualPath := "/api/my/farms/TS-PR-HF/ritual/"
+	plotPath := "/api/my/plots/TS-PR-HF!Plot-0"
+	c.grantWares("Farmer", "TS-PR-HF", schema.Wareset{Goods: map[string]uint64{"Water": 20, "Fertilizer": 5}})
+	type ritualResponse struct {
+		Farm schema.Farm `json:"farm"`
+		Warehouse schema.Warehouse `json:"warehouse"`
+	}
+	var ritual ritualResponse
+
+	// Hasten growth
+	c.expect(responses.Generic_Success, "POST", plotPath + "/plant", map[string]interface{}{"name": "Spectral Grass Seeds", "quantity": 16, "size": "Tiny"})
+	var grown schema.PlotActionResponse
+	c.decode(c.expect(responses.Generic_Success, "PATCH", plotPath + "/interact", map[string]string{"action": "Wait"}), &grown)
+	c.decode(c.expect(responses.Generic_Success, "POST", ritualPath + "KVKNNG", nil), &ritual)
+	hastened := ritual.Farm.Plots["Farmer|Farm-TS-PR-HF|Plot-0"]
+	expected := grown.Plot.GrowthCompleteTimestamp - main_dictionary.Rites["KVKNNG"].Effects[0].Seconds
+	if expected < clock.Now().Unix() {
+		expected = clock.Now().Unix()
+	}
+	if hastened.GrowthCompleteTimestamp != expected {
+		t.Fatalf("expected growth hastened to %d, got %d", expected, hastened.GrowthCompleteTimestamp)
+	}
+
+	// Add yield
+	clock.AdvanceSeconds(int64(main_dictionary.Rites["KVKNNG"].RejectionTime))
+	ritual = ritualResponse{}
+	c.decode(c.expect(responses.Generic_Success, "POST", ritualPath + "SGNFRTFL", nil), &ritual)
+	if yield := ritual.Farm.Plots["Farmer|Farm-TS-PR-HF|Plot-0"].PlantedPlant.Yield; yield != hastened.PlantedPlant.Yield + main_dictionary.Rites["SGNFRTFL"].Effects[0].Yield {
+		t.Fatalf("expected yield raised from %v, got %v", hastened.PlantedPlant.Yield, yield)
+	}
+
+	// Convert goods
+	clock.AdvanceSeconds(int64(main_dictionary.Rites["SGNFRTFL"].RejectionTime))
+	ritual = ritualResponse{}
+	c.decode(c.expect(responses.Generic_Success, "POST", ritualPath + "VTNNFSN", nil), &ritual)
+	if ritual.Warehouse.Goods["Water"] != 0 || ritual.Warehouse.Goods["Enchanted Water"] != 10 {
+		t.Fatalf("expected water converted to enchanted water, got goods %v", ritual.Warehouse.Goods)
+	}
+}
+
+// Assistants are hired at markets for coins, or summoned in the summoning circle, taking the next id
+func TestAcquireAssistants(t *testing.T) {
+	server, _ := newTestServer(t)
+	c := claimTestUser(t, server, "Farmer")
+	type hireResponse struct {
+		Assistant schema.Assistant `json:"assistant"`
+		Ledger schema.Ledger `json:"ledger"`
+	}
+
+	// Hire
+	c.expect(responses.Hire_Not_Allowed, "POST", "/api/my/markets/TS-PR-HF/hire/familiar", nil)
+	c.expect(responses.Hire_Not_Allowed, "POST", "/api/my/markets/TS-PR-HF/hire/golem", nil)
+	c.expect(responses.No_Assitant_At_Location, "POST", "/api/my/markets/TS-PR-BG/hire/golem", nil)
+	c.grantCoins("Farmer", 500)
+	var hired hireResponse
+	c.decode(c.expect(responses.Generic_Success, "POST", "/api/my/markets/TS-PR-HF/hire/familiar", nil), &hired)
+	if hired.Assistant.ID != 2 || hired.Assistant.Archetype != schema.Familiar || hired.Assistant.Location != "TS-PR-HF" {
+		t.Fatalf("expected familiar 2 hired at TS-PR-HF, got %+v", hired.Assistant)
+	}
+	if fee := main_dictionary.Markets["TS-PR-HF"].Hires["Familiar"]; hired.Ledger.Currencies["Coins"] != 600 - fee {
+		t.Fatalf("expected hiring fee %d charged, got ledger %v", fee, hired.Ledger.Currencies)
+	}
+
+	// Summon
+	rite := main_dictionary.Rites["LRSKPNNG"]
+	c.expect(responses.Bad_Request, "POST", "/api/my/farms/TS-PR-HF/ritual/LRSKPNNG", nil)
+	c.grantCoins("Farmer", rite.Currencies["Coins"])
+	c.grantWares("Farmer", "TS-PR-HF", rite.Materials)
+	c.expect(responses.Generic_Success, "POST", "/api/my/farms/TS-PR-HF/ritual/LRSKPNNG", nil)
+	var summoned schema.Assistant
+	c.decode(c.expect(responses.Generic_Success, "GET", "/api/my/assistants/3", nil), &summoned)
+	if summoned.Archetype != schema.Golem || summoned.Location != "TS-PR-HF" {
+		t.Fatalf("expected golem 3 summoned at TS-PR-HF, got %+v", summoned)
+	}
+}
+
+// Improvements cost coins and materials and raise the speed and carrying capacity of chartered caravans
+func TestImproveAssistant(t *testing.T) {
+	server, clock := newTestServer(t)
+	c := claimTestUser(t, server, "Farmer")
+	improvePath := "/api/my/assistants/1/improve"
+	water := schema.Wareset{Goods: map[string]uint64{"Water": 20}}
+	charter := schema.CaravanCharter{Origin: "TS-PR-HF", Destination: "TS-PR-BG", Assistants: []int64{1}, Wares: water}
+	c.grantWares("Farmer", "TS-PR-HF", water)
+
+	// Familiar cannot carry the water until improved
+	c.expect(responses.Bad_Request, "PATCH", "/api/my/caravans", charter)
+	c.expect(responses.Specified_Improvement_Not_Found, "POST", improvePath, schema.ImproveBody{Improvement: "Wings"})
+	c.expect(responses.Improvement_Not_Allowed, "POST", improvePath, schema.ImproveBody{Improvement: "Iron Plating"})
+	c.expect(responses.Improvement_Not_Allowed, "POST", improvePath, schema.ImproveBody{Improvement: "Saddlebags"})
+	c.grantWares("Farmer", "TS-PR-HF", main_dictionary.Improvements["Saddlebags"].Levels[0].Materials)
+	c.grantWares("Farmer", "TS-PR-HF", main_dictionary.Improvements["Swift Charm"].Levels[0].Materials)
+	c.grantCoins("Farmer", main_dictionary.Improvements["Swift Charm"].Levels[0].Currencies["Coins"] + 50)
+	var improved struct {
+		Assistant schema.Assistant `json:"assistant"`
+		Speed int `json:"speed"`
+		CarryCap int `json:"carrying_capacity"`
+	}
+	c.decode(c.expect(responses.Generic_Success, "POST", improvePath, schema.ImproveBody{Improvement: "Saddlebags"}), &improved)
+	if improved.Assistant.Improvements["Saddlebags"] != 1 || improved.CarryCap != improved.Assistant.CarryCap + main_dictionary.Improvements["Saddlebags"].Levels[0].CarryCap {
+		t.Fatalf("expected saddlebags level 1 raising carrying capacity, got %+v", improved)
+	}
+	c.decode(c.expect(responses.Generic_Success, "POST", improvePath, schema.ImproveBody{Improvement: "Swift Charm"}), &improved)
+	if improved.Speed != improved.Assistant.Speed + 1 {
+		t.Fatalf("expected swift charm to raise speed, got %+v", improved)
+	}
+
+	// Caravan uses improved speed and carrying capacity
+	var caravan schema.Caravan
+	c.decode(c.expect(responses.Generic_Success, "PATCH", "/api/my/caravans", charter), &caravan)
+	_, travelTime, _ := schema.CalculateTravelTime(world, "TS-PR-HF", "TS-PR-BG", improved.Speed)
+	if caravan.ArrivalTime != clock.Now().Unix() + int64(travelTime) {
+		t.Fatalf("expected caravan to arrive in %d seconds, got arrival %d at %d", travelTime, caravan.ArrivalTime, clock.Now().Unix())
+	}
+	c.expect(responses.Improvement_Not_Allowed, "POST", improvePath, schema.ImproveBody{Improvement: "Saddlebags"})
+}
+
+// Caravans cross any number of ports to reach other islands, paying the summed fares
+func TestMultiHopRoute(t *testing.T) {
+	server, clock := newTestServer(t)
+	c := claimTestUser(t, server, "Farmer")
+	var preview map[string]schema.Route
+	c.decode(c.expect(responses.Generic_Success, "GET", "/api/routes/TS-PR-HF/TS-SK-FL?speed=5", nil), &preview)
+	fastest := preview["fastest"]
+	if len(fastest.Legs) != 5 || fastest.Fare != 600 || fastest.SlowestSpeed != 5 || !fastest.IsPortTravel {
+		t.Fatalf("expected fastest route over 5 legs through Veldis for 600 coins, got %+v", fastest)
+	}
+	if fastest.ArrivalTime != clock.Now().Unix() + int64(fastest.TravelTime) {
+		t.Fatalf("expected fastest route quoted departing now, got %+v", fastest)
+	}
+	if cheapest := preview["cheapest"]; cheapest.Fare > fastest.Fare {
+		t.Fatalf("expected cheapest route to cost no more than fastest, got %d and %d", cheapest.Fare, fastest.Fare)
+	}
+	c.expect(responses.Bad_Request, "GET", "/api/routes/TS-PR-HF/TS-ZZ-FL", nil)
+
+	// Charter the route
+	charter := schema.CaravanCharter{Origin: "TS-PR-HF", Destination: "TS-SK-FL", Assistants: []int64{0}}
+	c.expect(responses.Bad_Request, "PATCH", "/api/my/caravans", charter)
+	c.grantCoins("Farmer", fastest.Fare)
+	var caravan schema.Caravan
+	c.decode(c.expect(responses.Generic_Success, "PATCH", "/api/my/caravans", charter), &caravan)
+	if caravan.Route.Duration != fastest.TravelTime || caravan.ArrivalTime != clock.Now().Unix() + int64(fastest.TravelTime) {
+		t.Fatalf("expected caravan to follow previewed route taking %d seconds, got %+v", fastest.TravelTime, caravan)
+	}
+	if coins := c.coins(); coins != 100 {
+		t.Fatalf("expected fares of %d charged, got coins %d", fastest.Fare, coins)
+	}
+}
+
+// Route quotes match the caravan chartered for the same team without moving the assistants
+func TestRouteQuote(t *testing.T) {
+	server, clock := newTestServer(t)
+	c := claimTestUser(t, server, "Farmer")
+	var route schema.Route
+	c.decode(c.expect(responses.Generic_Success, "GET", "/api/my/routes?origin=TS-PR-HF&destination=TS-PR-BG&assistants=0,1", nil), &route)
+	imp, familiar := schema.NewAssistant("Farmer", 0, schema.Imp, "TS-PR-HF"), schema.NewAssistant("Farmer", 1, schema.Familiar, "TS-PR-HF")
+	if route.SlowestSpeed != imp.Speed || route.CarryCap != schema.TeamCarryCap(imp.CarryCap + familiar.CarryCap, 2) || route.IsPortTravel {
+		t.Fatalf("expected quote for imp and familiar overland, got %+v", route)
+	}
+	c.expect(responses.Bad_Request, "GET", "/api/my/routes?origin=TS-PR-HF&destination=TS-PR-BG&assistants=7", nil)
+	c.expect(responses.Bad_Request, "GET", "/api/my/routes?origin=TS-PR-HF&destination=TS-PR-BG", nil)
+
+	var caravan schema.Caravan
+	c.decode(c.expect(responses.Generic_Success, "PATCH", "/api/my/caravans", schema.CaravanCharter{Origin: "TS-PR-HF", Destination: "TS-PR-BG", Assistants: []int64{0, 1}}), &caravan)
+	if caravan.ArrivalTime != route.ArrivalTime || caravan.Route.Fare != route.Fare || caravan.ArrivalTime != clock.Now().Unix() + int64(route.TravelTime) {
+		t.Fatalf("expected caravan to match quote %+v, got %+v", route, caravan)
+	}
+}
+
+// Recalled caravans retrace the time spent travelling, refunding fares of crossings not yet begun
+func TestRecallCaravan(t *testing.T) {
+	server, clock := newTestServer(t)
+	c := claimTestUser(t, server, "Farmer")
+	c.grantCoins("Farmer", 600)
+	var caravan schema.Caravan
+	c.decode(c.expect(responses.Generic_Success, "PATCH", "/api/my/caravans", schema.CaravanCharter{Origin: "TS-PR-HF", Destination: "TS-SK-FL", Assistants: []int64{0}}), &caravan)
+	firstCrossing := caravan.Route.Legs[1]
+	if !firstCrossing.IsPortTravel {
+		t.Fatalf("expected second leg to be a port crossing, got %+v", caravan.Route.Legs)
+	}
+
+	// Recall midway through the first crossing
+	elapsed := int64(caravan.Route.Legs[0].Duration + firstCrossing.Duration / 2)
+	clock.AdvanceSeconds(elapsed)
+	recallPath := fmt.Sprintf("/api/my/caravans/%d/recall", caravan.ID)
+	var recalled struct {
+		Caravan schema.Caravan `json:"caravan"`
+		RefundedFare uint64 `json:"refunded_fare"`
+	}
+	c.decode(c.expect(responses.Generic_Success, "POST", recallPath, nil), &recalled)
+	if recalled.RefundedFare != caravan.Route.Fare - firstCrossing.Fare || c.coins() != 100 + recalled.RefundedFare {
+		t.Fatalf("expected fares after the first crossing refunded, got refund %d and coins %d", recalled.RefundedFare, c.coins())
+	}
+	if recalled.Caravan.Destination != "TS-PR-HF" || recalled.Caravan.ArrivalTime != clock.Now().Unix() + elapsed {
+		t.Fatalf("expected caravan returning to TS-PR-HF in %d seconds, got %+v", elapsed, recalled.Caravan)
+	}
+	c.expect(responses.Recall_Not_Allowed, "POST", recallPath, nil)
+
+	// Unpack at origin
+	clock.AdvanceSeconds(elapsed)
+	c.expect(responses.Generic_Success, "DELETE", fmt.Sprintf("/api/my/caravans/%d", caravan.ID), nil)
+	var imp schema.Assistant
+	c.decode(c.expect(responses.Generic_Success, "GET", "/api/my/assistants/0", nil), &imp)
+	if imp.Location != "TS-PR-HF" {
+		t.Fatalf("expected recalled assistant back at TS-PR-HF, got %s", imp.Location)
+	}
+}
+
+// Arrived caravans chartered with auto_unpack are unpacked once, even with several schedulers scanning the same DBs
+func TestAutoUnpackCaravan(t *testing.T) {
+	server, clock := newTestServer(t)
+	c := claimTestUser(t, server, "Farmer")
+	c.grantWares("Farmer", "TS-PR-HF", schema.Wareset{Goods: map[string]uint64{"Spectral Fiber": 10}})
+	var auto, manual schema.Caravan
+	c.decode(c.expect(responses.Generic_Success, "PATCH", "/api/my/caravans", schema.CaravanCharter{
+		Origin: "TS-PR-HF",
+		Destination: "TS-PR-BG",
+		Assistants: []int64{0},
+		Wares: schema.Wareset{Goods: map[string]uint64{"Spectral Fiber": 10}},
+		AutoUnpack: true,
+	}), &auto)
+	clock.AdvanceSeconds(1)
+	c.decode(c.expect(responses.Generic_Success, "PATCH", "/api/my/caravans", schema.CaravanCharter{Origin: "TS-PR-HF", Destination: "TS-PR-BG", Assistants: []int64{1}}), &manual)
+
+	// Not arrived yet
+	scheduler := func() *handlers.CaravanScheduler {
+		return &handlers.CaravanScheduler{Dbs: &dbs, MainDictionary: &main_dictionary, World: &world, Clock: clock}
+	}
+	if unpacked := scheduler().UnpackArrived(); unpacked != 0 {
+		t.Fatalf("expected no caravans unpacked before arrival, got %d", unpacked)
+	}
+	// Only the auto_unpack caravan is indexed, and scans read it only once it arrives
+	arrived := func(now int64) []string {
+		uuids, err := schema.GetArrivedCaravanUUIDsFromDB(now, dbs["caravans"])
+		if err != nil {
+			t.Fatalf("could not get arrived caravans: %v", err)
+		}
+		return uuids
+	}
+	if uuids := arrived(auto.ArrivalTime - 1); len(uuids) != 0 {
+		t.Fatalf("expected no caravans arrived before %d, got %v", auto.ArrivalTime, uuids)
+	}
+	if uuids := arrived(math.MaxInt64); !reflect.DeepEqual(uuids, []string{auto.UUID}) {
+		t.Fatalf("expected only the auto_unpack caravan indexed, got %v", uuids)
+	}
+
+	// Two schedulers race once both caravans have arrived
+	clock.AdvanceSeconds(auto.ArrivalTime - clock.Now().Unix())
+	results := make(chan int)
+	for i := 0; i < 2; i++ {
+		go func() { results <- scheduler().UnpackArrived() }()
+	}
+	if unpacked := <-results + <-results; unpacked != 1 {
+		t.Fatalf("expected the auto_unpack caravan unpacked exactly once, got %d", unpacked)
+	}
+	if fiber := c.warehouse("TS-PR-BG").Goods["Spectral Fiber"]; fiber != 10 {
+		t.Fatalf("expected 10 spectral fiber unpacked at TS-PR-BG, got %d", fiber)
+	}
+	if uuids := arrived(clock.Now().Unix()); len(uuids) != 0 {
+		t.Fatalf("expected unpacked caravan removed from the index, got %v", uuids)
+	}
+	var imp schema.Assistant
+	c.decode(c.expect(responses.Generic_Success, "GET", "/api/my/assistants/0", nil), &imp)
+	if imp.Location != "TS-PR-BG" {
+		t.Fatalf("expected auto unpacked assistant at TS-PR-BG, got %s", imp.Location)
+	}
+	c.expect(responses.Bad_Request, "DELETE", fmt.Sprintf("/api/my/caravans/%d", auto.ID), nil)
+	c.expect(responses.Generic_Success, "DELETE", fmt.Sprintf("/api/my/caravans/%d", manual.ID), nil)
+}
+
+// Queued plot actions are taken as soon as the plot is ready, a failed action pauses the queue until it is replaced
+func TestPlotQueue(t *testing.T) {
+	server, clock := newTestServer(t)
+	c := claimTestUser(t, server, "Farmer")
+	plotPath := "/api/my/plots/TS-PR-HF!Plot-0"
+	scheduler := &handlers.PlotScheduler{Dbs: &dbs, MainDictionary: &main_dictionary, World: &world, Clock: clock, HarvestSeed: harvest_seed}
+	c.expect(responses.Generic_Success, "POST", plotPath + "/plant", map[string]interface{}{"name": "Spectral Grass Seeds", "quantity": 16, "size": "Tiny"})
+	c.expect(responses.Bad_Request, "PUT", plotPath + "/queue", schema.PlotQueueBody{})
+
+	// Reap is out of order, so the queue pauses on it once the plot is ready
+	c.expect(responses.Generic_Success, "PUT", plotPath + "/queue", schema.PlotQueueBody{Actions: []schema.PlotInteractBody{{Action: "Wait"}, {Action: "Reap"}}})
+	if taken := scheduler.TakeReadyActions(); taken != 1 {
+		t.Fatalf("expected only the wait action taken before growth, got %d", taken)
+	}
+	getPlot := func() schema.Plot {
+		var plot schema.Plot
+		c.decode(c.expect(responses.Generic_Success, "GET", plotPath, nil), &plot)
+		return plot
+	}
+	plot := getPlot()
+	clock.AdvanceSeconds(plot.GrowthCompleteTimestamp - clock.Now().Unix())
+	if taken := scheduler.TakeReadyActions(); taken != 0 {
+		t.Fatalf("expected the out of order reap to fail, got %d taken", taken)
+	}
+	plot = getPlot()
+	if plot.Queue == nil || !plot.Queue.Paused || plot.Queue.Failure == nil || plot.Queue.Failure.Code != responses.Invalid_Plot_Action || plot.Queue.Failure.Action.Action != "Reap" {
+		t.Fatalf("expected queue paused on the failed reap, got %+v", plot.Queue)
+	}
+	if taken := scheduler.TakeReadyActions(); taken != 0 {
+		t.Fatalf("expected paused queue left alone, got %d taken", taken)
+	}
+
+	// Replacing the queue resumes it through to harvest
+	c.expect(responses.Generic_Success, "PUT", plotPath + "/queue", schema.PlotQueueBody{Actions: []schema.PlotInteractBody{{Action: "Trim"}, {Action: "Reap"}}})
+	if taken := scheduler.TakeReadyActions(); taken != 1 {
+		t.Fatalf("expected the trim action taken, got %d", taken)
+	}
+	plot = getPlot()
+	clock.AdvanceSeconds(plot.GrowthCompleteTimestamp - clock.Now().Unix())
+	if taken := scheduler.TakeReadyActions(); taken != 1 {
+		t.Fatalf("expected the reap action taken, got %d", taken)
+	}
+	plot = getPlot()
+	if plot.PlantedPlant != nil || plot.Queue != nil {
+		t.Fatalf("expected plot harvested with its queue finished, got %+v", plot)
+	}
+	if fiber := c.warehouse("TS-PR-HF").Goods["Spectral Fiber"]; fiber == 0 {
+		t.Fatalf("expected spectral fiber from the queued harvest")
+	}
+	c.expect(responses.Object_Not_Found, "DELETE", plotPath + "/queue", nil)
+
+	// Clearing a plot drops its queue, leaving nothing for the scheduler to fail on
+	c.grantWares("Farmer", "TS-PR-HF", schema.Wareset{Seeds: map[string]uint64{"Spectral Grass Seeds": 1}})
+	c.expect(responses.Generic_Success, "POST", plotPath + "/plant", map[string]interface{}{"name": "Spectral Grass Seeds", "quantity": 1, "size": "Tiny"})
+	c.expect(responses.Generic_Success, "PUT", plotPath + "/queue", schema.PlotQueueBody{Actions: []schema.PlotInteractBody{{Action: "Wait"}, {Action: "Trim"}}})
+	c.expect(responses.Generic_Success, "PUT", plotPath + "/clear", nil)
+	if plot = getPlot(); plot.Queue != nil {
+		t.Fatalf("expected queue dropped with the cleared plant, got %+v", plot.Queue)
+	}
+	if taken := scheduler.TakeReadyActions(); taken != 0 {
+		t.Fatalf("expected no queued actions on the cleared plot, got %d taken", taken)
+	}
+}
+
+// Batches report a result per plot, and are rejected whole when the warehouse cannot supply every plot
+func TestBatchPlotOperations(t *testing.T) {
+	server, clock := newTestServer(t)
+	c := claimTestUser(t, server, "Farmer")
+	batchPath := "/api/my/farms/TS-PR-HF/plots"
+	seeds := c.warehouse("TS-PR-HF").Seeds["Spectral Grass Seeds"]
+	plant := func(plotID string, quantity uint) schema.PlotBatchPlantEntry {
+		return schema.PlotBatchPlantEntry{PlotID: plotID, PlotPlantBody: schema.PlotPlantBody{SeedName: "Spectral Grass Seeds", SeedQuantity: quantity, SeedSize: schema.Tiny}}
+	}
+
+	// Seeds for the whole batch are validated up front
+	c.expect(responses.Bad_Request, "POST", batchPath + "/plant", schema.PlotBatchPlantBody{Plots: []schema.PlotBatchPlantEntry{plant("Plot-0", 1), plant("Plot-0", 1)}})
+	c.expect(responses.Not_Enough_Items_In_Warehouse, "POST", batchPath + "/plant", schema.PlotBatchPlantBody{Plots: []schema.PlotBatchPlantEntry{plant("Plot-0", uint(seeds)), plant("Plot-1", 1)}})
+	if left := c.warehouse("TS-PR-HF").Seeds["Spectral Grass Seeds"]; left != seeds {
+		t.Fatalf("expected rejected batch to leave %d seeds, got %d", seeds, left)
+	}
+
+	// Each plot gets its own result
+	var planted schema.PlotBatchResponse
+	c.decode(c.expect(responses.Generic_Success, "POST", batchPath + "/plant", schema.PlotBatchPlantBody{Plots: []schema.PlotBatchPlantEntry{plant("Plot-0", 2), plant("Plot-1", 2), plant("Plot-99", 1)}}), &planted)
+	codes := func(results []schema.PlotBatchResult) []responses.ResponseCode {
+		res := make([]responses.ResponseCode, len(results))
+		for i, result := range results {
+			res[i] = result.Code
+		}
+		return res
+	}
+	if got := codes(planted.Results); !reflect.DeepEqual(got, []responses.ResponseCode{responses.Generic_Success, responses.Generic_Success, responses.Object_Not_Found}) {
+		t.Fatalf("unexpected plant results %v", planted.Results)
+	}
+	if planted.Warehouse.Seeds["Spectral Grass Seeds"] != seeds - 4 {
+		t.Fatalf("expected 4 seeds planted, warehouse has %d of %d", planted.Warehouse.Seeds["Spectral Grass Seeds"], seeds)
+	}
+
+	// Plot-1 fails as it is not planted, the rest still apply
+	var interacted schema.PlotBatchResponse
+	c.decode(c.expect(responses.Generic_Success, "PATCH", batchPath + "/interact", schema.PlotBatchInteractBody{Plots: []schema.PlotBatchInteractEntry{
+		{PlotID: "Plot-0", PlotInteractBody: schema.PlotInteractBody{Action: "Wait"}},
+		{PlotID: "Plot-2", PlotInteractBody: schema.PlotInteractBody{Action: "Wait"}},
+		{PlotID: "Plot-1", PlotInteractBody: schema.PlotInteractBody{Action: "Wait"}},
+	}}), &interacted)
+	if got := codes(interacted.Results); !reflect.DeepEqual(got, []responses.ResponseCode{responses.Generic_Success, responses.Plot_Not_Planted, responses.Generic_Success}) {
+		t.Fatalf("unexpected interact results %v", interacted.Results)
+	}
+	if interacted.Results[0].Plot.GrowthCompleteTimestamp <= clock.Now().Unix() {
+		t.Fatalf("expected Plot-0 growing after wait, got %+v", interacted.Results[0].Plot)
+	}
+
+	// Harvests from a batch are tracked once the batch commits
+	interactAll := func(action string) {
+		c.decode(c.expect(responses.Generic_Success, "PATCH", batchPath + "/interact", schema.PlotBatchInteractBody{Plots: []schema.PlotBatchInteractEntry{
+			{PlotID: "Plot-0", PlotInteractBody: schema.PlotInteractBody{Action: action}},
+			{PlotID: "Plot-1", PlotInteractBody: schema.PlotInteractBody{Action: action}},
+		}}), &interacted)
+		if got := codes(interacted.Results); !reflect.DeepEqual(got, []responses.ResponseCode{responses.Generic_Success, responses.Generic_Success}) {
+			t.Fatalf("unexpected %s results %v", action, interacted.Results)
+		}
+	}
+	clock.AdvanceSeconds(interacted.Results[0].Plot.GrowthCompleteTimestamp - clock.Now().Unix())
+	interactAll("Trim")
+	clock.AdvanceSeconds(interacted.Results[0].Plot.GrowthCompleteTimestamp - clock.Now().Unix())
+	harvestsBefore := metrics.TrackingHarvests.HarvestData["Spectral Grass"]
+	interactAll("Reap")
+	if harvests := metrics.TrackingHarvests.HarvestData["Spectral Grass"]; harvests != harvestsBefore + 2 {
+		t.Fatalf("expected 2 spectral grass harvests tracked from the batch, got %d", harvests - harvestsBefore)
+	}
+
+	c.expect(responses.Generic_Success, "POST", batchPath + "/plant", schema.PlotBatchPlantBody{Plots: []schema.PlotBatchPlantEntry{plant("Plot-0", 2)}})
+	var cleared schema.PlotBatchResponse
+	c.decode(c.expect(responses.Generic_Success, "PUT", batchPath + "/clear", schema.PlotBatchClearBody{PlotIDs: []string{"Plot-0", "Plot-1", "Plot-2"}}), &cleared)
+	if got := codes(cleared.Results); !reflect.DeepEqual(got, []responses.ResponseCode{responses.Generic_Success, responses.Plot_Already_Empty, responses.Plot_Already_Empty}) {
+		t.Fatalf("unexpected clear results %v", cleared.Results)
+	}
+}
+
+// Requests are limited per user at their tier's limit, or per IP without a valid token, reporting the remaining budget in headers
+func TestRateLimit(t *testing.T) {
+	server, _ := newTestServer(t)
+	farmer, other, dev := claimTestUser(t, server, "Farmer"), claimTestUser(t, server, "Other"), claimTestUser(t, server, "Developer")
+	devData, _, _ := schema.GetUserByUsernameFromDB("Developer", dbs["users"])
+	devData.Achievements = append(devData.Achievements, schema.Achievement_Contributor)
+	if err := schema.SaveUserToDB(dbs["users"], &devData); err != nil {
+		t.Fatalf("could not save user: %v", err)
+	}
+	limiter := ratelimit.Limiter{Users: dbs["users"], Counters: dbs["ratelimits"], Tiers: ratelimit.Tiers{
+		Public: ratelimit.Tier{Name: "Public", Limit: 2, Window: time.Minute},
+		Standard: ratelimit.Tier{Name: "Standard", Limit: 3, Window: time.Minute},
+		Dev: ratelimit.Tier{Name: "Dev", Limit: 5, Window: time.Minute},
+	}}
+	limited := httptest.NewServer(limiter.Handler(build_router(make([]string, 0))))
+	t.Cleanup(limited.Close)
+
+	// Send requests as token until one is limited, returning the number allowed and the headers of the last allowed
+	exhaust := func(token string, path string) (int, http.Header) {
+		var header http.Header
+		for allowed := 0; allowed < 10; allowed++ {
+			req, _ := http.NewRequest("GET", limited.URL + path, nil)
+			if token != "" {
+				req.Header.Set("Authorization", "Bearer " + token)
+			}
+			res, err := limited.Client().Do(req)
+			if err != nil {
+				t.Fatalf("request %s failed: %v", path, err)
+			}
+			res.Body.Close()
+			if res.StatusCode == http.StatusTooManyRequests {
+				if res.Header.Get(ratelimit.RemainingHeader) != "0" || res.Header.Get("Retry-After") == "" {
+					t.Fatalf("expected limited response to report no remaining budget, got headers %v", res.Header)
+				}
+				return allowed, header
+			}
+			header = res.Header
+		}
+		t.Fatalf("expected %s to be limited within 10 requests", path)
+		return 0, nil
+	}
+	allowed, header := exhaust(farmer.token, "/api/my/user")
+	if allowed != 3 || header.Get(ratelimit.LimitHeader) != "3" || header.Get(ratelimit.RemainingHeader) != "0" || header.Get(ratelimit.ResetHeader) == "" {
+		t.Fatalf("expected 3 standard requests allowed, got %d with headers %v", allowed, header)
+	}
+	limitedFarmer := &testClient{t: t, server: limited, token: farmer.token}
+	limitedFarmer.expect(responses.Rate_Limit_Exceeded, "GET", "/api/about", nil)
+
+	// Each user has their own budget, whichever IP they share
+	if allowed, _ := exhaust(other.token, "/api/my/user"); allowed != 3 {
+		t.Fatalf("expected other user unaffected, got %d allowed", allowed)
+	}
+	if allowed, _ := exhaust(dev.token, "/api/my/user"); allowed != 5 {
+		t.Fatalf("expected 5 dev requests allowed, got %d", allowed)
+	}
+	// Missing and invalid tokens share the public budget for the IP
+	if allowed, _ := exhaust("", "/api/about"); allowed != 2 {
+		t.Fatalf("expected 2 public requests allowed, got %d", allowed)
+	}
+	if allowed, _ := exhaust("invalid", "/api/about"); allowed != 0 {
+		t.Fatalf("expected invalid token limited with public requests, got %d allowed", allowed)
+	}
+
+	// Behind a trusted proxy each client IP it reports has its own public budget, taken from the address the proxy appended
+	limiter.ProxyHeader = "X-Forwarded-For"
+	proxied := func(forwardedFor string) int {
+		for allowed := 0; allowed < 10; allowed++ {
+			req, _ := http.NewRequest("GET", limited.URL + "/api/about", nil)
+			req.Header.Set("X-Forwarded-For", forwardedFor)
+			res, err := limited.Client().Do(req)
+			if err != nil {
+				t.Fatalf("proxied request failed: %v", err)
+			}
+			res.Body.Close()
+			if res.StatusCode == http.StatusTooManyRequests {
+				return allowed
+			}
+		}
+		t.Fatalf("expected proxied requests from %s to be limited within 10 requests", forwardedFor)
+		return 0
+	}
+	if allowed := proxied("203.0.113.1"); allowed != 2 {
+		t.Fatalf("expected 2 public requests allowed for proxied client, got %d", allowed)
+	}
+	if allowed := proxied("198.51.100.7, 203.0.113.2"); allowed != 2 {
+		t.Fatalf("expected another proxied client to have its own budget, got %d allowed", allowed)
+	}
+	if allowed := proxied("203.0.113.1, 203.0.113.2"); allowed != 0 {
+		t.Fatalf("expected a spoofed first address to be ignored, got %d allowed", allowed)
+	}
+
+	// Tiers are cached, so the user is not read again for each request and a change in achievements applies once the cache expires
+	devData.Achievements = nil
+	if err := schema.SaveUserToDB(dbs["users"], &devData); err != nil {
+		t.Fatalf("could not save user: %v", err)
+	}
+	res, err := limited.Client().Do(func() *http.Request {
+		req, _ := http.NewRequest("GET", limited.URL + "/api/my/user", nil)
+		req.Header.Set("Authorization", "Bearer " + dev.token)
+		return req
+	}())
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	res.Body.Close()
+	if res.Header.Get(ratelimit.LimitHeader) != "5" {
+		t.Fatalf("expected cached dev tier until the cache expires, got limit %s", res.Header.Get(ratelimit.LimitHeader))
+	}
+}
+
+// Defines the response to placing a limit order
+type testLimitOrderResponse struct {
+	Order schema.LimitOrder `json:"order"`
+	Fills []schema.OrderFill `json:"fills"`
+	Warehouse schema.Warehouse `json:"warehouse"`
+	Ledger schema.Ledger `json:"ledger"`
+}
+
+// Place a limit order for cabbage seeds at the home market
+func (c *testClient) limitOrder(txType string, quantity uint64, price uint64) testLimitOrderResponse {
+	c.t.Helper()
+	var placed testLimitOrderResponse
+	c.decode(c.expect(responses.Generic_Success, "PATCH", "/api/my/markets/TS-PR-HF/order", map[string]interface{}{
+		"order_type": "LIMIT",
+		"transaction_type": txType,
+		"item_category": "SEEDS",
+		"item_name": "Cabbage Seeds",
+		"quantity": quantity,
+		"price": price,
+	}), &placed)
+	return placed
+}
+
+// Get the user's ledger
+func (c *testClient) ledger() schema.Ledger {
+	c.t.Helper()
+	var user schema.User
+	c.decode(c.expect(responses.Generic_Success, "GET", "/api/my/user", nil), &user)
+	return user.Ledger
+}
+
+// Limit orders escrow their funds or items, fill partially at the resting price, and refund their escrow when cancelled
+func TestLimitOrders(t *testing.T) {
+	server, clock := newTestServer(t)
+	seller, buyer := claimTestUser(t, server, "Seller"), claimTestUser(t, server, "Buyer")
+
+	// The ask rests with the seeds held in escrow
+	ask := seller.limitOrder("SELL", 8, 3)
+	if len(ask.Fills) != 0 || ask.Warehouse.Seeds["Cabbage Seeds"] != 0 || ask.Ledger.EscrowWares[ask.Order.UUID] != 8 {
+		t.Fatalf("expected 8 seeds moved to escrow, got %+v", ask)
+	}
+
+	// A crossing bid fills at the ask's price, refunding the difference from its limit
+	bid := buyer.limitOrder("BUY", 5, 4)
+	if len(bid.Fills) != 1 || bid.Fills[0].Price != 3 || bid.Fills[0].Quantity != 5 || bid.Order.Quantity != 0 {
+		t.Fatalf("expected bid filled 5 at 3, got %+v", bid)
+	}
+	if buyer.coins() != 100 - 15 || len(bid.Ledger.EscrowCoins) != 0 || bid.Warehouse.Seeds["Cabbage Seeds"] != 8 + 5 {
+		t.Fatalf("expected buyer to pay 15 for 5 seeds, got coins %d and %+v", buyer.coins(), bid)
+	}
+	sellerLedger := seller.ledger()
+	if sellerLedger.Currencies["Coins"] != 100 + 15 || sellerLedger.EscrowWares[ask.Order.UUID] != 3 {
+		t.Fatalf("expected seller paid 15 with 3 seeds left in escrow, got %+v", sellerLedger)
+	}
+
+	// Orders placed in the same second take the next id, and non-crossing bids rest with their cost in escrow
+	low := buyer.limitOrder("BUY", 4, 2)
+	if low.Order.ID == ask.Order.ID || low.Order.ID == bid.Order.ID || len(low.Fills) != 0 || low.Ledger.EscrowCoins[low.Order.UUID] != 8 || buyer.coins() != 100 - 15 - 8 {
+		t.Fatalf("expected bid of 4 at 2 resting with 8 coins in escrow, got %+v", low)
+	}
+	var book struct {
+		MyOrders []schema.LimitOrder `json:"my_orders"`
+	}
+	buyer.decode(buyer.expect(responses.Generic_Success, "GET", "/api/my/markets/TS-PR-HF/orders", nil), &book)
+	if len(book.MyOrders) != 1 || book.MyOrders[0].UUID != low.Order.UUID {
+		t.Fatalf("expected only the low bid resting for buyer, got %+v", book.MyOrders)
+	}
+
+	// Cancelling refunds the escrow, funds for bids and items for asks
+	buyer.expect(responses.Generic_Success, "DELETE", fmt.Sprintf("/api/my/markets/TS-PR-HF/orders/%d", low.Order.ID), nil)
+	if ledger := buyer.ledger(); ledger.Currencies["Coins"] != 100 - 15 || len(ledger.EscrowCoins) != 0 {
+		t.Fatalf("expected bid escrow refunded, got %+v", ledger)
+	}
+	buyer.expect(responses.Object_Not_Found, "DELETE", fmt.Sprintf("/api/my/markets/TS-PR-HF/orders/%d", ask.Order.ID), nil)
+	var cancelled testLimitOrderResponse
+	seller.decode(seller.expect(responses.Generic_Success, "DELETE", fmt.Sprintf("/api/my/markets/TS-PR-HF/orders/%d", ask.Order.ID), nil), &cancelled)
+	if cancelled.Warehouse.Seeds["Cabbage Seeds"] != 3 || len(cancelled.Ledger.EscrowWares) != 0 {
+		t.Fatalf("expected 3 seeds returned from escrow, got %+v", cancelled)
+	}
+
+	// Prices above the maximum and order values overflowing are rejected before anything is escrowed
+	clock.AdvanceSeconds(1)
+	order := map[string]interface{}{"order_type": "LIMIT", "transaction_type": "BUY", "item_category": "SEEDS", "item_name": "Cabbage Seeds", "quantity": 2, "price": uint64(1) << 63}
+	buyer.expect(responses.Bad_Request, "PATCH", "/api/my/markets/TS-PR-HF/order", order)
+	order["transaction_type"], order["quantity"], order["price"] = "SELL", uint64(1) << 40, schema.MaxLimitOrderPrice
+	seller.expect(responses.Bad_Request, "PATCH", "/api/my/markets/TS-PR-HF/order", order)
+	if buyer.coins() != 100 - 15 || seller.coins() != 100 + 15 {
+		t.Fatalf("expected rejected orders to move no coins, buyer has %d and seller %d", buyer.coins(), seller.coins())
+	}
+
+	// Resting bids only fill up to the room left in their owner's warehouse, the rest of the ask rests
+	resting := buyer.limitOrder("BUY", 3, 3)
+	full := buyer.warehouse("TS-PR-HF")
+	buyer.grantWares("Buyer", "TS-PR-HF", schema.Wareset{Goods: map[string]uint64{"Bundle of Materials": full.Capacity - full.TotalSize() - 1}})
+	crossing := seller.limitOrder("SELL", 3, 3)
+	if len(crossing.Fills) != 1 || crossing.Fills[0].OrderUUID != resting.Order.UUID || crossing.Fills[0].Quantity != 1 || crossing.Order.Quantity != 2 {
+		t.Fatalf("expected ask filled 1 against the bid with room for 1, got %+v", crossing)
+	}
+	if full = buyer.warehouse("TS-PR-HF"); full.TotalSize() != full.Capacity {
+		t.Fatalf("expected buyer warehouse filled to capacity %d, got %d", full.Capacity, full.TotalSize())
+	}
+	buyer.decode(buyer.expect(responses.Generic_Success, "GET", "/api/my/markets/TS-PR-HF/orders", nil), &book)
+	if len(book.MyOrders) != 1 || book.MyOrders[0].Quantity != 2 {
+		t.Fatalf("expected bid resting with 2 unfilled, got %+v", book.MyOrders)
+	}
+}
+
+// Each unit traded is priced along the impact of the units before it, and buys move export prices apart from import prices
+func TestMarketPrices(t *testing.T) {
+	server, _ := newTestServer(t)
+	c := claimTestUser(t, server, "Farmer")
+	c.grantCoins("Farmer", 2000)
+	imp, _, _ := schema.GetAssistantFromDB("Farmer|Assistant-0", dbs["assistants"])
+	imp.Location = "TS-PR-YD"
+	if err := schema.SaveAssistantToDB(dbs["assistants"], &imp); err != nil {
+		t.Fatalf("could not save assistant: %v", err)
+	}
+	yudoa := main_dictionary.Markets["TS-PR-YD"]
+	exportPrice, importPrice := yudoa.Exports.Seeds["Cabbage Seeds"], yudoa.Imports.Seeds["Cabbage Seeds"]
+	order := map[string]interface{}{"order_type": "MARKET", "transaction_type": "BUY", "item_category": "SEEDS", "item_name": "Cabbage Seeds", "quantity": 140}
+
+	// Buying up seeds pays more for each unit and raises only the export price
+	coinsBefore := c.coins()
+	c.expect(responses.Generic_Success, "PATCH", "/api/my/markets/TS-PR-YD/order", order)
+	cost := coinsBefore - c.coins()
+	if cost <= 140 * exportPrice {
+		t.Fatalf("expected 140 seeds to cost more than %d along the price impact, paid %d", 140 * exportPrice, cost)
+	}
+	var live schema.Market
+	c.decode(c.expect(responses.Generic_Success, "GET", "/api/my/markets/TS-PR-YD", nil), &live)
+	if live.Exports.Seeds["Cabbage Seeds"] <= exportPrice || live.Imports.Seeds["Cabbage Seeds"] != importPrice {
+		t.Fatalf("expected export price above %d and import price at %d, got %d and %d", exportPrice, importPrice, live.Exports.Seeds["Cabbage Seeds"], live.Imports.Seeds["Cabbage Seeds"])
+	}
+
+	// Selling them straight back is paid at falling import prices, so the round trip loses coins
+	order["transaction_type"] = "SELL"
+	c.expect(responses.Generic_Success, "PATCH", "/api/my/markets/TS-PR-YD/order", order)
+	if revenue := c.coins() - (coinsBefore - cost); revenue >= 140 * importPrice || revenue >= cost {
+		t.Fatalf("expected selling 140 seeds to earn less than %d and less than the %d paid, got %d", 140 * importPrice, cost, revenue)
+	}
+}
+
+// Contract boards rotate with the clock, listings are accepted once per user, and accepted contracts pay out with favor when fulfilled
+func TestContractBoard(t *testing.T) {
+	server, clock := newTestServer(t)
+	c := claimTestUser(t, server, "Farmer")
+	boardPath := "/api/my/locations/TS-PR-HF/contracts"
+	c.expect(responses.No_Assitant_At_Location, "GET", "/api/my/locations/TS-PR-PSH/contracts", nil)
+
+	// Refresh until the board offers a listing fulfilled at home which needs no favor, a new board each rotation
+	var board schema.ContractBoard
+	var listing schema.ContractListing
+	for rotation := 0; listing.NPC == ""; rotation++ {
+		if rotation == 20 {
+			t.Fatalf("no listing without favor at TS-PR-HF in 20 rotations")
+		}
+		previous := board.Rotation
+		c.decode(c.expect(responses.Generic_Success, "GET", boardPath, nil), &board)
+		if rotation > 0 && board.Rotation != previous + 1 {
+			t.Fatalf("expected board to refresh to rotation %d, got %d", previous + 1, board.Rotation)
+		}
+		if board.ExpiresAt != (board.Rotation + 1) * schema.ContractBoardRotationSeconds || len(board.Listings) == 0 {
+			t.Fatalf("expected listings until the end of the rotation, got %+v", board)
+		}
+		for _, candidate := range board.Listings {
+			if candidate.MinFavor == 0 && candidate.LocationSymbol == "TS-PR-HF" {
+				listing = candidate
+				break
+			}
+		}
+		if listing.NPC == "" {
+			clock.AdvanceSeconds(schema.ContractBoardRotationSeconds)
+		}
+	}
+
+	// Accept
+	listingPath := fmt.Sprintf("%s/%d", boardPath, listing.ID)
+	c.expect(responses.Could_Not_Parse_URI_Param, "POST", boardPath + "/first", nil)
+	c.expect(responses.Object_Not_Found, "POST", fmt.Sprintf("%s/%d", boardPath, len(board.Listings)), nil)
+	var contract schema.Contract
+	c.decode(c.expect(responses.Generic_Success, "POST", listingPath, nil), &contract)
+	if contract.NPC != listing.NPC || !reflect.DeepEqual(contract.Terms, listing.Terms) || !reflect.DeepEqual(contract.Reward, listing.Reward) {
+		t.Fatalf("expected contract for listing %+v, got %+v", listing, contract)
+	}
+	c.expect(responses.Object_Not_Found, "POST", listingPath, nil)
+	var accepted schema.ContractBoard
+	c.decode(c.expect(responses.Generic_Success, "GET", boardPath, nil), &accepted)
+	if len(accepted.Listings) != len(board.Listings) - 1 {
+		t.Fatalf("expected accepted listing hidden from board, got %+v", accepted.Listings)
+	}
+
+	// Fulfil once the term items are in the local warehouse
+	contractPath := "/api/my/contracts/" + strings.Split(contract.UUID, "|Contract-")[1] + "/fulfill"
+	wares := schema.Wareset{Goods: map[string]uint64{}, Seeds: map[string]uint64{}, Produce: map[string]uint64{}, Tools: map[string]uint64{}}
+	for _, term := range contract.Terms {
+		if term.Item == "" {
+			continue
+		}
+		category, found := main_dictionary.GetItemCategory(term.Item)
+		if !found {
+			t.Fatalf("contract term item %s not in dictionary", term.Item)
+		}
+		map[schema.ItemCategory]map[string]uint64{schema.GOOD: wares.Goods, schema.SEED: wares.Seeds, schema.PRODUCE: wares.Produce, schema.TOOL: wares.Tools}[category][term.Item] += term.Quantity
+	}
+	if wares.TotalSize() > 0 {
+		c.expect(responses.Contract_Terms_Not_Met, "POST", contractPath, nil)
+		c.grantWares("Farmer", "TS-PR-HF", wares)
+	}
+	before := c.ledger()
+	c.expect(responses.Generic_Success, "POST", contractPath, nil)
+	ledger := c.ledger()
+	if ledger.Currencies["Coins"] != before.Currencies["Coins"] + contract.Reward[0].Quantity || ledger.Favor[listing.NPC] != before.Favor[listing.NPC] + schema.ContractFavorGain {
+		t.Fatalf("expected reward of %d coins and %d more favor with %s, got ledger %+v from %+v", contract.Reward[0].Quantity, schema.ContractFavorGain, listing.NPC, ledger, before)
+	}
+	c.expect(responses.Object_Not_Found, "POST", contractPath, nil)
+
+	// The next rotation offers every listing again
+	clock.AdvanceSeconds(schema.ContractBoardRotationSeconds)
+	var refreshed schema.ContractBoard
+	c.decode(c.expect(responses.Generic_Success, "GET", boardPath, nil), &refreshed)
+	if refreshed.Rotation != board.Rotation + 1 || len(refreshed.Listings) != len(board.Listings) {
+		t.Fatalf("expected a full board in the next rotation, got %+v", refreshed)
+	}
+}
+
+// Give username a contract directly, for setting up tests
+func (c *testClient) grantContract(username string, locationSymbol string, contractType schema.ContractTypes, terms []schema.ContractTerms, reward []schema.ContractReward) string {
+	c.t.Helper()
+	udb := dbs["users"]
+	userData, found, err := schema.GetUserByUsernameFromDB(username, udb)
+	if err != nil || !found {
+		c.t.Fatalf("could not get user %s, found: %v, error: %v", username, found, err)
+	}
+	count := uint64(len(userData.Contracts) + len(userData.ArchivedContracts))
+	contract := schema.NewContract(userData.Username, count, locationSymbol, contractType, "Viridis", terms, reward)
+	if err := schema.SaveContractToDB(dbs["contracts"], contract); err != nil {
+		c.t.Fatalf("could not save contract: %v", err)
+	}
+	userData.Contracts = append(userData.Contracts, contract.UUID)
+	if err := schema.SaveUserToDB(udb, &userData); err != nil {
+		c.t.Fatalf("could not save user: %v", err)
+	}
+	return fmt.Sprintf("/api/my/contracts/%d/fulfill", count)
+}
+
+// Contracts check their terms against the assistants and warehouse at their location, then pay out and are archived
+func TestFulfillContract(t *testing.T) {
+	server, _ := newTestServer(t)
+	c := claimTestUser(t, server, "Farmer")
+	type fulfillResponse struct {
+		Contract schema.Contract `json:"contract"`
+		Ledger schema.Ledger `json:"ledger"`
+		Warehouse schema.Warehouse `json:"warehouse"`
+	}
+
+	// Starting talk contract, the imp is already home
+	var fulfilled fulfillResponse
+	c.decode(c.expect(responses.Generic_Success, "POST", "/api/my/contracts/0/fulfill", nil), &fulfilled)
+	if !fulfilled.Contract.Fulfilled || fulfilled.Ledger.Currencies["Coins"] != 200 || fulfilled.Ledger.Favor["Viridis"] != schema.ContractFavorGain {
+		t.Fatalf("expected 100 coin reward and favor for talk contract, got %+v", fulfilled)
+	}
+	var user schema.User
+	c.decode(c.expect(responses.Generic_Success, "GET", "/api/my/user", nil), &user)
+	if len(user.Contracts) != 0 || !reflect.DeepEqual(user.ArchivedContracts, []string{fulfilled.Contract.UUID}) {
+		t.Fatalf("expected contract archived, got active %v archived %v", user.Contracts, user.ArchivedContracts)
+	}
+	c.expect(responses.Object_Not_Found, "POST", "/api/my/contracts/0/fulfill", nil)
+	c.expect(responses.Object_Not_Found, "POST", "/api/my/contracts/7/fulfill", nil)
+
+	// Deliver contracts need an assistant at their location
+	seeds := []schema.ContractTerms{{NPC: "Viridis", Item: "Cabbage Seeds", Quantity: 4}}
+	pitchfork := []schema.ContractReward{{RewardType: schema.RewardType_Item, Item: "Pitchfork", Quantity: 1}}
+	deliverPath := c.grantContract("Farmer", "TS-PR-BG", schema.ContractType_Deliver, seeds, pitchfork)
+	c.expect(responses.Contract_Terms_Not_Met, "POST", deliverPath, nil)
+
+	// Item rewards must fit in the warehouse at the contract location
+	home := c.warehouse("TS-PR-HF")
+	pitchforks := []schema.ContractReward{{RewardType: schema.RewardType_Item, Item: "Pitchfork", Quantity: home.Capacity - home.TotalSize() + 1}}
+	c.expect(responses.Warehouse_Capacity_Exceeded, "POST", c.grantContract("Farmer", "TS-PR-HF", schema.ContractType_Talk, []schema.ContractTerms{{NPC: "Viridis"}}, pitchforks), nil)
+
+	// Collect contracts need no assistant, their items are taken from any of the user's warehouses, and item rewards are added at the contract location
+	tooMany := []schema.ContractTerms{{NPC: "Viridis", Item: "Cabbage Seeds", Quantity: 9}}
+	c.expect(responses.Contract_Terms_Not_Met, "POST", c.grantContract("Farmer", "TS-PR-BG", schema.ContractType_Collect, tooMany, pitchfork), nil)
+	c.decode(c.expect(responses.Generic_Success, "POST", c.grantContract("Farmer", "TS-PR-BG", schema.ContractType_Collect, seeds, pitchfork), nil), &fulfilled)
+	if fulfilled.Warehouse.LocationSymbol != "TS-PR-BG" || fulfilled.Warehouse.Tools["Pitchfork"] != 1 || fulfilled.Ledger.Favor["Viridis"] != 2 * schema.ContractFavorGain {
+		t.Fatalf("expected a pitchfork at TS-PR-BG and more favor, got %+v", fulfilled)
+	}
+	if home := c.warehouse("TS-PR-HF"); home.Seeds["Cabbage Seeds"] != 4 {
+		t.Fatalf("expected 4 seeds collected from TS-PR-HF, %d left", home.Seeds["Cabbage Seeds"])
+	}
+}
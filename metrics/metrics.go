@@ -18,7 +18,7 @@ func SaveMetrics() {
 	mYaml := schema.SaveMetricsYaml{
 		UniqueUsers: TrackingUniqueUsers.Usernames, // Handled by TrackUserCall
 		UserActivity: TrackingActiveUsers.UserActivity, // Handled by TrackUserCall
-		Coins: TrackingUserCoins.Coins, // Handled by TrackUserCall and TrackMarketBuySell
+		Coins: TrackingUserCoins.Coins, // Handled by TrackUserCoins
 		MarketData: TrackingMarket.MarketData, // Handled by TrackMarketBuySell
 		HarvestData: TrackingHarvests.HarvestData, // Handled by TrackHarvest
 		RitualData: TrackingRituals.RitualData, // Handled by TrackRitual
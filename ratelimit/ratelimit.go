@@ -0,0 +1,194 @@
+// Package ratelimit limits requests per authenticated user, or per IP for requests without a valid token, with counters kept in the DB so several servers share them
+package ratelimit
+
+import (
+	"apricate/auth"
+	"apricate/log"
+	"apricate/rdb"
+	"apricate/responses"
+	"apricate/schema"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Headers reporting the budget of the tier the request was counted against
+const (
+	LimitHeader = "X-RateLimit-Limit"
+	RemainingHeader = "X-RateLimit-Remaining"
+	ResetHeader = "X-RateLimit-Reset" // unix timestamp the window resets at
+)
+
+// Defines how many requests a tier allows per window
+type Tier struct {
+	Name string
+	Limit int64
+	Window time.Duration
+}
+
+// Defines the tier for each kind of requester
+type Tiers struct {
+	Public Tier // requests without a valid token, counted per IP
+	Standard Tier // authenticated users
+	Dev Tier // authenticated users with the Owner or Contributor achievement
+}
+
+// Get the default tiers, public and standard match the old per-IP limit of 4 requests per second
+func DefaultTiers() Tiers {
+	return Tiers{
+		Public: Tier{Name: "Public", Limit: 4, Window: time.Second},
+		Standard: Tier{Name: "Standard", Limit: 4, Window: time.Second},
+		Dev: Tier{Name: "Dev", Limit: 16, Window: time.Second},
+	}
+}
+
+// Parse a tier limit formatted as requests/window, such as 4/1s
+func ParseTier(name string, value string) (Tier, error) {
+	split := strings.Split(value, "/")
+	if len(split) != 2 {
+		return Tier{}, fmt.Errorf("rate limit %s must be formatted as requests/window, such as 4/1s", value)
+	}
+	limit, limitErr := strconv.ParseInt(split[0], 10, 64)
+	if limitErr != nil || limit < 1 {
+		return Tier{}, fmt.Errorf("rate limit %s must allow at least 1 request", value)
+	}
+	window, windowErr := time.ParseDuration(split[1])
+	if windowErr != nil || window < time.Millisecond {
+		return Tier{}, fmt.Errorf("rate limit %s must have a window of at least 1ms", value)
+	}
+	return Tier{Name: name, Limit: limit, Window: window}, nil
+}
+
+// Format the tier's limit as requests/window, the inverse of ParseTier
+func (t Tier) String() string {
+	return fmt.Sprintf("%d/%v", t.Limit, t.Window)
+}
+
+// How long the tier found for a token is reused before the user is read again, so the user DB is not read on every request
+var TierCacheTTL = 30 * time.Second
+
+// Cached tiers kept before expired entries are swept
+const tierCacheSweepSize int = 10000
+
+// Defines a requester found for a token and when it must be read again
+type cachedRequester struct {
+	key string
+	tier Tier
+	expires time.Time
+}
+
+// Defines a limiter counting requests in fixed windows per requester.
+//
+// Users are read to choose their tier, cached for TierCacheTTL, counters are incremented in their own DB.
+// Requests without a valid token are counted per IP, read from ProxyHeader when set so requests relayed by a trusted reverse proxy are told apart
+type Limiter struct {
+	Users rdb.InteractiveDB
+	Counters rdb.InteractiveDB
+	Tiers Tiers
+	ProxyHeader string // such as X-Forwarded-For, only set when every request arrives through a proxy setting it
+	mu sync.Mutex
+	cache map[string]cachedRequester
+}
+
+// Get the counter key and tier for the request, keyed on the username of a valid token, else the IP
+func (l *Limiter) requester(r *http.Request) (string, Tier) {
+	validationPair, tokenErr := auth.ExtractTokenMetadata(r)
+	if tokenErr == nil {
+		if key, tier, ok := l.userRequester(validationPair.Token, validationPair.Username); ok {
+			return key, tier
+		}
+	}
+	return "IP|" + l.clientIP(r), l.Tiers.Public
+}
+
+// Get the counter key and tier of the user with token, from the cache while fresh, bool is user found
+func (l *Limiter) userRequester(token string, username string) (string, Tier, bool) {
+	now := time.Now()
+	l.mu.Lock()
+	cached, ok := l.cache[token]
+	l.mu.Unlock()
+	if ok && now.Before(cached.expires) {
+		return cached.key, cached.tier, true
+	}
+	userData, userFound, userErr := schema.GetUserFromDB(token, l.Users)
+	if userErr != nil {
+		log.Error.Printf("Error in Limiter, could not get user %s from DB, limiting by IP. error: %v", username, userErr)
+		return "", Tier{}, false
+	}
+	if !userFound {
+		return "", Tier{}, false
+	}
+	tier := l.Tiers.Standard
+	for _, achievement := range userData.Achievements {
+		if achievement == schema.Achievement_Owner || achievement == schema.Achievement_Contributor {
+			tier = l.Tiers.Dev
+		}
+	}
+	cached = cachedRequester{key: "User|" + userData.Username, tier: tier, expires: now.Add(TierCacheTTL)}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.cache == nil {
+		l.cache = make(map[string]cachedRequester)
+	}
+	if len(l.cache) >= tierCacheSweepSize {
+		for cachedToken, entry := range l.cache {
+			if !now.Before(entry.expires) {
+				delete(l.cache, cachedToken)
+			}
+		}
+	}
+	l.cache[token] = cached
+	return cached.key, cached.tier, true
+}
+
+// Get the client IP, from the last address in ProxyHeader when set as that is the one added by the trusted proxy, else the connection address
+func (l *Limiter) clientIP(r *http.Request) string {
+	if l.ProxyHeader != "" {
+		if forwarded := r.Header.Values(l.ProxyHeader); len(forwarded) > 0 {
+			addresses := strings.Split(forwarded[len(forwarded)-1], ",")
+			if ip := strings.TrimSpace(addresses[len(addresses)-1]); ip != "" {
+				return ip
+			}
+		}
+	}
+	ip, _, splitErr := net.SplitHostPort(r.RemoteAddr)
+	if splitErr != nil {
+		ip = r.RemoteAddr
+	}
+	return ip
+}
+
+// Wrap next so each request is counted against its requester's tier, rejecting requests over the limit with Rate_Limit_Exceeded
+func (l *Limiter) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key, tier := l.requester(r)
+		count, ttl, incrErr := l.Counters.Incr(key, tier.Window)
+		if incrErr != nil {
+			// Fail open, the DB being unreachable will surface in the route itself
+			log.Error.Printf("Error in Limiter, could not count request for %s. error: %v", key, incrErr)
+			next.ServeHTTP(w, r)
+			return
+		}
+		remaining := tier.Limit - count
+		if remaining < 0 {
+			remaining = 0
+		}
+		reset := time.Now().Add(ttl)
+		w.Header().Set(LimitHeader, strconv.FormatInt(tier.Limit, 10))
+		w.Header().Set(RemainingHeader, strconv.FormatInt(remaining, 10))
+		w.Header().Set(ResetHeader, strconv.FormatInt(reset.Unix(), 10))
+		if count > tier.Limit {
+			log.Debug.Printf("Limiter rejected request %d of %d for %s in tier %s", count, tier.Limit, key, tier.Name)
+			retryAfter := int64((ttl + time.Second - 1) / time.Second)
+			w.Header().Set("Retry-After", strconv.FormatInt(retryAfter, 10))
+			w.Header().Set("Content-Type", "application/json")
+			responses.SendRes(w, responses.Rate_Limit_Exceeded, nil, fmt.Sprintf("%s tier allows %s", tier.Name, tier))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
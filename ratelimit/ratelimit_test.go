@@ -0,0 +1,38 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+)
+
+// Tier limits are read from configuration as requests/window, and print back the same way
+func TestParseTier(t *testing.T) {
+	tests := []struct {
+		value string
+		want Tier
+		wantErr bool
+	}{
+		{"4/1s", Tier{Name: "Standard", Limit: 4, Window: time.Second}, false},
+		{"100/1m0s", Tier{Name: "Standard", Limit: 100, Window: time.Minute}, false},
+		{"1/1ms", Tier{Name: "Standard", Limit: 1, Window: time.Millisecond}, false},
+		{"4", Tier{}, true},
+		{"4/1s/2", Tier{}, true},
+		{"four/1s", Tier{}, true},
+		{"0/1s", Tier{}, true},
+		{"-4/1s", Tier{}, true},
+		{"4/soon", Tier{}, true},
+		{"4/1us", Tier{}, true},
+		{"", Tier{}, true},
+	}
+	for _, test := range tests {
+		t.Run(test.value, func(t *testing.T) {
+			got, err := ParseTier("Standard", test.value)
+			if (err != nil) != test.wantErr || got != test.want {
+				t.Fatalf("got %+v (err %v), want %+v (err %t)", got, err, test.want, test.wantErr)
+			}
+			if !test.wantErr && got.String() != test.value {
+				t.Errorf("got string %s, want %s", got.String(), test.value)
+			}
+		})
+	}
+}
@@ -0,0 +1,641 @@
+package rdb
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"apricate/log"
+
+	goredis "github.com/go-redis/redis/v8"
+)
+
+// Define an in-process store of logical DBs holding JSON documents, shared by MemoryDatabases so transactions can span them
+type MemoryStore struct {
+	mu sync.Mutex
+	dbs map[int]map[string]*memoryEntry
+	counters map[int]map[string]*memoryCounter
+	sorted map[int]map[string]map[string]int64 // sorted sets by key, of member scores
+	channels map[string]map[chan []byte]bool
+	version uint64
+}
+
+// Messages buffered per memory subscription before new messages are dropped
+const MemorySubscriptionBufferSize int = 256
+
+// Define a stored JSON document and the version of its last write
+type memoryEntry struct {
+	value interface{}
+	version uint64
+}
+
+// Define a counter incremented with Incr and when it expires
+type memoryCounter struct {
+	count int64
+	expires time.Time
+}
+
+// Create new empty memory store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		dbs: make(map[int]map[string]*memoryEntry),
+		counters: make(map[int]map[string]*memoryCounter),
+		sorted: make(map[int]map[string]map[string]int64),
+		channels: make(map[string]map[chan []byte]bool),
+	}
+}
+
+// Define in-memory Database implementing InteractiveDB with RedisJSON path semantics, for tests and local runs
+type MemoryDatabase struct {
+	Store *MemoryStore
+	DBNum int
+	tx *memoryTx // set when bound to a transaction with Tx.Bind
+}
+
+// Create new in-memory database for the given logical db in store
+func NewMemoryDatabase(store *MemoryStore, dbNum int) MemoryDatabase {
+	return MemoryDatabase{
+		Store: store,
+		DBNum: dbNum,
+	}
+}
+
+// Define a segment of a json path, either an object key or an array index
+type pathSegment struct {
+	key string
+	index int
+	isIndex bool
+}
+
+// Parse a RedisJSON path like '.', 'plots', '.plots["LOC!Plot-0"].quantity' or 'wares[1]' into segments
+func parsePath(path string) ([]pathSegment, error) {
+	path = strings.TrimPrefix(path, "$")
+	segments := make([]pathSegment, 0)
+	i := 0
+	for i < len(path) {
+		switch path[i] {
+		case '.':
+			i++
+		case '[':
+			end := strings.IndexByte(path[i:], ']')
+			if end < 0 {
+				return nil, fmt.Errorf("ERR invalid path '%s'", path)
+			}
+			inner := path[i+1 : i+end]
+			if unquoted, err := strconv.Unquote(inner); err == nil {
+				segments = append(segments, pathSegment{key: unquoted})
+			} else if index, err := strconv.Atoi(inner); err == nil {
+				segments = append(segments, pathSegment{index: index, isIndex: true})
+			} else {
+				return nil, fmt.Errorf("ERR invalid path '%s'", path)
+			}
+			i += end + 1
+		default:
+			end := strings.IndexAny(path[i:], ".[")
+			if end < 0 {
+				end = len(path) - i
+			}
+			segments = append(segments, pathSegment{key: path[i : i+end]})
+			i += end
+		}
+	}
+	return segments, nil
+}
+
+// Get child of a decoded json value for a segment, bool is found
+func (s pathSegment) child(value interface{}) (interface{}, bool) {
+	if s.isIndex {
+		arr, ok := value.([]interface{})
+		if !ok {
+			return nil, false
+		}
+		index := s.index
+		if index < 0 {
+			index += len(arr)
+		}
+		if index < 0 || index >= len(arr) {
+			return nil, false
+		}
+		return arr[index], true
+	}
+	obj, ok := value.(map[string]interface{})
+	if !ok {
+		return nil, false
+	}
+	child, ok := obj[s.key]
+	return child, ok
+}
+
+// Get the value at segments within value, bool is found
+func getAtPath(value interface{}, segments []pathSegment) (interface{}, bool) {
+	for _, segment := range segments {
+		var ok bool
+		value, ok = segment.child(value)
+		if !ok {
+			return nil, false
+		}
+	}
+	return value, true
+}
+
+// Set the value at segments within root, the parent must exist as in RedisJSON
+func setAtPath(root interface{}, segments []pathSegment, newValue interface{}) error {
+	parent, ok := getAtPath(root, segments[:len(segments)-1])
+	if !ok {
+		return errors.New("ERR missing parent for path")
+	}
+	last := segments[len(segments)-1]
+	if last.isIndex {
+		arr, ok := parent.([]interface{})
+		if !ok || last.index >= len(arr) || last.index < -len(arr) {
+			return errors.New("ERR array index out of range")
+		}
+		index := last.index
+		if index < 0 {
+			index += len(arr)
+		}
+		arr[index] = newValue
+		return nil
+	}
+	obj, ok := parent.(map[string]interface{})
+	if !ok {
+		return errors.New("ERR path parent is not an object")
+	}
+	obj[last.key] = newValue
+	return nil
+}
+
+// Delete the value at segments within root, returns # of paths deleted
+func delAtPath(root interface{}, segments []pathSegment) int64 {
+	parent, ok := getAtPath(root, segments[:len(segments)-1])
+	if !ok {
+		return 0
+	}
+	last := segments[len(segments)-1]
+	if last.isIndex {
+		// Arrays cannot be shortened in place from here, so deleting indices is unsupported
+		return 0
+	}
+	obj, ok := parent.(map[string]interface{})
+	if !ok {
+		return 0
+	}
+	if _, exists := obj[last.key]; !exists {
+		return 0
+	}
+	delete(obj, last.key)
+	return 1
+}
+
+// Decode json into generic values, keeping numbers exact so large ints like unix nano timestamps survive
+func decodeJson(data []byte) (interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var value interface{}
+	err := decoder.Decode(&value)
+	return value, err
+}
+
+// Get the db map for dbNum, creating if needed. Store must be locked
+func (store *MemoryStore) db(dbNum int) map[string]*memoryEntry {
+	db, ok := store.dbs[dbNum]
+	if !ok {
+		db = make(map[string]*memoryEntry)
+		store.dbs[dbNum] = db
+	}
+	return db
+}
+
+// Get current version of key, 0 if not found. Store must be locked
+func (store *MemoryStore) versionOf(dbNum int, key string) uint64 {
+	entry, ok := store.db(dbNum)[key]
+	if !ok {
+		return 0
+	}
+	return entry.version
+}
+
+// Get json for key at path. Store must be locked
+func (store *MemoryStore) get(dbNum int, key string, path string) ([]uint8, error) {
+	entry, ok := store.db(dbNum)[key]
+	if !ok {
+		return nil, goredis.Nil
+	}
+	segments, err := parsePath(path)
+	if err != nil {
+		return nil, err
+	}
+	value, found := getAtPath(entry.value, segments)
+	if !found {
+		return nil, fmt.Errorf("ERR Path '%s' does not exist", path)
+	}
+	return json.Marshal(value)
+}
+
+// Set json for key at path. Store must be locked
+func (store *MemoryStore) set(dbNum int, key string, path string, dataJSON []byte) error {
+	value, err := decodeJson(dataJSON)
+	if err != nil {
+		return err
+	}
+	segments, err := parsePath(path)
+	if err != nil {
+		return err
+	}
+	db := store.db(dbNum)
+	entry, ok := db[key]
+	if len(segments) == 0 {
+		store.version++
+		db[key] = &memoryEntry{value: value, version: store.version}
+		return nil
+	}
+	if !ok {
+		return errors.New("ERR new objects must be created at the root")
+	}
+	if err := setAtPath(entry.value, segments, value); err != nil {
+		return err
+	}
+	store.version++
+	entry.version = store.version
+	return nil
+}
+
+// Delete key at path, returns # of paths deleted. Store must be locked
+func (store *MemoryStore) del(dbNum int, key string, path string) (int64, error) {
+	db := store.db(dbNum)
+	entry, ok := db[key]
+	if !ok {
+		return 0, nil
+	}
+	segments, err := parsePath(path)
+	if err != nil {
+		return 0, err
+	}
+	if len(segments) == 0 {
+		delete(db, key)
+		return 1, nil
+	}
+	deleted := delAtPath(entry.value, segments)
+	if deleted > 0 {
+		store.version++
+		entry.version = store.version
+	}
+	return deleted, nil
+}
+
+// Define methods for MemoryDatabase struct so it implements InteractiveDB interface
+
+// Set json data for key at path.
+func (db MemoryDatabase) SetJsonData(key string, path string, data interface{}) error {
+	log.Debug.Printf("New attempt JsonSetData (memory)")
+	log.Debug.Printf("Key: '%s', Path: '%s', Data:\n%s", key, path, data)
+	dataJSON, err := json.Marshal(data)
+	if err != nil {
+		log.Error.Printf("Failed to JSONSet (key: %s, path: %s), error: '%v'. Data:\n%s", key, path, err, data)
+		return err
+	}
+	if db.tx != nil {
+		// Buffer until transaction exec
+		db.tx.writes = append(db.tx.writes, txWrite{dbNum: db.DBNum, key: key, path: path, data: dataJSON})
+		return nil
+	}
+	db.Store.mu.Lock()
+	defer db.Store.mu.Unlock()
+	if err := db.Store.set(db.DBNum, key, path, dataJSON); err != nil {
+		log.Error.Printf("Failed to JSONSet (key: %s, path: %s), error: '%v'. Data:\n%s", key, path, err, data)
+		return err
+	}
+	return nil
+}
+
+// Get json data for key at path.
+//
+// Returns marshalled json byte array so make sure to unmarshall externally into an appropriate struct.
+func (db MemoryDatabase) GetJsonData(key string, path string) ([]uint8, error) {
+	log.Debug.Printf("New attempt GetJsonData (memory)")
+	log.Debug.Printf("Key: '%s', Path: '%s'", key, path)
+	if db.tx != nil {
+		if writes, replaced := pendingWrites(db.tx.writes, db.DBNum, key); len(writes) > 0 {
+			// Read own buffered writes, applied over the stored document unless a root write replaced it
+			var stored []byte
+			if !replaced {
+				db.Store.mu.Lock()
+				db.tx.watch(db.DBNum, key)
+				var err error
+				stored, err = db.Store.get(db.DBNum, key, ".")
+				db.Store.mu.Unlock()
+				if err == goredis.Nil {
+					stored, err = nil, nil
+				}
+				if err != nil {
+					return nil, err
+				}
+			}
+			return readPendingWrites(stored, writes, path)
+		}
+	}
+	db.Store.mu.Lock()
+	defer db.Store.mu.Unlock()
+	if db.tx != nil {
+		db.tx.watch(db.DBNum, key)
+	}
+	dataJSON, err := db.Store.get(db.DBNum, key, path)
+	if err != nil {
+		log.Debug.Printf("Failed to JSONGet (key: %s, path: %s), reason: '%v'", key, path, err)
+		return nil, err
+	}
+	return dataJSON, nil
+}
+
+// Get json data at path for multiple keys, entries are nil for keys not found
+//
+// Returns marshalled json byte array so make sure to unmarshall externally into an appropriate struct.
+func (db MemoryDatabase) MGetJsonData(path string, keys []string) ([][]uint8, error) {
+	log.Debug.Printf("New attempt MGetJsonData (memory)")
+	log.Debug.Printf("Keys: '%s', Path: '%s'", keys, path)
+	dataJSON := make([][]byte, len(keys))
+	for i, key := range keys {
+		data, err := db.GetJsonData(key, path)
+		if err != nil {
+			log.Debug.Printf("Failed to JSONMGet for key %s, reason: '%v'", key, err)
+			continue
+		}
+		dataJSON[i] = data
+	}
+	return dataJSON, nil
+}
+
+// Del json data for key at path.
+//
+// Returns # of paths deleted
+func (db MemoryDatabase) DelJsonData(key string, path string) (int64, error) {
+	log.Debug.Printf("New attempt DelJsonData (memory)")
+	log.Debug.Printf("Key: '%s', Path: '%s'", key, path)
+	if db.tx != nil {
+		// Buffer until transaction exec
+		db.tx.writes = append(db.tx.writes, txWrite{dbNum: db.DBNum, key: key, path: path, del: true})
+		return 1, nil
+	}
+	db.Store.mu.Lock()
+	defer db.Store.mu.Unlock()
+	return db.Store.del(db.DBNum, key, path)
+}
+
+// Get keys matching glob pattern in sorted order.
+//
+// Keys are not watched by transactions, read each key through a bound database to watch it
+func (db MemoryDatabase) Keys(pattern string) ([]string, error) {
+	log.Debug.Printf("New attempt Keys (memory)")
+	log.Debug.Printf("Pattern: '%s'", pattern)
+	db.Store.mu.Lock()
+	defer db.Store.mu.Unlock()
+	keys := make([]string, 0)
+	for key := range db.Store.db(db.DBNum) {
+		matched, err := path.Match(pattern, key)
+		if err != nil {
+			return nil, err
+		}
+		if matched {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+// Set or remove member of the sorted set at key. Store must be locked
+func (store *MemoryStore) setMember(dbNum int, key string, member string, score int64, del bool) {
+	sets, ok := store.sorted[dbNum]
+	if !ok {
+		sets = make(map[string]map[string]int64)
+		store.sorted[dbNum] = sets
+	}
+	if del {
+		delete(sets[key], member)
+		if len(sets[key]) == 0 {
+			delete(sets, key)
+		}
+		return
+	}
+	if _, ok := sets[key]; !ok {
+		sets[key] = make(map[string]int64)
+	}
+	sets[key][member] = score
+}
+
+// Add member to the sorted set at key with score, replacing its score if already a member
+func (db MemoryDatabase) ZAdd(key string, member string, score int64) error {
+	if db.tx != nil {
+		// Buffer until transaction exec
+		db.tx.writes = append(db.tx.writes, txWrite{dbNum: db.DBNum, key: key, sorted: true, member: member, score: score})
+		return nil
+	}
+	db.Store.mu.Lock()
+	defer db.Store.mu.Unlock()
+	db.Store.setMember(db.DBNum, key, member, score, false)
+	return nil
+}
+
+// Remove member from the sorted set at key
+func (db MemoryDatabase) ZRem(key string, member string) error {
+	if db.tx != nil {
+		// Buffer until transaction exec
+		db.tx.writes = append(db.tx.writes, txWrite{dbNum: db.DBNum, key: key, sorted: true, member: member, del: true})
+		return nil
+	}
+	db.Store.mu.Lock()
+	defer db.Store.mu.Unlock()
+	db.Store.setMember(db.DBNum, key, member, 0, true)
+	return nil
+}
+
+// Get members of the sorted set at key with score up to and including max, lowest score first then by member like redis.
+//
+// Sorted sets are not watched by transactions, and reads do not include writes buffered by a bound database
+func (db MemoryDatabase) ZRangeByScore(key string, max int64) ([]string, error) {
+	db.Store.mu.Lock()
+	defer db.Store.mu.Unlock()
+	scores := db.Store.sorted[db.DBNum][key]
+	members := make([]string, 0)
+	for member, score := range scores {
+		if score <= max {
+			members = append(members, member)
+		}
+	}
+	sort.Slice(members, func(i, j int) bool {
+		if scores[members[i]] != scores[members[j]] {
+			return scores[members[i]] < scores[members[j]]
+		}
+		return members[i] < members[j]
+	})
+	return members, nil
+}
+
+// Increment the counter at key, which expires window after its first increment.
+//
+// Returns the count and the time until the counter expires. Counters are kept apart from JSON documents and are not part of transactions
+func (db MemoryDatabase) Incr(key string, window time.Duration) (int64, time.Duration, error) {
+	db.Store.mu.Lock()
+	defer db.Store.mu.Unlock()
+	counters, ok := db.Store.counters[db.DBNum]
+	if !ok {
+		counters = make(map[string]*memoryCounter)
+		db.Store.counters[db.DBNum] = counters
+	}
+	now := time.Now()
+	counter, ok := counters[key]
+	if !ok || !now.Before(counter.expires) {
+		counter = &memoryCounter{expires: now.Add(window)}
+		counters[key] = counter
+	}
+	counter.count++
+	return counter.count, counter.expires.Sub(now), nil
+}
+
+// Publish message to every subscriber of channel, dropping it for subscribers whose buffer is full.
+//
+// Channels are shared by every logical DB of the store and are not part of transactions
+func (db MemoryDatabase) Publish(channel string, message []byte) error {
+	db.Store.mu.Lock()
+	defer db.Store.mu.Unlock()
+	for ch := range db.Store.channels[channel] {
+		select {
+		case ch <- message:
+		default:
+			log.Important.Printf("Dropped message on %s, memory subscription buffer full", channel)
+		}
+	}
+	return nil
+}
+
+// Subscribe to messages published to channel from when Subscribe returns until stop is closed, then the returned channel is closed
+func (db MemoryDatabase) Subscribe(channel string, stop <-chan struct{}) (<-chan []byte, error) {
+	messages := make(chan []byte, MemorySubscriptionBufferSize)
+	db.Store.mu.Lock()
+	if _, ok := db.Store.channels[channel]; !ok {
+		db.Store.channels[channel] = make(map[chan []byte]bool)
+	}
+	db.Store.channels[channel][messages] = true
+	db.Store.mu.Unlock()
+	go func() {
+		<-stop
+		db.Store.mu.Lock()
+		delete(db.Store.channels[channel], messages)
+		if len(db.Store.channels[channel]) == 0 {
+			delete(db.Store.channels, channel)
+		}
+		db.Store.mu.Unlock()
+		close(messages)
+	}()
+	return messages, nil
+}
+
+// Flush database
+func (db MemoryDatabase) Flush() error {
+	db.Store.mu.Lock()
+	defer db.Store.mu.Unlock()
+	delete(db.Store.dbs, db.DBNum)
+	delete(db.Store.counters, db.DBNum)
+	delete(db.Store.sorted, db.DBNum)
+	log.Important.Printf("Flushed memory DB: %d", db.DBNum)
+	return nil
+}
+
+// Ping always succeeds for memory databases
+func (db MemoryDatabase) Ping() error {
+	return nil
+}
+
+// Define an optimistic transaction across logical DBs of a memory store.
+// Versions of read keys are recorded and checked again under lock before buffered writes are applied
+type memoryTx struct {
+	store *MemoryStore
+	watched map[string]uint64
+	writes []txWrite
+}
+
+// Record version of key the first time it is read. Store must be locked
+func (tx *memoryTx) watch(dbNum int, key string) {
+	watchKey := fmt.Sprintf("%d|%s", dbNum, key)
+	if _, ok := tx.watched[watchKey]; !ok {
+		tx.watched[watchKey] = tx.store.versionOf(dbNum, key)
+	}
+}
+
+// Bind a database to this transaction, so reads are watched and writes are buffered
+func (tx *memoryTx) Bind(db InteractiveDB) InteractiveDB {
+	memoryDB, ok := db.(MemoryDatabase)
+	if !ok || memoryDB.Store != tx.store {
+		log.Error.Printf("Cannot bind %T to a memory transaction, using it unbound", db)
+		return db
+	}
+	memoryDB.tx = tx
+	return memoryDB
+}
+
+// Bind every database in the map to this transaction
+func (tx *memoryTx) BindAll(dbs map[string]InteractiveDB) map[string]InteractiveDB {
+	res := make(map[string]InteractiveDB, len(dbs))
+	for name, db := range dbs {
+		res[name] = tx.Bind(db)
+	}
+	return res
+}
+
+// Apply buffered writes if no watched key changed, bool is applied. Store must be locked
+func (tx *memoryTx) exec() (bool, error) {
+	for watchKey, version := range tx.watched {
+		split := strings.SplitN(watchKey, "|", 2)
+		dbNum, _ := strconv.Atoi(split[0])
+		if tx.store.versionOf(dbNum, split[1]) != version {
+			return false, nil
+		}
+	}
+	for _, write := range tx.writes {
+		var err error
+		if write.sorted {
+			tx.store.setMember(write.dbNum, write.key, write.member, write.score, write.del)
+		} else if write.del {
+			_, err = tx.store.del(write.dbNum, write.key, write.path)
+		} else {
+			err = tx.store.set(write.dbNum, write.key, write.path, write.data)
+		}
+		if err != nil {
+			return true, err
+		}
+	}
+	return true, nil
+}
+
+// Run fn in an optimistic transaction over the memory store, retrying up to maxAttempts times when a read key is changed concurrently.
+//
+// fn should read and write only through databases bound with tx.Bind or tx.BindAll.
+// If fn returns an error nothing is written and the error is returned without retrying
+func (db MemoryDatabase) Transact(maxAttempts int, fn func(tx Tx) error) error {
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		tx := &memoryTx{
+			store: db.Store,
+			watched: make(map[string]uint64),
+			writes: make([]txWrite, 0),
+		}
+		if fnErr := fn(tx); fnErr != nil {
+			return fnErr
+		}
+		db.Store.mu.Lock()
+		applied, execErr := tx.exec()
+		db.Store.mu.Unlock()
+		if !applied {
+			log.Debug.Printf("Transaction conflict on attempt %d of %d, retrying", attempt, maxAttempts)
+			continue
+		}
+		return execErr
+	}
+	return ErrTxConflict
+}
@@ -0,0 +1,93 @@
+package rdb
+
+import (
+	"reflect"
+	"testing"
+)
+
+// The in-memory database follows the RedisJSON path semantics used by schema helpers
+func TestMemoryDatabase(t *testing.T) {
+	store := NewMemoryStore()
+	db, other := NewMemoryDatabase(store, 0), NewMemoryDatabase(store, 1)
+	farm := map[string]interface{}{
+		"plots": map[string]interface{}{"TS-PR-HF!Plot-0": map[string]interface{}{"quantity": 1}},
+		"tools": []string{"Hoe", "Shears"},
+		"created": int64(1640995200123456789),
+	}
+	if err := db.SetJsonData("Farmer|Farm-TS-PR-HF", ".", farm); err != nil {
+		t.Fatalf("could not set farm: %v", err)
+	}
+	get := func(key string, path string) string {
+		t.Helper()
+		data, err := db.GetJsonData(key, path)
+		if err != nil {
+			t.Fatalf("could not get %s at %s: %v", key, path, err)
+		}
+		return string(data)
+	}
+
+	// Paths into objects, quoted keys, and arrays, keeping large numbers exact
+	if plot := get("Farmer|Farm-TS-PR-HF", `.plots["TS-PR-HF!Plot-0"].quantity`); plot != "1" {
+		t.Fatalf("expected quoted key path to read 1, got %s", plot)
+	}
+	if tool := get("Farmer|Farm-TS-PR-HF", "tools[1]"); tool != `"Shears"` {
+		t.Fatalf("expected array index to read Shears, got %s", tool)
+	}
+	if created := get("Farmer|Farm-TS-PR-HF", "created"); created != "1640995200123456789" {
+		t.Fatalf("expected unix nano timestamp kept exact, got %s", created)
+	}
+	if err := db.SetJsonData("Farmer|Farm-TS-PR-HF", `plots["TS-PR-HF!Plot-1"]`, map[string]int{"quantity": 2}); err != nil {
+		t.Fatalf("could not set plot: %v", err)
+	}
+	if err := db.SetJsonData("Farmer|Farm-TS-PR-HF", "tools[-1]", "Sickle"); err != nil {
+		t.Fatalf("could not set tool: %v", err)
+	}
+	if plots, tools := get("Farmer|Farm-TS-PR-HF", "plots"), get("Farmer|Farm-TS-PR-HF", "tools"); plots != `{"TS-PR-HF!Plot-0":{"quantity":1},"TS-PR-HF!Plot-1":{"quantity":2}}` || tools != `["Hoe","Sickle"]` {
+		t.Fatalf("expected sub path writes applied, got plots %s tools %s", plots, tools)
+	}
+
+	// Missing keys, paths and parents are errors as in RedisJSON
+	if _, err := db.GetJsonData("Farmer|Farm-TS-PR-YD", "."); err == nil || err.Error() != "redis: nil" {
+		t.Fatalf("expected redis nil for missing key, got %v", err)
+	}
+	if _, err := db.GetJsonData("Farmer|Farm-TS-PR-HF", "buildings"); err == nil {
+		t.Fatalf("expected error for missing path")
+	}
+	if err := db.SetJsonData("Farmer|Farm-TS-PR-YD", "plots", 1); err == nil {
+		t.Fatalf("expected new objects to only be created at the root")
+	}
+	if err := db.SetJsonData("Farmer|Farm-TS-PR-HF", "buildings.Barn", 1); err == nil {
+		t.Fatalf("expected error setting a path without a parent")
+	}
+
+	// Multi get leaves missing keys nil, and logical dbs are kept apart
+	other.SetJsonData("Farmer|Farm-TS-PR-YD", ".", farm)
+	db.SetJsonData("Farmer|Farm-TS-PR-BG", ".", farm)
+	multi, err := db.MGetJsonData("created", []string{"Farmer|Farm-TS-PR-HF", "Farmer|Farm-TS-PR-YD", "Farmer|Farm-TS-PR-BG"})
+	if err != nil || string(multi[0]) != "1640995200123456789" || multi[1] != nil || string(multi[2]) != "1640995200123456789" {
+		t.Fatalf("expected multi get of db 0 only, got %q: %v", multi, err)
+	}
+	if keys, err := db.Keys("Farmer|Farm-*"); err != nil || !reflect.DeepEqual(keys, []string{"Farmer|Farm-TS-PR-BG", "Farmer|Farm-TS-PR-HF"}) {
+		t.Fatalf("expected sorted keys of db 0, got %v: %v", keys, err)
+	}
+
+	// Deleting paths and keys, and flushing a single db
+	if deleted, err := db.DelJsonData("Farmer|Farm-TS-PR-HF", `plots["TS-PR-HF!Plot-0"]`); err != nil || deleted != 1 {
+		t.Fatalf("expected plot deleted, got %d: %v", deleted, err)
+	}
+	if plots := get("Farmer|Farm-TS-PR-HF", "plots"); plots != `{"TS-PR-HF!Plot-1":{"quantity":2}}` {
+		t.Fatalf("expected one plot left, got %s", plots)
+	}
+	if deleted, err := db.DelJsonData("Farmer|Farm-TS-PR-BG", "."); err != nil || deleted != 1 {
+		t.Fatalf("expected farm deleted, got %d: %v", deleted, err)
+	}
+	if err := db.Flush(); err != nil {
+		t.Fatalf("could not flush: %v", err)
+	}
+	if keys, _ := db.Keys("*"); len(keys) != 0 {
+		t.Fatalf("expected flushed db empty, got %v", keys)
+	}
+	if keys, _ := other.Keys("*"); len(keys) != 1 {
+		t.Fatalf("expected other db untouched by flush, got %v", keys)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	"apricate/log"
 
@@ -30,13 +31,25 @@ func NewDatabase(redisAddr string, dbNum int) Database {
 type InteractiveDB interface {
 	SetJsonData(key string, path string, data interface{}) (error)
 	GetJsonData(key string, path string) ([]uint8, error)
+	MGetJsonData(path string, keys []string) ([][]uint8, error)
+	DelJsonData(key string, path string) (int64, error)
+	Keys(pattern string) ([]string, error)
+	ZAdd(key string, member string, score int64) (error)
+	ZRem(key string, member string) (error)
+	ZRangeByScore(key string, max int64) ([]string, error)
+	Incr(key string, window time.Duration) (int64, time.Duration, error)
+	Publish(channel string, message []byte) (error)
+	Subscribe(channel string, stop <-chan struct{}) (<-chan []byte, error)
 	Flush() (error)
+	Ping() (error)
+	Transact(maxAttempts int, fn func(tx Tx) error) (error)
 }
 
 // Define Database data as struct
 type Database struct {
 	Rejson *rejson.Handler
 	Goredis *goredis.Client
+	tx *redisTx // set when bound to a transaction with Tx.Bind
 }
 
 // Define methods for Database struct so it implements InteractiveDB interface
@@ -45,6 +58,10 @@ type Database struct {
 func (db Database) SetJsonData(key string, path string, data interface{}) error {
 	log.Debug.Printf("New attempt JsonSetData")
 	log.Debug.Printf("Key: '%s', Path: '%s', Data:\n%s", key, path, data)
+	if db.tx != nil {
+		// Buffer until transaction exec
+		return db.tx.setJsonData(db.Goredis.Options().DB, key, path, data)
+	}
 	
 	// Attempt jsonset for key and path with data
 	res, err := db.Rejson.JSONSet(key, path, data)
@@ -68,8 +85,15 @@ func (db Database) SetJsonData(key string, path string, data interface{}) error
 func (db Database) GetJsonData(key string, path string) ([]uint8, error) {
 	log.Debug.Printf("New attempt GetJsonData")
 	log.Debug.Printf("Key: '%s', Path: '%s'", key, path)
-	// return bytevalue of jsonget for path at key
-	dataJSON, err := Bytes(db.Rejson.JSONGet(key, path))
+	var dataJSON []byte
+	var err error
+	if db.tx != nil {
+		// Watch and read on transaction connection
+		dataJSON, err = db.tx.getJsonData(db.Goredis.Options().DB, key, path)
+	} else {
+		// return bytevalue of jsonget for path at key
+		dataJSON, err = Bytes(db.Rejson.JSONGet(key, path))
+	}
 	if err != nil {
 		log.Debug.Printf("Failed to JSONGet (key: %s, path: %s), reason: '%v'", key, path, err)
 		return nil, err
@@ -83,8 +107,15 @@ func (db Database) GetJsonData(key string, path string) ([]uint8, error) {
 func (db Database) MGetJsonData(path string, keys []string) ([][]uint8, error) {
 	log.Debug.Printf("New attempt MGetJsonData")
 	log.Debug.Printf("Keys: '%s', Path: '%s'", keys, path)
-	// return bytevalue of jsonget for path at key
-	data, err := db.Rejson.JSONMGet(path, keys...)
+	var data interface{}
+	var err error
+	if db.tx != nil {
+		// Watch and read on transaction connection
+		data, err = db.tx.mGetJsonData(db.Goredis.Options().DB, path, keys)
+	} else {
+		// return bytevalue of jsonget for path at key
+		data, err = db.Rejson.JSONMGet(path, keys...)
+	}
 	if err != nil {
 		log.Debug.Printf("Failed to JSONMGet (keys: %s, path: %s), reason: '%v'", keys, path, err)
 		return nil, err
@@ -113,6 +144,11 @@ func (db Database) MGetJsonData(path string, keys []string) ([][]uint8, error) {
 func (db Database) DelJsonData(key string, path string) (int64, error) {
 	log.Debug.Printf("New attempt DelJsonData")
 	log.Debug.Printf("Key: '%s', Path: '%s'", key, path)
+	if db.tx != nil {
+		// Buffer until transaction exec
+		db.tx.delJsonData(db.Goredis.Options().DB, key, path)
+		return 1, nil
+	}
 	res, err := db.Rejson.JSONDel(key, path)
 	if err != nil {
 		log.Debug.Printf("Failed to JSONDel reason: %v", err)
@@ -121,6 +157,141 @@ func (db Database) DelJsonData(key string, path string) (int64, error) {
 	return res.(int64), nil
 }
 
+// Get keys matching glob pattern using SCAN, so the server is not blocked on large DBs.
+//
+// Keys are not watched by transactions, read each key through a bound database to watch it
+func (db Database) Keys(pattern string) ([]string, error) {
+	log.Debug.Printf("New attempt Keys")
+	log.Debug.Printf("Pattern: '%s'", pattern)
+	ctx := context.Background()
+	keys := make([]string, 0)
+	cursor := uint64(0)
+	for {
+		batch, next, err := db.Goredis.Scan(ctx, cursor, pattern, 100).Result()
+		if err != nil {
+			log.Debug.Printf("Failed to SCAN (pattern: %s), reason: '%v'", pattern, err)
+			return nil, err
+		}
+		keys = append(keys, batch...)
+		cursor = next
+		if cursor == 0 {
+			return keys, nil
+		}
+	}
+}
+
+// Add member to the sorted set at key with score, replacing its score if already a member
+func (db Database) ZAdd(key string, member string, score int64) error {
+	log.Debug.Printf("New attempt ZAdd")
+	log.Debug.Printf("Key: '%s', Member: '%s', Score: %d", key, member, score)
+	if db.tx != nil {
+		// Buffer until transaction exec
+		db.tx.writes = append(db.tx.writes, txWrite{dbNum: db.Goredis.Options().DB, key: key, sorted: true, member: member, score: score})
+		return nil
+	}
+	if err := db.Goredis.ZAdd(context.Background(), key, &goredis.Z{Score: float64(score), Member: member}).Err(); err != nil {
+		log.Debug.Printf("Failed to ZAdd (key: %s, member: %s), reason: '%v'", key, member, err)
+		return err
+	}
+	return nil
+}
+
+// Remove member from the sorted set at key
+func (db Database) ZRem(key string, member string) error {
+	log.Debug.Printf("New attempt ZRem")
+	log.Debug.Printf("Key: '%s', Member: '%s'", key, member)
+	if db.tx != nil {
+		// Buffer until transaction exec
+		db.tx.writes = append(db.tx.writes, txWrite{dbNum: db.Goredis.Options().DB, key: key, sorted: true, member: member, del: true})
+		return nil
+	}
+	if err := db.Goredis.ZRem(context.Background(), key, member).Err(); err != nil {
+		log.Debug.Printf("Failed to ZRem (key: %s, member: %s), reason: '%v'", key, member, err)
+		return err
+	}
+	return nil
+}
+
+// Get members of the sorted set at key with score up to and including max, lowest score first.
+//
+// Sorted sets are not watched by transactions, and reads do not include writes buffered by a bound database
+func (db Database) ZRangeByScore(key string, max int64) ([]string, error) {
+	members, err := db.Goredis.ZRangeByScore(context.Background(), key, &goredis.ZRangeBy{Min: "-inf", Max: fmt.Sprintf("%d", max)}).Result()
+	if err != nil {
+		log.Debug.Printf("Failed to ZRangeByScore (key: %s), reason: '%v'", key, err)
+		return nil, err
+	}
+	return members, nil
+}
+
+// Increments the counter at key in one round trip, starting its window when the counter is created or has no expiry
+var incrScript = goredis.NewScript(`
+local count = redis.call("INCR", KEYS[1])
+if count == 1 or redis.call("PTTL", KEYS[1]) < 0 then
+	redis.call("PEXPIRE", KEYS[1], ARGV[1])
+end
+return {count, redis.call("PTTL", KEYS[1])}
+`)
+
+// Increment the counter at key, which expires window after its first increment.
+//
+// Returns the count and the time until the counter expires. Counters are not part of transactions, so bound databases increment immediately
+func (db Database) Incr(key string, window time.Duration) (int64, time.Duration, error) {
+	res, err := incrScript.Run(context.Background(), db.Goredis, []string{key}, window.Milliseconds()).Slice()
+	if err != nil {
+		log.Debug.Printf("Failed to Incr (key: %s), reason: '%v'", key, err)
+		return 0, 0, err
+	}
+	count, _ := res[0].(int64)
+	ttl, _ := res[1].(int64)
+	return count, time.Duration(ttl) * time.Millisecond, nil
+}
+
+// Publish message to every subscriber of channel.
+//
+// Channels are shared by every logical DB on the server. Publishing is not part of transactions, so bound databases publish immediately
+func (db Database) Publish(channel string, message []byte) error {
+	if err := db.Goredis.Publish(context.Background(), channel, message).Err(); err != nil {
+		log.Debug.Printf("Failed to Publish (channel: %s), reason: '%v'", channel, err)
+		return err
+	}
+	return nil
+}
+
+// Subscribe to messages published to channel from when Subscribe returns until stop is closed, then the returned channel is closed
+func (db Database) Subscribe(channel string, stop <-chan struct{}) (<-chan []byte, error) {
+	ctx := context.Background()
+	pubsub := db.Goredis.Subscribe(ctx, channel)
+	// Wait for the subscription to be confirmed, so no message published after returning is missed
+	if _, err := pubsub.Receive(ctx); err != nil {
+		log.Debug.Printf("Failed to Subscribe (channel: %s), reason: '%v'", channel, err)
+		pubsub.Close()
+		return nil, err
+	}
+	messages := make(chan []byte)
+	go func() {
+		defer close(messages)
+		defer pubsub.Close()
+		received := pubsub.Channel()
+		for {
+			select {
+			case <-stop:
+				return
+			case msg, ok := <-received:
+				if !ok {
+					return
+				}
+				select {
+				case messages <- []byte(msg.Payload):
+				case <-stop:
+					return
+				}
+			}
+		}
+	}()
+	return messages, nil
+}
+
 // Flush database using Goredis
 func (db Database) Flush() error {
 	if err := db.Goredis.FlushDB(context.Background()).Err(); err != nil {
@@ -135,6 +306,11 @@ func (db Database) Flush() error {
 	}
 }
 
+// Ping redis server using Goredis
+func (db Database) Ping() error {
+	return db.Goredis.Ping(context.Background()).Err()
+}
+
 var ErrNil = errors.New("db.go: nil returned")
 
 // Copied from github.com/redigo/redis
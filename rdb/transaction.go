@@ -0,0 +1,310 @@
+package rdb
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"apricate/log"
+
+	goredis "github.com/go-redis/redis/v8"
+)
+
+// Returned by Transact when every attempt conflicted with a concurrent write
+var ErrTxConflict = errors.New("rdb: transaction conflicted on every attempt")
+
+// Returned from a transaction func to abort without writing or retrying
+var ErrTxAborted = errors.New("rdb: transaction aborted")
+
+// Define a write buffered until a transaction is executed
+type txWrite struct {
+	dbNum int
+	key string
+	path string
+	data []byte // nil for deletes
+	del bool
+	sorted bool // set for writes of member to the sorted set at key, path and data unused
+	member string
+	score int64
+}
+
+// Define an optimistic transaction across keys in several logical DBs.
+//
+// Reads through a bound database are tracked, writes are buffered and applied together
+// on exec, which fails if any tracked key was changed in the meantime
+type Tx interface {
+	Bind(db InteractiveDB) InteractiveDB
+	BindAll(dbs map[string]InteractiveDB) map[string]InteractiveDB
+}
+
+// Define an optimistic transaction across keys in any logical DB on the same redis server.
+//
+// Reads through a bound Database WATCH their keys on a single connection, writes are buffered
+// and applied with MULTI/EXEC, which fails if any watched key was changed in the meantime
+type redisTx struct {
+	ctx context.Context
+	conn *goredis.Conn
+	homeDB int
+	selected int
+	writes []txWrite
+}
+
+// Bind a database to this transaction, so reads are watched and writes are buffered
+func (tx *redisTx) Bind(db InteractiveDB) InteractiveDB {
+	redisDB, ok := db.(Database)
+	if !ok {
+		log.Error.Printf("Cannot bind %T to a redis transaction, using it unbound", db)
+		return db
+	}
+	return Database{
+		Rejson: redisDB.Rejson,
+		Goredis: redisDB.Goredis,
+		tx: tx,
+	}
+}
+
+// Bind every database in the map to this transaction
+func (tx *redisTx) BindAll(dbs map[string]InteractiveDB) map[string]InteractiveDB {
+	res := make(map[string]InteractiveDB, len(dbs))
+	for name, db := range dbs {
+		res[name] = tx.Bind(db)
+	}
+	return res
+}
+
+// Select logical DB on the transaction connection if not already selected
+func (tx *redisTx) selectDB(dbNum int) error {
+	if tx.selected == dbNum {
+		return nil
+	}
+	if err := tx.conn.Select(tx.ctx, dbNum).Err(); err != nil {
+		return err
+	}
+	tx.selected = dbNum
+	return nil
+}
+
+// Run a command on the transaction connection in the given logical DB
+func (tx *redisTx) do(dbNum int, args ...interface{}) (interface{}, error) {
+	if err := tx.selectDB(dbNum); err != nil {
+		return nil, err
+	}
+	cmd := goredis.NewCmd(tx.ctx, args...)
+	tx.conn.Process(tx.ctx, cmd)
+	return cmd.Result()
+}
+
+// Get buffered writes for key since its last write at root, in order.
+// bool is whether that root write exists, in which case the stored document is replaced and need not be read
+func pendingWrites(writes []txWrite, dbNum int, key string) ([]txWrite, bool) {
+	for i := len(writes) - 1; i >= 0; i-- {
+		if writes[i].dbNum != dbNum || writes[i].key != key || writes[i].sorted {
+			continue
+		}
+		if segments, err := parsePath(writes[i].path); err == nil && len(segments) == 0 {
+			res := []txWrite{writes[i]}
+			for _, write := range writes[i+1:] {
+				if write.dbNum == dbNum && write.key == key && !write.sorted {
+					res = append(res, write)
+				}
+			}
+			return res, true
+		}
+	}
+	res := make([]txWrite, 0)
+	for _, write := range writes {
+		if write.dbNum == dbNum && write.key == key && !write.sorted {
+			res = append(res, write)
+		}
+	}
+	return res, false
+}
+
+// Get json at path of the document as it will be once writes are applied over stored, the stored json or nil if the key does not exist.
+// Writes are applied like RedisJSON would on exec, so reads in a transaction see its own writes at any path
+func readPendingWrites(stored []byte, writes []txWrite, path string) ([]uint8, error) {
+	var document interface{}
+	found := stored != nil
+	if found {
+		var err error
+		if document, err = decodeJson(stored); err != nil {
+			return nil, err
+		}
+	}
+	for _, write := range writes {
+		segments, err := parsePath(write.path)
+		if err != nil {
+			return nil, err
+		}
+		if write.del {
+			if len(segments) == 0 {
+				document, found = nil, false
+			} else if found {
+				delAtPath(document, segments)
+			}
+			continue
+		}
+		value, err := decodeJson(write.data)
+		if err != nil {
+			return nil, err
+		}
+		if len(segments) == 0 {
+			document, found = value, true
+			continue
+		}
+		if !found {
+			return nil, errors.New("ERR new objects must be created at the root")
+		}
+		if err := setAtPath(document, segments, value); err != nil {
+			return nil, err
+		}
+	}
+	if !found {
+		return nil, goredis.Nil
+	}
+	segments, err := parsePath(path)
+	if err != nil {
+		return nil, err
+	}
+	value, ok := getAtPath(document, segments)
+	if !ok {
+		return nil, fmt.Errorf("ERR Path '%s' does not exist", path)
+	}
+	return json.Marshal(value)
+}
+
+// Watch keys then get json data for key at path, applying any writes this transaction buffered for the key
+func (tx *redisTx) getJsonData(dbNum int, key string, path string) ([]uint8, error) {
+	writes, replaced := pendingWrites(tx.writes, dbNum, key)
+	if replaced {
+		return readPendingWrites(nil, writes, path)
+	}
+	if _, err := tx.do(dbNum, "WATCH", key); err != nil {
+		return nil, err
+	}
+	if len(writes) == 0 {
+		return Bytes(tx.do(dbNum, "JSON.GET", key, path))
+	}
+	// Read the whole stored document so the writes can be applied over it
+	stored, err := Bytes(tx.do(dbNum, "JSON.GET", key, "."))
+	if err == ErrNil || err == goredis.Nil {
+		stored, err = nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return readPendingWrites(stored, writes, path)
+}
+
+// Watch keys then get json data at path for multiple keys
+func (tx *redisTx) mGetJsonData(dbNum int, path string, keys []string) (interface{}, error) {
+	watchArgs := []interface{}{"WATCH"}
+	mgetArgs := []interface{}{"JSON.MGET"}
+	for _, key := range keys {
+		watchArgs = append(watchArgs, key)
+		mgetArgs = append(mgetArgs, key)
+	}
+	mgetArgs = append(mgetArgs, path)
+	if _, err := tx.do(dbNum, watchArgs...); err != nil {
+		return nil, err
+	}
+	data, err := tx.do(dbNum, mgetArgs...)
+	if err != nil {
+		return nil, err
+	}
+	// Replace entries for keys this transaction wrote with the buffered result, nil where it no longer exists at path
+	if entries, ok := data.([]interface{}); ok {
+		for i, key := range keys {
+			if writes, _ := pendingWrites(tx.writes, dbNum, key); len(writes) > 0 && i < len(entries) {
+				pending, pendingErr := tx.getJsonData(dbNum, key, path)
+				if pendingErr != nil {
+					entries[i] = nil
+				} else {
+					entries[i] = string(pending)
+				}
+			}
+		}
+	}
+	return data, nil
+}
+
+// Buffer json data to be set for key at path on exec
+func (tx *redisTx) setJsonData(dbNum int, key string, path string, data interface{}) error {
+	dataJSON, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	tx.writes = append(tx.writes, txWrite{dbNum: dbNum, key: key, path: path, data: dataJSON})
+	return nil
+}
+
+// Buffer deletion of key at path on exec
+func (tx *redisTx) delJsonData(dbNum int, key string, path string) {
+	tx.writes = append(tx.writes, txWrite{dbNum: dbNum, key: key, path: path, del: true})
+}
+
+// Apply buffered writes atomically, returns goredis.TxFailedErr if a watched key changed
+func (tx *redisTx) exec() error {
+	if len(tx.writes) == 0 {
+		_, err := tx.do(tx.selected, "UNWATCH")
+		return err
+	}
+	_, err := tx.conn.TxPipelined(tx.ctx, func(pipe goredis.Pipeliner) error {
+		for _, write := range tx.writes {
+			pipe.Select(tx.ctx, write.dbNum)
+			if write.sorted && write.del {
+				pipe.ZRem(tx.ctx, write.key, write.member)
+			} else if write.sorted {
+				pipe.ZAdd(tx.ctx, write.key, &goredis.Z{Score: float64(write.score), Member: write.member})
+			} else if write.del {
+				pipe.Do(tx.ctx, "JSON.DEL", write.key, write.path)
+			} else {
+				pipe.Do(tx.ctx, "JSON.SET", write.key, write.path, string(write.data))
+			}
+		}
+		return nil
+	})
+	// SELECT inside MULTI only applies if EXEC ran, so selection is unknown now
+	tx.selected = -1
+	return err
+}
+
+// Return the connection to its pool with the original DB selected
+func (tx *redisTx) close() {
+	if err := tx.conn.Select(tx.ctx, tx.homeDB).Err(); err != nil {
+		log.Error.Printf("Could not restore DB %d on transaction connection: %v", tx.homeDB, err)
+	}
+	tx.conn.Close()
+}
+
+// Run fn in an optimistic transaction anchored on db, retrying up to maxAttempts times when a watched key is changed concurrently.
+//
+// fn should read and write only through databases bound with tx.Bind or tx.BindAll.
+// If fn returns an error nothing is written and the error is returned without retrying
+func (db Database) Transact(maxAttempts int, fn func(tx Tx) error) error {
+	ctx := context.Background()
+	homeDB := db.Goredis.Options().DB
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		tx := &redisTx{
+			ctx: ctx,
+			conn: db.Goredis.Conn(ctx),
+			homeDB: homeDB,
+			selected: homeDB,
+			writes: make([]txWrite, 0),
+		}
+		if fnErr := fn(tx); fnErr != nil {
+			tx.do(tx.selected, "UNWATCH")
+			tx.close()
+			return fnErr
+		}
+		execErr := tx.exec()
+		tx.close()
+		if execErr == goredis.TxFailedErr {
+			log.Debug.Printf("Transaction conflict on attempt %d of %d, retrying", attempt, maxAttempts)
+			continue
+		}
+		return execErr
+	}
+	return ErrTxConflict
+}
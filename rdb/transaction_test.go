@@ -0,0 +1,89 @@
+package rdb
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+// Reads in a transaction see its own writes at any path, and are retried when a read key changes before commit
+func TestTransactions(t *testing.T) {
+	db := NewMemoryDatabase(NewMemoryStore(), 0)
+	if err := db.SetJsonData("Farm", ".", map[string]interface{}{"plots": map[string]int{"a": 1}, "name": "Farm"}); err != nil {
+		t.Fatalf("could not seed farm: %v", err)
+	}
+	get := func(db InteractiveDB, key string, path string) interface{} {
+		t.Helper()
+		data, err := db.GetJsonData(key, path)
+		if err != nil {
+			t.Fatalf("could not get %s at %s: %v", key, path, err)
+		}
+		var value interface{}
+		json.Unmarshal(data, &value)
+		return value
+	}
+
+	// Read your writes, at sub paths over the stored document and after writes at root
+	txErr := db.Transact(1, func(tx Tx) error {
+		txdb := tx.Bind(db)
+		txdb.SetJsonData("Farm", "plots", map[string]int{"b": 2})
+		if plots := get(txdb, "Farm", "."); !reflect.DeepEqual(plots, map[string]interface{}{"plots": map[string]interface{}{"b": float64(2)}, "name": "Farm"}) {
+			t.Fatalf("expected sub path write applied over stored farm, got %v", plots)
+		}
+		txdb.SetJsonData("Farm", `plots["c"]`, 3)
+		txdb.DelJsonData("Farm", "name")
+		if plot := get(txdb, "Farm", "plots.c"); plot != float64(3) {
+			t.Fatalf("expected nested write to be read back, got %v", plot)
+		}
+		if _, err := txdb.GetJsonData("Farm", "name"); err == nil {
+			t.Fatalf("expected deleted path to be missing")
+		}
+		txdb.SetJsonData("New", ".", map[string]int{"n": 1})
+		txdb.SetJsonData("New", "n", 2)
+		if multi, err := txdb.MGetJsonData("n", []string{"New", "Missing"}); err != nil || string(multi[0]) != "2" || multi[1] != nil {
+			t.Fatalf("expected multi get to read buffered writes, got %q: %v", multi, err)
+		}
+		if stored := get(db, "Farm", "plots"); !reflect.DeepEqual(stored, map[string]interface{}{"a": float64(1)}) {
+			t.Fatalf("expected writes buffered until commit, got %v", stored)
+		}
+		return nil
+	})
+	if txErr != nil {
+		t.Fatalf("transaction failed: %v", txErr)
+	}
+	if farm := get(db, "Farm", "."); !reflect.DeepEqual(farm, map[string]interface{}{"plots": map[string]interface{}{"b": float64(2), "c": float64(3)}}) {
+		t.Fatalf("expected committed farm to match what the transaction read, got %v", farm)
+	}
+
+	// Conflicting write to a read key retries the transaction, which then sees the new value
+	attempts := 0
+	txErr = db.Transact(3, func(tx Tx) error {
+		attempts++
+		txdb := tx.Bind(db)
+		count := get(txdb, "New", "n").(float64)
+		if attempts == 1 {
+			db.SetJsonData("New", "n", 10)
+		}
+		return txdb.SetJsonData("New", "n", count + 1)
+	})
+	if txErr != nil || attempts != 2 {
+		t.Fatalf("expected conflict to be retried once, attempts: %d, error: %v", attempts, txErr)
+	}
+	if count := get(db, "New", "n"); count != float64(11) {
+		t.Fatalf("expected retried increment of concurrent write, got %v", count)
+	}
+
+	// Giving up once every attempt conflicts, without writing
+	txErr = db.Transact(2, func(tx Tx) error {
+		txdb := tx.Bind(db)
+		count := get(txdb, "New", "n").(float64)
+		db.SetJsonData("New", "n", count + 100)
+		return txdb.SetJsonData("New", "n", 0)
+	})
+	if txErr != ErrTxConflict {
+		t.Fatalf("expected ErrTxConflict, got %v", txErr)
+	}
+	if count := get(db, "New", "n"); count != float64(211) {
+		t.Fatalf("expected only the concurrent writes to apply, got %v", count)
+	}
+}
@@ -56,6 +56,18 @@ const (
 	Caravan_Not_Arrived ResponseCode = 29
 	Specified_Rite_Not_Found ResponseCode = 30
 	Object_Not_Found ResponseCode = 31
+	Contract_Terms_Not_Met ResponseCode = 32
+	Transaction_Conflict ResponseCode = 33
+	Specified_Building_Not_Found ResponseCode = 34
+	Construction_Not_Allowed ResponseCode = 35
+	Warehouse_Capacity_Exceeded ResponseCode = 36
+	Specified_Recipe_Not_Found ResponseCode = 37
+	Crafting_Not_Allowed ResponseCode = 38
+	Hire_Not_Allowed ResponseCode = 39
+	Specified_Improvement_Not_Found ResponseCode = 40
+	Improvement_Not_Allowed ResponseCode = 41
+	Recall_Not_Allowed ResponseCode = 42
+	Rate_Limit_Exceeded ResponseCode = 43
 )
 
 // Defines Response structure for output
@@ -210,6 +222,54 @@ var ResponseMap = map[ResponseCode]ResponseConfig{
 		Message: "[Object_Not_Found] The specified object was not found, ensure the symbol is correct and object is not hidden by fog of war",
 		HttpResponse: http.StatusNotFound,
 	},
+	Contract_Terms_Not_Met: {
+		Message: "[Contract_Terms_Not_Met] The terms of the specified contract have not been met, ensure an assistant is at the contract location and the required items are in the local warehouse",
+		HttpResponse: http.StatusConflict,
+	},
+	Transaction_Conflict: {
+		Message: "[Transaction_Conflict] The request conflicted with concurrent changes to the same objects and could not be applied, please try again",
+		HttpResponse: http.StatusConflict,
+	},
+	Specified_Building_Not_Found: {
+		Message: "[Specified_Building_Not_Found] The specified building was not found in the master dictionary",
+		HttpResponse: http.StatusNotFound,
+	},
+	Construction_Not_Allowed: {
+		Message: "[Construction_Not_Allowed] The building cannot be built or upgraded, ensure nothing else is under construction on the farm, the building is below max level, required buildings are built, and the costs are in the ledger and local warehouse",
+		HttpResponse: http.StatusConflict,
+	},
+	Warehouse_Capacity_Exceeded: {
+		Message: "[Warehouse_Capacity_Exceeded] The local warehouse does not have room for the incoming items, make room by selling, planting, or moving items, or raise its capacity with buildings such as a Silo or Barn",
+		HttpResponse: http.StatusConflict,
+	},
+	Specified_Recipe_Not_Found: {
+		Message: "[Specified_Recipe_Not_Found] The specified recipe was not found in the master dictionary",
+		HttpResponse: http.StatusNotFound,
+	},
+	Crafting_Not_Allowed: {
+		Message: "[Crafting_Not_Allowed] The recipe cannot be crafted or collected, ensure the farm's buildings unlock the recipe category, nothing else is being crafted on the farm, the materials are in the local warehouse, and crafting is complete before collecting",
+		HttpResponse: http.StatusConflict,
+	},
+	Hire_Not_Allowed: {
+		Message: "[Hire_Not_Allowed] The assistant cannot be hired, ensure the market hires the specified archetype and the fee is in the ledger",
+		HttpResponse: http.StatusConflict,
+	},
+	Specified_Improvement_Not_Found: {
+		Message: "[Specified_Improvement_Not_Found] The specified improvement was not found in the master dictionary",
+		HttpResponse: http.StatusNotFound,
+	},
+	Improvement_Not_Allowed: {
+		Message: "[Improvement_Not_Allowed] The assistant cannot take the improvement, ensure the assistant is not travelling, the improvement allows its archetype and is below max level, and the costs are in the ledger and the warehouse where the assistant is",
+		HttpResponse: http.StatusConflict,
+	},
+	Recall_Not_Allowed: {
+		Message: "[Recall_Not_Allowed] The caravan cannot be recalled, ensure it has not already arrived and is not already returning from a recall",
+		HttpResponse: http.StatusConflict,
+	},
+	Rate_Limit_Exceeded: {
+		Message: "[Rate_Limit_Exceeded] Too many requests, wait until the time in the X-RateLimit-Reset header before retrying",
+		HttpResponse: http.StatusTooManyRequests,
+	},
 }
 
 // Returns the prettified json string of a properly structure api response given the inputs
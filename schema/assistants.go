@@ -7,6 +7,8 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"strconv"
+	"strings"
 )
 
 // enum for assistant types
@@ -65,8 +67,38 @@ func NewAssistant(username string, countOfUserAssistants int, archetype Assistan
 	}
 }
 
+// Get the id for a user's next assistant, one past the highest id among the given assistant uuids
+func NextAssistantID(uuids []string) int {
+	next := 0
+	for _, uuid := range uuids {
+		idx := strings.LastIndex(uuid, "|Assistant-")
+		if idx < 0 {
+			continue
+		}
+		id, err := strconv.Atoi(uuid[idx+len("|Assistant-"):])
+		if err == nil && id >= next {
+			next = id + 1
+		}
+	}
+	return next
+}
+
+// Archetypes markets may hire out for coins. Golem, Oni and Dragon are only summoned through rites,
+// which charge coins, materials and flux and require a Summoning Circle
+var HireableAssistantTypes = map[AssistantTypes]bool{
+	Imp: true,
+	Familiar: true,
+	Sprite: true,
+}
+
+// Get the archetype with the given name, bool is archetype found
+func GetAssistantType(name string) (AssistantTypes, bool) {
+	archetype, ok := assistantTypesToID[name]
+	return archetype, ok
+}
+
 // Check DB for existing assistant with given uuid and return bool for if exists, and error if error encountered
-func CheckForExistingAssistant (uuid string, tdb rdb.Database) (bool, error) {
+func CheckForExistingAssistant (uuid string, tdb rdb.InteractiveDB) (bool, error) {
 	// Get assistant
 	_, getError := tdb.GetJsonData(uuid, ".")
 	if getError != nil {
@@ -82,7 +114,7 @@ func CheckForExistingAssistant (uuid string, tdb rdb.Database) (bool, error) {
 }
 
 // Get assistant from DB, bool is assistant found
-func GetAssistantFromDB (uuid string, tdb rdb.Database) (Assistant, bool, error) {
+func GetAssistantFromDB (uuid string, tdb rdb.InteractiveDB) (Assistant, bool, error) {
 	// Get assistant json
 	someJson, getError := tdb.GetJsonData(uuid, ".")
 	if getError != nil {
@@ -104,7 +136,7 @@ func GetAssistantFromDB (uuid string, tdb rdb.Database) (Assistant, bool, error)
 }
 
 // Get assistant from DB, bool is assistant found
-func GetAssistantsFromDB (uuids []string, tdb rdb.Database) (map[string]Assistant, bool, error) {
+func GetAssistantsFromDB (uuids []string, tdb rdb.InteractiveDB) (map[string]Assistant, bool, error) {
 	// Get assistant json
 	someJson, getError := tdb.MGetJsonData(".", uuids)
 	if getError != nil {
@@ -131,7 +163,7 @@ func GetAssistantsFromDB (uuids []string, tdb rdb.Database) (map[string]Assistan
 }
 
 // Get assistantdata at path from DB, bool is assistant found
-func GetAssistantDataAtPathFromDB (uuid string, path string, tdb rdb.Database) (interface{}, bool, error) {
+func GetAssistantDataAtPathFromDB (uuid string, path string, tdb rdb.InteractiveDB) (interface{}, bool, error) {
 	// Get assistant json
 	someJson, getError := tdb.GetJsonData(uuid, path)
 	if getError != nil {
@@ -153,7 +185,7 @@ func GetAssistantDataAtPathFromDB (uuid string, path string, tdb rdb.Database) (
 }
 
 // Attempt to save assistant, returns error or nil if successful
-func SaveAssistantToDB(tdb rdb.Database, assistantData *Assistant) error {
+func SaveAssistantToDB(tdb rdb.InteractiveDB, assistantData *Assistant) error {
 	log.Debug.Printf("Saving assistant %s to DB", assistantData.UUID)
 	err := tdb.SetJsonData(assistantData.UUID, ".", assistantData)
 	// creationSuccess := rdb.CreateAssistant(tdb, assistantname, uuid, 0)
@@ -161,7 +193,7 @@ func SaveAssistantToDB(tdb rdb.Database, assistantData *Assistant) error {
 }
 
 // Attempt to save assistant data at path, returns error or nil if successful
-func SaveAssistantDataAtPathToDB(tdb rdb.Database, uuid string, path string, newValue interface{}) error {
+func SaveAssistantDataAtPathToDB(tdb rdb.InteractiveDB, uuid string, path string, newValue interface{}) error {
 	log.Debug.Printf("Saving assistant data at path %s to DB for uuid %s", path, uuid)
 	err := tdb.SetJsonData(uuid, path, newValue)
 	return err
@@ -207,4 +239,17 @@ func (s *AssistantTypes) UnmarshalJSON(b []byte) error {
 	// Note that if the string cannot be found then it will be set to the zero value, 'Created' in this case.
 	*s = assistantTypesToID[j]
 	return nil
-}
\ No newline at end of file
+}
+// UnmarshalYAML unmashals a quoted yaml string to the enum value
+func (s *AssistantTypes) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var j string
+	if err := unmarshal(&j); err != nil {
+		return err
+	}
+	archetype, ok := assistantTypesToID[j]
+	if !ok {
+		return fmt.Errorf("unknown assistant archetype %s", j)
+	}
+	*s = archetype
+	return nil
+}
@@ -2,10 +2,144 @@
 package schema
 
 import (
+	"apricate/filemngr"
+	"apricate/log"
 	"bytes"
 	"encoding/json"
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
 )
 
+// Define building dictionary entry, levels are in order so Levels[0] is level 1
+type BuildingDefinition struct {
+	Name string `yaml:"Name" json:"name" binding:"required"`
+	Description string `yaml:"Description" json:"description" binding:"required"`
+	Levels []BuildingLevel `yaml:"Levels" json:"levels" binding:"required"`
+}
+
+// Define the cost and effects of a building level, effects are those of the building at that level, not in addition to lower levels
+type BuildingLevel struct {
+	Currencies map[string]uint64 `yaml:"Currencies" json:"currencies,omitempty"`
+	Materials Wareset `yaml:"Materials" json:"materials" binding:"required"`
+	ConstructionTime int `yaml:"ConstructionTime" json:"construction_time" binding:"required"` // seconds
+	RequiredBuildings map[string]uint8 `yaml:"RequiredBuildings" json:"required_buildings,omitempty"`
+	Effects BuildingEffects `yaml:"Effects" json:"effects" binding:"required"`
+}
+
+// Define the effects a building level has on its farm
+type BuildingEffects struct {
+	Description string `yaml:"Description" json:"description" binding:"required"`
+	WarehouseCapacity uint64 `yaml:"WarehouseCapacity" json:"warehouse_capacity,omitempty"` // added to the capacity of the farm's warehouse
+	RecipeCategories []string `yaml:"RecipeCategories" json:"recipe_categories,omitempty"` // categories of crafting recipes which may be made at the farm
+	Livestock []string `yaml:"Livestock" json:"livestock,omitempty"` // livestock plants which may be raised in the farm's plots
+	SurveyScope SurveyScope `yaml:"SurveyScope" json:"survey_scope,omitempty"` // markets whose prices are visible from the farm without an assistant present
+}
+
+// Defines the combined effects of all buildings on a farm
+type FarmEffects struct {
+	WarehouseCapacity uint64 `json:"warehouse_capacity" binding:"required"`
+	RecipeCategories []string `json:"recipe_categories" binding:"required"`
+	Livestock []string `json:"livestock" binding:"required"`
+	SurveyScope SurveyScope `json:"survey_scope" binding:"required"`
+}
+
+// Check if the farm may craft recipes of category
+func (e *FarmEffects) HasRecipeCategory(category string) bool {
+	for _, c := range e.RecipeCategories {
+		if c == category {
+			return true
+		}
+	}
+	return false
+}
+
+// Check if the farm may raise the named livestock
+func (e *FarmEffects) HasLivestock(plantName string) bool {
+	for _, l := range e.Livestock {
+		if l == plantName {
+			return true
+		}
+	}
+	return false
+}
+
+// Get the combined effects of the buildings on a farm from the effects of each building's current level
+func GetFarmEffects(buildings map[BuildingTypes]uint8, dictionary map[string]BuildingDefinition) FarmEffects {
+	effects := FarmEffects{
+		RecipeCategories: make([]string, 0),
+		Livestock: make([]string, 0),
+		SurveyScope: Survey_None,
+	}
+	for building, level := range buildings {
+		buildingDef, ok := dictionary[building.String()]
+		if !ok {
+			continue
+		}
+		levelDef, ok := buildingDef.Level(level)
+		if !ok {
+			continue
+		}
+		effects.WarehouseCapacity += levelDef.Effects.WarehouseCapacity
+		for _, category := range levelDef.Effects.RecipeCategories {
+			if !effects.HasRecipeCategory(category) {
+				effects.RecipeCategories = append(effects.RecipeCategories, category)
+			}
+		}
+		for _, livestock := range levelDef.Effects.Livestock {
+			if !effects.HasLivestock(livestock) {
+				effects.Livestock = append(effects.Livestock, livestock)
+			}
+		}
+		if levelDef.Effects.SurveyScope > effects.SurveyScope {
+			effects.SurveyScope = levelDef.Effects.SurveyScope
+		}
+	}
+	return effects
+}
+
+// Get the highest level the building can be built to
+func (b *BuildingDefinition) MaxLevel() uint8 {
+	return uint8(len(b.Levels))
+}
+
+// Get the definition of level, bool is level defined
+func (b *BuildingDefinition) Level(level uint8) (BuildingLevel, bool) {
+	if level < 1 || level > b.MaxLevel() {
+		return BuildingLevel{}, false
+	}
+	return b.Levels[level - 1], true
+}
+
+// Define a building under construction on a farm
+type BuildingConstruction struct {
+	Building BuildingTypes `json:"building" binding:"required"`
+	Level uint8 `json:"level" binding:"required"`
+	CompleteTimestamp int64 `json:"complete_timestamp" binding:"required"`
+}
+
+// Load building struct by unmarhsalling given yaml file
+func Buildings_load(path_to_buildings_yaml string) map[string]BuildingDefinition {
+	buildingsBytes, readErr := filemngr.ReadFileToBytes(path_to_buildings_yaml)
+	if readErr != nil {
+		// Essential to server start
+		panic(readErr)
+	}
+	var buildings map[string]BuildingDefinition
+	err := yaml.Unmarshal(buildingsBytes, &buildings)
+	if err != nil {
+		log.Error.Fatalln(err)
+	}
+	for name := range buildings {
+		if _, ok := BuildingsToID[name]; !ok {
+			log.Error.Fatalf("Unknown building %s in buildings yaml", name)
+		}
+	}
+	
+	return buildings
+}
+
 // enum for farm bonuses
 type BuildingTypes uint8
 const (
@@ -56,6 +190,22 @@ func (s BuildingTypes) MarshalText() ([]byte, error) {
 
 // UnmarshalJSON unmashals a text string to the enum value
 func (s *BuildingTypes) UnmarshalText(b []byte) error {
+	// Text is unquoted (e.g. map keys), so look it up directly.
+	// Note that if the string cannot be found then it will be set to the zero value, 'Created' in this case.
+	*s = BuildingsToID[string(b)]
+	return nil
+}
+
+// MarshalJSON marshals the enum as a quoted json string
+func (s BuildingTypes) MarshalJSON() ([]byte, error) {
+	buffer := bytes.NewBufferString(`"`)
+	buffer.WriteString(buildingsToString[s])
+	buffer.WriteString(`"`)
+	return buffer.Bytes(), nil
+}
+
+// UnmarshalJSON unmashals a quoted json string to the enum value
+func (s *BuildingTypes) UnmarshalJSON(b []byte) error {
 	var j string
 	err := json.Unmarshal(b, &j)
 	if err != nil {
@@ -66,22 +216,84 @@ func (s *BuildingTypes) UnmarshalText(b []byte) error {
 	return nil
 }
 
+// enum for how far a survey office reveals market prices, wider scopes have higher values
+type SurveyScope uint8
+const (
+	Survey_None SurveyScope = 0
+	Survey_Island SurveyScope = 1
+	Survey_Region SurveyScope = 2
+)
+
+func (s SurveyScope) String() string {
+	return surveyScopesToString[s]
+}
+
+var surveyScopesToString = map[SurveyScope]string {
+	Survey_None: "None",
+	Survey_Island: "Island",
+	Survey_Region: "Region",
+}
+
+var surveyScopesToID = map[string]SurveyScope {
+	"None": Survey_None,
+	"Island": Survey_Island,
+	"Region": Survey_Region,
+}
+
+// Check if a survey from the farm at farmSymbol reaches the location at locationSymbol, symbols are formatted REGION-ISLAND-LOCATION
+func (s SurveyScope) Reaches(farmSymbol string, locationSymbol string) bool {
+	farmSlice := strings.Split(farmSymbol, "-")
+	locationSlice := strings.Split(locationSymbol, "-")
+	shared := 0
+	switch s {
+	case Survey_Island:
+		shared = 2
+	case Survey_Region:
+		shared = 1
+	default:
+		return false
+	}
+	if len(farmSlice) < shared || len(locationSlice) < shared {
+		return false
+	}
+	for i := 0; i < shared; i++ {
+		if farmSlice[i] != locationSlice[i] {
+			return false
+		}
+	}
+	return true
+}
+
 // MarshalJSON marshals the enum as a quoted json string
-func (s BuildingTypes) MarshalJSON() ([]byte, error) {
+func (s SurveyScope) MarshalJSON() ([]byte, error) {
 	buffer := bytes.NewBufferString(`"`)
-	buffer.WriteString(buildingsToString[s])
+	buffer.WriteString(surveyScopesToString[s])
 	buffer.WriteString(`"`)
 	return buffer.Bytes(), nil
 }
 
 // UnmarshalJSON unmashals a quoted json string to the enum value
-func (s *BuildingTypes) UnmarshalJSON(b []byte) error {
+func (s *SurveyScope) UnmarshalJSON(b []byte) error {
 	var j string
 	err := json.Unmarshal(b, &j)
 	if err != nil {
 		return err
 	}
-	// Note that if the string cannot be found then it will be set to the zero value, 'Created' in this case.
-	*s = BuildingsToID[j]
+	// Note that if the string cannot be found then it will be set to the zero value, 'None' in this case.
+	*s = surveyScopesToID[j]
 	return nil
-}
\ No newline at end of file
+}
+
+// UnmarshalYAML unmashals a quoted yaml string to the enum value
+func (s *SurveyScope) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var j string
+	if err := unmarshal(&j); err != nil {
+		return err
+	}
+	scope, ok := surveyScopesToID[j]
+	if !ok {
+		return fmt.Errorf("unknown survey scope %s", j)
+	}
+	*s = scope
+	return nil
+}
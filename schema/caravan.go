@@ -7,6 +7,7 @@ import (
 	"apricate/timecalc"
 	"encoding/json"
 	"fmt"
+	"math"
 	"time"
 )
 
@@ -16,6 +17,8 @@ type CaravanCharter struct {
 	Destination string `json:"destination" binding:"required"`
 	Assistants []int64 `json:"assistants" binding:"required"`
 	Wares Wareset `json:"wares,omitempty"`
+	RoutePriority RoutePriority `json:"route_priority,omitempty"` // FASTEST by default, or CHEAPEST to minimize port fares
+	AutoUnpack bool `json:"auto_unpack,omitempty"` // if set, the caravan is unpacked by the server once it arrives
 }
 
 // Validate caravan charter, return validation map
@@ -33,16 +36,24 @@ func ValidateCaravanCharter(charter CaravanCharter) map[string]string {
 	return res
 }
 
+// Get the carrying capacity of a caravan team, each assistant past the first adds 10% to the team's combined capacity
+func TeamCarryCap(totalCarryCap int, teamSize int) uint64 {
+	teamFactor := 1 + (float64(0.1) * float64(teamSize - 1))
+	return uint64(math.Ceil(float64(totalCarryCap) * teamFactor))
+}
+
 // Defines a caravan
 type Caravan struct {
 	UUID string `json:"uuid" binding:"required"`
 	ID int64 `json:"id" binding:"required"`
 	CaravanCharter `yaml:",inline"`
+	Route RoutePlan `json:"route" binding:"required"`
+	RecalledFrom string `json:"recalled_from,omitempty"` // original destination of a recalled caravan, which now returns to its origin
 	ArrivalTime int64 `json:"arrival_time" binding:"required"`
 	SecondsTillArrival int64 `json:"seconds_till_arrival" binding:"required"` // SHOULD BE STORED AS 0, ONLY FOR FORMATTING RESPONSE
 }
 
-func NewCaravan(UUID string, timestamp time.Time, origin string, destination string, assistants []int64, wares Wareset, travelTimeSeconds int) *Caravan {
+func NewCaravan(UUID string, timestamp time.Time, origin string, destination string, assistants []int64, wares Wareset, autoUnpack bool, route RoutePlan) *Caravan {
 	return &Caravan{
 		UUID: UUID,
 		ID: timestamp.UnixNano(),
@@ -51,14 +62,49 @@ func NewCaravan(UUID string, timestamp time.Time, origin string, destination str
 			Destination: destination,
 			Assistants: assistants,
 			Wares: wares,
+			AutoUnpack: autoUnpack,
 		},
-		ArrivalTime: timecalc.AddSecondsToTimestamp(timestamp, travelTimeSeconds).Unix(),
-		SecondsTillArrival: int64(travelTimeSeconds),
+		Route: route,
+		ArrivalTime: timecalc.AddSecondsToTimestamp(timestamp, route.Duration).Unix(),
+		SecondsTillArrival: int64(route.Duration),
+	}
+}
+
+// Plan the return of a caravan recalled at now, retracing in reverse the legs it has begun for as long as it spent on them.
+// Fares of legs not yet begun are refunded, fares of legs begun are forfeited and the return crossing is not charged again
+// Returns the return route and the fare refunded
+func (c *Caravan) PlanRecall(now int64) (RoutePlan, uint64) {
+	elapsed := int(now - time.Unix(0, c.ID).Unix())
+	if elapsed < 0 {
+		elapsed = 0
+	}
+	recall := RoutePlan{Legs: make([]RouteLeg, 0)}
+	if len(c.Route.Legs) == 0 {
+		// caravan chartered without a planned route, simply retrace the time elapsed
+		recall.Duration = elapsed
+		return recall, 0
+	}
+	refund := uint64(0)
+	legStart := 0
+	for _, leg := range c.Route.Legs {
+		if legStart >= elapsed {
+			refund += leg.Fare
+			continue
+		}
+		travelled := leg.Duration
+		if elapsed - legStart < travelled {
+			travelled = elapsed - legStart
+		}
+		returnLeg := RouteLeg{Origin: leg.Destination, Destination: leg.Origin, IsPortTravel: leg.IsPortTravel, Duration: travelled}
+		recall.Legs = append([]RouteLeg{returnLeg}, recall.Legs...)
+		recall.Duration += travelled
+		legStart += leg.Duration
 	}
+	return recall, refund
 }
 
 // Check DB for existing caravan with given uuid and return bool for if exists, and error if error encountered
-func CheckForExistingCaravan (uuid string, tdb rdb.Database) (bool, error) {
+func CheckForExistingCaravan (uuid string, tdb rdb.InteractiveDB) (bool, error) {
 	// Get caravan
 	_, getError := tdb.GetJsonData(uuid, ".")
 	if getError != nil {
@@ -74,7 +120,7 @@ func CheckForExistingCaravan (uuid string, tdb rdb.Database) (bool, error) {
 }
 
 // Get caravan from DB, bool is caravan found
-func GetCaravanFromDB (uuid string, tdb rdb.Database) (Caravan, bool, error) {
+func GetCaravanFromDB (uuid string, tdb rdb.InteractiveDB) (Caravan, bool, error) {
 	// Get caravan json
 	someJson, getError := tdb.GetJsonData(uuid, ".")
 	if getError != nil {
@@ -96,7 +142,7 @@ func GetCaravanFromDB (uuid string, tdb rdb.Database) (Caravan, bool, error) {
 }
 
 // Get caravan from DB, bool is caravan found
-func GetCaravansFromDB (uuids []string, tdb rdb.Database) ([]Caravan, bool, error) {
+func GetCaravansFromDB (uuids []string, tdb rdb.InteractiveDB) ([]Caravan, bool, error) {
 	// Get caravan json
 	someJson, getError := tdb.MGetJsonData(".", uuids)
 	if getError != nil {
@@ -123,7 +169,7 @@ func GetCaravansFromDB (uuids []string, tdb rdb.Database) ([]Caravan, bool, erro
 }
 
 // Get caravandata at path from DB, bool is caravan found
-func GetCaravanDataAtPathFromDB (uuid string, path string, tdb rdb.Database) (interface{}, bool, error) {
+func GetCaravanDataAtPathFromDB (uuid string, path string, tdb rdb.InteractiveDB) (interface{}, bool, error) {
 	// Get caravan json
 	someJson, getError := tdb.GetJsonData(uuid, path)
 	if getError != nil {
@@ -145,24 +191,42 @@ func GetCaravanDataAtPathFromDB (uuid string, path string, tdb rdb.Database) (in
 }
 
 // Attempt to save caravan, returns error or nil if successful
-func SaveCaravanToDB(tdb rdb.Database, caravanData *Caravan) error {
+func SaveCaravanToDB(tdb rdb.InteractiveDB, caravanData *Caravan) error {
 	log.Debug.Printf("Saving caravan %s to DB", caravanData.UUID)
 	err := tdb.SetJsonData(caravanData.UUID, ".", caravanData)
+	if err != nil {
+		return err
+	}
 	// creationSuccess := rdb.CreateCaravan(tdb, caravanname, uuid, 0)
-	return err
+	// Keep caravans to auto unpack indexed by arrival time
+	if caravanData.AutoUnpack {
+		return tdb.ZAdd(CaravanArrivalsKey, caravanData.UUID, caravanData.ArrivalTime)
+	}
+	return tdb.ZRem(CaravanArrivalsKey, caravanData.UUID)
 }
 
 // Attempt to save caravan data at path, returns error or nil if successful
-func SaveCaravanDataAtPathToDB(tdb rdb.Database, uuid string, path string, newValue interface{}) error {
+func SaveCaravanDataAtPathToDB(tdb rdb.InteractiveDB, uuid string, path string, newValue interface{}) error {
 	log.Debug.Printf("Saving caravan data at path %s to DB for uuid %s", path, uuid)
 	err := tdb.SetJsonData(uuid, path, newValue)
 	return err
 }
 
 // Attempt to delete caravan, returns error or nil if successful
-func DeleteCaravanFromDB(tdb rdb.Database, uuid string) error {
+func DeleteCaravanFromDB(tdb rdb.InteractiveDB, uuid string) error {
 	log.Debug.Printf("Saving caravan %s to DB", uuid)
 	_, err := tdb.DelJsonData(uuid, ".")
+	if err != nil {
+		return err
+	}
 	// creationSuccess := rdb.CreateCaravan(tdb, caravanname, uuid, 0)
-	return err
+	return tdb.ZRem(CaravanArrivalsKey, uuid)
+}
+
+// Key of the sorted set of caravans chartered with auto_unpack, scored by arrival time
+const CaravanArrivalsKey string = "CaravanArrivals"
+
+// Get uuids of caravans chartered with auto_unpack which arrive by now, earliest first
+func GetArrivedCaravanUUIDsFromDB(now int64, tdb rdb.InteractiveDB) ([]string, error) {
+	return tdb.ZRangeByScore(CaravanArrivalsKey, now)
 }
\ No newline at end of file
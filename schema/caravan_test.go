@@ -0,0 +1,67 @@
+package schema
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+// A recalled caravan retraces the legs it has begun for as long as it spent on them, and is refunded the fares of legs not begun
+func TestPlanRecall(t *testing.T) {
+	route := RoutePlan{Legs: []RouteLeg{
+		{"TS-AA-HF", "TS-AA-PT", false, 100, 0},
+		{"TS-AA-PT", "TS-BB-PT", true, 200, 30},
+		{"TS-BB-PT", "TS-BB-XP", false, 100, 0},
+		{"TS-BB-XP", "TS-CC-PT", true, 50, 5},
+	}, Duration: 450, Fare: 35}
+	tests := []struct {
+		name string
+		route RoutePlan
+		elapsed int64
+		want RoutePlan
+		wantRefund uint64
+	}{
+		{
+			name: "not yet departed", route: route, elapsed: 0,
+			want: RoutePlan{Legs: []RouteLeg{}}, wantRefund: 35,
+		},
+		{
+			name: "clock behind departure", route: route, elapsed: -60,
+			want: RoutePlan{Legs: []RouteLeg{}}, wantRefund: 35,
+		},
+		{
+			name: "partway overland", route: route, elapsed: 50,
+			want: RoutePlan{Legs: []RouteLeg{{"TS-AA-PT", "TS-AA-HF", false, 50, 0}}, Duration: 50}, wantRefund: 35,
+		},
+		{
+			name: "partway across the sea", route: route, elapsed: 150,
+			want: RoutePlan{Legs: []RouteLeg{
+				{"TS-BB-PT", "TS-AA-PT", true, 50, 0},
+				{"TS-AA-PT", "TS-AA-HF", false, 100, 0},
+			}, Duration: 150}, wantRefund: 5,
+		},
+		{
+			name: "arrived", route: route, elapsed: 1000,
+			want: RoutePlan{Legs: []RouteLeg{
+				{"TS-CC-PT", "TS-BB-XP", true, 50, 0},
+				{"TS-BB-XP", "TS-BB-PT", false, 100, 0},
+				{"TS-BB-PT", "TS-AA-PT", true, 200, 0},
+				{"TS-AA-PT", "TS-AA-HF", false, 100, 0},
+			}, Duration: 450}, wantRefund: 0,
+		},
+		{
+			name: "no planned route", route: RoutePlan{}, elapsed: 70,
+			want: RoutePlan{Legs: []RouteLeg{}, Duration: 70}, wantRefund: 0,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			departed := time.Unix(1000000, 0)
+			caravan := NewCaravan("Farmer|Caravan-0", departed, "TS-AA-HF", "TS-CC-PT", []int64{0}, Wareset{}, false, test.route)
+			recall, refund := caravan.PlanRecall(departed.Unix() + test.elapsed)
+			if !reflect.DeepEqual(recall, test.want) || refund != test.wantRefund {
+				t.Errorf("got %+v refund %d, want %+v refund %d", recall, refund, test.want, test.wantRefund)
+			}
+		})
+	}
+}
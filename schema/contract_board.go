@@ -0,0 +1,245 @@
+// Package schema defines database and JSON schema as structs, as well as functions for creating and using these structs
+package schema
+
+import (
+	"apricate/log"
+	"apricate/rdb"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"math/rand"
+	"sort"
+	"time"
+)
+
+// Seconds each contract board stays up before being replaced with new listings
+const ContractBoardRotationSeconds int64 = 6 * 60 * 60
+
+// Favor gained with an NPC when fulfilling their contract
+const ContractFavorGain int8 = 5
+
+// Sizes requested by produce contracts
+var contractProduceSizes = []Size{Tiny, Small, Modest, Average}
+
+// Defines a contract available on a contract board, rewards are before favor is applied
+type ContractListing struct {
+	ID uint64 `json:"id" binding:"required"`
+	ContractType ContractTypes `json:"type" binding:"required"`
+	LocationSymbol string `json:"location_symbol" binding:"required"` // where the contract is fulfilled
+	NPC string `json:"NPC" binding:"required"`
+	MinFavor int8 `json:"min_favor" binding:"required"`
+	Terms []ContractTerms `json:"terms" binding:"required"`
+	Reward []ContractReward `json:"reward" binding:"required"`
+	AcceptedBy []string `json:"accepted_by,omitempty"`
+}
+
+// Defines the rotating contract board of a location
+type ContractBoard struct {
+	UUID string `json:"uuid" binding:"required"`
+	LocationSymbol string `json:"location_symbol" binding:"required"`
+	Rotation int64 `json:"rotation" binding:"required"`
+	ExpiresAt int64 `json:"expires_at" binding:"required"`
+	Listings []ContractListing `json:"listings" binding:"required"`
+}
+
+// Get the rotation number for the given time
+func ContractBoardRotation(timestamp time.Time) int64 {
+	return timestamp.Unix() / ContractBoardRotationSeconds
+}
+
+// Item available for contract terms, price is per unit including size
+type contractItem struct {
+	Category ItemCategory
+	Name string
+	Price uint64
+}
+
+// Get every item in a market io field, with a random size chosen for produce
+func contractItemsFromIOField(rng *rand.Rand, ioField MarketIOField) []contractItem {
+	items := make([]contractItem, 0)
+	for _, name := range sortedKeys(ioField.Goods) {
+		items = append(items, contractItem{Category: GOOD, Name: name, Price: ioField.Goods[name]})
+	}
+	for _, name := range sortedKeys(ioField.Produce) {
+		size := contractProduceSizes[rng.Intn(len(contractProduceSizes))]
+		items = append(items, contractItem{Category: PRODUCE, Name: name + "|" + size.String(), Price: ioField.Produce[name] * uint64(size)})
+	}
+	return items
+}
+
+// Get the keys of a price map in a stable order so boards generate deterministically
+func sortedKeys(dict map[string]uint64) []string {
+	keys := make([]string, 0, len(dict))
+	for key := range dict {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Generate the contract board of a location for the rotation containing timestamp.
+// Each NPC at the location posts one listing built from the local market and its neighbours; boards are deterministic per location and rotation
+func GenerateContractBoard(world World, markets map[string]Market, locationSymbol string, timestamp time.Time) *ContractBoard {
+	rotation := ContractBoardRotation(timestamp)
+	hash := fnv.New64a()
+	hash.Write([]byte(locationSymbol))
+	rng := rand.New(rand.NewSource(int64(hash.Sum64()) + rotation))
+
+	board := &ContractBoard{
+		UUID: "ContractBoard-" + locationSymbol,
+		LocationSymbol: locationSymbol,
+		Rotation: rotation,
+		ExpiresAt: (rotation + 1) * ContractBoardRotationSeconds,
+		Listings: make([]ContractListing, 0),
+	}
+	location, ok := world.Locations[locationSymbol]
+	if !ok {
+		return board
+	}
+
+	// Items wanted here are the local market imports
+	wanted := make([]contractItem, 0)
+	if market, ok := markets[locationSymbol]; ok {
+		wanted = contractItemsFromIOField(rng, market.Imports)
+	}
+	// Items to courier here are exports of other markets on the same island
+	courierable := make([]contractItem, 0)
+	// NPCs to talk to at other locations on the same island
+	talkTargets := make([][2]string, 0)
+	locationSymbols := make([]string, 0, len(world.Locations))
+	for symbol := range world.Locations {
+		locationSymbols = append(locationSymbols, symbol)
+	}
+	sort.Strings(locationSymbols)
+	for _, symbol := range locationSymbols {
+		other := world.Locations[symbol]
+		if symbol == locationSymbol || other.IslandName != location.IslandName {
+			continue
+		}
+		if otherMarket, ok := markets[symbol]; ok {
+			courierable = append(courierable, contractItemsFromIOField(rng, otherMarket.Exports)...)
+		}
+		for _, npc := range other.NPCs {
+			talkTargets = append(talkTargets, [2]string{symbol, npc})
+		}
+	}
+
+	for _, npc := range location.NPCs {
+		// Higher tiers ask for more, pay more, and require more favor with the NPC
+		tier := uint64(rng.Intn(3))
+		listing := ContractListing{
+			ID: uint64(len(board.Listings)),
+			LocationSymbol: locationSymbol,
+			NPC: npc,
+			MinFavor: int8(tier * 25),
+		}
+		options := make([]ContractTypes, 0)
+		if len(wanted) > 0 {
+			options = append(options, ContractType_Collect, ContractType_Deliver)
+		}
+		if len(courierable) > 0 {
+			options = append(options, ContractType_Courier)
+		}
+		if len(talkTargets) > 0 {
+			options = append(options, ContractType_Talk)
+		}
+		if len(options) == 0 {
+			continue
+		}
+		listing.ContractType = options[rng.Intn(len(options))]
+		quantity := uint64(5 + rng.Intn(16)) * (1 + tier)
+		var coins uint64
+		switch listing.ContractType {
+		case ContractType_Collect:
+			item := wanted[rng.Intn(len(wanted))]
+			listing.Terms = []ContractTerms{{NPC: npc, Item: item.Name, Quantity: quantity}}
+			coins = item.Price * quantity * 5 / 4
+		case ContractType_Deliver:
+			item := wanted[rng.Intn(len(wanted))]
+			listing.Terms = []ContractTerms{{NPC: npc, Item: item.Name, Quantity: quantity}}
+			coins = item.Price * quantity * 3 / 2
+		case ContractType_Courier:
+			item := courierable[rng.Intn(len(courierable))]
+			listing.Terms = []ContractTerms{{NPC: npc, Item: item.Name, Quantity: quantity}}
+			coins = item.Price * quantity * 2
+		case ContractType_Talk:
+			target := talkTargets[rng.Intn(len(talkTargets))]
+			listing.LocationSymbol = target[0]
+			listing.Terms = []ContractTerms{{NPC: target[1]}}
+			coins = 25 * (1 + tier)
+		}
+		if coins < 10 {
+			coins = 10
+		}
+		listing.Reward = []ContractReward{{RewardType: RewardType_Currency, Item: "Coins", Quantity: coins}}
+		board.Listings = append(board.Listings, listing)
+	}
+	return board
+}
+
+// Get currency rewards scaled by the given favor with the listing NPC, 1% per point of favor
+func (l *ContractListing) RewardForFavor(favor int8) []ContractReward {
+	res := make([]ContractReward, len(l.Reward))
+	for i, reward := range l.Reward {
+		if reward.RewardType == RewardType_Currency {
+			scaled := int64(reward.Quantity) * (100 + int64(favor)) / 100
+			if scaled < 1 {
+				scaled = 1
+			}
+			reward.Quantity = uint64(scaled)
+		}
+		res[i] = reward
+	}
+	return res
+}
+
+// Get a copy of the board as seen by the given user: listings they accepted are hidden and rewards include their favor
+func (b *ContractBoard) ForUser(username string, favor map[string]int8) ContractBoard {
+	res := *b
+	res.Listings = make([]ContractListing, 0)
+	for _, listing := range b.Listings {
+		accepted := false
+		for _, acceptedBy := range listing.AcceptedBy {
+			if acceptedBy == username {
+				accepted = true
+				break
+			}
+		}
+		if accepted {
+			continue
+		}
+		listing.Reward = listing.RewardForFavor(favor[listing.NPC])
+		listing.AcceptedBy = nil
+		res.Listings = append(res.Listings, listing)
+	}
+	return res
+}
+
+// Get contract board from DB, bool is contract board found
+func GetContractBoardFromDB (locationSymbol string, tdb rdb.InteractiveDB) (ContractBoard, bool, error) {
+	// Get contract board json
+	someJson, getError := tdb.GetJsonData("ContractBoard-" + locationSymbol, ".")
+	if getError != nil {
+		if fmt.Sprint(getError) == "redis: nil" {
+			// contract board not found
+			return ContractBoard{}, false, nil
+		}
+		// error
+		return ContractBoard{}, false, getError
+	}
+	// Got successfully, unmarshal
+	someData := ContractBoard{}
+	unmarshalErr := json.Unmarshal(someJson, &someData)
+	if unmarshalErr != nil {
+		log.Error.Fatalf("Could not unmarshal contract board json from DB: %v", unmarshalErr)
+		return ContractBoard{}, false, unmarshalErr
+	}
+	return someData, true, nil
+}
+
+// Attempt to save contract board, returns error or nil if successful
+func SaveContractBoardToDB(tdb rdb.InteractiveDB, contractBoardData *ContractBoard) error {
+	log.Debug.Printf("Saving contract board %s to DB", contractBoardData.UUID)
+	err := tdb.SetJsonData(contractBoardData.UUID, ".", contractBoardData)
+	return err
+}
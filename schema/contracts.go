@@ -27,6 +27,7 @@ type Contract struct {
 	NPC string `json:"NPC" binding:"required"`
 	Terms []ContractTerms `json:"terms" binding:"required"`
 	Reward []ContractReward `json:"reward" binding:"required"`
+	Fulfilled bool `json:"fulfilled" binding:"required"`
 }
 
 // Defines ContractTerms
@@ -63,7 +64,7 @@ func NewContract(username string, countOfUserContracts uint64, locationSymbol st
 }
 
 // Check DB for existing contract with given uuid and return bool for if exists, and error if error encountered
-func CheckForExistingContract (uuid string, tdb rdb.Database) (bool, error) {
+func CheckForExistingContract (uuid string, tdb rdb.InteractiveDB) (bool, error) {
 	// Get contract
 	_, getError := tdb.GetJsonData(uuid, ".")
 	if getError != nil {
@@ -79,11 +80,11 @@ func CheckForExistingContract (uuid string, tdb rdb.Database) (bool, error) {
 }
 
 // Get contract from DB, bool is contract found
-func GetContractFromDB (uuid string, tdb rdb.Database) (Contract, bool, error) {
+func GetContractFromDB (uuid string, tdb rdb.InteractiveDB) (Contract, bool, error) {
 	// Get contract json
 	someJson, getError := tdb.GetJsonData(uuid, ".")
 	if getError != nil {
-		if fmt.Sprint(getError) != "redis: nil" {
+		if fmt.Sprint(getError) == "redis: nil" {
 			// contract not found
 			return Contract{}, false, nil
 		}
@@ -101,11 +102,11 @@ func GetContractFromDB (uuid string, tdb rdb.Database) (Contract, bool, error) {
 }
 
 // Get contract from DB, bool is contract found
-func GetContractsFromDB (uuids []string, tdb rdb.Database) ([]Contract, bool, error) {
+func GetContractsFromDB (uuids []string, tdb rdb.InteractiveDB) ([]Contract, bool, error) {
 	// Get contract json
 	someJson, getError := tdb.MGetJsonData(".", uuids)
 	if getError != nil {
-		if fmt.Sprint(getError) != "redis: nil" {
+		if fmt.Sprint(getError) == "redis: nil" {
 			// contract not found
 			return []Contract{}, false, nil
 		}
@@ -128,11 +129,11 @@ func GetContractsFromDB (uuids []string, tdb rdb.Database) ([]Contract, bool, er
 }
 
 // Get contractdata at path from DB, bool is contract found
-func GetContractDataAtPathFromDB (uuid string, path string, tdb rdb.Database) (interface{}, bool, error) {
+func GetContractDataAtPathFromDB (uuid string, path string, tdb rdb.InteractiveDB) (interface{}, bool, error) {
 	// Get contract json
 	someJson, getError := tdb.GetJsonData(uuid, path)
 	if getError != nil {
-		if fmt.Sprint(getError) != "redis: nil" {
+		if fmt.Sprint(getError) == "redis: nil" {
 			// contract not found
 			return nil, false, nil
 		}
@@ -150,7 +151,7 @@ func GetContractDataAtPathFromDB (uuid string, path string, tdb rdb.Database) (i
 }
 
 // Attempt to save contract, returns error or nil if successful
-func SaveContractToDB(tdb rdb.Database, contractData *Contract) error {
+func SaveContractToDB(tdb rdb.InteractiveDB, contractData *Contract) error {
 	log.Debug.Printf("Saving contract %s to DB", contractData.UUID)
 	err := tdb.SetJsonData(contractData.UUID, ".", contractData)
 	// creationSuccess := rdb.CreateContract(tdb, contractname, uuid, 0)
@@ -158,7 +159,7 @@ func SaveContractToDB(tdb rdb.Database, contractData *Contract) error {
 }
 
 // Attempt to save contract data at path, returns error or nil if successful
-func SaveContractDataAtPathToDB(tdb rdb.Database, uuid string, path string, newValue interface{}) error {
+func SaveContractDataAtPathToDB(tdb rdb.InteractiveDB, uuid string, path string, newValue interface{}) error {
 	log.Debug.Printf("Saving contract data at path %s to DB for uuid %s", path, uuid)
 	err := tdb.SetJsonData(uuid, path, newValue)
 	return err
@@ -1,6 +1,10 @@
 // Package schema defines database and JSON schema as structs, as well as functions for creating and using these structs
 package schema
 
+import (
+	"strings"
+)
+
 type MainDictionary struct {
 	Goods map[string]interface{} `yaml:"Goods" json:"goods" binding:"required"`
 	Seeds map[string]string`yaml:"Seeds" json:"seeds" binding:"required"`
@@ -8,4 +12,38 @@ type MainDictionary struct {
 	Plants map[string]PlantDefinition `yaml:"Plants" json:"plants" binding:"required"`
 	Markets map[string]Market `yaml:"Markets" json:"markets" binding:"required"`
 	Rites map[string]Rite `yaml:"Rites" json:"rites" binding:"required"`
-}
\ No newline at end of file
+	Buildings map[string]BuildingDefinition `yaml:"Buildings" json:"buildings" binding:"required"`
+	Recipes map[string]Recipe `yaml:"Recipes" json:"recipes" binding:"required"`
+	Improvements map[string]ImprovementDefinition `yaml:"Improvements" json:"improvements" binding:"required"`
+}
+
+// Get the category of the named item, produce names must include size like 'Potato|Large'
+func (d *MainDictionary) GetItemCategory(name string) (ItemCategory, bool) {
+	if _, ok := d.Goods[name]; ok {
+		return GOOD, true
+	}
+	if _, ok := d.Seeds[name]; ok {
+		return SEED, true
+	}
+	if _, ok := d.Produce[strings.Split(name, "|")[0]]; ok {
+		return PRODUCE, true
+	}
+	if _, ok := toolTypesToID[name]; ok {
+		return TOOL, true
+	}
+	return GOOD, false
+}
+
+
+// Get the named recipe ignoring case, as recipe names like 'Quality Bundle of Materials' are not title case
+func (d *MainDictionary) GetRecipe(name string) (Recipe, bool) {
+	if recipe, ok := d.Recipes[name]; ok {
+		return recipe, true
+	}
+	for recipeName, recipe := range d.Recipes {
+		if strings.EqualFold(recipeName, name) {
+			return recipe, true
+		}
+	}
+	return Recipe{}, false
+}
@@ -0,0 +1,86 @@
+// Package schema defines database and JSON schema as structs, as well as functions for creating and using these structs
+package schema
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// Defines a game event streamed to a player, subject is the uuid of the caravan, plot, order, or contract concerned
+type Event struct {
+	Type EventType `json:"type" binding:"required"`
+	Timestamp int64 `json:"timestamp" binding:"required"`
+	Subject string `json:"subject,omitempty"`
+	Data interface{} `json:"data,omitempty"`
+}
+
+func NewEvent(eventType EventType, timestamp int64, subject string, data interface{}) *Event {
+	return &Event{
+		Type: eventType,
+		Timestamp: timestamp,
+		Subject: subject,
+		Data: data,
+	}
+}
+
+// enum for event types
+type EventType uint16
+const (
+	Event_CaravanArrived EventType = 0
+	Event_PlotGrowthComplete EventType = 1
+	Event_LatticeRejectionEnded EventType = 2
+	Event_MarketOrderFilled EventType = 3
+	Event_ContractCompleted EventType = 4
+	Event_CaravanUnpacked EventType = 5
+	Event_PlotInteracted EventType = 6
+	Event_MarketOrderExecuted EventType = 7
+	Event_PlotQueuePaused EventType = 8
+)
+
+func (s EventType) String() string {
+	return EventTypeToString[s]
+}
+
+var EventTypeToString = map[EventType]string {
+	Event_CaravanArrived: "caravan_arrived",
+	Event_PlotGrowthComplete: "plot_growth_complete",
+	Event_LatticeRejectionEnded: "lattice_rejection_ended",
+	Event_MarketOrderFilled: "market_order_filled",
+	Event_ContractCompleted: "contract_completed",
+	Event_CaravanUnpacked: "caravan_unpacked",
+	Event_PlotInteracted: "plot_interacted",
+	Event_MarketOrderExecuted: "market_order_executed",
+	Event_PlotQueuePaused: "plot_queue_paused",
+}
+
+var EventTypeToID = map[string]EventType {
+	"caravan_arrived": Event_CaravanArrived,
+	"plot_growth_complete": Event_PlotGrowthComplete,
+	"lattice_rejection_ended": Event_LatticeRejectionEnded,
+	"market_order_filled": Event_MarketOrderFilled,
+	"contract_completed": Event_ContractCompleted,
+	"caravan_unpacked": Event_CaravanUnpacked,
+	"plot_interacted": Event_PlotInteracted,
+	"market_order_executed": Event_MarketOrderExecuted,
+	"plot_queue_paused": Event_PlotQueuePaused,
+}
+
+// MarshalJSON marshals the enum as a quoted json string
+func (s EventType) MarshalJSON() ([]byte, error) {
+	buffer := bytes.NewBufferString(`"`)
+	buffer.WriteString(EventTypeToString[s])
+	buffer.WriteString(`"`)
+	return buffer.Bytes(), nil
+}
+
+// UnmarshalJSON unmashals a quoted json string to the enum value
+func (s *EventType) UnmarshalJSON(b []byte) error {
+	var j string
+	err := json.Unmarshal(b, &j)
+	if err != nil {
+		return err
+	}
+	// Note that if the string cannot be found then it will be set to the zero value, 'caravan_arrived' in this case.
+	*s = EventTypeToID[j]
+	return nil
+}
@@ -7,6 +7,7 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"strings"
 )
 
 // enum for farm bonuses
@@ -26,9 +27,13 @@ type Farm struct {
 	Bonuses []FarmBonuses `json:"bonuses" binding:"required"`
 	Buildings map[BuildingTypes]uint8 `json:"buildings" binding:"required"`
 	Plots map[string]Plot `json:"plots" binding:"required"`
+	Construction *BuildingConstruction `json:"construction"` // nil when nothing is being built
+	Crafting *Crafting `json:"crafting"` // nil when nothing is being crafted
+	TemporaryBonuses []TemporaryFarmBonus `json:"temporary_bonuses,omitempty"` // granted by rituals
+	Effects *FarmEffects `json:"effects,omitempty"` // SHOULD BE STORED AS NIL, ONLY FOR FORMATTING RESPONSE
 }
 
-func NewFarm(pdb rdb.Database, totalplotcount uint64, username string, locationSymbol string) *Farm {
+func NewFarm(pdb rdb.InteractiveDB, totalplotcount uint64, username string, locationSymbol string) *Farm {
 	var bonuses []FarmBonuses
 	var buildings map[BuildingTypes]uint8
 	var plots map[string]Plot
@@ -52,8 +57,60 @@ func NewFarm(pdb rdb.Database, totalplotcount uint64, username string, locationS
 	}
 }
 
+// Defines a farm bonus which expires
+type TemporaryFarmBonus struct {
+	Bonus FarmBonuses `json:"bonus" binding:"required"`
+	ExpireTimestamp int64 `json:"expire_timestamp" binding:"required"`
+}
+
+// Check if the farm has bonus, permanently or temporarily at timestamp
+func (f *Farm) HasBonus(bonus FarmBonuses, timestamp int64) bool {
+	for _, b := range f.Bonuses {
+		if b == bonus {
+			return true
+		}
+	}
+	for _, b := range f.TemporaryBonuses {
+		if b.Bonus == bonus && b.ExpireTimestamp > timestamp {
+			return true
+		}
+	}
+	return false
+}
+
+// Grant bonus until expireTimestamp, extending it if already granted, and drop expired bonuses
+func (f *Farm) AddTemporaryBonus(bonus FarmBonuses, timestamp int64, expireTimestamp int64) {
+	bonuses := make([]TemporaryFarmBonus, 0, len(f.TemporaryBonuses) + 1)
+	for _, b := range f.TemporaryBonuses {
+		if b.ExpireTimestamp <= timestamp {
+			continue
+		}
+		if b.Bonus == bonus {
+			if b.ExpireTimestamp > expireTimestamp {
+				expireTimestamp = b.ExpireTimestamp
+			}
+			continue
+		}
+		bonuses = append(bonuses, b)
+	}
+	f.TemporaryBonuses = append(bonuses, TemporaryFarmBonus{Bonus: bonus, ExpireTimestamp: expireTimestamp})
+}
+
+// Finish construction if complete by timestamp, bool is construction finished
+func (f *Farm) CompleteConstruction(timestamp int64) bool {
+	if f.Construction == nil || f.Construction.CompleteTimestamp > timestamp {
+		return false
+	}
+	if f.Buildings == nil {
+		f.Buildings = make(map[BuildingTypes]uint8)
+	}
+	f.Buildings[f.Construction.Building] = f.Construction.Level
+	f.Construction = nil
+	return true
+}
+
 // Check DB for existing farm with given uuid and return bool for if exists, and error if error encountered
-func CheckForExistingFarm (uuid string, tdb rdb.Database) (bool, error) {
+func CheckForExistingFarm (uuid string, tdb rdb.InteractiveDB) (bool, error) {
 	// Get farm
 	_, getError := tdb.GetJsonData(uuid, ".")
 	if getError != nil {
@@ -69,7 +126,7 @@ func CheckForExistingFarm (uuid string, tdb rdb.Database) (bool, error) {
 }
 
 // Get farm from DB, bool is farm found
-func GetFarmFromDB (uuid string, tdb rdb.Database) (Farm, bool, error) {
+func GetFarmFromDB (uuid string, tdb rdb.InteractiveDB) (Farm, bool, error) {
 	// Get farm json
 	someJson, getError := tdb.GetJsonData(uuid, ".")
 	if getError != nil {
@@ -91,7 +148,7 @@ func GetFarmFromDB (uuid string, tdb rdb.Database) (Farm, bool, error) {
 }
 
 // Get farm from DB, bool is farm found
-func GetFarmsFromDB (uuids []string, tdb rdb.Database) ([]Farm, bool, error) {
+func GetFarmsFromDB (uuids []string, tdb rdb.InteractiveDB) ([]Farm, bool, error) {
 	// Get farm json
 	someJson, getError := tdb.MGetJsonData(".", uuids)
 	if getError != nil {
@@ -118,7 +175,7 @@ func GetFarmsFromDB (uuids []string, tdb rdb.Database) ([]Farm, bool, error) {
 }
 
 // Get farmdata at path from DB, bool is farm found
-func GetFarmDataAtPathFromDB (uuid string, path string, tdb rdb.Database) (interface{}, bool, error) {
+func GetFarmDataAtPathFromDB (uuid string, path string, tdb rdb.InteractiveDB) (interface{}, bool, error) {
 	// Get farm json
 	someJson, getError := tdb.GetJsonData(uuid, path)
 	if getError != nil {
@@ -140,7 +197,7 @@ func GetFarmDataAtPathFromDB (uuid string, path string, tdb rdb.Database) (inter
 }
 
 // Attempt to save farm, returns error or nil if successful
-func SaveFarmToDB(tdb rdb.Database, farmData *Farm) error {
+func SaveFarmToDB(tdb rdb.InteractiveDB, farmData *Farm) error {
 	log.Debug.Printf("Saving farm %s to DB", farmData.UUID)
 	err := tdb.SetJsonData(farmData.UUID, ".", farmData)
 	// creationSuccess := rdb.CreateFarm(tdb, farmname, uuid, 0)
@@ -148,7 +205,7 @@ func SaveFarmToDB(tdb rdb.Database, farmData *Farm) error {
 }
 
 // Attempt to save farm data at path, returns error or nil if successful
-func SaveFarmDataAtPathToDB(tdb rdb.Database, uuid string, path string, newValue interface{}) error {
+func SaveFarmDataAtPathToDB(tdb rdb.InteractiveDB, uuid string, path string, newValue interface{}) error {
 	log.Debug.Printf("Saving farm data at path %s to DB for uuid %s", path, uuid)
 	err := tdb.SetJsonData(uuid, path, newValue)
 	return err
@@ -192,4 +249,18 @@ func (s *FarmBonuses) UnmarshalJSON(b []byte) error {
 	// Note that if the string cannot be found then it will be set to the zero value, 'Created' in this case.
 	*s = farmBonusesToID[j]
 	return nil
-}
\ No newline at end of file
+}
+// UnmarshalYAML unmashals a yaml string to the enum value, matching only the bonus name before the description, e.g. 'Pristine Soil'
+func (s *FarmBonuses) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var j string
+	if err := unmarshal(&j); err != nil {
+		return err
+	}
+	for bonus, name := range farmBonusesToString {
+		if strings.Split(name, " | ")[0] == j {
+			*s = bonus
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown farm bonus %s", j)
+}
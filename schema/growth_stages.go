@@ -115,13 +115,9 @@ func (s GrowthAction) MarshalText() ([]byte, error) {
 
 // UnmarshalJSON unmashals a text string to the enum value
 func (s *GrowthAction) UnmarshalText(b []byte) error {
-	var j string
-	err := json.Unmarshal(b, &j)
-	if err != nil {
-		return err
-	}
+	// Text is unquoted (e.g. map keys), so look it up directly.
 	// Note that if the string cannot be found then it will be set to the zero value, 'Created' in this case.
-	*s = growthActionsToID[j]
+	*s = growthActionsToID[string(b)]
 	return nil
 }
 
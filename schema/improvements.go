@@ -0,0 +1,100 @@
+// Package schema defines database and JSON schema as structs, as well as functions for creating and using these structs
+package schema
+
+import (
+	"apricate/filemngr"
+	"apricate/log"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Define assistant improvement dictionary entry, levels are in order so Levels[0] is level 1
+type ImprovementDefinition struct {
+	Name string `yaml:"Name" json:"name" binding:"required"`
+	Description string `yaml:"Description" json:"description" binding:"required"`
+	Archetypes []AssistantTypes `yaml:"Archetypes" json:"archetypes,omitempty"` // if set, only assistants of these archetypes may take the improvement
+	Levels []ImprovementLevel `yaml:"Levels" json:"levels" binding:"required"`
+}
+
+// Define the cost and effects of an improvement level, effects are those of the improvement at that level, not in addition to lower levels
+type ImprovementLevel struct {
+	Currencies map[string]uint64 `yaml:"Currencies" json:"currencies,omitempty"`
+	Materials Wareset `yaml:"Materials" json:"materials" binding:"required"` // consumed from the warehouse where the assistant is
+	Speed int `yaml:"Speed" json:"speed,omitempty"` // added to the assistant's speed
+	CarryCap int `yaml:"CarryCap" json:"carrying_capacity,omitempty"` // added to the assistant's carrying capacity
+}
+
+// Defines an improve request body
+type ImproveBody struct {
+	Improvement string `json:"improvement" binding:"required"`
+}
+
+// Get the highest level the improvement can be taken to
+func (i *ImprovementDefinition) MaxLevel() uint8 {
+	return uint8(len(i.Levels))
+}
+
+// Get the definition of level, bool is level defined
+func (i *ImprovementDefinition) Level(level uint8) (ImprovementLevel, bool) {
+	if level < 1 || level > i.MaxLevel() {
+		return ImprovementLevel{}, false
+	}
+	return i.Levels[level - 1], true
+}
+
+// Check if an assistant of archetype may take the improvement
+func (i *ImprovementDefinition) AllowsArchetype(archetype AssistantTypes) bool {
+	if len(i.Archetypes) == 0 {
+		return true
+	}
+	for _, a := range i.Archetypes {
+		if a == archetype {
+			return true
+		}
+	}
+	return false
+}
+
+// Get the assistant's speed including the effects of its improvements
+func (a *Assistant) ImprovedSpeed(dictionary map[string]ImprovementDefinition) int {
+	speed := a.Speed
+	for name, level := range a.Improvements {
+		improvementDef := dictionary[name]
+		if levelDef, ok := improvementDef.Level(level); ok {
+			speed += levelDef.Speed
+		}
+	}
+	if speed < 1 {
+		// improvements may slow an assistant but never stop it
+		speed = 1
+	}
+	return speed
+}
+
+// Get the assistant's carrying capacity including the effects of its improvements
+func (a *Assistant) ImprovedCarryCap(dictionary map[string]ImprovementDefinition) int {
+	carryCap := a.CarryCap
+	for name, level := range a.Improvements {
+		improvementDef := dictionary[name]
+		if levelDef, ok := improvementDef.Level(level); ok {
+			carryCap += levelDef.CarryCap
+		}
+	}
+	return carryCap
+}
+
+// Load improvement struct by unmarhsalling given yaml file
+func Improvements_load(path_to_improvements_yaml string) map[string]ImprovementDefinition {
+	improvementsBytes, readErr := filemngr.ReadFileToBytes(path_to_improvements_yaml)
+	if readErr != nil {
+		// Essential to server start
+		panic(readErr)
+	}
+	var improvements map[string]ImprovementDefinition
+	err := yaml.Unmarshal(improvementsBytes, &improvements)
+	if err != nil {
+		log.Error.Fatalln(err)
+	}
+
+	return improvements
+}
@@ -1,11 +1,17 @@
 // Package schema defines database and JSON schema as structs, as well as functions for creating and using these structs
 package schema
 
+import (
+	"fmt"
+	"math/bits"
+)
+
 // Defines a ledger
 type Ledger struct {
 	Currencies map[string]uint64 `json:"currencies" binding:"required"`
 	Favor map[string]int8 `json:"favor" binding:"required"`
-	Escrow map[string]uint64 `json:"escrow" binding:"required"`
+	EscrowCoins map[string]uint64 `json:"escrow_coins" binding:"required"` // coins held for each resting BUY order by uuid
+	EscrowWares map[string]uint64 `json:"escrow_wares" binding:"required"` // items held for each resting SELL order by uuid, of the order's item
 }
 
 func (l *Ledger) AddCurrency (name string, quantity uint64) {
@@ -17,4 +23,80 @@ func (l *Ledger) RemoveCurrency(name string, quantity uint64) {
 	if l.Currencies[name] <= 0 {
 		delete(l.Currencies, name)
 	}
-}
\ No newline at end of file
+}
+
+// Get the name of the first currency not held in sufficient quantity, empty if ledger holds all of currencies
+func (l *Ledger) MissingCurrency(currencies map[string]uint64) string {
+	for name, quantity := range currencies {
+		if l.Currencies[name] < quantity {
+			return name
+		}
+	}
+	return ""
+}
+
+// Remove all of currencies, which must be held
+func (l *Ledger) RemoveCurrencies(currencies map[string]uint64) {
+	for name, quantity := range currencies {
+		l.RemoveCurrency(name, quantity)
+	}
+}
+
+// Hold coins for the resting BUY order uuid
+func (l *Ledger) AddEscrowCoins(uuid string, coins uint64) {
+	if l.EscrowCoins == nil {
+		l.EscrowCoins = make(map[string]uint64)
+	}
+	l.EscrowCoins[uuid] += coins
+}
+
+// Release coins held for the BUY order uuid, errors without removing anything if fewer are held
+func (l *Ledger) RemoveEscrowCoins(uuid string, coins uint64) error {
+	return removeEscrow(l.EscrowCoins, uuid, coins)
+}
+
+// Hold quantity of the resting SELL order uuid's item
+func (l *Ledger) AddEscrowWares(uuid string, quantity uint64) {
+	if l.EscrowWares == nil {
+		l.EscrowWares = make(map[string]uint64)
+	}
+	l.EscrowWares[uuid] += quantity
+}
+
+// Release quantity of the item held for the SELL order uuid, errors without removing anything if less is held
+func (l *Ledger) RemoveEscrowWares(uuid string, quantity uint64) error {
+	return removeEscrow(l.EscrowWares, uuid, quantity)
+}
+
+func removeEscrow(escrow map[string]uint64, uuid string, quantity uint64) error {
+	held := escrow[uuid]
+	if quantity > held {
+		return fmt.Errorf("cannot remove %d from escrow %s holding %d", quantity, uuid, held)
+	}
+	if held == quantity {
+		delete(escrow, uuid)
+	} else {
+		escrow[uuid] = held - quantity
+	}
+	return nil
+}
+
+// Multiply a and b, bool is false if the product overflows uint64
+func MulUint64(a uint64, b uint64) (uint64, bool) {
+	hi, lo := bits.Mul64(a, b)
+	return lo, hi == 0
+}
+
+// Adjust favor with an NPC, clamped to [-100, 100]
+func (l *Ledger) AddFavor(npc string, amount int8) {
+	if l.Favor == nil {
+		l.Favor = make(map[string]int8)
+	}
+	favor := int16(l.Favor[npc]) + int16(amount)
+	if favor > 100 {
+		favor = 100
+	} else if favor < -100 {
+		favor = -100
+	}
+	l.Favor[npc] = int8(favor)
+}
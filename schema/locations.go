@@ -5,7 +5,6 @@ import (
 	"apricate/filemngr"
 	"apricate/log"
 	"fmt"
-	"math"
 	"strings"
 
 	"gopkg.in/yaml.v3"
@@ -20,12 +19,20 @@ type Location struct {
 	Y int8 `yaml:"Y" json:"y" binding:"required"` //-100:100
 	Description string `yaml:"Description" json:"description" binding:"required"`
 	NPCs []string `yaml:"NPCs" json:"npcs" binding:"required"`
+	WarehouseCapacity uint64 `yaml:"WarehouseCapacity" json:"warehouse_capacity,omitempty"` // If 0, warehouses here have BaseWarehouseCapacity
 }
 
-// Calculate travel time for a caravan between two locations
-// Return validation map incase not same island and not ports, and travel time in seconds, and fare cost in coins
+// Calculate travel time for a caravan between two locations along the fastest route
+// Return validation map incase locations invalid or unreachable, and travel time in seconds, and fare cost in coins
 func CalculateTravelTime(world World, a string, b string, slowestSpeed int) (map[string]string, int, uint64) {
-	log.Debug.Printf("CalculateTravelTime a: %s b: %s, slowestSpeed: %d", a, b, slowestSpeed)
+	validationMap, plan := PlanCaravanRoute(world, a, b, slowestSpeed, Route_Fastest)
+	return validationMap, plan.Duration, plan.Fare
+}
+
+// Plan a caravan route between two locations, crossing islands by any number of ports
+// Return validation map incase locations invalid or unreachable, and the best route given priority
+func PlanCaravanRoute(world World, a string, b string, slowestSpeed int, priority RoutePriority) (map[string]string, RoutePlan) {
+	log.Debug.Printf("PlanCaravanRoute a: %s b: %s, slowestSpeed: %d, priority: %s", a, b, slowestSpeed, priority)
 	validationMap := make(map[string]string)
 	// Ensure Symbols conforms to expectations
 	originSymbolSlice := strings.Split(a, "-")
@@ -33,17 +40,17 @@ func CalculateTravelTime(world World, a string, b string, slowestSpeed int) (map
 		// Origin Symbol not 3-part, invalid
 		validationMap["origin"] = "Origin symbol must be 3-part, like TS-PR-HF"
 	}
-	destSymbolSlice := strings.Split(a, "-")
+	destSymbolSlice := strings.Split(b, "-")
 	if len(destSymbolSlice) < 3 {
 		// Dest Symbol not 3-part, invalid
 		validationMap["destination"] = "Destination symbol must be 3-part, like TS-PR-HF"
 	}
 	if len(validationMap) > 0 {
 		// Return early if found validation issues
-		return validationMap, 0, 0
+		return validationMap, RoutePlan{}
 	}
 	originIslandSymbol := strings.Join(originSymbolSlice[0:2], "-")
-	originIsland, oiOK := world.Islands[originIslandSymbol]
+	_, oiOK := world.Islands[originIslandSymbol]
 	if !oiOK {
 		// Island symbol not found in world dictionary
 		validationMap["origin"] = "Origin symbol island component not found in world dict, ensure matches expected structure like example TS-PR-HF and island abbreviation (PR) is correct"
@@ -56,11 +63,11 @@ func CalculateTravelTime(world World, a string, b string, slowestSpeed int) (map
 	}
 	if len(validationMap) > 0 {
 		// Return early if found validation issues
-		return validationMap, 0, 0
+		return validationMap, RoutePlan{}
 	}
 	// Get Locations Info from World
-	aLoc, aOk := world.Locations[a]
-	bLoc, bOk := world.Locations[b]
+	_, aOk := world.Locations[a]
+	_, bOk := world.Locations[b]
 	if !aOk {
 		validationMap["origin"] = "Could not map origin to known location, ensure matches expected structure like example TS-PR-HF and location exists"
 	}
@@ -69,37 +76,14 @@ func CalculateTravelTime(world World, a string, b string, slowestSpeed int) (map
 	}
 	if len(validationMap) > 0 {
 		// Return early if found validation issues
-		return validationMap, 0, 0
+		return validationMap, RoutePlan{}
 	}
-	var travelTime int
-	if aLoc.IslandName != bLoc.IslandName {
-		// Validate origin is a port in the originIsland definition
-		portListString := "["
-		for _, port := range originIsland.Ports {
-			if port.Symbol == a {
-				// Origin Found in Port List, Check that Dest is ConnectedLocation
-				if port.ConnectedLocation == b {
-					// Dest is ConnectedLocation, return travel time and fare
-					return validationMap, port.Duration, port.Fare
-				}
-				// Dest Not connected location
-				validationMap["destination"] = fmt.Sprintf("Origin and Destination are on different islands. Origin was found to be a port, but destination was not the connected location. Did you mean %s?", port.ConnectedLocation)
-				return validationMap, 0, 0
-			}
-			if len(portListString) == 1 {
-				portListString += port.Symbol
-			} else {
-				portListString += ", " + port.Symbol
-			}
-		}
-		// Origin not found in island ports list
-		portListString += "]"
-		validationMap["origin"] = fmt.Sprintf("Origin and Destination are on different islands. Origin was not found to be a port. Ports at this island are from the list: %s", portListString)
-		travelTime = 0
-	} else {
-		travelTime = int(math.Ceil(math.Sqrt(math.Pow(float64(bLoc.X - aLoc.X), 2) + math.Pow(float64(bLoc.Y - aLoc.Y), 2)))) * 10 / slowestSpeed
+	plan, reachable := PlanRoute(world, a, b, slowestSpeed, priority)
+	if !reachable {
+		validationMap["destination"] = fmt.Sprintf("No route found from %s to %s over land or through the ports connecting islands", a, b)
+		return validationMap, RoutePlan{}
 	}
-	return validationMap, travelTime, 0
+	return validationMap, plan
 }
 
 // Load location struct by unmarhsalling given yaml file
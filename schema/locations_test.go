@@ -0,0 +1,88 @@
+package schema
+
+import (
+	"reflect"
+	"testing"
+)
+
+// Islands AA, BB and CC are linked by ports, a fast dear crossing runs straight from AA to CC, DD has no ports
+var testRouteWorld = World{
+	Islands: map[string]Island{
+		"TS-AA": {Ports: map[string]Port{
+			"TS-AA-PT": {Symbol: "TS-AA-PT", ConnectedLocation: "TS-BB-PT", Fare: 10, Duration: 100},
+			"TS-AA-EX": {Symbol: "TS-AA-EX", ConnectedLocation: "TS-CC-PT", Fare: 200, Duration: 10},
+		}},
+		"TS-BB": {Ports: map[string]Port{
+			"TS-BB-XP": {Symbol: "TS-BB-XP", ConnectedLocation: "TS-CC-PT", Fare: 50, Duration: 50},
+		}},
+		"TS-CC": {Ports: map[string]Port{}},
+		"TS-DD": {Ports: map[string]Port{}},
+	},
+	Locations: map[string]Location{
+		"TS-AA-HF": {Symbol: "TS-AA-HF", IslandName: "AA", X: 0, Y: 0},
+		"TS-AA-PT": {Symbol: "TS-AA-PT", IslandName: "AA", X: 0, Y: 10},
+		"TS-AA-EX": {Symbol: "TS-AA-EX", IslandName: "AA", X: 10, Y: 0},
+		"TS-BB-PT": {Symbol: "TS-BB-PT", IslandName: "BB", X: 0, Y: 0},
+		"TS-BB-XP": {Symbol: "TS-BB-XP", IslandName: "BB", X: 0, Y: 10},
+		"TS-CC-PT": {Symbol: "TS-CC-PT", IslandName: "CC", X: 0, Y: 0},
+		"TS-DD-HF": {Symbol: "TS-DD-HF", IslandName: "DD", X: 0, Y: 0},
+	},
+}
+
+// Routes cross any number of ports, picking the fastest or cheapest plan, and invalid or unreachable locations are reported
+func TestPlanCaravanRoute(t *testing.T) {
+	tests := []struct {
+		name string
+		origin string
+		destination string
+		speed int
+		priority RoutePriority
+		want RoutePlan
+		wantInvalid []string
+	}{
+		{
+			name: "overland", origin: "TS-AA-HF", destination: "TS-AA-PT", speed: 1, priority: Route_Fastest,
+			want: RoutePlan{Legs: []RouteLeg{{"TS-AA-HF", "TS-AA-PT", false, 100, 0}}, Duration: 100},
+		},
+		{
+			name: "overland faster team", origin: "TS-AA-HF", destination: "TS-AA-PT", speed: 2, priority: Route_Fastest,
+			want: RoutePlan{Legs: []RouteLeg{{"TS-AA-HF", "TS-AA-PT", false, 50, 0}}, Duration: 50},
+		},
+		{
+			name: "fastest crossing", origin: "TS-AA-HF", destination: "TS-CC-PT", speed: 1, priority: Route_Fastest,
+			want: RoutePlan{Legs: []RouteLeg{
+				{"TS-AA-HF", "TS-AA-EX", false, 100, 0},
+				{"TS-AA-EX", "TS-CC-PT", true, 10, 200},
+			}, Duration: 110, Fare: 200},
+		},
+		{
+			name: "cheapest crossing through two ports", origin: "TS-AA-HF", destination: "TS-CC-PT", speed: 1, priority: Route_Cheapest,
+			want: RoutePlan{Legs: []RouteLeg{
+				{"TS-AA-HF", "TS-AA-PT", false, 100, 0},
+				{"TS-AA-PT", "TS-BB-PT", true, 100, 10},
+				{"TS-BB-PT", "TS-BB-XP", false, 100, 0},
+				{"TS-BB-XP", "TS-CC-PT", true, 50, 50},
+			}, Duration: 350, Fare: 60},
+		},
+		{name: "symbol not 3-part", origin: "TS-AA", destination: "TS-CC-PT", speed: 1, wantInvalid: []string{"origin"}},
+		{name: "unknown island", origin: "TS-AA-HF", destination: "TS-ZZ-HF", speed: 1, wantInvalid: []string{"destination"}},
+		{name: "unknown locations", origin: "TS-AA-NO", destination: "TS-BB-NO", speed: 1, wantInvalid: []string{"origin", "destination"}},
+		{name: "unreachable", origin: "TS-AA-HF", destination: "TS-DD-HF", speed: 1, wantInvalid: []string{"destination"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			validationMap, plan := PlanCaravanRoute(testRouteWorld, test.origin, test.destination, test.speed, test.priority)
+			if len(validationMap) != len(test.wantInvalid) {
+				t.Fatalf("got validation %v, want fields %v", validationMap, test.wantInvalid)
+			}
+			for _, field := range test.wantInvalid {
+				if _, ok := validationMap[field]; !ok {
+					t.Fatalf("got validation %v, want fields %v", validationMap, test.wantInvalid)
+				}
+			}
+			if len(test.wantInvalid) == 0 && !reflect.DeepEqual(plan, test.want) {
+				t.Errorf("got plan %+v, want %+v", plan, test.want)
+			}
+		})
+	}
+}
@@ -15,13 +15,14 @@ type MarketOrder struct {
 	ItemCategory ItemCategory `json:"item_category" binding:"required"`
 	ItemName string `json:"item_name" binding:"required"`
 	Quantity uint64 `json:"quantity" binding:"required"`
+	Price uint64 `json:"price,omitempty"` // limit price per unit, only used by LIMIT orders
 }
 
 // enum for assistant types
 type OrderType uint16
 const (
 	MARKET OrderType = 0
-	// LIMIT OrderType = 1
+	LIMIT OrderType = 1
 	// STOP OrderType = 2
 	// STOPLIMIT OrderType = 3
 )
@@ -32,14 +33,14 @@ func (s OrderType) String() string {
 
 var orderTypeToString = map[OrderType]string {
 	MARKET: "MARKET",
-	// LIMIT: "LIMIT",
+	LIMIT: "LIMIT",
 	// STOP: "STOP",
 	// STOPLIMIT: "STOPLIMIT",
 }
 
 var orderTypeToID = map[string]OrderType {
 	"MARKET": MARKET,
-	// "LIMIT": LIMIT,
+	"LIMIT": LIMIT,
 	// "STOP": STOP,
 	// "STOPLIMIT": STOPLIMIT,
 }
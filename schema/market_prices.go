@@ -0,0 +1,195 @@
+// Package schema defines database and JSON schema as structs, as well as functions for creating and using these structs
+package schema
+
+import (
+	"apricate/log"
+	"apricate/rdb"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/bits"
+	"strings"
+	"time"
+)
+
+// How much each unit bought (sold) multiplies (divides) an item's price pressure
+const MarketPriceImpactPerUnit float64 = 0.01
+// Bounds on price pressure, relative to the base price in markets.yaml
+const MarketPriceMinPressure float64 = 0.25
+const MarketPriceMaxPressure float64 = 4.0
+// Seconds for the distance between price pressure and base (1.0) to halve
+const MarketPriceDriftHalfLife float64 = 3600
+
+// Defines the live price state of a market, stored in the clearinghouse db
+// Pressure is keyed by 'EXPORTS|CATEGORY|ItemName' or 'IMPORTS|CATEGORY|ItemName' and multiplies the base price from markets.yaml.
+// Buys move only export prices and sells only import prices, so buying up an item does not raise what the market pays for it
+type MarketPrices struct {
+	UUID string `json:"uuid" binding:"required"`
+	LocationSymbol string `json:"location_symbol" binding:"required"`
+	Pressure map[string]float64 `json:"pressure" binding:"required"`
+	LastUpdate int64 `json:"last_update" binding:"required"`
+}
+
+func NewMarketPrices(locationSymbol string, timestamp time.Time) *MarketPrices {
+	return &MarketPrices{
+		UUID: "MarketPrices-" + locationSymbol,
+		LocationSymbol: locationSymbol,
+		Pressure: make(map[string]float64),
+		LastUpdate: timestamp.Unix(),
+	}
+}
+
+// Get the pressure key for an item bought from (isBuy) or sold to the market, produce sizes are stripped as size is applied separately
+func marketPriceKey(isBuy bool, category ItemCategory, itemName string) string {
+	direction := "IMPORTS"
+	if isBuy {
+		direction = "EXPORTS"
+	}
+	return direction + "|" + category.String() + "|" + strings.Split(itemName, "|")[0]
+}
+
+// Drift all pressures back toward base for the time elapsed since the last update
+func (m *MarketPrices) ApplyDrift(timestamp time.Time) {
+	elapsed := float64(timestamp.Unix() - m.LastUpdate)
+	if elapsed <= 0 {
+		return
+	}
+	decay := math.Pow(0.5, elapsed / MarketPriceDriftHalfLife)
+	for key, pressure := range m.Pressure {
+		pressure = 1 + (pressure - 1) * decay
+		if math.Abs(pressure - 1) < 0.001 {
+			delete(m.Pressure, key)
+			continue
+		}
+		m.Pressure[key] = pressure
+	}
+	m.LastUpdate = timestamp.Unix()
+}
+
+// Get the current pressure for key, 1 if the price is at base
+func (m *MarketPrices) pressure(key string) float64 {
+	pressure, ok := m.Pressure[key]
+	if !ok {
+		return 1
+	}
+	return pressure
+}
+
+// Get the pressure after trading one more unit, clamped so it stops moving at the bounds
+func nextPressure(pressure float64, isBuy bool) float64 {
+	if isBuy {
+		return math.Min(MarketPriceMaxPressure, pressure * (1 + MarketPriceImpactPerUnit))
+	}
+	return math.Max(MarketPriceMinPressure, pressure / (1 + MarketPriceImpactPerUnit))
+}
+
+// Get the price of one unit at the given pressure, never below 1
+func unitPrice(basePrice uint64, pressure float64) uint64 {
+	price := uint64(math.Round(float64(basePrice) * pressure))
+	if price < 1 {
+		price = 1
+	}
+	return price
+}
+
+// Get the total value of trading quantity units, each priced at the pressure left by the units before it
+// Returns value, and bool is false if the value overflows uint64
+func (m *MarketPrices) QuoteTrade(isBuy bool, category ItemCategory, itemName string, basePrice uint64, quantity uint64) (uint64, bool) {
+	pressure := m.pressure(marketPriceKey(isBuy, category, itemName))
+	var total, carry uint64
+	for quantity > 0 {
+		price := unitPrice(basePrice, pressure)
+		next := nextPressure(pressure, isBuy)
+		if next == pressure {
+			// at a bound, the rest trade at this price
+			rest, restOk := MulUint64(price, quantity)
+			total, carry = bits.Add64(total, rest, 0)
+			return total, restOk && carry == 0
+		}
+		total, carry = bits.Add64(total, price, 0)
+		if carry != 0 {
+			return total, false
+		}
+		pressure = next
+		quantity--
+	}
+	return total, true
+}
+
+// Push the export price of an item up for buys or its import price down for sells
+func (m *MarketPrices) ApplyTrade(timestamp time.Time, isBuy bool, category ItemCategory, itemName string, quantity uint64) {
+	m.ApplyDrift(timestamp)
+	if m.Pressure == nil {
+		m.Pressure = make(map[string]float64)
+	}
+	key := marketPriceKey(isBuy, category, itemName)
+	impact := math.Pow(1 + MarketPriceImpactPerUnit, float64(quantity))
+	pressure := m.pressure(key)
+	if isBuy {
+		pressure *= impact
+	} else {
+		pressure /= impact
+	}
+	m.Pressure[key] = math.Max(MarketPriceMinPressure, math.Min(MarketPriceMaxPressure, pressure))
+	m.LastUpdate = timestamp.Unix()
+}
+
+// Get the live price of one unit bought from (isBuy) or sold to the market for the given base price
+func (m *MarketPrices) GetPrice(isBuy bool, category ItemCategory, itemName string, basePrice uint64) uint64 {
+	return unitPrice(basePrice, m.pressure(marketPriceKey(isBuy, category, itemName)))
+}
+
+// Get a copy of the given market with live prices in place of the base prices
+func (m *MarketPrices) PriceMarket(market Market) Market {
+	priceIOField := func(ioField MarketIOField, isBuy bool) MarketIOField {
+		priceDict := func(category ItemCategory, dict map[string]uint64) map[string]uint64 {
+			if dict == nil {
+				return nil
+			}
+			res := make(map[string]uint64, len(dict))
+			for name, basePrice := range dict {
+				res[name] = m.GetPrice(isBuy, category, name, basePrice)
+			}
+			return res
+		}
+		return MarketIOField{
+			Produce: priceDict(PRODUCE, ioField.Produce),
+			Seeds: priceDict(SEED, ioField.Seeds),
+			Goods: priceDict(GOOD, ioField.Goods),
+			Tools: priceDict(TOOL, ioField.Tools),
+		}
+	}
+	market.Imports = priceIOField(market.Imports, false)
+	market.Exports = priceIOField(market.Exports, true)
+	return market
+}
+
+// Get market prices from DB, creating fresh state seeded at base prices if not found, with drift applied up to timestamp
+func GetMarketPricesFromDB (locationSymbol string, timestamp time.Time, tdb rdb.InteractiveDB) (MarketPrices, error) {
+	// Get market prices json
+	someJson, getError := tdb.GetJsonData("MarketPrices-" + locationSymbol, ".")
+	if getError != nil {
+		if fmt.Sprint(getError) == "redis: nil" {
+			// market prices not found, seed from base
+			return *NewMarketPrices(locationSymbol, timestamp), nil
+		}
+		// error
+		return MarketPrices{}, getError
+	}
+	// Got successfully, unmarshal
+	someData := MarketPrices{}
+	unmarshalErr := json.Unmarshal(someJson, &someData)
+	if unmarshalErr != nil {
+		log.Error.Fatalf("Could not unmarshal market prices json from DB: %v", unmarshalErr)
+		return MarketPrices{}, unmarshalErr
+	}
+	someData.ApplyDrift(timestamp)
+	return someData, nil
+}
+
+// Attempt to save market prices, returns error or nil if successful
+func SaveMarketPricesToDB(tdb rdb.InteractiveDB, marketPricesData *MarketPrices) error {
+	log.Debug.Printf("Saving market prices %s to DB", marketPricesData.UUID)
+	err := tdb.SetJsonData(marketPricesData.UUID, ".", marketPricesData)
+	return err
+}
@@ -0,0 +1,72 @@
+package schema
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+// Each unit of a trade is priced at the pressure left by the units before it, produce sizes share their item's price
+func TestQuoteTrade(t *testing.T) {
+	tests := []struct {
+		name string
+		pressure map[string]float64
+		isBuy bool
+		itemName string
+		basePrice uint64
+		quantity uint64
+		want uint64
+		wantOk bool
+	}{
+		{"buy at base", nil, true, "Cabbage Seeds", 100, 3, 100 + 101 + 102, true},
+		{"sell at base", nil, false, "Cabbage Seeds", 100, 3, 100 + 99 + 98, true},
+		{"nothing", nil, true, "Cabbage Seeds", 100, 0, 0, true},
+		{"cheap never free", nil, false, "Cabbage Seeds", 1, 2, 2, true},
+		{"buy at max bound", map[string]float64{"EXPORTS|SEEDS|Cabbage Seeds": MarketPriceMaxPressure}, true, "Cabbage Seeds", 100, 5, 5 * 400, true},
+		{"sell at min bound", map[string]float64{"IMPORTS|SEEDS|Cabbage Seeds": MarketPriceMinPressure}, false, "Cabbage Seeds", 100, 5, 5 * 25, true},
+		{"buys ignore import pressure", map[string]float64{"IMPORTS|SEEDS|Cabbage Seeds": MarketPriceMinPressure}, true, "Cabbage Seeds", 100, 1, 100, true},
+		{"produce size stripped", map[string]float64{"EXPORTS|SEEDS|Cabbage": 2}, true, "Cabbage|Large", 100, 1, 200, true},
+		{"overflow at bound", map[string]float64{"EXPORTS|SEEDS|Cabbage Seeds": MarketPriceMaxPressure}, true, "Cabbage Seeds", 1 << 61, 2, 0, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			prices := NewMarketPrices("TS-PR-HF", time.Unix(0, 0))
+			for key, pressure := range test.pressure {
+				prices.Pressure[key] = pressure
+			}
+			got, ok := prices.QuoteTrade(test.isBuy, SEED, test.itemName, test.basePrice, test.quantity)
+			if ok != test.wantOk || (ok && got != test.want) {
+				t.Errorf("got %d (ok %t), want %d (ok %t)", got, ok, test.want, test.wantOk)
+			}
+		})
+	}
+}
+
+// Pressure halves its distance to base every half life, and is dropped once back at base
+func TestApplyDrift(t *testing.T) {
+	tests := []struct {
+		name string
+		pressure float64
+		elapsed int64
+		want float64
+		wantKept bool
+	}{
+		{"one half life up", 2, 3600, 1.5, true},
+		{"two half lives up", 2, 7200, 1.25, true},
+		{"one half life down", 0.5, 3600, 0.75, true},
+		{"back at base", 1.0015, 3600, 1, false},
+		{"no time passed", 2, 0, 2, true},
+		{"clock behind", 2, -3600, 2, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			prices := NewMarketPrices("TS-PR-HF", time.Unix(1000000, 0))
+			prices.Pressure["EXPORTS|SEEDS|Cabbage Seeds"] = test.pressure
+			prices.ApplyDrift(time.Unix(1000000 + test.elapsed, 0))
+			got, kept := prices.Pressure["EXPORTS|SEEDS|Cabbage Seeds"]
+			if kept != test.wantKept || (kept && math.Abs(got - test.want) > 1e-9) {
+				t.Errorf("got %f (kept %t), want %f (kept %t)", got, kept, test.want, test.wantKept)
+			}
+		})
+	}
+}
@@ -16,6 +16,7 @@ type Market struct {
 	LocationSymbol string `yaml:"Location" json:"location_symbol" binding:"required"`
 	Imports MarketIOField `yaml:"Imports" json:"imports" binding:"required"`
 	Exports MarketIOField `yaml:"Exports" json:"exports" binding:"required"`
+	Hires map[string]uint64 `yaml:"Hires" json:"hires,omitempty"` // assistant archetype to coins charged to hire one
 }
 
 // Define a market import or export field
@@ -38,6 +39,17 @@ func Markets_load(path_to_markets_yaml string) map[string]Market {
 	if err != nil {
 		log.Error.Fatalln(err)
 	}
+	for symbol, market := range markets {
+		for archetype := range market.Hires {
+			assistantType, ok := GetAssistantType(archetype)
+			if !ok {
+				log.Error.Fatalf("Market %s hires unknown assistant archetype %s", symbol, archetype)
+			}
+			if !HireableAssistantTypes[assistantType] {
+				log.Error.Fatalf("Market %s hires archetype %s, which may only be summoned", symbol, archetype)
+			}
+		}
+	}
 	
 	return markets
 }
\ No newline at end of file
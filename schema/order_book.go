@@ -0,0 +1,227 @@
+// Package schema defines database and JSON schema as structs, as well as functions for creating and using these structs
+package schema
+
+import (
+	"apricate/log"
+	"apricate/rdb"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Highest limit price per unit, keeping the coins an order moves well within uint64
+const MaxLimitOrderPrice uint64 = 1000000000
+
+// Defines a limit order resting in a market's order book
+type LimitOrder struct {
+	UUID string `json:"uuid" binding:"required"`
+	ID int64 `json:"id" binding:"required"`
+	Username string `json:"username" binding:"required"`
+	LocationSymbol string `json:"location_symbol" binding:"required"`
+	TXType TXType `json:"transaction_type" binding:"required"`
+	ItemCategory ItemCategory `json:"item_category" binding:"required"`
+	ItemName string `json:"item_name" binding:"required"`
+	Price uint64 `json:"price" binding:"required"` // coins per unit
+	Quantity uint64 `json:"quantity" binding:"required"` // remaining unfilled quantity
+	Filled uint64 `json:"filled" binding:"required"`
+	Timestamp int64 `json:"timestamp" binding:"required"`
+}
+
+// Create a limit order with id taken from the market's order book by NextOrderID
+func NewLimitOrder(id int64, username string, timestamp time.Time, locationSymbol string, txType TXType, itemCategory ItemCategory, itemName string, price uint64, quantity uint64) *LimitOrder {
+	return &LimitOrder{
+		UUID: LimitOrderUUID(username, locationSymbol, id),
+		ID: id,
+		Username: username,
+		LocationSymbol: locationSymbol,
+		TXType: txType,
+		ItemCategory: itemCategory,
+		ItemName: itemName,
+		Price: price,
+		Quantity: quantity,
+		Filled: 0,
+		Timestamp: timestamp.Unix(),
+	}
+}
+
+// Get the uuid of a user's limit order in a market, ids are only unique within a market's order book
+func LimitOrderUUID(username string, locationSymbol string, id int64) string {
+	return username + "|" + locationSymbol + "|Order-" + fmt.Sprintf("%d", id)
+}
+
+// Defines a single fill between an incoming order and a resting order
+type OrderFill struct {
+	OrderUUID string `json:"order_uuid" binding:"required"` // the resting order that was filled
+	Username string `json:"username" binding:"required"` // owner of the resting order
+	ItemCategory ItemCategory `json:"item_category" binding:"required"`
+	ItemName string `json:"item_name" binding:"required"`
+	Price uint64 `json:"price" binding:"required"`
+	Quantity uint64 `json:"quantity" binding:"required"`
+}
+
+// Defines the order book of a market, stored in the clearinghouse db
+type OrderBook struct {
+	UUID string `json:"uuid" binding:"required"`
+	LocationSymbol string `json:"location_symbol" binding:"required"`
+	Bids []LimitOrder `json:"bids" binding:"required"` // sorted highest price first, then oldest first
+	Asks []LimitOrder `json:"asks" binding:"required"` // sorted lowest price first, then oldest first
+	LastOrderID int64 `json:"last_order_id"` // ids are assigned in sequence, so also order time priority
+}
+
+func NewOrderBook(locationSymbol string) *OrderBook {
+	return &OrderBook{
+		UUID: "OrderBook-" + locationSymbol,
+		LocationSymbol: locationSymbol,
+		Bids: make([]LimitOrder, 0),
+		Asks: make([]LimitOrder, 0),
+	}
+}
+
+// Take the next order id in sequence
+func (b *OrderBook) NextOrderID() int64 {
+	b.LastOrderID++
+	return b.LastOrderID
+}
+
+// Match an incoming order against the opposite side of the book using price-time priority.
+// Resting orders owned by the same user are skipped. Fills execute at the resting order's price.
+// A non-nil room limits the total quantity filled to each resting order's owner, owners missing from room are not filled.
+// Mutates both the incoming order and the book, returns the list of fills
+func (b *OrderBook) Match(order *LimitOrder, room map[string]uint64) []OrderFill {
+	fills := make([]OrderFill, 0)
+	var side []LimitOrder
+	if order.TXType == BUY {
+		side = b.Asks
+	} else {
+		side = b.Bids
+	}
+	remaining := make([]LimitOrder, 0, len(side))
+	for _, resting := range side {
+		crosses := (order.TXType == BUY && resting.Price <= order.Price) || (order.TXType == SELL && resting.Price >= order.Price)
+		sameItem := resting.ItemCategory == order.ItemCategory && resting.ItemName == order.ItemName
+		if order.Quantity == 0 || !crosses || !sameItem || resting.Username == order.Username {
+			remaining = append(remaining, resting)
+			continue
+		}
+		quantity := order.Quantity
+		if resting.Quantity < quantity {
+			quantity = resting.Quantity
+		}
+		if room != nil {
+			if room[resting.Username] < quantity {
+				quantity = room[resting.Username]
+			}
+			if quantity == 0 {
+				remaining = append(remaining, resting)
+				continue
+			}
+			room[resting.Username] -= quantity
+		}
+		log.Debug.Printf("Matched %s against %s for %s x%d at %d", order.UUID, resting.UUID, resting.ItemName, quantity, resting.Price)
+		fills = append(fills, OrderFill{
+			OrderUUID: resting.UUID,
+			Username: resting.Username,
+			ItemCategory: resting.ItemCategory,
+			ItemName: resting.ItemName,
+			Price: resting.Price,
+			Quantity: quantity,
+		})
+		order.Quantity -= quantity
+		order.Filled += quantity
+		resting.Quantity -= quantity
+		resting.Filled += quantity
+		if resting.Quantity > 0 {
+			remaining = append(remaining, resting)
+		}
+	}
+	if order.TXType == BUY {
+		b.Asks = remaining
+	} else {
+		b.Bids = remaining
+	}
+	return fills
+}
+
+// Add an order to the correct side of the book, keeping price-time priority
+func (b *OrderBook) Insert(order LimitOrder) {
+	if order.TXType == BUY {
+		b.Bids = append(b.Bids, order)
+		sort.SliceStable(b.Bids, func(i, j int) bool {
+			if b.Bids[i].Price == b.Bids[j].Price {
+				return b.Bids[i].ID < b.Bids[j].ID
+			}
+			return b.Bids[i].Price > b.Bids[j].Price
+		})
+	} else {
+		b.Asks = append(b.Asks, order)
+		sort.SliceStable(b.Asks, func(i, j int) bool {
+			if b.Asks[i].Price == b.Asks[j].Price {
+				return b.Asks[i].ID < b.Asks[j].ID
+			}
+			return b.Asks[i].Price < b.Asks[j].Price
+		})
+	}
+}
+
+// Remove an order from the book by uuid, returns the removed order and whether it was found
+func (b *OrderBook) Remove(uuid string) (LimitOrder, bool) {
+	for i, order := range b.Bids {
+		if order.UUID == uuid {
+			b.Bids = append(b.Bids[:i], b.Bids[i+1:]...)
+			return order, true
+		}
+	}
+	for i, order := range b.Asks {
+		if order.UUID == uuid {
+			b.Asks = append(b.Asks[:i], b.Asks[i+1:]...)
+			return order, true
+		}
+	}
+	return LimitOrder{}, false
+}
+
+// Get all orders in the book belonging to the given user
+func (b *OrderBook) OrdersForUser(username string) []LimitOrder {
+	res := make([]LimitOrder, 0)
+	for _, order := range b.Bids {
+		if order.Username == username {
+			res = append(res, order)
+		}
+	}
+	for _, order := range b.Asks {
+		if order.Username == username {
+			res = append(res, order)
+		}
+	}
+	return res
+}
+
+// Get order book from DB, bool is order book found
+func GetOrderBookFromDB (locationSymbol string, tdb rdb.InteractiveDB) (OrderBook, bool, error) {
+	// Get order book json
+	someJson, getError := tdb.GetJsonData("OrderBook-" + locationSymbol, ".")
+	if getError != nil {
+		if fmt.Sprint(getError) == "redis: nil" {
+			// order book not found
+			return OrderBook{}, false, nil
+		}
+		// error
+		return OrderBook{}, false, getError
+	}
+	// Got successfully, unmarshal
+	someData := OrderBook{}
+	unmarshalErr := json.Unmarshal(someJson, &someData)
+	if unmarshalErr != nil {
+		log.Error.Fatalf("Could not unmarshal order book json from DB: %v", unmarshalErr)
+		return OrderBook{}, false, unmarshalErr
+	}
+	return someData, true, nil
+}
+
+// Attempt to save order book, returns error or nil if successful
+func SaveOrderBookToDB(tdb rdb.InteractiveDB, orderBookData *OrderBook) error {
+	log.Debug.Printf("Saving order book %s to DB", orderBookData.UUID)
+	err := tdb.SetJsonData(orderBookData.UUID, ".", orderBookData)
+	return err
+}
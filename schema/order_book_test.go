@@ -0,0 +1,128 @@
+package schema
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+// Order ids follow on from the last one taken, including for books loaded back from the DB
+func TestNextOrderID(t *testing.T) {
+	tests := []struct {
+		name string
+		lastOrderID int64
+		want []int64
+	}{
+		{"new book", 0, []int64{1, 2, 3}},
+		{"loaded book", 41, []int64{42, 43}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			book := NewOrderBook("TS-PR-HF")
+			book.LastOrderID = test.lastOrderID
+			got := make([]int64, 0)
+			for range test.want {
+				got = append(got, book.NextOrderID())
+			}
+			if !reflect.DeepEqual(got, test.want) || book.LastOrderID != test.want[len(test.want) - 1] {
+				t.Errorf("got ids %v (last %d), want %v", got, book.LastOrderID, test.want)
+			}
+		})
+	}
+}
+
+// Incoming orders fill against crossing resting orders by price then time, at the resting price, within each owner's room
+func TestOrderBookMatch(t *testing.T) {
+	type resting struct {
+		username string
+		price uint64
+		quantity uint64
+	}
+	tests := []struct {
+		name string
+		resting []resting
+		txType TXType
+		price uint64
+		quantity uint64
+		room map[string]uint64
+		want []OrderFill
+		wantLeft uint64
+	}{
+		{
+			name: "buy takes cheapest asks first",
+			resting: []resting{{"Ann", 12, 2}, {"Bob", 10, 2}, {"Cat", 11, 2}},
+			txType: BUY, price: 11, quantity: 3,
+			want: []OrderFill{{Username: "Bob", Price: 10, Quantity: 2}, {Username: "Cat", Price: 11, Quantity: 1}},
+			wantLeft: 0,
+		},
+		{
+			name: "buy below every ask",
+			resting: []resting{{"Ann", 12, 2}},
+			txType: BUY, price: 11, quantity: 3,
+			want: []OrderFill{},
+			wantLeft: 3,
+		},
+		{
+			name: "sell takes highest bids first, oldest first at a price",
+			resting: []resting{{"Ann", 9, 1}, {"Bob", 10, 1}, {"Cat", 10, 1}},
+			txType: SELL, price: 9, quantity: 2,
+			want: []OrderFill{{Username: "Bob", Price: 10, Quantity: 1}, {Username: "Cat", Price: 10, Quantity: 1}},
+			wantLeft: 0,
+		},
+		{
+			name: "own orders skipped",
+			resting: []resting{{"Farmer", 10, 2}, {"Bob", 11, 2}},
+			txType: BUY, price: 11, quantity: 2,
+			want: []OrderFill{{Username: "Bob", Price: 11, Quantity: 2}},
+			wantLeft: 0,
+		},
+		{
+			name: "sell limited by buyers' room",
+			resting: []resting{{"Ann", 10, 5}, {"Bob", 10, 5}},
+			txType: SELL, price: 10, quantity: 6,
+			room: map[string]uint64{"Ann": 2},
+			want: []OrderFill{{Username: "Ann", Price: 10, Quantity: 2}},
+			wantLeft: 4,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			book := NewOrderBook("TS-PR-HF")
+			restingType := BUY
+			if test.txType == BUY {
+				restingType = SELL
+			}
+			for _, order := range test.resting {
+				book.Insert(*NewLimitOrder(book.NextOrderID(), order.username, time.Unix(0, 0), "TS-PR-HF", restingType, SEED, "Cabbage Seeds", order.price, order.quantity))
+			}
+			before := make(map[string]uint64)
+			for _, order := range append(book.Bids, book.Asks...) {
+				before[order.Username] = order.Quantity
+			}
+			order := NewLimitOrder(book.NextOrderID(), "Farmer", time.Unix(0, 0), "TS-PR-HF", test.txType, SEED, "Cabbage Seeds", test.price, test.quantity)
+			fills := book.Match(order, test.room)
+			got := make([]OrderFill, 0)
+			filled := make(map[string]uint64)
+			for _, fill := range fills {
+				got = append(got, OrderFill{Username: fill.Username, Price: fill.Price, Quantity: fill.Quantity})
+				filled[fill.Username] += fill.Quantity
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("got fills %+v, want %+v", got, test.want)
+			}
+			if order.Quantity != test.wantLeft || order.Filled != test.quantity - test.wantLeft {
+				t.Errorf("got order left %d filled %d, want left %d", order.Quantity, order.Filled, test.wantLeft)
+			}
+			// filled resting orders leave the book, partly filled ones keep their place
+			left := make(map[string]uint64)
+			for _, order := range append(book.Bids, book.Asks...) {
+				left[order.Username] = order.Quantity
+			}
+			for username, quantity := range before {
+				if left[username] != quantity - filled[username] {
+					t.Errorf("got %s resting %d, want %d", username, left[username], quantity - filled[username])
+				}
+			}
+		})
+	}
+}
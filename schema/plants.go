@@ -32,6 +32,7 @@ type PlantDefinition struct {
 	Description string `yaml:"Description" json:"description" binding:"required"`
 	MinSize string `yaml:"MinSize" json:"min_size" binding:"required"`
 	MaxSize string `yaml:"MaxSize" json:"max_size" binding:"required"`
+	Livestock bool `yaml:"Livestock" json:"livestock,omitempty"` // livestock may only be planted on farms whose buildings allow them
 	GrowthStages []GrowthStage `yaml:"GrowthStages" json:"growth_stages" binding:"required"`
 }
 
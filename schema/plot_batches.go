@@ -0,0 +1,110 @@
+// Package schema defines database and JSON schema as structs, as well as functions for creating and using these structs
+package schema
+
+import (
+	"apricate/responses"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Most plots a batch of plot operations may list
+const MaxPlotBatchSize int = 64
+
+// Defines a batch plant request body, plots are named within the farm such as Plot-0
+type PlotBatchPlantBody struct {
+	Plots []PlotBatchPlantEntry `json:"plots" binding:"required"`
+}
+
+// Defines the planting of one plot in a batch
+type PlotBatchPlantEntry struct {
+	PlotID string `json:"plot_id" binding:"required"`
+	PlotPlantBody `yaml:",inline"`
+}
+
+// Defines a batch interact request body, plots are named within the farm such as Plot-0
+type PlotBatchInteractBody struct {
+	Plots []PlotBatchInteractEntry `json:"plots" binding:"required"`
+}
+
+// Defines the interaction with one plot in a batch
+type PlotBatchInteractEntry struct {
+	PlotID string `json:"plot_id" binding:"required"`
+	PlotInteractBody `yaml:",inline"`
+}
+
+// Defines a batch clear request body, plots are named within the farm such as Plot-0
+type PlotBatchClearBody struct {
+	PlotIDs []string `json:"plot_ids" binding:"required"`
+}
+
+// Defines the result of one plot in a batch, code and message are those the single plot route would respond with
+type PlotBatchResult struct {
+	PlotID string `json:"plot_id" binding:"required"`
+	Code responses.ResponseCode `json:"code" binding:"required"`
+	Message string `json:"message,omitempty"`
+	Plot *Plot `json:"plot,omitempty"`
+	NextStage *GrowthStage `json:"next_stage,omitempty"`
+}
+
+// Defines a batch response body, warehouse is the farm's warehouse after the whole batch
+type PlotBatchResponse struct {
+	Warehouse *Warehouse `json:"warehouse" binding:"required"`
+	Results []PlotBatchResult `json:"results" binding:"required"`
+}
+
+// Validate the plots listed by a batch, return validation map
+func ValidatePlotBatch(plotIDs []string) map[string]string {
+	res := make(map[string]string)
+	if len(plotIDs) < 1 {
+		res["plots"] = "Must specify at least one plot"
+	} else if len(plotIDs) > MaxPlotBatchSize {
+		res["plots"] = fmt.Sprintf("Too Long, expect maximum %d plots", MaxPlotBatchSize)
+	}
+	seen := make(map[string]bool, len(plotIDs))
+	for _, plotID := range plotIDs {
+		if !strings.HasPrefix(plotID, "Plot-") {
+			res["plot_id"] = fmt.Sprintf("Malformed plot id, format must be 'Plot-[id-number]' received: %s", plotID)
+		}
+		if seen[plotID] {
+			res["plot_id"] = fmt.Sprintf("Plot listed more than once: %s", plotID)
+		}
+		seen[plotID] = true
+	}
+	return res
+}
+
+// Get the uuid of the farm's plot named plotID, such as Plot-0
+func (f *Farm) PlotUUID(plotID string) string {
+	return f.UUID + "|" + plotID
+}
+
+// Get the seeds a batch of plantings takes from the warehouse
+func BatchSeeds(entries []PlotBatchPlantEntry) Wareset {
+	seeds := make(map[string]uint64)
+	for _, entry := range entries {
+		seeds[entry.SeedName] += uint64(entry.SeedQuantity)
+	}
+	return Wareset{Seeds: seeds}
+}
+
+// Get the consumables a batch of interactions with the farm's plots takes from the warehouse.
+// Only interactions the plots are ready for at now are counted, the rest fail without consuming anything
+func (f *Farm) BatchConsumables(entries []PlotBatchInteractEntry, plants map[string]PlantDefinition, tools map[string]uint64, now int64) Wareset {
+	goods := make(map[string]uint64)
+	for _, entry := range entries {
+		plot, foundPlot := f.Plots[f.PlotUUID(entry.PlotID)]
+		if entry.Consumable == "" || !foundPlot || plot.PlantedPlant == nil || plot.GrowthCompleteTimestamp > now {
+			continue
+		}
+		plantDef, plantDefOk := plants[plot.PlantedPlant.PlantType]
+		if !plantDefOk {
+			continue
+		}
+		code, _, used, _, _, _, _ := plot.IsInteractable(entry.PlotInteractBody, plantDef, math.MaxUint64, tools)
+		if code == responses.Generic_Success {
+			goods[strings.Title(strings.ToLower(entry.Consumable))] += used
+		}
+	}
+	return Wareset{Goods: goods}
+}
@@ -0,0 +1,47 @@
+package schema
+
+import (
+	"reflect"
+	"testing"
+)
+
+// Only the consumables of interactions the plots are ready for are taken, summed over the batch and scaled by each plot's plants
+func TestBatchConsumables(t *testing.T) {
+	plants := Plants_load("../yaml/plants.yaml")
+	now := int64(1000000)
+	farm := Farm{UUID: "Farmer|Farm-TS-PR-HF", Plots: map[string]Plot{
+		"Farmer|Farm-TS-PR-HF|Plot-0": {Quantity: 1, GrowthCompleteTimestamp: now, PlantedPlant: NewPlant("Cabbage", Small)},
+		"Farmer|Farm-TS-PR-HF|Plot-1": {Quantity: 2, GrowthCompleteTimestamp: now - 60, PlantedPlant: NewPlant("Cabbage", Small)},
+		"Farmer|Farm-TS-PR-HF|Plot-2": {Quantity: 1, GrowthCompleteTimestamp: now + 1, PlantedPlant: NewPlant("Cabbage", Small)},
+		"Farmer|Farm-TS-PR-HF|Plot-3": {Quantity: 0},
+	}}
+	fertilize := func(plotID string, consumable string) PlotBatchInteractEntry {
+		return PlotBatchInteractEntry{PlotID: plotID, PlotInteractBody: PlotInteractBody{Action: "Fertilize", Consumable: consumable}}
+	}
+	pitchfork := map[string]uint64{"Pitchfork": 1}
+	tests := []struct {
+		name string
+		entries []PlotBatchInteractEntry
+		tools map[string]uint64
+		want map[string]uint64
+	}{
+		{"one plot", []PlotBatchInteractEntry{fertilize("Plot-0", "Fertilizer")}, pitchfork, map[string]uint64{"Fertilizer": 4}},
+		{"summed over plots", []PlotBatchInteractEntry{fertilize("Plot-0", "fertilizer"), fertilize("Plot-1", "FERTILIZER")}, pitchfork, map[string]uint64{"Fertilizer": 12}},
+		{"each consumable", []PlotBatchInteractEntry{fertilize("Plot-0", "Fertilizer"), fertilize("Plot-1", "Enchanted Fertilizer")}, pitchfork, map[string]uint64{"Fertilizer": 4, "Enchanted Fertilizer": 8}},
+		{"still growing", []PlotBatchInteractEntry{fertilize("Plot-2", "Fertilizer")}, pitchfork, map[string]uint64{}},
+		{"nothing planted", []PlotBatchInteractEntry{fertilize("Plot-3", "Fertilizer")}, pitchfork, map[string]uint64{}},
+		{"unknown plot", []PlotBatchInteractEntry{fertilize("Plot-9", "Fertilizer")}, pitchfork, map[string]uint64{}},
+		{"no consumable", []PlotBatchInteractEntry{fertilize("Plot-0", "")}, pitchfork, map[string]uint64{}},
+		{"consumable not an option", []PlotBatchInteractEntry{fertilize("Plot-0", "Water")}, pitchfork, map[string]uint64{}},
+		{"missing tool", []PlotBatchInteractEntry{fertilize("Plot-0", "Fertilizer")}, map[string]uint64{}, map[string]uint64{}},
+		{"wrong action", []PlotBatchInteractEntry{{PlotID: "Plot-0", PlotInteractBody: PlotInteractBody{Action: "Weed", Consumable: "Fertilizer"}}}, map[string]uint64{"Hoe": 1}, map[string]uint64{}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := farm.BatchConsumables(test.entries, plants, test.tools, now)
+			if !reflect.DeepEqual(got.Goods, test.want) {
+				t.Errorf("got %v, want %v", got.Goods, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,64 @@
+// Package schema defines database and JSON schema as structs, as well as functions for creating and using these structs
+package schema
+
+import (
+	"apricate/responses"
+	"fmt"
+)
+
+// Most actions a plot queue may hold
+const MaxPlotQueueLength int = 32
+
+// Defines a queue of actions the server takes on a plot, each as soon as the plot is ready for it
+type PlotQueue struct {
+	Actions []PlotInteractBody `json:"actions" binding:"required"`
+	Paused bool `json:"paused"` // set when an action fails, until the queue is replaced
+	Failure *PlotQueueFailure `json:"failure,omitempty"`
+}
+
+// Defines the failure of a queued action, which pauses the queue
+type PlotQueueFailure struct {
+	Action PlotInteractBody `json:"action" binding:"required"`
+	Code responses.ResponseCode `json:"code" binding:"required"`
+	Message string `json:"message" binding:"required"`
+	Timestamp int64 `json:"timestamp" binding:"required"`
+}
+
+// Defines a plot queue request body
+type PlotQueueBody struct {
+	Actions []PlotInteractBody `json:"actions" binding:"required"`
+}
+
+// Validate plot queue body, return validation map
+func ValidatePlotQueueBody(body PlotQueueBody) map[string]string {
+	res := make(map[string]string)
+	if len(body.Actions) < 1 {
+		res["actions"] = "Must specify at least one action to queue"
+	} else if len(body.Actions) > MaxPlotQueueLength {
+		res["actions"] = fmt.Sprintf("Too Long, expect maximum %d actions", MaxPlotQueueLength)
+	}
+	for _, action := range body.Actions {
+		if action.Action == "" {
+			res["actions"] = "Each queued action must specify an action"
+		}
+	}
+	return res
+}
+
+// Check if the queue has an action for the plot to take at now
+func (p *Plot) IsQueueReady(now int64) bool {
+	return p.Queue != nil && !p.Queue.Paused && len(p.Queue.Actions) > 0 && p.GrowthCompleteTimestamp <= now
+}
+
+// Remove and return the next action from the queue, the queue is removed once empty
+func (p *Plot) PopQueuedAction() PlotInteractBody {
+	action := p.Queue.Actions[0]
+	remaining := make([]PlotInteractBody, len(p.Queue.Actions) - 1)
+	copy(remaining, p.Queue.Actions[1:])
+	if len(remaining) == 0 {
+		p.Queue = nil
+	} else {
+		p.Queue = &PlotQueue{Actions: remaining}
+	}
+	return action
+}
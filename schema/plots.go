@@ -5,7 +5,9 @@ import (
 	"apricate/log"
 	"apricate/rdb"
 	"apricate/responses"
+	"encoding/binary"
 	"fmt"
+	"hash/fnv"
 	"math"
 	"math/rand"
 	"strings"
@@ -21,6 +23,7 @@ type Plot struct {
 	Quantity uint `json:"plant_quantity" binding:"required"`
 	GrowthCompleteTimestamp int64 `json:"growth_complete_timestamp" binding:"required"`
 	PlantedPlant *Plant `json:"plant" binding:"required"`
+	Queue *PlotQueue `json:"queue,omitempty"` // actions scheduled by the player, nil if none
 }
 
 func NewPlot(username string, countOfPlots uint64, locationSymbol string, capacity Size) *Plot {
@@ -35,7 +38,7 @@ func NewPlot(username string, countOfPlots uint64, locationSymbol string, capaci
 	}
 }
 
-func NewPlots(pdb rdb.Database, username string, countOfPlots uint64, locationSymbol string, capacities []Size) map[string]Plot {
+func NewPlots(pdb rdb.InteractiveDB, username string, countOfPlots uint64, locationSymbol string, capacities []Size) map[string]Plot {
 	res := make(map[string]Plot, len(capacities))
 	for i, size := range capacities {
 		plot := NewPlot(username, countOfPlots + uint64(i), locationSymbol, size)
@@ -51,6 +54,20 @@ type HarvestProduce struct {
 	Goods map[string]uint64 `json:"goods" binding:"required"`
 }
 
+func (h *HarvestProduce) TotalSize() uint64 {
+	size := uint64(0)
+	for _, q := range h.Produce {
+		size += q
+	}
+	for _, q := range h.Seeds {
+		size += q
+	}
+	for _, q := range h.Goods {
+		size += q
+	}
+	return size
+}
+
 // Defines a plot plant request body
 type PlotPlantBody struct {
 	SeedName string `json:"name" binding:"required"`
@@ -167,7 +184,16 @@ func (p *Plot) IsInteractable(pib PlotInteractBody, plantDef PlantDefinition, co
 	return responses.Invalid_Plot_Action, 0, 0, nil, 0, false, invalidActionMsg
 }
 
-func (p *Plot) CalculateProduce(growthHarvest *GrowthHarvest) HarvestProduce {
+// Get the RNG for a harvest of this plot at timestamp, the same seed, plot, and timestamp always produce the same harvest
+func (p *Plot) HarvestRand(seed int64, timestamp time.Time) *rand.Rand {
+	hash := fnv.New64a()
+	hash.Write([]byte(p.UUID))
+	binary.Write(hash, binary.LittleEndian, timestamp.Unix())
+	return rand.New(rand.NewSource(seed ^ int64(hash.Sum64())))
+}
+
+// Calculate harvest for the plot, drawing all randomness from rng
+func (p *Plot) CalculateProduce(rng *rand.Rand, growthHarvest *GrowthHarvest) HarvestProduce {
 	harvest := HarvestProduce{
 		Produce: make(map[string]uint64),
 		Seeds: make(map[string]uint64),
@@ -178,15 +204,14 @@ func (p *Plot) CalculateProduce(growthHarvest *GrowthHarvest) HarvestProduce {
 	sizeFloat := float64(size)
 	totalYield := p.PlantedPlant.Yield
 	quantityModifier := 1 + ((totalYield - 1)/2)
-	quantityRNG := 0.8 + rand.Float64() * (1.2 - 0.8)
-	rand.Seed(time.Now().UnixNano())
+	quantityRNG := 0.8 + rng.Float64() * (1.2 - 0.8)
 	randMin := 0.0
 	randMax := 1.0
 	log.Debug.Println(growthHarvest)
 	// Calculate random rngModifiers for each plant
 	harvestRNG := make([]float64, p.Quantity)
 	for i := uint(0); i < p.Quantity; i++ {
-		harvestRNG[i] = randMin + rand.Float64() * (randMax - randMin)
+		harvestRNG[i] = randMin + rng.Float64() * (randMax - randMin)
 	}
 	// Calculate Produce - Quantity Affected By AddedYield NOT Size
 	for produceName, harvestChance := range growthHarvest.Produce {
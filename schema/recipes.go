@@ -0,0 +1,85 @@
+// Package schema defines database and JSON schema as structs, as well as functions for creating and using these structs
+package schema
+
+import (
+	"apricate/filemngr"
+	"apricate/log"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Most batches of a recipe crafted at once
+const MaxCraftBatches uint64 = 1000
+
+// Define recipe dictionary entry, amounts are per batch
+type Recipe struct {
+	Name string `yaml:"Name" json:"name" binding:"required"`
+	Description string `yaml:"Description" json:"description" binding:"required"`
+	Category string `yaml:"Category" json:"category,omitempty"` // if set, the farm must have a building unlocking this recipe category, such as a Kitchen
+	Materials Wareset `yaml:"Materials" json:"materials" binding:"required"` // consumed from the farm's warehouse
+	Products Wareset `yaml:"Products" json:"products" binding:"required"` // added to the farm's warehouse when collected
+	CraftingTime int `yaml:"CraftingTime" json:"crafting_time" binding:"required"` // seconds
+}
+
+// Defines a craft request body
+type CraftBody struct {
+	Recipe string `json:"recipe" binding:"required"`
+	Quantity uint64 `json:"quantity" binding:"required"` // number of batches, at most MaxCraftBatches
+}
+
+// Define a recipe being crafted on a farm
+type Crafting struct {
+	Recipe string `json:"recipe" binding:"required"`
+	Quantity uint64 `json:"quantity" binding:"required"`
+	Products Wareset `json:"products" binding:"required"`
+	CompleteTimestamp int64 `json:"complete_timestamp" binding:"required"`
+}
+
+// Multiply each item in the wareset by quantity, bool is false if any quantity overflows
+func (ws *Wareset) Scale(quantity uint64) (Wareset, bool) {
+	ok := true
+	scale := func(items map[string]uint64) map[string]uint64 {
+		if len(items) == 0 {
+			return nil
+		}
+		scaled := make(map[string]uint64, len(items))
+		for name, q := range items {
+			scaledQuantity, scaledOk := MulUint64(q, quantity)
+			ok = ok && scaledOk
+			scaled[name] = scaledQuantity
+		}
+		return scaled
+	}
+	return Wareset{
+		Tools: scale(ws.Tools),
+		Produce: scale(ws.Produce),
+		Seeds: scale(ws.Seeds),
+		Goods: scale(ws.Goods),
+	}, ok
+}
+
+// Add all of wares to the warehouse
+func (w *Warehouse) AddWares(wares Wareset) {
+	categories := map[ItemCategory]map[string]uint64{GOOD: wares.Goods, SEED: wares.Seeds, PRODUCE: wares.Produce, TOOL: wares.Tools}
+	for category, items := range categories {
+		for name, quantity := range items {
+			w.AddItem(category, name, quantity)
+		}
+	}
+}
+
+// Load recipe struct by unmarhsalling given yaml file
+func Recipes_load(path_to_recipes_yaml string) map[string]Recipe {
+	recipesBytes, readErr := filemngr.ReadFileToBytes(path_to_recipes_yaml)
+	if readErr != nil {
+		// Essential to server start
+		panic(readErr)
+	}
+	var recipes map[string]Recipe
+	err := yaml.Unmarshal(recipesBytes, &recipes)
+	if err != nil {
+		log.Error.Fatalln(err)
+	}
+
+	return recipes
+}
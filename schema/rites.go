@@ -4,6 +4,9 @@ package schema
 import (
 	"apricate/filemngr"
 	"apricate/log"
+	"bytes"
+	"encoding/json"
+	"fmt"
 
 	"gopkg.in/yaml.v3"
 )
@@ -20,6 +23,17 @@ type Rite struct {
 	RejectionTime int `yaml:"RejectionTime" json:"lattice_rejection_time" binding:"required"`
 	Currencies map[string]uint64 `yaml:"Currencies" json:"currencies" binding:"required"`
 	Materials Wareset `yaml:"Materials" json:"materials" binding:"required"`
+	Effects []RiteEffect `yaml:"Effects" json:"effects,omitempty"` // applied in order once the ritual is conducted
+}
+
+// Define an effect of conducting a rite, only the fields used by its type are set
+type RiteEffect struct {
+	Type RiteEffectType `yaml:"Type" json:"type" binding:"required"`
+	Archetype *AssistantTypes `yaml:"Archetype" json:"archetype,omitempty"` // Summon Assistant
+	Seconds int64 `yaml:"Seconds" json:"seconds,omitempty"` // Hasten Growth, Grant Farm Bonus duration
+	Yield float64 `yaml:"Yield" json:"yield,omitempty"` // Add Yield
+	Wares Wareset `yaml:"Wares" json:"wares,omitempty"` // Grant Wares, added to the farm's warehouse after the rite's materials are consumed
+	Bonus *FarmBonuses `yaml:"Bonus" json:"bonus,omitempty"` // Grant Farm Bonus
 }
 
 // Load rite struct by unmarhsalling given yaml file
@@ -34,6 +48,80 @@ func Rites_load(path_to_rites_yaml string) map[string]Rite {
 	if err != nil {
 		log.Error.Fatalln(err)
 	}
+	for symbol, rite := range rites {
+		for _, effect := range rite.Effects {
+			if effect.Type == RiteEffect_SummonAssistant && effect.Archetype == nil {
+				log.Error.Fatalf("Rite %s summons an assistant without an Archetype", symbol)
+			}
+			if effect.Type == RiteEffect_GrantFarmBonus && (effect.Bonus == nil || effect.Seconds <= 0) {
+				log.Error.Fatalf("Rite %s grants a farm bonus without a Bonus and Seconds", symbol)
+			}
+		}
+	}
 	
 	return rites
-}
\ No newline at end of file
+}
+
+// enum for rite effect types
+type RiteEffectType uint8
+const (
+	RiteEffect_SummonAssistant RiteEffectType = 0 // Summon a new assistant of Archetype at the farm
+	RiteEffect_HastenGrowth RiteEffectType = 1 // Reduce the growth time remaining on every planted plot on the farm by Seconds
+	RiteEffect_AddYield RiteEffectType = 2 // Add Yield to every planted plot on the farm
+	RiteEffect_GrantWares RiteEffectType = 3 // Add Wares to the farm's warehouse, with materials this converts goods
+	RiteEffect_GrantFarmBonus RiteEffectType = 4 // Grant the farm Bonus for Seconds
+)
+
+func (s RiteEffectType) String() string {
+	return riteEffectTypesToString[s]
+}
+
+var riteEffectTypesToString = map[RiteEffectType]string {
+	RiteEffect_SummonAssistant: "Summon Assistant",
+	RiteEffect_HastenGrowth: "Hasten Growth",
+	RiteEffect_AddYield: "Add Yield",
+	RiteEffect_GrantWares: "Grant Wares",
+	RiteEffect_GrantFarmBonus: "Grant Farm Bonus",
+}
+
+var riteEffectTypesToID = map[string]RiteEffectType {
+	"Summon Assistant": RiteEffect_SummonAssistant,
+	"Hasten Growth": RiteEffect_HastenGrowth,
+	"Add Yield": RiteEffect_AddYield,
+	"Grant Wares": RiteEffect_GrantWares,
+	"Grant Farm Bonus": RiteEffect_GrantFarmBonus,
+}
+
+// MarshalJSON marshals the enum as a quoted json string
+func (s RiteEffectType) MarshalJSON() ([]byte, error) {
+	buffer := bytes.NewBufferString(`"`)
+	buffer.WriteString(riteEffectTypesToString[s])
+	buffer.WriteString(`"`)
+	return buffer.Bytes(), nil
+}
+
+// UnmarshalJSON unmashals a quoted json string to the enum value
+func (s *RiteEffectType) UnmarshalJSON(b []byte) error {
+	var j string
+	err := json.Unmarshal(b, &j)
+	if err != nil {
+		return err
+	}
+	// Note that if the string cannot be found then it will be set to the zero value, 'Summon Assistant' in this case.
+	*s = riteEffectTypesToID[j]
+	return nil
+}
+
+// UnmarshalYAML unmashals a quoted yaml string to the enum value
+func (s *RiteEffectType) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var j string
+	if err := unmarshal(&j); err != nil {
+		return err
+	}
+	effectType, ok := riteEffectTypesToID[j]
+	if !ok {
+		return fmt.Errorf("unknown rite effect type %s", j)
+	}
+	*s = effectType
+	return nil
+}
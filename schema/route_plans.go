@@ -0,0 +1,157 @@
+// Package schema defines database and JSON schema as structs, as well as functions for creating and using these structs
+package schema
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+)
+
+// Defines one leg of a planned caravan route, either overland within an island or by ship from a port to its connected location
+type RouteLeg struct {
+	Origin string `json:"origin" binding:"required"`
+	Destination string `json:"destination" binding:"required"`
+	IsPortTravel bool `json:"is_port_travel" binding:"required"`
+	Duration int `json:"duration" binding:"required"` // seconds
+	Fare uint64 `json:"fare" binding:"required"`
+}
+
+// Defines a planned caravan route, duration and fare are summed over the legs
+type RoutePlan struct {
+	Legs []RouteLeg `json:"legs" binding:"required"`
+	Duration int `json:"duration" binding:"required"` // seconds
+	Fare uint64 `json:"fare" binding:"required"`
+}
+
+// Get a copy of the plan with leg appended
+func (p RoutePlan) extend(leg RouteLeg) RoutePlan {
+	legs := make([]RouteLeg, len(p.Legs), len(p.Legs) + 1)
+	copy(legs, p.Legs)
+	return RoutePlan{
+		Legs: append(legs, leg),
+		Duration: p.Duration + leg.Duration,
+		Fare: p.Fare + leg.Fare,
+	}
+}
+
+// Check if the plan is preferred over other given priority, ties are broken by the other measure then fewer legs
+func (p RoutePlan) betterThan(other RoutePlan, priority RoutePriority) bool {
+	if priority == Route_Cheapest && p.Fare != other.Fare {
+		return p.Fare < other.Fare
+	}
+	if p.Duration != other.Duration {
+		return p.Duration < other.Duration
+	}
+	if p.Fare != other.Fare {
+		return p.Fare < other.Fare
+	}
+	return len(p.Legs) < len(other.Legs)
+}
+
+// Calculate travel time in seconds overland between two locations on the same island
+func OverlandTravelTime(a Location, b Location, slowestSpeed int) int {
+	return int(math.Ceil(math.Sqrt(math.Pow(float64(b.X - a.X), 2) + math.Pow(float64(b.Y - a.Y), 2)))) * 10 / slowestSpeed
+}
+
+// Plan a caravan route between two known locations over the world graph, travelling overland within islands and by ship between ports
+// Returns the best plan given priority, and bool for if destination is reachable
+func PlanRoute(world World, origin string, destination string, slowestSpeed int, priority RoutePriority) (RoutePlan, bool) {
+	// Nodes are the origin, destination, and every port and connected location with a known location
+	nodes := map[string]Location{origin: world.Locations[origin], destination: world.Locations[destination]}
+	ports := make(map[string]Port)
+	for _, island := range world.Islands {
+		for _, port := range island.Ports {
+			portLoc, portOk := world.Locations[port.Symbol]
+			connectedLoc, connectedOk := world.Locations[port.ConnectedLocation]
+			if !portOk || !connectedOk {
+				// port leads to or from an island without charted locations
+				continue
+			}
+			nodes[port.Symbol] = portLoc
+			nodes[port.ConnectedLocation] = connectedLoc
+			ports[port.Symbol] = port
+		}
+	}
+
+	// Dijkstra's over the nodes, the graph is small enough to scan for the next node
+	best := map[string]RoutePlan{origin: {Legs: make([]RouteLeg, 0)}}
+	visited := make(map[string]bool)
+	for {
+		current := ""
+		for symbol, plan := range best {
+			if visited[symbol] {
+				continue
+			}
+			if current == "" || plan.betterThan(best[current], priority) || (!best[current].betterThan(plan, priority) && symbol < current) {
+				current = symbol
+			}
+		}
+		if current == "" {
+			return RoutePlan{}, false
+		}
+		if current == destination {
+			return best[current], true
+		}
+		visited[current] = true
+		currentLoc := nodes[current]
+		legs := make([]RouteLeg, 0)
+		for symbol, loc := range nodes {
+			if symbol != current && loc.IslandName == currentLoc.IslandName {
+				legs = append(legs, RouteLeg{Origin: current, Destination: symbol, Duration: OverlandTravelTime(currentLoc, loc, slowestSpeed)})
+			}
+		}
+		if port, ok := ports[current]; ok {
+			legs = append(legs, RouteLeg{Origin: current, Destination: port.ConnectedLocation, IsPortTravel: true, Duration: port.Duration, Fare: port.Fare})
+		}
+		for _, leg := range legs {
+			if visited[leg.Destination] {
+				continue
+			}
+			candidate := best[current].extend(leg)
+			if existing, ok := best[leg.Destination]; !ok || candidate.betterThan(existing, priority) {
+				best[leg.Destination] = candidate
+			}
+		}
+	}
+}
+
+// enum for route planning priorities
+type RoutePriority uint8
+const (
+	Route_Fastest RoutePriority = 0
+	Route_Cheapest RoutePriority = 1
+)
+
+func (s RoutePriority) String() string {
+	return routePriorityToString[s]
+}
+
+var routePriorityToString = map[RoutePriority]string {
+	Route_Fastest: "FASTEST",
+	Route_Cheapest: "CHEAPEST",
+}
+
+var routePriorityToID = map[string]RoutePriority {
+	"FASTEST": Route_Fastest,
+	"CHEAPEST": Route_Cheapest,
+}
+
+// MarshalJSON marshals the enum as a quoted json string
+func (s RoutePriority) MarshalJSON() ([]byte, error) {
+	buffer := bytes.NewBufferString(`"`)
+	buffer.WriteString(routePriorityToString[s])
+	buffer.WriteString(`"`)
+	return buffer.Bytes(), nil
+}
+
+// UnmarshalJSON unmashals a quoted json string to the enum value
+func (s *RoutePriority) UnmarshalJSON(b []byte) error {
+	var j string
+	err := json.Unmarshal(b, &j)
+	if err != nil {
+		return err
+	}
+	// Note that if the string cannot be found then it will be set to the zero value, 'FASTEST' in this case.
+	*s = routePriorityToID[j]
+	return nil
+}
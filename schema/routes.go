@@ -1,7 +1,12 @@
 // Package schema defines database and JSON schema as structs, as well as functions for creating and using these structs
 package schema
 
-// Defines a route
+import (
+	"apricate/timecalc"
+	"time"
+)
+
+// Defines a route, quoted for a team of assistants departing now
 type Route struct {
 	UUID string `json:"uuid" binding:"required"`
 	Name string `json:"name" binding:"required"`
@@ -9,4 +14,36 @@ type Route struct {
 	StartSymbol string `json:"start_symbol" binding:"required"`
 	EndSymbol string `json:"end_symbol" binding:"required"`
 	ArrivalTime int64 `json:"arrival_time" binding:"required"`
+	Assistants []int64 `json:"assistants" binding:"required"`
+	SlowestSpeed int `json:"slowest_speed" binding:"required"`
+	CarryCap uint64 `json:"carrying_capacity" binding:"required"` // including team factor
+	Fare uint64 `json:"fare" binding:"required"`
+	TravelTime int `json:"travel_time" binding:"required"` // seconds
+	Legs []RouteLeg `json:"legs" binding:"required"`
+}
+
+// Create a route quoted for the user's team, username is empty for previews quoted without a team
+func NewRoute(username string, timestamp time.Time, assistants []int64, slowestSpeed int, carryCap uint64, plan RoutePlan, start string, end string) *Route {
+	uuid := "Route-" + start + "-" + end
+	if username != "" {
+		uuid = username + "|" + uuid
+	}
+	isPortTravel := false
+	for _, leg := range plan.Legs {
+		isPortTravel = isPortTravel || leg.IsPortTravel
+	}
+	return &Route{
+		UUID: uuid,
+		Name: start + " to " + end,
+		IsPortTravel: isPortTravel,
+		StartSymbol: start,
+		EndSymbol: end,
+		ArrivalTime: timecalc.AddSecondsToTimestamp(timestamp, plan.Duration).Unix(),
+		Assistants: assistants,
+		SlowestSpeed: slowestSpeed,
+		CarryCap: carryCap,
+		Fare: plan.Fare,
+		TravelTime: plan.Duration,
+		Legs: plan.Legs,
+	}
 }
\ No newline at end of file
@@ -78,13 +78,9 @@ func (s ToolTypes) MarshalText() ([]byte, error) {
 
 // UnmarshalJSON unmashals a text string to the enum value
 func (s *ToolTypes) UnmarshalText(b []byte) error {
-	var j string
-	err := json.Unmarshal(b, &j)
-	if err != nil {
-		return err
-	}
+	// Text is unquoted (e.g. map keys), so look it up directly.
 	// Note that if the string cannot be found then it will be set to the zero value, 'Created' in this case.
-	*s = toolTypesToID[j]
+	*s = toolTypesToID[string(b)]
 	return nil
 }
 
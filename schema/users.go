@@ -19,6 +19,7 @@ type User struct {
 	Token string `json:"token" binding:"required"`
 	PublicInfo
 	Contracts []string `json:"contracts" binding:"required"`
+	ArchivedContracts []string `json:"archived_contracts" binding:"required"`
 	Assistants []string `json:"assistants" binding:"required"`
 	Caravans []string `json:"caravans" binding:"required"`
 	Farms []string `json:"farms" binding:"required"`
@@ -42,7 +43,7 @@ func ConvertFluxToDistortion(flux float64) float64 {
 	return math.Floor(math.Log10(flux) * 100) / 100
 }
 
-func NewUser(token string, username string, dbs map[string]rdb.Database, devUser bool) *User {
+func NewUser(token string, username string, timestamp time.Time, dbs map[string]rdb.InteractiveDB, devUser bool) *User {
 	// starting location
 	startLocation := "TS-PR-HF"
 	// generate starting assistant
@@ -100,15 +101,17 @@ func NewUser(token string, username string, dbs map[string]rdb.Database, devUser
 			Ledger: Ledger{
 				Currencies: starting_currencies,
 				Favor: starting_favor,
-				Escrow: make(map[string]uint64),
+				EscrowCoins: make(map[string]uint64),
+				EscrowWares: make(map[string]uint64),
 			},
 			ArcaneFlux: startingFlux,
 			DistortionTier: ConvertFluxToDistortion(startingFlux),
 			Achievements: []Achievement{Achievement_Noob},
-			UserSince: time.Now().Unix(),
+			UserSince: timestamp.Unix(),
 		},
 		LatticeInterferenceRejectionEnd: 0,
 		Contracts: []string{starting_contract_id},
+		ArchivedContracts: make([]string, 0),
 		Farms: []string{starting_farm_id},
 		Plots: plotIds,
 		Warehouses: []string{starting_farm_warehouse_id},
@@ -117,7 +120,7 @@ func NewUser(token string, username string, dbs map[string]rdb.Database, devUser
 	}
 }
 
-func PregenerateUser(username string, dbs map[string]rdb.Database, devuser bool) {
+func PregenerateUser(username string, timestamp time.Time, dbs map[string]rdb.InteractiveDB, devuser bool) {
 	// generate token
 	token, genTokenErr := tokengen.GenerateToken(username)
 	if genTokenErr != nil {
@@ -127,7 +130,7 @@ func PregenerateUser(username string, dbs map[string]rdb.Database, devuser bool)
 		panic(genErrorMsg)
 	}
 	// create new user in DB
-	newUser := NewUser(token, username, dbs, devuser)
+	newUser := NewUser(token, username, timestamp, dbs, devuser)
 	newUser.Title = Achievement_Owner
 	newUser.Achievements = []Achievement{Achievement_Owner, Achievement_Contributor, Achievement_Noob}
 	saveUserErr := SaveUserToDB(dbs["users"], newUser)
@@ -137,6 +140,7 @@ func PregenerateUser(username string, dbs map[string]rdb.Database, devuser bool)
 		log.Debug.Println(saveUserErrMsg)
 		panic(saveUserErrMsg)
 	}
+	TrackUserCoins(newUser.Username, newUser.Ledger.Currencies["Coins"])
 	// Write out my token
 	lines, readErr := filemngr.ReadFileToLineSlice("data/secrets.env")
 	if readErr != nil {
@@ -176,7 +180,7 @@ func PregenerateUser(username string, dbs map[string]rdb.Database, devuser bool)
 }
 
 // Check DB for existing user with given token and return bool for if exists, and error if error encountered
-func CheckForExistingUser (token string, tdb rdb.Database) (bool, error) {
+func CheckForExistingUser (token string, tdb rdb.InteractiveDB) (bool, error) {
 	// Get user
 	_, getError := tdb.GetJsonData(token, ".")
 	if getError != nil {
@@ -192,7 +196,7 @@ func CheckForExistingUser (token string, tdb rdb.Database) (bool, error) {
 }
 
 // Get user from DB, bool is user found
-func GetUserFromDB (token string, tdb rdb.Database) (User, bool, error) {
+func GetUserFromDB (token string, tdb rdb.InteractiveDB) (User, bool, error) {
 	// Get user json
 	someJson, getError := tdb.GetJsonData(token, ".")
 	if getError != nil {
@@ -214,7 +218,7 @@ func GetUserFromDB (token string, tdb rdb.Database) (User, bool, error) {
 }
 
 // Get userdata at path from DB, bool is user found
-func GetUserDataAtPathFromDB (token string, path string, tdb rdb.Database) (interface{}, bool, error) {
+func GetUserDataAtPathFromDB (token string, path string, tdb rdb.InteractiveDB) (interface{}, bool, error) {
 	// Get user json
 	someJson, getError := tdb.GetJsonData(token, path)
 	if getError != nil {
@@ -236,7 +240,7 @@ func GetUserDataAtPathFromDB (token string, path string, tdb rdb.Database) (inte
 }
 
 // Get user from DB by username, bool is user found
-func GetUserByUsernameFromDB(username string, tdb rdb.Database) (User, bool, error) {
+func GetUserByUsernameFromDB(username string, tdb rdb.InteractiveDB) (User, bool, error) {
 	token, tokenErr := tokengen.GenerateToken(username)
 	if tokenErr != nil {
 		return User{}, false, tokenErr
@@ -245,16 +249,15 @@ func GetUserByUsernameFromDB(username string, tdb rdb.Database) (User, bool, err
 }
 
 // Attempt to save user, returns error or nil if successful
-func SaveUserToDB(tdb rdb.Database, userData *User) error {
+func SaveUserToDB(tdb rdb.InteractiveDB, userData *User) error {
 	log.Debug.Printf("Saving user %s to DB", userData.Username)
-	TrackUserCoins(userData.Username, userData.Ledger.Currencies["Coins"])
 	err := tdb.SetJsonData(userData.Token, ".", userData)
 	// creationSuccess := rdb.CreateUser(tdb, username, token, 0)
 	return err
 }
 
 // Attempt to save user data at path, returns error or nil if successful
-func SaveUserDataAtPathToDB(tdb rdb.Database, token string, path string, newValue interface{}) error {
+func SaveUserDataAtPathToDB(tdb rdb.InteractiveDB, token string, path string, newValue interface{}) error {
 	log.Debug.Printf("Saving user data at path %s to DB for token %s", path, token)
 	err := tdb.SetJsonData(token, path, newValue)
 	return err
@@ -17,10 +17,14 @@ type Wareset struct {
 	Goods map[string]uint64 `yaml:"Goods" json:"goods,omitempty"`
 }
 
+// Capacity of warehouses at locations which do not set their own
+const BaseWarehouseCapacity uint64 = 500
+
 // Defines a warehouse
 type Warehouse struct {
 	UUID string `json:"uuid" binding:"required"`
 	LocationSymbol string `json:"location_symbol" binding:"required"`
+	Capacity uint64 `json:"capacity,omitempty"` // SHOULD BE STORED AS 0, ONLY FOR FORMATTING RESPONSE
 	Wareset
 }
 
@@ -41,23 +45,41 @@ func NewWarehouse(username string, locationSymbol string, starting_tools map[str
 	}
 }
 
-func (w *Warehouse) TotalSize() uint64 {
+func (ws *Wareset) TotalSize() uint64 {
 	size := uint64(0)
-	for _, q := range w.Goods {
+	for _, q := range ws.Goods {
 		size += q
 	}
-	for _, q := range w.Tools {
+	for _, q := range ws.Tools {
 		size += q
 	}
-	for _, q := range w.Produce {
+	for _, q := range ws.Produce {
 		size += q
 	}
-	for _, q := range w.Seeds {
+	for _, q := range ws.Seeds {
 		size += q
 	}
 	return size
 }
 
+// Get the capacity of a warehouse at location, raised by the buildings of a farm there
+func WarehouseCapacity(location Location, effects FarmEffects) uint64 {
+	capacity := location.WarehouseCapacity
+	if capacity == 0 {
+		capacity = BaseWarehouseCapacity
+	}
+	return capacity + effects.WarehouseCapacity
+}
+
+// Get the room left in the warehouse before reaching capacity
+func (w *Warehouse) FreeCapacity(capacity uint64) uint64 {
+	size := w.TotalSize()
+	if size >= capacity {
+		return 0
+	}
+	return capacity - size
+}
+
 func (w *Warehouse) AddTools(name string, quantity uint64) {
 	if w.Tools == nil || len(w.Tools) == 0 {
 		w.Tools = make(map[string]uint64)
@@ -123,7 +145,7 @@ func (w *Warehouse) RemoveGoods(name string, quantity uint64) {
 }
 
 // Check DB for existing warehouse with given uuid and return bool for if exists, and error if error encountered
-func CheckForExistingWarehouse (uuid string, tdb rdb.Database) (bool, error) {
+func CheckForExistingWarehouse (uuid string, tdb rdb.InteractiveDB) (bool, error) {
 	// Get warehouse
 	_, getError := tdb.GetJsonData(uuid, ".")
 	if getError != nil {
@@ -139,7 +161,7 @@ func CheckForExistingWarehouse (uuid string, tdb rdb.Database) (bool, error) {
 }
 
 // Get warehouse from DB, bool is warehouse found
-func GetWarehouseFromDB (uuid string, tdb rdb.Database) (Warehouse, bool, error) {
+func GetWarehouseFromDB (uuid string, tdb rdb.InteractiveDB) (Warehouse, bool, error) {
 	// Get warehouse json
 	someJson, getError := tdb.GetJsonData(uuid, ".")
 	if getError != nil {
@@ -161,7 +183,7 @@ func GetWarehouseFromDB (uuid string, tdb rdb.Database) (Warehouse, bool, error)
 }
 
 // Get warehouse from DB, bool is warehouse found
-func GetWarehousesFromDB (uuids []string, tdb rdb.Database) ([]Warehouse, bool, error) {
+func GetWarehousesFromDB (uuids []string, tdb rdb.InteractiveDB) ([]Warehouse, bool, error) {
 	// Get warehouse json
 	someJson, getError := tdb.MGetJsonData(".", uuids)
 	if getError != nil {
@@ -188,7 +210,7 @@ func GetWarehousesFromDB (uuids []string, tdb rdb.Database) ([]Warehouse, bool,
 }
 
 // Get warehousedata at path from DB, bool is warehouse found
-func GetWarehouseDataAtPathFromDB (uuid string, path string, tdb rdb.Database) (interface{}, bool, error) {
+func GetWarehouseDataAtPathFromDB (uuid string, path string, tdb rdb.InteractiveDB) (interface{}, bool, error) {
 	// Get warehouse json
 	someJson, getError := tdb.GetJsonData(uuid, path)
 	if getError != nil {
@@ -210,7 +232,7 @@ func GetWarehouseDataAtPathFromDB (uuid string, path string, tdb rdb.Database) (
 }
 
 // Attempt to save warehouse, returns error or nil if successful
-func SaveWarehouseToDB(tdb rdb.Database, warehouseData *Warehouse) error {
+func SaveWarehouseToDB(tdb rdb.InteractiveDB, warehouseData *Warehouse) error {
 	log.Debug.Printf("Saving warehouse %s to DB", warehouseData.UUID)
 	err := tdb.SetJsonData(warehouseData.UUID, ".", warehouseData)
 	// creationSuccess := rdb.CreateWarehouse(tdb, warehousename, uuid, 0)
@@ -218,16 +240,105 @@ func SaveWarehouseToDB(tdb rdb.Database, warehouseData *Warehouse) error {
 }
 
 // Attempt to save warehouse data at path, returns error or nil if successful
-func SaveWarehouseDataAtPathToDB(tdb rdb.Database, uuid string, path string, newValue interface{}) error {
+func SaveWarehouseDataAtPathToDB(tdb rdb.InteractiveDB, uuid string, path string, newValue interface{}) error {
 	log.Debug.Printf("Saving warehouse data at path %s to DB for uuid %s", path, uuid)
 	err := tdb.SetJsonData(uuid, path, newValue)
 	return err
 }
 
 // Attempt to delete warehouse, returns error or nil if successful
-func DeleteWarehouseFromDB(tdb rdb.Database, uuid string) error {
+func DeleteWarehouseFromDB(tdb rdb.InteractiveDB, uuid string) error {
 	log.Debug.Printf("Saving warehouse %s to DB", uuid)
 	_, err := tdb.DelJsonData(uuid, ".")
 	// creationSuccess := rdb.CreateWarehouse(tdb, warehousename, uuid, 0)
 	return err
-}
\ No newline at end of file
+}
+
+// Get quantity of the named item in the given category
+func (w *Warehouse) GetItemQuantity(category ItemCategory, name string) uint64 {
+	switch category {
+	case GOOD:
+		return w.Goods[name]
+	case SEED:
+		return w.Seeds[name]
+	case PRODUCE:
+		return w.Produce[name]
+	case TOOL:
+		return w.Tools[name]
+	}
+	return 0
+}
+
+// Add the named item to the given category
+func (w *Warehouse) AddItem(category ItemCategory, name string, quantity uint64) {
+	switch category {
+	case GOOD:
+		w.AddGoods(name, quantity)
+	case SEED:
+		w.AddSeeds(name, quantity)
+	case PRODUCE:
+		w.AddProduce(name, quantity)
+	case TOOL:
+		w.AddTools(name, quantity)
+	}
+}
+
+// Remove the named item from the given category
+func (w *Warehouse) RemoveItem(category ItemCategory, name string, quantity uint64) {
+	switch category {
+	case GOOD:
+		w.RemoveGoods(name, quantity)
+	case SEED:
+		w.RemoveSeeds(name, quantity)
+	case PRODUCE:
+		w.RemoveProduce(name, quantity)
+	case TOOL:
+		w.RemoveTools(name, quantity)
+	}
+}
+
+// Get the category and name of the first item in wares not held in sufficient quantity, bool is any missing
+func (w *Warehouse) MissingWares(wares Wareset) (ItemCategory, string, bool) {
+	categories := map[ItemCategory]map[string]uint64{GOOD: wares.Goods, SEED: wares.Seeds, PRODUCE: wares.Produce, TOOL: wares.Tools}
+	for category, items := range categories {
+		for name, quantity := range items {
+			if w.GetItemQuantity(category, name) < quantity {
+				return category, name, true
+			}
+		}
+	}
+	return GOOD, "", false
+}
+
+// Remove all of wares, which must be held
+func (w *Warehouse) RemoveWares(wares Wareset) {
+	categories := map[ItemCategory]map[string]uint64{GOOD: wares.Goods, SEED: wares.Seeds, PRODUCE: wares.Produce, TOOL: wares.Tools}
+	for category, items := range categories {
+		for name, quantity := range items {
+			w.RemoveItem(category, name, quantity)
+		}
+	}
+}
+
+// Remove quantity of the named item across warehouses, taking from each in order until quantity is met.
+// Removes nothing and returns false if the warehouses hold less than quantity in total
+func RemoveItemAcrossWarehouses(warehouses []*Warehouse, category ItemCategory, name string, quantity uint64) bool {
+	held := uint64(0)
+	for _, w := range warehouses {
+		held += w.GetItemQuantity(category, name)
+	}
+	if held < quantity {
+		return false
+	}
+	for _, w := range warehouses {
+		take := w.GetItemQuantity(category, name)
+		if take > quantity {
+			take = quantity
+		}
+		if take > 0 {
+			w.RemoveItem(category, name, take)
+			quantity -= take
+		}
+	}
+	return true
+}
@@ -0,0 +1,176 @@
+// Package schema defines database and JSON schema as structs, as well as functions for creating and using these structs
+package schema
+
+import (
+	"apricate/log"
+	"apricate/rdb"
+	"encoding/json"
+	"fmt"
+)
+
+// Maximum webhooks a user may register
+const MaxWebhooksPerUser int = 5
+
+// Deliveries kept in each user's delivery log, oldest are dropped first
+const WebhookDeliveryLogSize int = 50
+
+// Defines a url events are delivered to, an empty events list subscribes to every event type
+type Webhook struct {
+	ID uint64 `json:"id" binding:"required"`
+	URL string `json:"url" binding:"required"`
+	Events []EventType `json:"events" binding:"required"`
+	CreatedAt int64 `json:"created_at" binding:"required"`
+}
+
+// Defines the body of a webhook registration request
+type WebhookRegistration struct {
+	URL string `json:"url" binding:"required"`
+	Events []string `json:"events,omitempty"`
+}
+
+// Check if the webhook is subscribed to eventType
+func (w *Webhook) Wants(eventType EventType) bool {
+	if len(w.Events) == 0 {
+		return true
+	}
+	for _, e := range w.Events {
+		if e == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// Defines a user's webhooks and the secret their deliveries are signed with
+type WebhookRegistry struct {
+	UUID string `json:"uuid" binding:"required"`
+	Secret string `json:"secret" binding:"required"`
+	Registered uint64 `json:"registered" binding:"required"` // count of webhooks ever registered, used for ids
+	Webhooks []Webhook `json:"webhooks" binding:"required"`
+}
+
+func NewWebhookRegistry(username string, secret string) *WebhookRegistry {
+	return &WebhookRegistry{
+		UUID: username + "|Webhooks",
+		Secret: secret,
+		Registered: 0,
+		Webhooks: make([]Webhook, 0),
+	}
+}
+
+// Register a webhook for url, returning it
+func (r *WebhookRegistry) Add(url string, events []EventType, timestamp int64) Webhook {
+	webhook := Webhook{
+		ID: r.Registered,
+		URL: url,
+		Events: events,
+		CreatedAt: timestamp,
+	}
+	r.Registered++
+	r.Webhooks = append(r.Webhooks, webhook)
+	return webhook
+}
+
+// Remove the webhook with id, bool is webhook found
+func (r *WebhookRegistry) Remove(id uint64) (Webhook, bool) {
+	for i, webhook := range r.Webhooks {
+		if webhook.ID == id {
+			r.Webhooks = append(r.Webhooks[:i], r.Webhooks[i+1:]...)
+			return webhook, true
+		}
+	}
+	return Webhook{}, false
+}
+
+// Defines the outcome of delivering an event to a webhook
+type WebhookDelivery struct {
+	UUID string `json:"uuid" binding:"required"`
+	WebhookID uint64 `json:"webhook_id" binding:"required"`
+	URL string `json:"url" binding:"required"`
+	Event Event `json:"event" binding:"required"`
+	Attempts int `json:"attempts" binding:"required"`
+	StatusCode int `json:"status_code" binding:"required"` // of the last attempt, 0 if no response
+	Success bool `json:"success" binding:"required"`
+	Error string `json:"error,omitempty"` // of the last attempt
+	StartedAt int64 `json:"started_at" binding:"required"`
+	CompletedAt int64 `json:"completed_at" binding:"required"`
+}
+
+// Defines a user's most recent webhook deliveries, newest first
+type WebhookDeliveryLog struct {
+	UUID string `json:"uuid" binding:"required"`
+	Deliveries []WebhookDelivery `json:"deliveries" binding:"required"`
+}
+
+func NewWebhookDeliveryLog(username string) *WebhookDeliveryLog {
+	return &WebhookDeliveryLog{
+		UUID: username + "|WebhookDeliveries",
+		Deliveries: make([]WebhookDelivery, 0),
+	}
+}
+
+// Add delivery to the front of the log, dropping the oldest past WebhookDeliveryLogSize
+func (l *WebhookDeliveryLog) Record(delivery WebhookDelivery) {
+	l.Deliveries = append([]WebhookDelivery{delivery}, l.Deliveries...)
+	if len(l.Deliveries) > WebhookDeliveryLogSize {
+		l.Deliveries = l.Deliveries[:WebhookDeliveryLogSize]
+	}
+}
+
+// Get webhook registry from DB, bool is webhook registry found
+func GetWebhookRegistryFromDB (username string, tdb rdb.InteractiveDB) (WebhookRegistry, bool, error) {
+	// Get webhook registry json
+	someJson, getError := tdb.GetJsonData(username + "|Webhooks", ".")
+	if getError != nil {
+		if fmt.Sprint(getError) == "redis: nil" {
+			// webhook registry not found
+			return WebhookRegistry{}, false, nil
+		}
+		// error
+		return WebhookRegistry{}, false, getError
+	}
+	// Got successfully, unmarshal
+	someData := WebhookRegistry{}
+	unmarshalErr := json.Unmarshal(someJson, &someData)
+	if unmarshalErr != nil {
+		log.Error.Fatalf("Could not unmarshal webhook registry json from DB: %v", unmarshalErr)
+		return WebhookRegistry{}, false, unmarshalErr
+	}
+	return someData, true, nil
+}
+
+// Attempt to save webhook registry, returns error or nil if successful
+func SaveWebhookRegistryToDB(tdb rdb.InteractiveDB, webhookRegistryData *WebhookRegistry) error {
+	log.Debug.Printf("Saving webhook registry %s to DB", webhookRegistryData.UUID)
+	err := tdb.SetJsonData(webhookRegistryData.UUID, ".", webhookRegistryData)
+	return err
+}
+
+// Get webhook delivery log from DB, bool is webhook delivery log found
+func GetWebhookDeliveryLogFromDB (username string, tdb rdb.InteractiveDB) (WebhookDeliveryLog, bool, error) {
+	// Get webhook delivery log json
+	someJson, getError := tdb.GetJsonData(username + "|WebhookDeliveries", ".")
+	if getError != nil {
+		if fmt.Sprint(getError) == "redis: nil" {
+			// webhook delivery log not found
+			return WebhookDeliveryLog{}, false, nil
+		}
+		// error
+		return WebhookDeliveryLog{}, false, getError
+	}
+	// Got successfully, unmarshal
+	someData := WebhookDeliveryLog{}
+	unmarshalErr := json.Unmarshal(someJson, &someData)
+	if unmarshalErr != nil {
+		log.Error.Fatalf("Could not unmarshal webhook delivery log json from DB: %v", unmarshalErr)
+		return WebhookDeliveryLog{}, false, unmarshalErr
+	}
+	return someData, true, nil
+}
+
+// Attempt to save webhook delivery log, returns error or nil if successful
+func SaveWebhookDeliveryLogToDB(tdb rdb.InteractiveDB, webhookDeliveryLogData *WebhookDeliveryLog) error {
+	log.Debug.Printf("Saving webhook delivery log %s to DB", webhookDeliveryLogData.UUID)
+	err := tdb.SetJsonData(webhookDeliveryLogData.UUID, ".", webhookDeliveryLogData)
+	return err
+}
@@ -0,0 +1,68 @@
+// Package timecalc provides helper functions for working with timestamps
+package timecalc
+
+import (
+	"sync"
+	"time"
+)
+
+// Defines a source of the current game time, so timers can be faked in tests or accelerated for events
+type Clock interface {
+	Now() time.Time
+}
+
+// Clock reading the system wall clock
+type SystemClock struct{}
+
+func (c SystemClock) Now() time.Time {
+	return time.Now()
+}
+
+// Clock running Speed times faster than the system clock from Epoch, for speed worlds.
+// Game time restarts from the system time if the server is restarted with a new epoch
+type SpeedClock struct {
+	Epoch time.Time
+	Speed float64
+}
+
+// Create a speed clock running from epoch, which should be kept across restarts so game time continues where it left off
+func NewSpeedClock(speed float64, epoch time.Time) *SpeedClock {
+	return &SpeedClock{
+		Epoch: epoch,
+		Speed: speed,
+	}
+}
+
+func (c *SpeedClock) Now() time.Time {
+	elapsed := time.Since(c.Epoch)
+	return c.Epoch.Add(time.Duration(float64(elapsed) * c.Speed))
+}
+
+// Clock which only moves when advanced, for tests and simulations
+type FakeClock struct {
+	mu sync.Mutex
+	now time.Time
+}
+
+// Create a fake clock stopped at start
+func NewFakeClock(start time.Time) *FakeClock {
+	return &FakeClock{now: start}
+}
+
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// Move the fake clock forward by duration
+func (c *FakeClock) Advance(duration time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(duration)
+}
+
+// Move the fake clock forward by seconds
+func (c *FakeClock) AdvanceSeconds(seconds int64) {
+	c.Advance(time.Second * time.Duration(seconds))
+}
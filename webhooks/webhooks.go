@@ -0,0 +1,227 @@
+// Package webhooks delivers game events to the urls players have registered, signed with each player's webhook secret
+package webhooks
+
+import (
+	"apricate/log"
+	"apricate/rdb"
+	"apricate/schema"
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// Headers sent with each delivery
+const (
+	EventHeader = "X-Apricate-Event"
+	DeliveryHeader = "X-Apricate-Delivery"
+	SignatureHeader = "X-Apricate-Signature"
+)
+
+// Attempts made to deliver each event before it is logged as failed
+var MaxAttempts int = 5
+
+// Wait before the first retry of a failed delivery, doubled after each further failure
+var RetryBackoff = 2 * time.Second
+
+// Timeout for each delivery attempt
+var DeliveryTimeout = 10 * time.Second
+
+// Attempts made to record a delivery when the log is written concurrently
+const logTransactionAttempts int = 5
+
+// Defines a dispatcher delivering events to the webhooks stored in the webhooks db
+type Dispatcher struct {
+	Dbs *map[string]rdb.InteractiveDB
+	Client *http.Client
+}
+
+// IPs or CIDR ranges deliveries may reach even though they are loopback, private, link-local or unspecified, set from config
+var AllowedNetworks []*net.IPNet
+
+// Parse a comma separated list of IPs and CIDR ranges, such as 127.0.0.1,10.0.0.0/8, into networks for AllowedNetworks
+func ParseAllowedNetworks(value string) ([]*net.IPNet, error) {
+	networks := make([]*net.IPNet, 0)
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if !strings.Contains(entry, "/") {
+			ip := net.ParseIP(entry)
+			if ip == nil {
+				return nil, fmt.Errorf("%s is not an IP or CIDR range", entry)
+			}
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip = ip.To4()
+				bits = 8 * net.IPv4len
+			}
+			networks = append(networks, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, network, parseErr := net.ParseCIDR(entry)
+		if parseErr != nil {
+			return nil, fmt.Errorf("%s is not an IP or CIDR range", entry)
+		}
+		networks = append(networks, network)
+	}
+	return networks, nil
+}
+
+// Check whether deliveries may connect to ip, rejecting internal addresses that are not in AllowedNetworks
+func allowedIP(ip net.IP) bool {
+	for _, network := range AllowedNetworks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return !(ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() || ip.IsUnspecified())
+}
+
+// Reject connections to internal addresses, run after DNS resolution so hostnames resolving to internal IPs are caught too
+func checkDialAddress(network string, address string, _ syscall.RawConn) error {
+	host, _, splitErr := net.SplitHostPort(address)
+	if splitErr != nil {
+		return splitErr
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || !allowedIP(ip) {
+		return fmt.Errorf("webhook deliveries may not connect to internal address %s", host)
+	}
+	return nil
+}
+
+func NewDispatcher(dbs *map[string]rdb.InteractiveDB) *Dispatcher {
+	dialer := &net.Dialer{Timeout: DeliveryTimeout, Control: checkDialAddress}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil // a proxy would connect on our behalf, bypassing the address check
+	transport.DialContext = dialer.DialContext
+	return &Dispatcher{
+		Dbs: dbs,
+		Client: &http.Client{
+			Timeout: DeliveryTimeout,
+			Transport: transport,
+			// Redirects are not followed, so receivers cannot bounce deliveries to internal urls
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+	}
+}
+
+// Sign body with secret, receivers verify deliveries by comparing this against the signature header
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Deliver event to each of username's webhooks subscribed to it, in the background
+func (d *Dispatcher) Dispatch(username string, event schema.Event) {
+	go d.dispatch(username, event)
+}
+
+func (d *Dispatcher) dispatch(username string, event schema.Event) {
+	registry, foundRegistry, registryErr := schema.GetWebhookRegistryFromDB(username, (*d.Dbs)["webhooks"])
+	if registryErr != nil {
+		log.Error.Printf("Error in Dispatch, could not get webhook registry from DB. error: %v", registryErr)
+		return
+	}
+	if !foundRegistry {
+		return // No webhooks registered
+	}
+	body, jsonErr := json.Marshal(event)
+	if jsonErr != nil {
+		log.Error.Printf("Error in Dispatch, could not format event as JSON. event: %v, error: %v", event, jsonErr)
+		return
+	}
+	for _, webhook := range registry.Webhooks {
+		if webhook.Wants(event.Type) {
+			go d.deliver(username, registry.Secret, webhook, event, body)
+		}
+	}
+}
+
+// Post body to webhook, retrying with backoff until it succeeds or MaxAttempts is reached, then record the outcome
+func (d *Dispatcher) deliver(username string, secret string, webhook schema.Webhook, event schema.Event, body []byte) {
+	// Delivery times are real time, as retries wait on the real clock regardless of game speed
+	startedAt := time.Now()
+	delivery := schema.WebhookDelivery{
+		UUID: fmt.Sprintf("%s|Webhook-%d|Delivery-%d", username, webhook.ID, startedAt.UnixNano()),
+		WebhookID: webhook.ID,
+		URL: webhook.URL,
+		Event: event,
+		StartedAt: startedAt.Unix(),
+	}
+	backoff := RetryBackoff
+	for attempt := 1; attempt <= MaxAttempts; attempt++ {
+		delivery.Attempts = attempt
+		statusCode, postErr := d.post(webhook.URL, secret, delivery.UUID, event, body)
+		delivery.StatusCode = statusCode
+		if postErr == nil {
+			delivery.Success = true
+			delivery.Error = ""
+			break
+		}
+		delivery.Error = postErr.Error()
+		log.Debug.Printf("Webhook delivery %s attempt %d of %d failed: %v", delivery.UUID, attempt, MaxAttempts, postErr)
+		if attempt < MaxAttempts {
+			time.Sleep(backoff)
+			backoff *= 2
+		}
+	}
+	delivery.CompletedAt = time.Now().Unix()
+	if !delivery.Success {
+		log.Important.Printf("Webhook delivery %s to %s failed after %d attempts: %s", delivery.UUID, webhook.URL, delivery.Attempts, delivery.Error)
+	}
+	d.record(username, delivery)
+}
+
+// Send a single delivery attempt, any non 2xx status is an error
+func (d *Dispatcher) post(url string, secret string, deliveryUUID string, event schema.Event, body []byte) (int, error) {
+	req, reqErr := http.NewRequest("POST", url, bytes.NewReader(body))
+	if reqErr != nil {
+		return 0, reqErr
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, event.Type.String())
+	req.Header.Set(DeliveryHeader, deliveryUUID)
+	req.Header.Set(SignatureHeader, Sign(secret, body))
+	res, postErr := d.Client.Do(req)
+	if postErr != nil {
+		return 0, postErr
+	}
+	defer res.Body.Close()
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return res.StatusCode, fmt.Errorf("receiver responded with status %d", res.StatusCode)
+	}
+	return res.StatusCode, nil
+}
+
+// Add delivery to username's delivery log
+func (d *Dispatcher) record(username string, delivery schema.WebhookDelivery) {
+	wdb := (*d.Dbs)["webhooks"]
+	txErr := wdb.Transact(logTransactionAttempts, func(tx rdb.Tx) error {
+		txdb := tx.Bind(wdb)
+		deliveryLog, foundLog, logErr := schema.GetWebhookDeliveryLogFromDB(username, txdb)
+		if logErr != nil {
+			return logErr
+		}
+		if !foundLog {
+			deliveryLog = *schema.NewWebhookDeliveryLog(username)
+		}
+		deliveryLog.Record(delivery)
+		return schema.SaveWebhookDeliveryLogToDB(txdb, &deliveryLog)
+	})
+	if txErr != nil {
+		log.Error.Printf("Error in Dispatch, could not record webhook delivery %s. error: %v", delivery.UUID, txErr)
+	}
+}
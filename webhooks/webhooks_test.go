@@ -0,0 +1,75 @@
+package webhooks
+
+import (
+	"net"
+	"reflect"
+	"testing"
+)
+
+// Allowed networks are read from configuration as comma separated IPs and CIDR ranges, a bare IP allowing only itself
+func TestParseAllowedNetworks(t *testing.T) {
+	tests := []struct {
+		value string
+		want []string
+		wantErr bool
+	}{
+		{"", []string{}, false},
+		{"127.0.0.1", []string{"127.0.0.1/32"}, false},
+		{"::1", []string{"::1/128"}, false},
+		{"127.0.0.1, 10.0.0.0/8,,fd00::/8 ", []string{"127.0.0.1/32", "10.0.0.0/8", "fd00::/8"}, false},
+		{"10.1.2.3/8", []string{"10.0.0.0/8"}, false},
+		{"localhost", nil, true},
+		{"127.0.0.1,10.0.0.0/33", nil, true},
+	}
+	for _, test := range tests {
+		t.Run(test.value, func(t *testing.T) {
+			networks, err := ParseAllowedNetworks(test.value)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("got err %v, want err %t", err, test.wantErr)
+			}
+			if test.wantErr {
+				return
+			}
+			got := make([]string, 0)
+			for _, network := range networks {
+				got = append(got, network.String())
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("got %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+// Deliveries may not reach internal addresses unless an allowed network contains them
+func TestAllowedIP(t *testing.T) {
+	allowed, parseErr := ParseAllowedNetworks("127.0.0.1,10.0.0.0/8")
+	if parseErr != nil {
+		t.Fatalf("could not parse allowed networks: %v", parseErr)
+	}
+	defer func(networks []*net.IPNet) { AllowedNetworks = networks }(AllowedNetworks)
+	tests := []struct {
+		ip string
+		allowed []*net.IPNet
+		want bool
+	}{
+		{"93.184.216.34", nil, true},
+		{"2606:2800:220:1::", nil, true},
+		{"127.0.0.1", nil, false},
+		{"::1", nil, false},
+		{"10.0.0.1", nil, false},
+		{"192.168.1.1", nil, false},
+		{"169.254.169.254", nil, false},
+		{"0.0.0.0", nil, false},
+		{"127.0.0.1", allowed, true},
+		{"127.0.0.2", allowed, false},
+		{"10.200.0.1", allowed, true},
+		{"192.168.1.1", allowed, false},
+	}
+	for _, test := range tests {
+		AllowedNetworks = test.allowed
+		if got := allowedIP(net.ParseIP(test.ip)); got != test.want {
+			t.Errorf("got %t for %s with allowed %v, want %t", got, test.ip, test.allowed, test.want)
+		}
+	}
+}